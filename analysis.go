@@ -0,0 +1,774 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/jonlawlor/parsefloat"
+)
+
+// analysisQuery describes how to group and fit the benchmark data for the
+// analysis endpoints, like /predict.  It is parsed from the querystring, which
+// carries the same settings as the plotter.
+type analysisQuery struct {
+	grouping
+	xTransform []parsefloat.Expression
+	yVar       string
+	fitter     estimator
+}
+
+// parseGrouping parses how to group the benchmarks from a querystring.
+// Missing parameters take the same defaults as the plotter.
+func parseGrouping(v url.Values) (grouping, error) {
+	var g grouping
+
+	nreValue := v.Get("nre")
+	if nreValue == "" {
+		nreValue = defaultNRE
+	}
+	nre, err := compileNRE(nreValue)
+	if err != nil {
+		return g, fmt.Errorf("invalid nre: %v", err)
+	}
+	g.nre = nre
+
+	g.unparameterized = v.Get("unparameterized")
+	switch g.unparameterized {
+	case "":
+		g.unparameterized = unparamTable
+	case unparamTable, unparamOne:
+	default:
+		return g, fmt.Errorf("invalid unparameterized: %s", g.unparameterized)
+	}
+
+	g.only = v.Get("group")
+	if g.filter, err = compileFilter(v.Get("filter"), v.Get("filterre") == "true"); err != nil {
+		return g, err
+	}
+	g.pkg = v.Get("pkg")
+	g.byPackage = v.Get("bypkg") == "true"
+	g.machine = v.Get("machine")
+	g.byMachine = v.Get("bymachine") == "true"
+	g.yVar = v.Get("yvar")
+	switch byFile := v.Get("byfile"); byFile {
+	case "", "false":
+		g.byFile = byFileNever
+	case "true":
+		g.byFile = byFileAlways
+	case byFileAuto:
+		g.byFile = byFileAuto
+	default:
+		return g, fmt.Errorf("invalid byfile: %s", byFile)
+	}
+	for _, path := range v["hide"] {
+		if g.hidden == nil {
+			g.hidden = make(map[string]bool)
+		}
+		g.hidden[path] = true
+	}
+	for _, group := range v["hidegroup"] {
+		if g.hiddenGroups == nil {
+			g.hiddenGroups = make(map[string]bool)
+		}
+		g.hiddenGroups[group] = true
+	}
+
+	g.cluster = v.Get("cluster")
+	switch g.cluster {
+	case clusterNone, clusterFile, clusterMachine:
+	default:
+		return g, fmt.Errorf("invalid cluster: %s", g.cluster)
+	}
+
+	g.aggregate = v.Get("aggregate")
+	switch g.aggregate {
+	case "":
+		g.aggregate = aggregateAll
+	case aggregateAll, aggregateMean, aggregateMedian, aggregateMin:
+	default:
+		return g, fmt.Errorf("invalid aggregate: %s", g.aggregate)
+	}
+	return g, nil
+}
+
+// compileFilter compiles the filter of the groups, which matches the groups
+// containing it, ignoring case, or which match it if it's a regexp.  It
+// returns nil if there isn't a filter.  The plotter has a copy of this, in
+// compileFilter.
+func compileFilter(filter string, isRegexp bool) (*regexp.Regexp, error) {
+	if filter == "" {
+		return nil, nil
+	}
+	if !isRegexp {
+		filter = "(?i)" + regexp.QuoteMeta(filter)
+	}
+	re, err := regexp.Compile(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %v", err)
+	}
+	return re, nil
+}
+
+// parseAnalysisQuery parses the grouping and fitting parameters from a
+// querystring.  Missing parameters take the same defaults as the plotter.
+func parseAnalysisQuery(v url.Values) (analysisQuery, error) {
+	var q analysisQuery
+	var err error
+	if q.grouping, err = parseGrouping(v); err != nil {
+		return q, err
+	}
+
+	xTransformValue, err := modelValue(v, defaultXTransform)
+	if err != nil {
+		return q, err
+	}
+	if q.xTransform, err = parseTransform(xTransformValue); err != nil {
+		return q, err
+	}
+
+	q.yVar = v.Get("yvar")
+	if q.yVar == "" {
+		q.yVar = "NsPerOp"
+	}
+	if _, ok := validYs[q.yVar]; !ok {
+		return q, fmt.Errorf("invalid yvar: %s", q.yVar)
+	}
+	q.fitter, err = parseFitter(v)
+	return q, err
+}
+
+// groupFit is the least squares fit of a single group of benchmarks.
+type groupFit struct {
+	Group string
+
+	xTransform []parsefloat.Expression
+	fitter     estimator
+	beta       model
+	r2, mse    float64
+	bint       []float64
+	iXTX       *mat64.Dense
+	dof        int // of the residuals
+	cdof       int // of the confidence intervals, which is fewer if they are clustered
+
+	// benchmarks that were fit, and their range
+	benchSet   []benchmarkResponse
+	xMin, xMax float64
+
+	// N of the benchmarks that were left out because a term wasn't finite
+	nonFinite []float64
+}
+
+// fitGroups fits every group in the data set which can be fit, in order of
+// group name.
+func (q analysisQuery) fitGroups(ds dataSet) []groupFit {
+	fits, _ := q.fitGroupsContext(context.Background(), ds)
+	return fits
+}
+
+// fitGroupsContext is fitGroups, which stops early with the error of ctx if
+// it is done.
+func (q analysisQuery) fitGroupsContext(ctx context.Context, ds dataSet) ([]groupFit, error) {
+	groups, _, _, _ := groupBenchmarks(ds, q.grouping)
+	var fits []groupFit
+	for group, benchSet := range groups {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !canFit(benchSet, len(q.xTransform)) {
+			continue
+		}
+		if gf, ok := fitGroup(ctx, group, benchSet, q.xTransform, q.yVar, q.fitter); ok {
+			fits = append(fits, gf)
+		}
+	}
+	sort.Sort(byGroup(fits))
+	return fits, ctx.Err()
+}
+
+type byGroup []groupFit
+
+func (a byGroup) Len() int           { return len(a) }
+func (a byGroup) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byGroup) Less(i, j int) bool { return a[i].Group < a[j].Group }
+
+// fitGroup fits a single group of benchmarks.  It returns false if the
+// estimate could not be found.
+func fitGroup(ctx context.Context, group string, benchSet []benchmarkResponse, xTransform []parsefloat.Expression, yVar string, fitter estimator) (groupFit, bool) {
+	xTransform, _, err := resolveK(ctx, benchSet, xTransform, yVar, fitter)
+	if err != nil {
+		return groupFit{}, false
+	}
+	benchSet, nonFinite := finiteTerms(benchSet, xTransform)
+	if !canFit(benchSet, len(xTransform)) {
+		return groupFit{}, false
+	}
+	samp := sampleGroup(benchSet, xTransform, yVar)
+	beta, st, err := fitter.Fit(samp)
+	if err != nil || ctx.Err() != nil {
+		return groupFit{}, false
+	}
+	gf := groupFit{
+		Group:      group,
+		xTransform: xTransform,
+		fitter:     fitter,
+		beta:       beta,
+		dof:        len(benchSet) - len(xTransform),
+		cdof:       len(benchSet) - len(xTransform),
+		benchSet:   benchSet,
+		nonFinite:  nonFinite,
+		xMin:       math.Inf(1),
+		xMax:       math.Inf(-1),
+	}
+	if c := benchClusters(benchSet); c != nil {
+		if cdof, ok := st.cluster(samp, beta, c); ok {
+			gf.cdof = cdof
+		}
+	}
+	gf.r2, gf.mse, gf.bint, gf.iXTX = st.r2, st.mse, st.cint, st.iXTX
+	for _, b := range benchSet {
+		gf.xMin = math.Min(gf.xMin, b.X)
+		gf.xMax = math.Max(gf.xMax, b.X)
+	}
+	return gf, true
+}
+
+// at returns the fitted value at x, and the variance of the fit there, divided
+// by the mse.
+func (gf groupFit) at(x float64) (yhat, v float64) {
+	xi := evaluate(gf.xTransform, []float64{x}).RowView(0)
+	for j, b := range gf.beta {
+		yhat += b * xi.At(j, 0)
+	}
+	return yhat, mat64.Inner(xi, gf.iXTX, xi)
+}
+
+// predict returns the fitted value at x, along with the half width of its 95%
+// prediction interval, which accounts for the noise in a single new benchmark
+// as well as the uncertainty in the fit.
+func (gf groupFit) predict(x float64) (yhat, width float64) {
+	yhat, v := gf.at(x)
+	return yhat, conf95(math.Sqrt(gf.mse*(1+v)), gf.cdof)
+}
+
+// prediction is the estimated response of a group at a given N.
+type prediction struct {
+	Group        string
+	N            float64
+	Yhat         float64
+	PredWidth    float64 // half width of the 95% prediction interval
+	Extrapolated bool    // N is outside of the range of the benchmarks
+}
+
+// servePredictions returns a handler which predicts the response of every
+// group at each N given in the querystring.
+func servePredictions(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q, err := parseAnalysisQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// n can be repeated, to predict at several N at once
+		if len(r.Form["n"]) == 0 {
+			http.Error(w, "invalid n: ", http.StatusBadRequest)
+			return
+		}
+		var ns []float64
+		for _, v := range r.Form["n"] {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				http.Error(w, "invalid n: "+v, http.StatusBadRequest)
+				return
+			}
+			ns = append(ns, n)
+		}
+
+		preds := []prediction{}
+		fits, err := q.fitGroupsContext(r.Context(), src.dataSet())
+		if err != nil {
+			fitError(w, err)
+			return
+		}
+		for _, gf := range fits {
+			for _, n := range ns {
+				yhat, width := gf.predict(n)
+				preds = append(preds, prediction{
+					Group:        gf.Group,
+					N:            n,
+					Yhat:         yhat,
+					PredWidth:    width,
+					Extrapolated: n < gf.xMin || n > gf.xMax,
+				})
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(preds)
+	}
+}
+
+// maxSolveN is the largest N that solve will search up to.
+const maxSolveN = 1e15
+
+// solve returns the largest N at which f is within the budget, by searching
+// upward from N = 1 until f exceeds the budget, and then bisecting.  It
+// returns 0 if f exceeds the budget at N = 1, and false if f is within the
+// budget all of the way up to maxSolveN.
+func solve(f func(float64) float64, budget float64) (float64, bool) {
+	if !(f(1) <= budget) {
+		return 0, true
+	}
+	lo, hi := 1.0, 2.0
+	for f(hi) <= budget {
+		lo, hi = hi, 2*hi
+		if hi > maxSolveN {
+			return maxSolveN, false
+		}
+	}
+	return math.Floor(bisect(func(x float64) bool { return f(x) <= budget }, lo, hi)), true
+}
+
+// bisect returns the point between lo and hi, to within half of an N, where
+// ok changes.  ok(lo) and ok(hi) must differ.  The returned point is on the
+// same side as lo.
+func bisect(ok func(float64) bool, lo, hi float64) float64 {
+	okLo := ok(lo)
+	for math.Abs(hi-lo) > 0.5 {
+		mid := lo + (hi-lo)/2
+		if ok(mid) == okLo {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// solution is the largest N at which a group's response is within a budget.
+type solution struct {
+	Group  string
+	Budget float64
+	N      float64
+
+	// N at which the upper and lower bounds of the 95% prediction interval
+	// reach the budget
+	NLow, NHigh float64
+
+	Unbounded    bool // the response is within the budget up to maxSolveN
+	Extrapolated bool // N is outside of the range of the benchmarks
+}
+
+// parseBudget parses a budget for the response, which is either a number, or
+// for NsPerOp, a duration like 100ms.
+func parseBudget(s, yVar string) (float64, error) {
+	if budget, err := strconv.ParseFloat(s, 64); err == nil {
+		return budget, nil
+	}
+	if yVar == "NsPerOp" {
+		if d, err := time.ParseDuration(s); err == nil {
+			return float64(d.Nanoseconds()), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid budget: %s", s)
+}
+
+// serveSolutions returns a handler which finds the largest N at which every
+// group's response is within the budget given in the querystring.  This is the
+// inverse of servePredictions.
+func serveSolutions(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q, err := parseAnalysisQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		budget, err := parseBudget(r.Form.Get("budget"), q.yVar)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		sols := []solution{}
+		fits, err := q.fitGroupsContext(r.Context(), src.dataSet())
+		if err != nil {
+			fitError(w, err)
+			return
+		}
+		for _, gf := range fits {
+			gf := gf
+			s := solution{Group: gf.Group, Budget: budget}
+			n, bounded := solve(func(x float64) float64 {
+				yhat, _ := gf.predict(x)
+				return yhat
+			}, budget)
+			s.N, s.Unbounded = n, !bounded
+			s.NLow, _ = solve(func(x float64) float64 {
+				yhat, width := gf.predict(x)
+				return yhat + width
+			}, budget)
+			s.NHigh, _ = solve(func(x float64) float64 {
+				yhat, width := gf.predict(x)
+				return yhat - width
+			}, budget)
+			s.Extrapolated = s.N < gf.xMin || s.N > gf.xMax
+			sols = append(sols, s)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sols)
+	}
+}
+
+// crossoverSteps is the number of points at which the difference between two
+// fits is evaluated when searching for crossovers.
+const crossoverSteps = 200
+
+// crossover is an N at which the fitted responses of two groups cross.
+type crossover struct {
+	A, B string
+	N    float64
+
+	// 95% confidence interval of the crossover, which is where the
+	// difference between the fits is not significant.  It is clipped to the
+	// range that was searched.
+	NLow, NHigh float64
+
+	Lower        string // the group with the lower response below N
+	Extrapolated bool   // N is outside of the range of either group's benchmarks
+}
+
+// crossovers returns every N between lb and ub at which the fits of a and b
+// cross.
+func crossovers(a, b groupFit, lb, ub float64) []crossover {
+	diff := func(x float64) float64 {
+		ya, _ := a.at(x)
+		yb, _ := b.at(x)
+		return ya - yb
+	}
+	// the difference is significant where it is wider than its confidence
+	// interval.
+	significant := func(x float64) bool {
+		ya, va := a.at(x)
+		yb, vb := b.at(x)
+		dof := a.cdof
+		if b.cdof < dof {
+			dof = b.cdof
+		}
+		return math.Abs(ya-yb) > conf95(math.Sqrt(a.mse*va+b.mse*vb), dof)
+	}
+
+	// Benchmarks are usually spaced geometrically, so search them that way.
+	xs := make([]float64, crossoverSteps)
+	for i := range xs {
+		t := float64(i) / float64(crossoverSteps-1)
+		if lb > 0 {
+			xs[i] = lb * math.Pow(ub/lb, t)
+		} else {
+			xs[i] = lb + t*(ub-lb)
+		}
+	}
+
+	var cs []crossover
+	for i := 1; i < len(xs); i++ {
+		d0, d1 := diff(xs[i-1]), diff(xs[i])
+		if d0 == 0 || (d0 < 0) == (d1 < 0) {
+			continue
+		}
+		c := crossover{
+			A:     a.Group,
+			B:     b.Group,
+			N:     bisect(func(x float64) bool { return diff(x) < 0 }, xs[i-1], xs[i]),
+			Lower: a.Group,
+		}
+		if d0 > 0 {
+			c.Lower = b.Group
+		}
+
+		c.NLow = lb
+		for j := i - 1; j >= 0; j-- {
+			if significant(xs[j]) {
+				c.NLow = bisect(significant, xs[j], math.Min(c.N, xs[j+1]))
+				break
+			}
+		}
+		c.NHigh = ub
+		for j := i; j < len(xs); j++ {
+			if significant(xs[j]) {
+				c.NHigh = bisect(significant, xs[j], math.Max(c.N, xs[j-1]))
+				break
+			}
+		}
+
+		c.Extrapolated = c.N < math.Max(a.xMin, b.xMin) || c.N > math.Min(a.xMax, b.xMax)
+		cs = append(cs, c)
+	}
+	return cs
+}
+
+// allCrossovers returns the crossovers between every pair of fits, between lb
+// and ub.  If lb or ub are nil, the range of the pair's benchmarks is used.
+func allCrossovers(fits []groupFit, lb, ub *float64) []crossover {
+	cs := []crossover{}
+	for i := range fits {
+		for j := i + 1; j < len(fits); j++ {
+			lo := math.Min(fits[i].xMin, fits[j].xMin)
+			if lb != nil {
+				lo = *lb
+			}
+			hi := math.Max(fits[i].xMax, fits[j].xMax)
+			if ub != nil {
+				hi = *ub
+			}
+			if lo < hi {
+				cs = append(cs, crossovers(fits[i], fits[j], lo, hi)...)
+			}
+		}
+	}
+	return cs
+}
+
+// serveCrossovers returns a handler which finds where the fits of every pair
+// of groups cross, or only groups a and b if they are in the querystring.
+func serveCrossovers(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q, err := parseAnalysisQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lb, err := parseBound(r.Form.Get("xlb"))
+		if err != nil {
+			http.Error(w, "invalid xlb: "+r.Form.Get("xlb"), http.StatusBadRequest)
+			return
+		}
+		ub, err := parseBound(r.Form.Get("xub"))
+		if err != nil {
+			http.Error(w, "invalid xub: "+r.Form.Get("xub"), http.StatusBadRequest)
+			return
+		}
+
+		all, err := q.fitGroupsContext(r.Context(), src.dataSet())
+		if err != nil {
+			fitError(w, err)
+			return
+		}
+		fits, err := selectPair(all, r.Form.Get("a"), r.Form.Get("b"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(allCrossovers(fits, lb, ub))
+	}
+}
+
+// selectPair returns the fits of groups a and b, or all of the fits if
+// neither is given.
+func selectPair(fits []groupFit, a, b string) ([]groupFit, error) {
+	if a == "" && b == "" {
+		return fits, nil
+	}
+	var pair []groupFit
+	for _, gf := range fits {
+		if gf.Group == a || gf.Group == b {
+			pair = append(pair, gf)
+		}
+	}
+	if len(pair) != 2 || a == b {
+		return nil, fmt.Errorf("unable to fit both %s and %s", a, b)
+	}
+	return pair, nil
+}
+
+// coefficients lines up the fitted coefficients of every group, which all use
+// the same terms.
+type coefficients struct {
+	Terms  []string
+	Groups []groupCoefficients
+}
+
+// groupCoefficients are the coefficients of a single group, the half widths
+// of their 95% confidence intervals, and the standardized coefficients, in
+// the order of the terms.
+type groupCoefficients struct {
+	Group      string
+	Beta       []float64
+	BInt       []float64
+	Std        []float64
+	Complexity string    // complexity class of the group, regardless of the model
+	NonFinite  []float64 `json:",omitempty"` // N of the benchmarks left out because a term wasn't finite
+}
+
+// serveCoefficients returns a handler which fits every group with the model
+// in the querystring, and returns their coefficients side by side.
+func serveCoefficients(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q, err := parseAnalysisQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		coefs := coefficients{Groups: []groupCoefficients{}}
+		for _, x := range q.xTransform {
+			coefs.Terms = append(coefs.Terms, x.String())
+		}
+		fits, err := q.fitGroupsContext(r.Context(), src.dataSet())
+		if err != nil {
+			fitError(w, err)
+			return
+		}
+		for _, gf := range fits {
+			coefs.Groups = append(coefs.Groups, groupCoefficients{
+				Group:      gf.Group,
+				Beta:       gf.beta,
+				BInt:       gf.bint,
+				Std:        standardizedCoefficients(gf.beta, sampleGroup(gf.benchSet, gf.xTransform, q.yVar)),
+				Complexity: complexity(gf.benchSet, q.yVar),
+				NonFinite:  gf.nonFinite,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(coefs)
+	}
+}
+
+// chowTest is a test of whether two groups have the same coefficients.
+type chowTest struct {
+	A, B     string
+	F        float64
+	DF1, DF2 int
+	P        float64 // probability of an F at least as large if they are the same
+}
+
+// chow compares the fits of two groups against a single fit of both of their
+// benchmarks.  It returns false if the benchmarks could not be fit together.
+func chow(a, b groupFit, yVar string) (chowTest, bool) {
+	benchSet := append(append([]benchmarkResponse{}, a.benchSet...), b.benchSet...)
+	// K, if the model has it, was already resolved for a, so this is a
+	// single fit
+	pooled, ok := fitGroup(context.Background(), "", benchSet, a.xTransform, yVar, a.fitter)
+	if !ok {
+		return chowTest{}, false
+	}
+	rss := a.mse*float64(a.dof) + b.mse*float64(b.dof)
+	rssPooled := pooled.mse * float64(pooled.dof)
+	ct := chowTest{
+		A:   a.Group,
+		B:   b.Group,
+		DF1: len(a.xTransform),
+		DF2: a.dof + b.dof,
+	}
+	ct.F = ((rssPooled - rss) / float64(ct.DF1)) / (rss / float64(ct.DF2))
+	ct.P = fSurvival(ct.F, ct.DF1, ct.DF2)
+	return ct, true
+}
+
+// allChowTests tests every pair of fits for equal coefficients.
+func allChowTests(fits []groupFit, yVar string) []chowTest {
+	cts := []chowTest{}
+	for i := range fits {
+		for j := i + 1; j < len(fits); j++ {
+			if ct, ok := chow(fits[i], fits[j], yVar); ok {
+				cts = append(cts, ct)
+			}
+		}
+	}
+	return cts
+}
+
+// serveChowTests returns a handler which tests whether every pair of groups,
+// or only groups a and b if they are in the querystring, have the same
+// coefficients.
+func serveChowTests(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q, err := parseAnalysisQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		all, err := q.fitGroupsContext(r.Context(), src.dataSet())
+		if err != nil {
+			fitError(w, err)
+			return
+		}
+		fits, err := selectPair(all, r.Form.Get("a"), r.Form.Get("b"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(allChowTests(fits, q.yVar))
+	}
+}
+
+// maxParseBytes is the largest body that /parse will read.
+const maxParseBytes = 32 << 20
+
+// parseResponse is the result of parsing the output of go test -bench.
+type parseResponse struct {
+	File      benchFile
+	Groups    map[string][]benchmarkResponse // benchmarks matching nre, by group
+	Unmatched []*benchmark                   // benchmarks which don't match nre
+}
+
+// parseHandleFunc parses the output of go test -bench in the body of a POST,
+// and groups the benchmarks with the settings in the querystring, in the same
+// way as the plotter.
+func parseHandleFunc(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "parse requires a POST", http.StatusMethodNotAllowed)
+		return
+	}
+	// The body is the benchmarks, so only the querystring has settings.
+	g, err := parseGrouping(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	bf, err := parseBenchFile(http.MaxBytesReader(w, r.Body, maxParseBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := parseResponse{File: bf, Unmatched: []*benchmark{}}
+	groups, unmatched, _, _ := groupBenchmarks(dataSet{Files: []benchFile{bf}}, g)
+	res.Groups = groups
+	if unmatched != nil {
+		res.Unmatched = unmatched
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,209 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+// linearBenchSet returns benchmarks with ns/op = a*N + b, plus a little noise.
+func linearBenchSet(a, b float64) []benchmarkResponse {
+	noise := []float64{0.5, -0.5, 0.25, -0.25, 0.1, -0.1}
+	var benchSet []benchmarkResponse
+	for i, e := range noise {
+		x := float64(10 * (i + 1))
+		benchSet = append(benchSet, benchmarkResponse{Benchmark: parse.Benchmark{NsPerOp: a*x + b + e}, X: x})
+	}
+	return benchSet
+}
+
+func TestPredict(t *testing.T) {
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"N, 1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gf, ok := fitGroup(context.Background(), "linear", linearBenchSet(3, 5), q.xTransform, q.yVar, q.fitter)
+	if !ok {
+		t.Fatal("unable to fit")
+	}
+	yhat, width := gf.predict(100)
+	if math.Abs(yhat-305) > 1 {
+		t.Errorf("got prediction %v, want about 305", yhat)
+	}
+	if width <= 0 || width > 5 {
+		t.Errorf("got prediction interval width %v, want a small positive width", width)
+	}
+	_, farWidth := gf.predict(1000)
+	if farWidth <= width {
+		t.Errorf("prediction interval at 1000 (%v) should be wider than at 100 (%v)", farWidth, width)
+	}
+}
+
+func TestParseAnalysisQuery(t *testing.T) {
+	for _, v := range []url.Values{
+		{"nre": {"("}},
+		{"unparameterized": {"sometimes"}},
+		{"xtransform": {"N +"}},
+		{"yvar": {"Bogus"}},
+	} {
+		if _, err := parseAnalysisQuery(v); err == nil {
+			t.Errorf("parseAnalysisQuery(%v) did not return an error", v)
+		}
+	}
+}
+
+func TestSolve(t *testing.T) {
+	for _, test := range []struct {
+		f       func(float64) float64
+		budget  float64
+		n       float64
+		bounded bool
+	}{
+		{func(x float64) float64 { return 3*x + 5 }, 305, 100, true},
+		{func(x float64) float64 { return x * math.Log(x) }, 1e6, 87847, true},
+		{func(x float64) float64 { return 3*x + 5 }, 1, 0, true},
+		{func(x float64) float64 { return 1 }, 2, maxSolveN, false},
+	} {
+		n, bounded := solve(test.f, test.budget)
+		if n != test.n || bounded != test.bounded {
+			t.Errorf("solve with budget %v = %v, %v, want %v, %v", test.budget, n, bounded, test.n, test.bounded)
+		}
+	}
+}
+
+func TestParseBudget(t *testing.T) {
+	for _, test := range []struct {
+		s, yVar string
+		budget  float64
+		ok      bool
+	}{
+		{"1e8", "NsPerOp", 1e8, true},
+		{"100ms", "NsPerOp", 1e8, true},
+		{"100ms", "AllocsPerOp", 0, false},
+		{"", "NsPerOp", 0, false},
+	} {
+		budget, err := parseBudget(test.s, test.yVar)
+		if budget != test.budget || (err == nil) != test.ok {
+			t.Errorf("parseBudget(%q, %q) = %v, %v, want %v", test.s, test.yVar, budget, err, test.budget)
+		}
+	}
+}
+
+func TestCrossovers(t *testing.T) {
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"N, 1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, ok := fitGroup(context.Background(), "a", linearBenchSet(20, 0), q.xTransform, q.yVar, q.fitter)
+	if !ok {
+		t.Fatal("unable to fit a")
+	}
+	b, ok := fitGroup(context.Background(), "b", linearBenchSet(10, 300), q.xTransform, q.yVar, q.fitter)
+	if !ok {
+		t.Fatal("unable to fit b")
+	}
+	cs := allCrossovers([]groupFit{a, b}, nil, nil)
+	if len(cs) != 1 {
+		t.Fatalf("got %d crossovers, want 1", len(cs))
+	}
+	c := cs[0]
+	if math.Abs(c.N-30) > 1 {
+		t.Errorf("got crossover at %v, want about 30", c.N)
+	}
+	if !(c.NLow < c.N && c.N < c.NHigh) {
+		t.Errorf("crossover %v is not within its confidence interval [%v, %v]", c.N, c.NLow, c.NHigh)
+	}
+	if c.Lower != "a" || c.Extrapolated {
+		t.Errorf("got lower group %q and extrapolated %v, want a and false", c.Lower, c.Extrapolated)
+	}
+}
+
+func TestFSurvival(t *testing.T) {
+	for _, test := range []struct {
+		f      float64
+		d1, d2 int
+		p      float64
+	}{
+		{1, 1, 1, 0.5},
+		{4.103, 2, 10, 0.05},
+		{2.711, 5, 20, 0.05},
+		{0, 3, 7, 1},
+	} {
+		if p := fSurvival(test.f, test.d1, test.d2); math.Abs(p-test.p) > 1e-3 {
+			t.Errorf("fSurvival(%v, %d, %d) = %v, want %v", test.f, test.d1, test.d2, p, test.p)
+		}
+	}
+}
+
+func TestChow(t *testing.T) {
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"N, 1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fit := func(group string, a, b float64) groupFit {
+		gf, ok := fitGroup(context.Background(), group, linearBenchSet(a, b), q.xTransform, q.yVar, q.fitter)
+		if !ok {
+			t.Fatalf("unable to fit %s", group)
+		}
+		return gf
+	}
+	same, ok := chow(fit("a", 20, 0), fit("b", 20, 0), q.yVar)
+	if !ok {
+		t.Fatal("unable to test a and b")
+	}
+	if same.P < 0.5 {
+		t.Errorf("got p = %v for identical groups, want a large p", same.P)
+	}
+	diff, ok := chow(fit("a", 20, 0), fit("c", 10, 300), q.yVar)
+	if !ok {
+		t.Fatal("unable to test a and c")
+	}
+	if diff.P > 1e-6 {
+		t.Errorf("got p = %v for different groups, want a small p", diff.P)
+	}
+	if diff.DF1 != 2 || diff.DF2 != 8 {
+		t.Errorf("got %d and %d degrees of freedom, want 2 and 8", diff.DF1, diff.DF2)
+	}
+}
+
+func TestParseHandleFunc(t *testing.T) {
+	in := `pkg: github.com/jonlawlor/benchplot
+BenchmarkSort10-4 1000000 1008 ns/op
+BenchmarkSort100-4 200000 8224 ns/op
+BenchmarkHash-4 5000000 300 ns/op
+`
+	r, err := http.NewRequest("POST", "/parse?nre="+url.QueryEscape(defaultNRE), strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	parseHandleFunc(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body)
+	}
+	var res parseResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.File.Benchmarks) != 3 {
+		t.Errorf("got %d benchmarks, want 3", len(res.File.Benchmarks))
+	}
+	if len(res.Groups["BenchmarkSort"]) != 2 {
+		t.Errorf("got groups %v, want 2 benchmarks in BenchmarkSort", res.Groups)
+	}
+	if len(res.Unmatched) != 1 || res.Unmatched[0].Name != "BenchmarkHash-4" {
+		t.Errorf("got unmatched %v, want BenchmarkHash-4", res.Unmatched)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// maxAnnotationLabel is the longest label of an annotation, in bytes.
+const maxAnnotationLabel = 200
+
+// annotation is a labeled vertical line at N, which is drawn across all of
+// the groups, like "fits in L2".
+type annotation struct {
+	N     float64
+	Label string
+	Bytes bool `json:",omitempty"` // only drawn if N is a number of bytes
+}
+
+type byAnnotationN []annotation
+
+func (a byAnnotationN) Len() int      { return len(a) }
+func (a byAnnotationN) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byAnnotationN) Less(i, j int) bool {
+	if a[i].N != a[j].N {
+		return a[i].N < a[j].N
+	}
+	return a[i].Label < a[j].Label
+}
+
+// parseAnnotation returns the annotation in the n and label parameters.
+func parseAnnotation(v url.Values) (annotation, error) {
+	n, err := strconv.ParseFloat(v.Get("n"), 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return annotation{}, fmt.Errorf("invalid n: %s", v.Get("n"))
+	}
+	label := v.Get("label")
+	if len(label) > maxAnnotationLabel {
+		return annotation{}, fmt.Errorf("label is longer than %d bytes", maxAnnotationLabel)
+	}
+	return annotation{N: n, Label: label}, nil
+}
+
+var (
+	annotationsOnce sync.Once
+	fileAnnotations []annotation
+	annotationsErr  error
+)
+
+// configAnnotations returns the annotations in the -annotations file, which
+// is a JSON list like [{"N": 32768, "Label": "fits in L1"}].  They're read
+// once, and are drawn on every plotter and report.
+func configAnnotations() ([]annotation, error) {
+	annotationsOnce.Do(func() {
+		if *annotations == "" {
+			return
+		}
+		b, err := ioutil.ReadFile(*annotations)
+		if err != nil {
+			annotationsErr = err
+			return
+		}
+		if err := json.Unmarshal(b, &fileAnnotations); err != nil {
+			annotationsErr = fmt.Errorf("%s: %v", *annotations, err)
+			return
+		}
+		sort.Sort(byAnnotationN(fileAnnotations))
+	})
+	return fileAnnotations, annotationsErr
+}
+
+// initialAnnotations returns those in the -annotations file along with
+// those of the cache sizes, ordered by N.
+func initialAnnotations() ([]annotation, error) {
+	anns, err := configAnnotations()
+	if err != nil {
+		return nil, err
+	}
+	caches, err := cacheAnnotations()
+	if err != nil {
+		return nil, err
+	}
+	all := append(append([]annotation(nil), anns...), caches...)
+	sort.Sort(byAnnotationN(all))
+	return all, nil
+}
+
+// annotationStore holds the annotations of a plotter, starting with those
+// in the -annotations file.  Annotations which are posted are kept in
+// memory.
+type annotationStore struct {
+	mu   sync.Mutex
+	list []annotation
+}
+
+func newAnnotationStore(list []annotation) *annotationStore {
+	return &annotationStore{list: append([]annotation(nil), list...)}
+}
+
+// all returns a copy of the annotations, ordered by N.
+func (s *annotationStore) all() []annotation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]annotation{}, s.list...)
+}
+
+// add adds an annotation, unless it's already there.
+func (s *annotationStore) add(a annotation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, b := range s.list {
+		if a == b {
+			return
+		}
+	}
+	s.list = append(s.list, a)
+	sort.Sort(byAnnotationN(s.list))
+}
+
+// remove removes the annotations at N, or only those with the label if it
+// is set, and returns how many were removed.
+func (s *annotationStore) remove(n float64, label string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.list[:0]
+	for _, a := range s.list {
+		if a.N != n || (label != "" && a.Label != label) {
+			kept = append(kept, a)
+		}
+	}
+	removed := len(s.list) - len(kept)
+	s.list = kept
+	return removed
+}
+
+// serveAnnotations lists the annotations on a GET, adds the one in the n and
+// label parameters on a POST, and removes those at n on a DELETE, which
+// can be limited to a label.  Each responds with the annotations.
+func serveAnnotations(s *annotationStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET", "HEAD":
+		case "POST", "DELETE":
+			if err := r.ParseForm(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			a, err := parseAnnotation(r.Form)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if r.Method == "POST" {
+				if a.Label == "" {
+					http.Error(w, "annotations require a label", http.StatusBadRequest)
+					return
+				}
+				s.add(a)
+			} else if s.remove(a.N, a.Label) == 0 {
+				http.Error(w, "no annotation at "+r.Form.Get("n"), http.StatusNotFound)
+				return
+			}
+		default:
+			http.Error(w, "annotations requires a GET, POST, or DELETE", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.all())
+	}
+}
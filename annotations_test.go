@@ -0,0 +1,60 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestServeAnnotations(t *testing.T) {
+	h := serveAnnotations(newAnnotationStore([]annotation{{N: 32768, Label: "fits in L1"}}))
+	tests := []struct {
+		method, query string
+		code          int
+		want          []annotation
+	}{
+		{"GET", "", http.StatusOK, []annotation{{N: 32768, Label: "fits in L1"}}},
+		{"POST", "n=4096&label=page+size", http.StatusOK, []annotation{{N: 4096, Label: "page size"}, {N: 32768, Label: "fits in L1"}}},
+		// the same annotation is only added once
+		{"POST", "n=4096&label=page+size", http.StatusOK, []annotation{{N: 4096, Label: "page size"}, {N: 32768, Label: "fits in L1"}}},
+		{"POST", "n=4096&label=huge+page", http.StatusOK, []annotation{{N: 4096, Label: "huge page"}, {N: 4096, Label: "page size"}, {N: 32768, Label: "fits in L1"}}},
+		{"POST", "n=4096", http.StatusBadRequest, nil},
+		{"POST", "n=NaN&label=x", http.StatusBadRequest, nil},
+		{"POST", "label=x", http.StatusBadRequest, nil},
+		{"DELETE", "n=4096&label=huge+page", http.StatusOK, []annotation{{N: 4096, Label: "page size"}, {N: 32768, Label: "fits in L1"}}},
+		{"DELETE", "n=4096", http.StatusOK, []annotation{{N: 32768, Label: "fits in L1"}}},
+		{"DELETE", "n=4096", http.StatusNotFound, nil},
+		{"PUT", "n=1&label=x", http.StatusMethodNotAllowed, nil},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(test.method, "/annotations?"+test.query, nil))
+		if w.Code != test.code {
+			t.Errorf("%s %s: got status %d, want %d", test.method, test.query, w.Code, test.code)
+			continue
+		}
+		if test.code != http.StatusOK {
+			continue
+		}
+		var got []annotation
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s %s: got %v, want %v", test.method, test.query, got, test.want)
+		}
+	}
+
+	// annotations can't be changed on a read-only server
+	w := httptest.NewRecorder()
+	readOnlyHandler(h).ServeHTTP(w, httptest.NewRequest("POST", "/annotations?n=1&label=x", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("got status %d from a read-only server, want %d", w.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// writeBenchfmt writes the benchmarks in the standard benchmark format, which
+// benchstat and perf.golang.org read, so that the output of other harnesses
+// can be compared with their tools.  Each file's configuration is written
+// before its benchmarks, and configuration which a file doesn't have is
+// cleared.  The files, packages, and machines which the grouping leaves out
+// are left out, and runs with the same name in a file are summarized by its
+// aggregate.
+func writeBenchfmt(w io.Writer, ds dataSet, g grouping) error {
+	bw := bufio.NewWriter(w)
+	config := make(map[string]string)
+	pkg := ""
+	for _, f := range ds.Files {
+		if g.hidden[f.Path] {
+			continue
+		}
+		var benchmarks []*benchmark
+		for _, b := range f.Benchmarks {
+			if (g.pkg == "" || b.Package == g.pkg) && (g.machine == "" || b.Machine == g.machine) {
+				benchmarks = append(benchmarks, b)
+			}
+		}
+		if len(benchmarks) == 0 {
+			continue
+		}
+		if g.aggregate != "" && g.aggregate != aggregateAll {
+			benchmarks = aggregateNames(benchmarks, g.aggregate)
+		}
+
+		var keys []string
+		for k := range config {
+			if _, ok := f.Config[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		for k, v := range f.Config {
+			if k != "pkg" && config[k] != v {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v, ok := f.Config[k]
+			if ok {
+				config[k] = v
+				fmt.Fprintf(bw, "%s: %s\n", k, v)
+			} else {
+				delete(config, k)
+				fmt.Fprintf(bw, "%s:\n", k)
+			}
+		}
+
+		for _, b := range benchmarks {
+			if b.Package != pkg {
+				pkg = b.Package
+				fmt.Fprintf(bw, "pkg: %s\n", pkg)
+			}
+			pb := b.Benchmark
+			pb.Name = benchfmtName(pb.Name)
+			fmt.Fprintln(bw, pb.String())
+		}
+	}
+	return bw.Flush()
+}
+
+// aggregateNames summarizes the runs of each benchmark, by package and name,
+// in the order that they first appear.
+func aggregateNames(benchmarks []*benchmark, aggregate string) []*benchmark {
+	type key struct{ pkg, name string }
+	index := make(map[key]int)
+	var firsts []*benchmark
+	var benchSet []benchmarkResponse
+	for _, b := range benchmarks {
+		k := key{b.Package, b.Name}
+		i, ok := index[k]
+		if !ok {
+			i = len(firsts)
+			index[k] = i
+			firsts = append(firsts, b)
+		}
+		benchSet = append(benchSet, benchmarkResponse{Benchmark: b.Benchmark, perfCounters: b.perfCounters, X: float64(i)})
+	}
+	agg := aggregateRuns(benchSet, aggregate)
+	summaries := make([]*benchmark, len(agg))
+	for i, a := range agg {
+		b := *firsts[int(a.X)]
+		b.Benchmark = a.Benchmark
+		b.perfCounters = a.perfCounters
+		summaries[i] = &b
+	}
+	return summaries
+}
+
+// benchfmtName makes a benchmark name valid in the benchmark format, which
+// must start with Benchmark and can't contain spaces, like the names of
+// benchmarks read from other harnesses.
+func benchfmtName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if !strings.HasPrefix(name, "Benchmark") {
+		if name != "" && unicode.IsLower([]rune(name)[0]) {
+			name = "_" + name
+		}
+		name = "Benchmark" + name
+	}
+	return name
+}
+
+// serveBenchfmt returns a handler which writes the benchmarks in the standard
+// benchmark format, grouped by the querystring like the plotter.
+func serveBenchfmt(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		g, err := parseGrouping(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writeBenchfmt(w, src.dataSet(), g)
+	}
+}
+
+func convertUsage(fs *flag.FlagSet) func() {
+	return func() {
+		fmt.Fprintf(os.Stderr, "usage: benchplot convert [options] bench1.json [bench2.csv ...]\n")
+		fmt.Fprintf(os.Stderr, "writes the benchmarks in the standard benchmark format, for benchstat\n")
+		fmt.Fprintf(os.Stderr, "example:\n")
+		fmt.Fprintf(os.Stderr, "   benchplot convert -o jmh.txt jmh-result.json\n")
+		fmt.Fprintf(os.Stderr, "options:\n")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+}
+
+func convertMain(args []string) {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	fs.Usage = convertUsage(fs)
+	out := fs.String("o", "-", "output file, or - for stdout")
+	aggregate := fs.String("aggregate", aggregateAll, "how to summarize repeated runs of a benchmark: "+strings.Join([]string{aggregateAll, aggregateMean, aggregateMedian, aggregateMin}, ", "))
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+	}
+	checkPatterns(fs.Args())
+	switch *aggregate {
+	case aggregateAll, aggregateMean, aggregateMedian, aggregateMin:
+	default:
+		fatalf("invalid aggregate: %s", *aggregate)
+	}
+
+	w := os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fatal(err)
+		}
+		w = f
+	}
+	err := writeBenchfmt(w, readDataSet(fs.Args()), grouping{aggregate: *aggregate})
+	if cerr := w.Close(); err == nil && *out != "-" {
+		err = cerr
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
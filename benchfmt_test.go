@@ -0,0 +1,62 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteBenchfmt(t *testing.T) {
+	jmh, err := parseBenchFile(strings.NewReader(jmhJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goBench, err := parseBenchFile(strings.NewReader("goos: linux\npkg: sort\nBenchmarkSort10-4 1000 1008 ns/op\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := dataSet{Files: []benchFile{jmh, goBench}}
+
+	var buf bytes.Buffer
+	if err := writeBenchfmt(&buf, ds, grouping{aggregate: aggregateMin}); err != nil {
+		t.Fatal(err)
+	}
+	// The output is read back in the same way as go test's.
+	bf, err := parseBenchFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bf.Warnings) != 0 {
+		t.Errorf("got warnings %v", bf.Warnings)
+	}
+	names := make(map[string]int)
+	for _, b := range bf.Benchmarks {
+		names[b.Name]++
+	}
+	if len(bf.Benchmarks) != len(names) {
+		t.Errorf("got benchmarks %v, want the runs of each summarized", names)
+	}
+	if last := bf.Benchmarks[len(bf.Benchmarks)-1]; last.Name != "BenchmarkSort10-4" || last.Package != "sort" {
+		t.Errorf("got %s in %s, want BenchmarkSort10-4 in sort", last.Name, last.Package)
+	}
+	if bf.Config["goos"] != "linux" || bf.Config["jmh"] != "" {
+		t.Errorf("got config %v, want only the last file's", bf.Config)
+	}
+}
+
+func TestBenchfmtName(t *testing.T) {
+	for name, want := range map[string]string{
+		"BenchmarkSort10-4":          "BenchmarkSort10-4",
+		"sort/size=1024-1":           "Benchmark_sort/size=1024-1",
+		"sort -n {size}/size=1000-1": "Benchmark_sort_-n_{size}/size=1000-1",
+		"BM_Sort/1024-1":             "BenchmarkBM_Sort/1024-1",
+	} {
+		if got := benchfmtName(name); got != want {
+			t.Errorf("benchfmtName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,2100 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: benchplot.proto
+
+package benchplotpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Benchmark is a single line of benchmark output.
+type Benchmark struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name              string  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	N                 int64   `protobuf:"varint,2,opt,name=n,proto3" json:"n,omitempty"`
+	NsPerOp           float64 `protobuf:"fixed64,3,opt,name=ns_per_op,json=nsPerOp,proto3" json:"ns_per_op,omitempty"`
+	AllocedBytesPerOp uint64  `protobuf:"varint,4,opt,name=alloced_bytes_per_op,json=allocedBytesPerOp,proto3" json:"alloced_bytes_per_op,omitempty"`
+	AllocsPerOp       uint64  `protobuf:"varint,5,opt,name=allocs_per_op,json=allocsPerOp,proto3" json:"allocs_per_op,omitempty"`
+	MbPerS            float64 `protobuf:"fixed64,6,opt,name=mb_per_s,json=mbPerS,proto3" json:"mb_per_s,omitempty"`
+	Package           string  `protobuf:"bytes,7,opt,name=package,proto3" json:"package,omitempty"`
+	// goarch and cpu of the machine that ran the benchmark, if they are known
+	Machine string `protobuf:"bytes,8,opt,name=machine,proto3" json:"machine,omitempty"`
+	// hardware counters from perf stat, per op, which are unset if they
+	// weren't counted
+	InstructionsPerOp *float64 `protobuf:"fixed64,9,opt,name=instructions_per_op,json=instructionsPerOp,proto3,oneof" json:"instructions_per_op,omitempty"`
+	CacheMissesPerOp  *float64 `protobuf:"fixed64,10,opt,name=cache_misses_per_op,json=cacheMissesPerOp,proto3,oneof" json:"cache_misses_per_op,omitempty"`
+	BranchMissesPerOp *float64 `protobuf:"fixed64,11,opt,name=branch_misses_per_op,json=branchMissesPerOp,proto3,oneof" json:"branch_misses_per_op,omitempty"`
+}
+
+func (x *Benchmark) Reset() {
+	*x = Benchmark{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Benchmark) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Benchmark) ProtoMessage() {}
+
+func (x *Benchmark) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Benchmark.ProtoReflect.Descriptor instead.
+func (*Benchmark) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Benchmark) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Benchmark) GetN() int64 {
+	if x != nil {
+		return x.N
+	}
+	return 0
+}
+
+func (x *Benchmark) GetNsPerOp() float64 {
+	if x != nil {
+		return x.NsPerOp
+	}
+	return 0
+}
+
+func (x *Benchmark) GetAllocedBytesPerOp() uint64 {
+	if x != nil {
+		return x.AllocedBytesPerOp
+	}
+	return 0
+}
+
+func (x *Benchmark) GetAllocsPerOp() uint64 {
+	if x != nil {
+		return x.AllocsPerOp
+	}
+	return 0
+}
+
+func (x *Benchmark) GetMbPerS() float64 {
+	if x != nil {
+		return x.MbPerS
+	}
+	return 0
+}
+
+func (x *Benchmark) GetPackage() string {
+	if x != nil {
+		return x.Package
+	}
+	return ""
+}
+
+func (x *Benchmark) GetMachine() string {
+	if x != nil {
+		return x.Machine
+	}
+	return ""
+}
+
+func (x *Benchmark) GetInstructionsPerOp() float64 {
+	if x != nil && x.InstructionsPerOp != nil {
+		return *x.InstructionsPerOp
+	}
+	return 0
+}
+
+func (x *Benchmark) GetCacheMissesPerOp() float64 {
+	if x != nil && x.CacheMissesPerOp != nil {
+		return *x.CacheMissesPerOp
+	}
+	return 0
+}
+
+func (x *Benchmark) GetBranchMissesPerOp() float64 {
+	if x != nil && x.BranchMissesPerOp != nil {
+		return *x.BranchMissesPerOp
+	}
+	return 0
+}
+
+// Grouping describes how to find the group and N of each benchmark from its
+// name.  Empty fields take the same defaults as the plotter.
+type Grouping struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// regular expression whose first submatch is the group and second is N
+	Nre string `protobuf:"bytes,1,opt,name=nre,proto3" json:"nre,omitempty"`
+	// how to handle benchmarks which don't match nre: "table" or "one"
+	Unparameterized string `protobuf:"bytes,2,opt,name=unparameterized,proto3" json:"unparameterized,omitempty"`
+	// only include benchmarks from this package
+	Pkg string `protobuf:"bytes,3,opt,name=pkg,proto3" json:"pkg,omitempty"`
+	// prefix groups with their package
+	ByPackage bool `protobuf:"varint,4,opt,name=by_package,json=byPackage,proto3" json:"by_package,omitempty"`
+	// how to summarize repeated runs at the same N: "all", "mean", "median",
+	// or "min"
+	Aggregate string `protobuf:"bytes,5,opt,name=aggregate,proto3" json:"aggregate,omitempty"`
+	// only include benchmarks from this machine
+	Machine string `protobuf:"bytes,6,opt,name=machine,proto3" json:"machine,omitempty"`
+	// prefix groups with their machine
+	ByMachine bool `protobuf:"varint,7,opt,name=by_machine,json=byMachine,proto3" json:"by_machine,omitempty"`
+	// what the confidence intervals are clustered by, so that runs in the same
+	// one aren't treated as independent: "file" or "machine", or empty not to
+	// cluster them
+	Cluster string `protobuf:"bytes,8,opt,name=cluster,proto3" json:"cluster,omitempty"`
+}
+
+func (x *Grouping) Reset() {
+	*x = Grouping{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Grouping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Grouping) ProtoMessage() {}
+
+func (x *Grouping) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Grouping.ProtoReflect.Descriptor instead.
+func (*Grouping) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Grouping) GetNre() string {
+	if x != nil {
+		return x.Nre
+	}
+	return ""
+}
+
+func (x *Grouping) GetUnparameterized() string {
+	if x != nil {
+		return x.Unparameterized
+	}
+	return ""
+}
+
+func (x *Grouping) GetPkg() string {
+	if x != nil {
+		return x.Pkg
+	}
+	return ""
+}
+
+func (x *Grouping) GetByPackage() bool {
+	if x != nil {
+		return x.ByPackage
+	}
+	return false
+}
+
+func (x *Grouping) GetAggregate() string {
+	if x != nil {
+		return x.Aggregate
+	}
+	return ""
+}
+
+func (x *Grouping) GetMachine() string {
+	if x != nil {
+		return x.Machine
+	}
+	return ""
+}
+
+func (x *Grouping) GetByMachine() bool {
+	if x != nil {
+		return x.ByMachine
+	}
+	return false
+}
+
+func (x *Grouping) GetCluster() string {
+	if x != nil {
+		return x.Cluster
+	}
+	return ""
+}
+
+// Point is a benchmark and its N.
+type Point struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Benchmark *Benchmark `protobuf:"bytes,1,opt,name=benchmark,proto3" json:"benchmark,omitempty"`
+	X         float64    `protobuf:"fixed64,2,opt,name=x,proto3" json:"x,omitempty"`
+	// the file or machine the benchmark came from, if the confidence intervals
+	// of a fit should be robust to correlation within them
+	Cluster string `protobuf:"bytes,3,opt,name=cluster,proto3" json:"cluster,omitempty"`
+}
+
+func (x *Point) Reset() {
+	*x = Point{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Point) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Point) ProtoMessage() {}
+
+func (x *Point) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Point.ProtoReflect.Descriptor instead.
+func (*Point) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Point) GetBenchmark() *Benchmark {
+	if x != nil {
+		return x.Benchmark
+	}
+	return nil
+}
+
+func (x *Point) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Point) GetCluster() string {
+	if x != nil {
+		return x.Cluster
+	}
+	return ""
+}
+
+// Group is the benchmarks of a single group.
+type Group struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name   string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Points []*Point `protobuf:"bytes,2,rep,name=points,proto3" json:"points,omitempty"`
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Group) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Group) GetPoints() []*Point {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+// Warning is a line which looks like a benchmark, but couldn't be parsed.
+type Warning struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Line int32  `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	Text string `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Err  string `protobuf:"bytes,3,opt,name=err,proto3" json:"err,omitempty"`
+}
+
+func (x *Warning) Reset() {
+	*x = Warning{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Warning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Warning) ProtoMessage() {}
+
+func (x *Warning) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Warning.ProtoReflect.Descriptor instead.
+func (*Warning) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Warning) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *Warning) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Warning) GetErr() string {
+	if x != nil {
+		return x.Err
+	}
+	return ""
+}
+
+type ParseRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// output of go test -bench
+	Text     string    `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Grouping *Grouping `protobuf:"bytes,2,opt,name=grouping,proto3" json:"grouping,omitempty"`
+}
+
+func (x *ParseRequest) Reset() {
+	*x = ParseRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ParseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseRequest) ProtoMessage() {}
+
+func (x *ParseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseRequest.ProtoReflect.Descriptor instead.
+func (*ParseRequest) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ParseRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ParseRequest) GetGrouping() *Grouping {
+	if x != nil {
+		return x.Grouping
+	}
+	return nil
+}
+
+type ParseResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Benchmarks []*Benchmark      `protobuf:"bytes,1,rep,name=benchmarks,proto3" json:"benchmarks,omitempty"`
+	Config     map[string]string `protobuf:"bytes,2,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Skipped    int32             `protobuf:"varint,3,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Warnings   []*Warning        `protobuf:"bytes,4,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// benchmarks matching nre, in order of group name
+	Groups []*Group `protobuf:"bytes,5,rep,name=groups,proto3" json:"groups,omitempty"`
+	// benchmarks which don't match nre
+	Unmatched []*Benchmark `protobuf:"bytes,6,rep,name=unmatched,proto3" json:"unmatched,omitempty"`
+}
+
+func (x *ParseResponse) Reset() {
+	*x = ParseResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ParseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseResponse) ProtoMessage() {}
+
+func (x *ParseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseResponse.ProtoReflect.Descriptor instead.
+func (*ParseResponse) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ParseResponse) GetBenchmarks() []*Benchmark {
+	if x != nil {
+		return x.Benchmarks
+	}
+	return nil
+}
+
+func (x *ParseResponse) GetConfig() map[string]string {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *ParseResponse) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *ParseResponse) GetWarnings() []*Warning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *ParseResponse) GetGroups() []*Group {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *ParseResponse) GetUnmatched() []*Benchmark {
+	if x != nil {
+		return x.Unmatched
+	}
+	return nil
+}
+
+type FitRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Points []*Point `protobuf:"bytes,1,rep,name=points,proto3" json:"points,omitempty"`
+	// comma separated terms of the model, like "math.Log(N) * N, 1.0"
+	XTransform string `protobuf:"bytes,2,opt,name=x_transform,json=xTransform,proto3" json:"x_transform,omitempty"`
+	// response to fit, like "NsPerOp"
+	YVar string `protobuf:"bytes,3,opt,name=y_var,json=yVar,proto3" json:"y_var,omitempty"`
+	// bounds of the regression line, which each default to the range of the
+	// points if they're unset
+	Xlb *float64 `protobuf:"fixed64,4,opt,name=xlb,proto3,oneof" json:"xlb,omitempty"`
+	Xub *float64 `protobuf:"fixed64,5,opt,name=xub,proto3,oneof" json:"xub,omitempty"`
+	// number of points on the regression line, which defaults to 1000
+	NLineSteps int32 `protobuf:"varint,6,opt,name=n_line_steps,json=nLineSteps,proto3" json:"n_line_steps,omitempty"`
+	// name of the estimator, like "ols" or "huber", which defaults to "ols"
+	Fitter string `protobuf:"bytes,7,opt,name=fitter,proto3" json:"fitter,omitempty"`
+	// strength of regularization, for the ridge and lasso fitters
+	Lambda *float64 `protobuf:"fixed64,8,opt,name=lambda,proto3,oneof" json:"lambda,omitempty"`
+}
+
+func (x *FitRequest) Reset() {
+	*x = FitRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FitRequest) ProtoMessage() {}
+
+func (x *FitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FitRequest.ProtoReflect.Descriptor instead.
+func (*FitRequest) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FitRequest) GetPoints() []*Point {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+func (x *FitRequest) GetXTransform() string {
+	if x != nil {
+		return x.XTransform
+	}
+	return ""
+}
+
+func (x *FitRequest) GetYVar() string {
+	if x != nil {
+		return x.YVar
+	}
+	return ""
+}
+
+func (x *FitRequest) GetXlb() float64 {
+	if x != nil && x.Xlb != nil {
+		return *x.Xlb
+	}
+	return 0
+}
+
+func (x *FitRequest) GetXub() float64 {
+	if x != nil && x.Xub != nil {
+		return *x.Xub
+	}
+	return 0
+}
+
+func (x *FitRequest) GetNLineSteps() int32 {
+	if x != nil {
+		return x.NLineSteps
+	}
+	return 0
+}
+
+func (x *FitRequest) GetFitter() string {
+	if x != nil {
+		return x.Fitter
+	}
+	return ""
+}
+
+func (x *FitRequest) GetLambda() float64 {
+	if x != nil && x.Lambda != nil {
+		return *x.Lambda
+	}
+	return 0
+}
+
+// LinePoint is a point on the regression line.
+type LinePoint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X    float64 `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
+	Yhat float64 `protobuf:"fixed64,2,opt,name=yhat,proto3" json:"yhat,omitempty"`
+	// half width of the 95% confidence interval
+	ConfWidth    float64 `protobuf:"fixed64,3,opt,name=conf_width,json=confWidth,proto3" json:"conf_width,omitempty"`
+	Extrapolated bool    `protobuf:"varint,4,opt,name=extrapolated,proto3" json:"extrapolated,omitempty"`
+}
+
+func (x *LinePoint) Reset() {
+	*x = LinePoint{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LinePoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinePoint) ProtoMessage() {}
+
+func (x *LinePoint) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinePoint.ProtoReflect.Descriptor instead.
+func (*LinePoint) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LinePoint) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *LinePoint) GetYhat() float64 {
+	if x != nil {
+		return x.Yhat
+	}
+	return 0
+}
+
+func (x *LinePoint) GetConfWidth() float64 {
+	if x != nil {
+		return x.ConfWidth
+	}
+	return 0
+}
+
+func (x *LinePoint) GetExtrapolated() bool {
+	if x != nil {
+		return x.Extrapolated
+	}
+	return false
+}
+
+// Term is a single term of a fitted model.
+type Term struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	XTrans string  `protobuf:"bytes,1,opt,name=x_trans,json=xTrans,proto3" json:"x_trans,omitempty"`
+	Beta   float64 `protobuf:"fixed64,2,opt,name=beta,proto3" json:"beta,omitempty"`
+	// half width of the 95% confidence interval
+	BInt float64 `protobuf:"fixed64,3,opt,name=b_int,json=bInt,proto3" json:"b_int,omitempty"`
+	// variance inflation factor, 0 for constant terms
+	Vif float64 `protobuf:"fixed64,4,opt,name=vif,proto3" json:"vif,omitempty"`
+	// standardized coefficient, 0 for constant terms
+	Std float64 `protobuf:"fixed64,5,opt,name=std,proto3" json:"std,omitempty"`
+}
+
+func (x *Term) Reset() {
+	*x = Term{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Term) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Term) ProtoMessage() {}
+
+func (x *Term) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Term.ProtoReflect.Descriptor instead.
+func (*Term) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Term) GetXTrans() string {
+	if x != nil {
+		return x.XTrans
+	}
+	return ""
+}
+
+func (x *Term) GetBeta() float64 {
+	if x != nil {
+		return x.Beta
+	}
+	return 0
+}
+
+func (x *Term) GetBInt() float64 {
+	if x != nil {
+		return x.BInt
+	}
+	return 0
+}
+
+func (x *Term) GetVif() float64 {
+	if x != nil {
+		return x.Vif
+	}
+	return 0
+}
+
+func (x *Term) GetStd() float64 {
+	if x != nil {
+		return x.Std
+	}
+	return 0
+}
+
+// Row is a row of a matrix.
+type Row struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Values []float64 `protobuf:"fixed64,1,rep,packed,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *Row) Reset() {
+	*x = Row{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Row) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Row) ProtoMessage() {}
+
+func (x *Row) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Row.ProtoReflect.Descriptor instead.
+func (*Row) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Row) GetValues() []float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type FitResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Line       []*LinePoint `protobuf:"bytes,1,rep,name=line,proto3" json:"line,omitempty"`
+	Model      []*Term      `protobuf:"bytes,2,rep,name=model,proto3" json:"model,omitempty"`
+	R2         float64      `protobuf:"fixed64,3,opt,name=r2,proto3" json:"r2,omitempty"`
+	Mse        float64      `protobuf:"fixed64,4,opt,name=mse,proto3" json:"mse,omitempty"`
+	XMin       float64      `protobuf:"fixed64,5,opt,name=x_min,json=xMin,proto3" json:"x_min,omitempty"`
+	XMax       float64      `protobuf:"fixed64,6,opt,name=x_max,json=xMax,proto3" json:"x_max,omitempty"`
+	Equation   string       `protobuf:"bytes,7,opt,name=equation,proto3" json:"equation,omitempty"`
+	Latex      string       `protobuf:"bytes,8,opt,name=latex,proto3" json:"latex,omitempty"`
+	Complexity string       `protobuf:"bytes,9,opt,name=complexity,proto3" json:"complexity,omitempty"`
+	// condition number of the terms, each scaled to unit length
+	Condition float64 `protobuf:"fixed64,10,opt,name=condition,proto3" json:"condition,omitempty"`
+	// set if the terms are too close to collinear to trust the coefficients
+	Collinear bool `protobuf:"varint,11,opt,name=collinear,proto3" json:"collinear,omitempty"`
+	// number of linearly independent terms, less than the number of terms if
+	// the model is rank deficient
+	Rank int32 `protobuf:"varint,12,opt,name=rank,proto3" json:"rank,omitempty"`
+	// number of clusters that the confidence intervals allow to be correlated,
+	// if the points have clusters
+	Clusters int32 `protobuf:"varint,13,opt,name=clusters,proto3" json:"clusters,omitempty"`
+	// residual degrees of freedom, the points less the terms
+	Df int32 `protobuf:"varint,14,opt,name=df,proto3" json:"df,omitempty"`
+	// r2 adjusted for the number of terms
+	AdjR2 float64 `protobuf:"fixed64,15,opt,name=adj_r2,json=adjR2,proto3" json:"adj_r2,omitempty"`
+	// F statistic that all of the coefficients are zero, and its p-value
+	F      float64 `protobuf:"fixed64,16,opt,name=f,proto3" json:"f,omitempty"`
+	PValue float64 `protobuf:"fixed64,17,opt,name=p_value,json=pValue,proto3" json:"p_value,omitempty"`
+	// estimate of K, if the model uses it, which is already substituted into
+	// the terms
+	K float64 `protobuf:"fixed64,18,opt,name=k,proto3" json:"k,omitempty"`
+	// covariance matrix of the coefficients, in the order of the terms, and
+	// the same scaled to correlations
+	Covariance  []*Row `protobuf:"bytes,19,rep,name=covariance,proto3" json:"covariance,omitempty"`
+	Correlation []*Row `protobuf:"bytes,20,rep,name=correlation,proto3" json:"correlation,omitempty"`
+	// N of the points that were left out of the fit because a term overflowed
+	// or was NaN there
+	NonFinite []float64 `protobuf:"fixed64,21,rep,packed,name=non_finite,json=nonFinite,proto3" json:"non_finite,omitempty"`
+}
+
+func (x *FitResponse) Reset() {
+	*x = FitResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FitResponse) ProtoMessage() {}
+
+func (x *FitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FitResponse.ProtoReflect.Descriptor instead.
+func (*FitResponse) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FitResponse) GetLine() []*LinePoint {
+	if x != nil {
+		return x.Line
+	}
+	return nil
+}
+
+func (x *FitResponse) GetModel() []*Term {
+	if x != nil {
+		return x.Model
+	}
+	return nil
+}
+
+func (x *FitResponse) GetR2() float64 {
+	if x != nil {
+		return x.R2
+	}
+	return 0
+}
+
+func (x *FitResponse) GetMse() float64 {
+	if x != nil {
+		return x.Mse
+	}
+	return 0
+}
+
+func (x *FitResponse) GetXMin() float64 {
+	if x != nil {
+		return x.XMin
+	}
+	return 0
+}
+
+func (x *FitResponse) GetXMax() float64 {
+	if x != nil {
+		return x.XMax
+	}
+	return 0
+}
+
+func (x *FitResponse) GetEquation() string {
+	if x != nil {
+		return x.Equation
+	}
+	return ""
+}
+
+func (x *FitResponse) GetLatex() string {
+	if x != nil {
+		return x.Latex
+	}
+	return ""
+}
+
+func (x *FitResponse) GetComplexity() string {
+	if x != nil {
+		return x.Complexity
+	}
+	return ""
+}
+
+func (x *FitResponse) GetCondition() float64 {
+	if x != nil {
+		return x.Condition
+	}
+	return 0
+}
+
+func (x *FitResponse) GetCollinear() bool {
+	if x != nil {
+		return x.Collinear
+	}
+	return false
+}
+
+func (x *FitResponse) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *FitResponse) GetClusters() int32 {
+	if x != nil {
+		return x.Clusters
+	}
+	return 0
+}
+
+func (x *FitResponse) GetDf() int32 {
+	if x != nil {
+		return x.Df
+	}
+	return 0
+}
+
+func (x *FitResponse) GetAdjR2() float64 {
+	if x != nil {
+		return x.AdjR2
+	}
+	return 0
+}
+
+func (x *FitResponse) GetF() float64 {
+	if x != nil {
+		return x.F
+	}
+	return 0
+}
+
+func (x *FitResponse) GetPValue() float64 {
+	if x != nil {
+		return x.PValue
+	}
+	return 0
+}
+
+func (x *FitResponse) GetK() float64 {
+	if x != nil {
+		return x.K
+	}
+	return 0
+}
+
+func (x *FitResponse) GetCovariance() []*Row {
+	if x != nil {
+		return x.Covariance
+	}
+	return nil
+}
+
+func (x *FitResponse) GetCorrelation() []*Row {
+	if x != nil {
+		return x.Correlation
+	}
+	return nil
+}
+
+func (x *FitResponse) GetNonFinite() []float64 {
+	if x != nil {
+		return x.NonFinite
+	}
+	return nil
+}
+
+type CompareRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// output of go test -bench, or empty to use the server's benchmarks
+	Text       string    `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Grouping   *Grouping `protobuf:"bytes,2,opt,name=grouping,proto3" json:"grouping,omitempty"`
+	XTransform string    `protobuf:"bytes,3,opt,name=x_transform,json=xTransform,proto3" json:"x_transform,omitempty"`
+	YVar       string    `protobuf:"bytes,4,opt,name=y_var,json=yVar,proto3" json:"y_var,omitempty"`
+	// if both are set, only these groups are compared
+	A string `protobuf:"bytes,5,opt,name=a,proto3" json:"a,omitempty"`
+	B string `protobuf:"bytes,6,opt,name=b,proto3" json:"b,omitempty"`
+	// name of the estimator, which defaults to "ols"
+	Fitter string `protobuf:"bytes,7,opt,name=fitter,proto3" json:"fitter,omitempty"`
+	// strength of regularization, for the ridge and lasso fitters
+	Lambda *float64 `protobuf:"fixed64,8,opt,name=lambda,proto3,oneof" json:"lambda,omitempty"`
+}
+
+func (x *CompareRequest) Reset() {
+	*x = CompareRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompareRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareRequest) ProtoMessage() {}
+
+func (x *CompareRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareRequest.ProtoReflect.Descriptor instead.
+func (*CompareRequest) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CompareRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *CompareRequest) GetGrouping() *Grouping {
+	if x != nil {
+		return x.Grouping
+	}
+	return nil
+}
+
+func (x *CompareRequest) GetXTransform() string {
+	if x != nil {
+		return x.XTransform
+	}
+	return ""
+}
+
+func (x *CompareRequest) GetYVar() string {
+	if x != nil {
+		return x.YVar
+	}
+	return ""
+}
+
+func (x *CompareRequest) GetA() string {
+	if x != nil {
+		return x.A
+	}
+	return ""
+}
+
+func (x *CompareRequest) GetB() string {
+	if x != nil {
+		return x.B
+	}
+	return ""
+}
+
+func (x *CompareRequest) GetFitter() string {
+	if x != nil {
+		return x.Fitter
+	}
+	return ""
+}
+
+func (x *CompareRequest) GetLambda() float64 {
+	if x != nil && x.Lambda != nil {
+		return *x.Lambda
+	}
+	return 0
+}
+
+type GroupCoefficients struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Group string    `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Beta  []float64 `protobuf:"fixed64,2,rep,packed,name=beta,proto3" json:"beta,omitempty"`
+	BInt  []float64 `protobuf:"fixed64,3,rep,packed,name=b_int,json=bInt,proto3" json:"b_int,omitempty"`
+	// complexity class of the group, regardless of the model, like "O(N log N)"
+	Complexity string `protobuf:"bytes,4,opt,name=complexity,proto3" json:"complexity,omitempty"`
+}
+
+func (x *GroupCoefficients) Reset() {
+	*x = GroupCoefficients{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GroupCoefficients) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupCoefficients) ProtoMessage() {}
+
+func (x *GroupCoefficients) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupCoefficients.ProtoReflect.Descriptor instead.
+func (*GroupCoefficients) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GroupCoefficients) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *GroupCoefficients) GetBeta() []float64 {
+	if x != nil {
+		return x.Beta
+	}
+	return nil
+}
+
+func (x *GroupCoefficients) GetBInt() []float64 {
+	if x != nil {
+		return x.BInt
+	}
+	return nil
+}
+
+func (x *GroupCoefficients) GetComplexity() string {
+	if x != nil {
+		return x.Complexity
+	}
+	return ""
+}
+
+type ChowTest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	A   string  `protobuf:"bytes,1,opt,name=a,proto3" json:"a,omitempty"`
+	B   string  `protobuf:"bytes,2,opt,name=b,proto3" json:"b,omitempty"`
+	F   float64 `protobuf:"fixed64,3,opt,name=f,proto3" json:"f,omitempty"`
+	Df1 int32   `protobuf:"varint,4,opt,name=df1,proto3" json:"df1,omitempty"`
+	Df2 int32   `protobuf:"varint,5,opt,name=df2,proto3" json:"df2,omitempty"`
+	P   float64 `protobuf:"fixed64,6,opt,name=p,proto3" json:"p,omitempty"`
+}
+
+func (x *ChowTest) Reset() {
+	*x = ChowTest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChowTest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChowTest) ProtoMessage() {}
+
+func (x *ChowTest) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChowTest.ProtoReflect.Descriptor instead.
+func (*ChowTest) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ChowTest) GetA() string {
+	if x != nil {
+		return x.A
+	}
+	return ""
+}
+
+func (x *ChowTest) GetB() string {
+	if x != nil {
+		return x.B
+	}
+	return ""
+}
+
+func (x *ChowTest) GetF() float64 {
+	if x != nil {
+		return x.F
+	}
+	return 0
+}
+
+func (x *ChowTest) GetDf1() int32 {
+	if x != nil {
+		return x.Df1
+	}
+	return 0
+}
+
+func (x *ChowTest) GetDf2() int32 {
+	if x != nil {
+		return x.Df2
+	}
+	return 0
+}
+
+func (x *ChowTest) GetP() float64 {
+	if x != nil {
+		return x.P
+	}
+	return 0
+}
+
+type Crossover struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	A            string  `protobuf:"bytes,1,opt,name=a,proto3" json:"a,omitempty"`
+	B            string  `protobuf:"bytes,2,opt,name=b,proto3" json:"b,omitempty"`
+	N            float64 `protobuf:"fixed64,3,opt,name=n,proto3" json:"n,omitempty"`
+	NLow         float64 `protobuf:"fixed64,4,opt,name=n_low,json=nLow,proto3" json:"n_low,omitempty"`
+	NHigh        float64 `protobuf:"fixed64,5,opt,name=n_high,json=nHigh,proto3" json:"n_high,omitempty"`
+	Lower        string  `protobuf:"bytes,6,opt,name=lower,proto3" json:"lower,omitempty"`
+	Extrapolated bool    `protobuf:"varint,7,opt,name=extrapolated,proto3" json:"extrapolated,omitempty"`
+}
+
+func (x *Crossover) Reset() {
+	*x = Crossover{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Crossover) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Crossover) ProtoMessage() {}
+
+func (x *Crossover) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Crossover.ProtoReflect.Descriptor instead.
+func (*Crossover) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Crossover) GetA() string {
+	if x != nil {
+		return x.A
+	}
+	return ""
+}
+
+func (x *Crossover) GetB() string {
+	if x != nil {
+		return x.B
+	}
+	return ""
+}
+
+func (x *Crossover) GetN() float64 {
+	if x != nil {
+		return x.N
+	}
+	return 0
+}
+
+func (x *Crossover) GetNLow() float64 {
+	if x != nil {
+		return x.NLow
+	}
+	return 0
+}
+
+func (x *Crossover) GetNHigh() float64 {
+	if x != nil {
+		return x.NHigh
+	}
+	return 0
+}
+
+func (x *Crossover) GetLower() string {
+	if x != nil {
+		return x.Lower
+	}
+	return ""
+}
+
+func (x *Crossover) GetExtrapolated() bool {
+	if x != nil {
+		return x.Extrapolated
+	}
+	return false
+}
+
+type CompareResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Terms        []string             `protobuf:"bytes,1,rep,name=terms,proto3" json:"terms,omitempty"`
+	Coefficients []*GroupCoefficients `protobuf:"bytes,2,rep,name=coefficients,proto3" json:"coefficients,omitempty"`
+	ChowTests    []*ChowTest          `protobuf:"bytes,3,rep,name=chow_tests,json=chowTests,proto3" json:"chow_tests,omitempty"`
+	Crossovers   []*Crossover         `protobuf:"bytes,4,rep,name=crossovers,proto3" json:"crossovers,omitempty"`
+}
+
+func (x *CompareResponse) Reset() {
+	*x = CompareResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_benchplot_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompareResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareResponse) ProtoMessage() {}
+
+func (x *CompareResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_benchplot_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareResponse.ProtoReflect.Descriptor instead.
+func (*CompareResponse) Descriptor() ([]byte, []int) {
+	return file_benchplot_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CompareResponse) GetTerms() []string {
+	if x != nil {
+		return x.Terms
+	}
+	return nil
+}
+
+func (x *CompareResponse) GetCoefficients() []*GroupCoefficients {
+	if x != nil {
+		return x.Coefficients
+	}
+	return nil
+}
+
+func (x *CompareResponse) GetChowTests() []*ChowTest {
+	if x != nil {
+		return x.ChowTests
+	}
+	return nil
+}
+
+func (x *CompareResponse) GetCrossovers() []*Crossover {
+	if x != nil {
+		return x.Crossovers
+	}
+	return nil
+}
+
+var File_benchplot_proto protoreflect.FileDescriptor
+
+var file_benchplot_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x09, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x22, 0xd4, 0x03, 0x0a,
+	0x09, 0x42, 0x65, 0x6e, 0x63, 0x68, 0x6d, 0x61, 0x72, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x0c,
+	0x0a, 0x01, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x01, 0x6e, 0x12, 0x1a, 0x0a, 0x09,
+	0x6e, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x6f, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x07, 0x6e, 0x73, 0x50, 0x65, 0x72, 0x4f, 0x70, 0x12, 0x2f, 0x0a, 0x14, 0x61, 0x6c, 0x6c, 0x6f,
+	0x63, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x6f, 0x70,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x11, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x65, 0x64, 0x42,
+	0x79, 0x74, 0x65, 0x73, 0x50, 0x65, 0x72, 0x4f, 0x70, 0x12, 0x22, 0x0a, 0x0d, 0x61, 0x6c, 0x6c,
+	0x6f, 0x63, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x6f, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x0b, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x73, 0x50, 0x65, 0x72, 0x4f, 0x70, 0x12, 0x18, 0x0a,
+	0x08, 0x6d, 0x62, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x06, 0x6d, 0x62, 0x50, 0x65, 0x72, 0x53, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61,
+	0x67, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x12, 0x33, 0x0a, 0x13, 0x69,
+	0x6e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f,
+	0x6f, 0x70, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x11, 0x69, 0x6e, 0x73, 0x74,
+	0x72, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x50, 0x65, 0x72, 0x4f, 0x70, 0x88, 0x01, 0x01,
+	0x12, 0x32, 0x0a, 0x13, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73,
+	0x5f, 0x70, 0x65, 0x72, 0x5f, 0x6f, 0x70, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52,
+	0x10, 0x63, 0x61, 0x63, 0x68, 0x65, 0x4d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x50, 0x65, 0x72, 0x4f,
+	0x70, 0x88, 0x01, 0x01, 0x12, 0x34, 0x0a, 0x14, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x5f, 0x6d,
+	0x69, 0x73, 0x73, 0x65, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x6f, 0x70, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x01, 0x48, 0x02, 0x52, 0x11, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x4d, 0x69, 0x73, 0x73,
+	0x65, 0x73, 0x50, 0x65, 0x72, 0x4f, 0x70, 0x88, 0x01, 0x01, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x69,
+	0x6e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f,
+	0x6f, 0x70, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x6d, 0x69, 0x73,
+	0x73, 0x65, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x6f, 0x70, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x62,
+	0x72, 0x61, 0x6e, 0x63, 0x68, 0x5f, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x5f, 0x70, 0x65, 0x72,
+	0x5f, 0x6f, 0x70, 0x22, 0xe8, 0x01, 0x0a, 0x08, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x69, 0x6e, 0x67,
+	0x12, 0x10, 0x0a, 0x03, 0x6e, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6e,
+	0x72, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x75, 0x6e, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65,
+	0x72, 0x69, 0x7a, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x75, 0x6e, 0x70,
+	0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x69, 0x7a, 0x65, 0x64, 0x12, 0x10, 0x0a, 0x03,
+	0x70, 0x6b, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x70, 0x6b, 0x67, 0x12, 0x1d,
+	0x0a, 0x0a, 0x62, 0x79, 0x5f, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x09, 0x62, 0x79, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x12, 0x1c, 0x0a,
+	0x09, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d,
+	0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x61,
+	0x63, 0x68, 0x69, 0x6e, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x79, 0x5f, 0x6d, 0x61, 0x63, 0x68,
+	0x69, 0x6e, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x62, 0x79, 0x4d, 0x61, 0x63,
+	0x68, 0x69, 0x6e, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x22, 0x63,
+	0x0a, 0x05, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x32, 0x0a, 0x09, 0x62, 0x65, 0x6e, 0x63, 0x68,
+	0x6d, 0x61, 0x72, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x62, 0x65, 0x6e,
+	0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x2e, 0x42, 0x65, 0x6e, 0x63, 0x68, 0x6d, 0x61, 0x72, 0x6b,
+	0x52, 0x09, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x6d, 0x61, 0x72, 0x6b, 0x12, 0x0c, 0x0a, 0x01, 0x78,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x78, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6c, 0x75,
+	0x73, 0x74, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6c, 0x75, 0x73,
+	0x74, 0x65, 0x72, 0x22, 0x45, 0x0a, 0x05, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x28, 0x0a, 0x06, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x10, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x2e, 0x50, 0x6f, 0x69,
+	0x6e, 0x74, 0x52, 0x06, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x22, 0x43, 0x0a, 0x07, 0x57, 0x61,
+	0x72, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x10, 0x0a,
+	0x03, 0x65, 0x72, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x72, 0x72, 0x22,
+	0x53, 0x0a, 0x0c, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x65, 0x78, 0x74, 0x12, 0x2f, 0x0a, 0x08, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x69, 0x6e, 0x67, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f,
+	0x74, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x67, 0x72, 0x6f, 0x75,
+	0x70, 0x69, 0x6e, 0x67, 0x22, 0xe6, 0x02, 0x0a, 0x0d, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x0a, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x6d,
+	0x61, 0x72, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x62, 0x65, 0x6e,
+	0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x2e, 0x42, 0x65, 0x6e, 0x63, 0x68, 0x6d, 0x61, 0x72, 0x6b,
+	0x52, 0x0a, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x6d, 0x61, 0x72, 0x6b, 0x73, 0x12, 0x3c, 0x0a, 0x06,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x62,
+	0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x6b,
+	0x69, 0x70, 0x70, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x73, 0x6b, 0x69,
+	0x70, 0x70, 0x65, 0x64, 0x12, 0x2e, 0x0a, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73,
+	0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c,
+	0x6f, 0x74, 0x2e, 0x57, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x77, 0x61, 0x72, 0x6e,
+	0x69, 0x6e, 0x67, 0x73, 0x12, 0x28, 0x0a, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74,
+	0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x32,
+	0x0a, 0x09, 0x75, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x14, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x2e, 0x42, 0x65,
+	0x6e, 0x63, 0x68, 0x6d, 0x61, 0x72, 0x6b, 0x52, 0x09, 0x75, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68,
+	0x65, 0x64, 0x1a, 0x39, 0x0a, 0x0b, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x8c, 0x02,
+	0x0a, 0x0a, 0x46, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x28, 0x0a, 0x06,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x62,
+	0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x06,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x78, 0x5f, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x78, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x13, 0x0a, 0x05, 0x79, 0x5f, 0x76, 0x61, 0x72,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x79, 0x56, 0x61, 0x72, 0x12, 0x15, 0x0a, 0x03,
+	0x78, 0x6c, 0x62, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x03, 0x78, 0x6c, 0x62,
+	0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x78, 0x75, 0x62, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x01, 0x52, 0x03, 0x78, 0x75, 0x62, 0x88, 0x01, 0x01, 0x12, 0x20, 0x0a, 0x0c, 0x6e, 0x5f,
+	0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x73, 0x74, 0x65, 0x70, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0a, 0x6e, 0x4c, 0x69, 0x6e, 0x65, 0x53, 0x74, 0x65, 0x70, 0x73, 0x12, 0x16, 0x0a, 0x06,
+	0x66, 0x69, 0x74, 0x74, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x69,
+	0x74, 0x74, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x06, 0x6c, 0x61, 0x6d, 0x62, 0x64, 0x61, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x01, 0x48, 0x02, 0x52, 0x06, 0x6c, 0x61, 0x6d, 0x62, 0x64, 0x61, 0x88, 0x01,
+	0x01, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x78, 0x6c, 0x62, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x78, 0x75,
+	0x62, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x6c, 0x61, 0x6d, 0x62, 0x64, 0x61, 0x22, 0x70, 0x0a, 0x09,
+	0x4c, 0x69, 0x6e, 0x65, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x0c, 0x0a, 0x01, 0x78, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x79, 0x68, 0x61, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x79, 0x68, 0x61, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x63,
+	0x6f, 0x6e, 0x66, 0x5f, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x09, 0x63, 0x6f, 0x6e, 0x66, 0x57, 0x69, 0x64, 0x74, 0x68, 0x12, 0x22, 0x0a, 0x0c, 0x65, 0x78,
+	0x74, 0x72, 0x61, 0x70, 0x6f, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0c, 0x65, 0x78, 0x74, 0x72, 0x61, 0x70, 0x6f, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x22, 0x6c,
+	0x0a, 0x04, 0x54, 0x65, 0x72, 0x6d, 0x12, 0x17, 0x0a, 0x07, 0x78, 0x5f, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x78, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x12,
+	0x12, 0x0a, 0x04, 0x62, 0x65, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x62,
+	0x65, 0x74, 0x61, 0x12, 0x13, 0x0a, 0x05, 0x62, 0x5f, 0x69, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x04, 0x62, 0x49, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x69, 0x66, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x76, 0x69, 0x66, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x74,
+	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x73, 0x74, 0x64, 0x22, 0x1d, 0x0a, 0x03,
+	0x52, 0x6f, 0x77, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x01, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0xc5, 0x04, 0x0a, 0x0b,
+	0x46, 0x69, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x04, 0x6c,
+	0x69, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x62, 0x65, 0x6e, 0x63,
+	0x68, 0x70, 0x6c, 0x6f, 0x74, 0x2e, 0x4c, 0x69, 0x6e, 0x65, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52,
+	0x04, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x25, 0x0a, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74,
+	0x2e, 0x54, 0x65, 0x72, 0x6d, 0x52, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x0e, 0x0a, 0x02,
+	0x72, 0x32, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x02, 0x72, 0x32, 0x12, 0x10, 0x0a, 0x03,
+	0x6d, 0x73, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6d, 0x73, 0x65, 0x12, 0x13,
+	0x0a, 0x05, 0x78, 0x5f, 0x6d, 0x69, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x78,
+	0x4d, 0x69, 0x6e, 0x12, 0x13, 0x0a, 0x05, 0x78, 0x5f, 0x6d, 0x61, 0x78, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x04, 0x78, 0x4d, 0x61, 0x78, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x71, 0x75, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x71, 0x75, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x74, 0x65, 0x78, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x61, 0x74, 0x65, 0x78, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f,
+	0x6d, 0x70, 0x6c, 0x65, 0x78, 0x69, 0x74, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x69, 0x74, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f,
+	0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x63,
+	0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6c, 0x6c,
+	0x69, 0x6e, 0x65, 0x61, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6c,
+	0x6c, 0x69, 0x6e, 0x65, 0x61, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x6e, 0x6b, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x72, 0x61, 0x6e, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6c,
+	0x75, 0x73, 0x74, 0x65, 0x72, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x63, 0x6c,
+	0x75, 0x73, 0x74, 0x65, 0x72, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x64, 0x66, 0x18, 0x0e, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x02, 0x64, 0x66, 0x12, 0x15, 0x0a, 0x06, 0x61, 0x64, 0x6a, 0x5f, 0x72, 0x32,
+	0x18, 0x0f, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x61, 0x64, 0x6a, 0x52, 0x32, 0x12, 0x0c, 0x0a,
+	0x01, 0x66, 0x18, 0x10, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x66, 0x12, 0x17, 0x0a, 0x07, 0x70,
+	0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x11, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x70, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x12, 0x0c, 0x0a, 0x01, 0x6b, 0x18, 0x12, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x01, 0x6b, 0x12, 0x2e, 0x0a, 0x0a, 0x63, 0x6f, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x63, 0x65,
+	0x18, 0x13, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c,
+	0x6f, 0x74, 0x2e, 0x52, 0x6f, 0x77, 0x52, 0x0a, 0x63, 0x6f, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e,
+	0x63, 0x65, 0x12, 0x30, 0x0a, 0x0b, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x14, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70,
+	0x6c, 0x6f, 0x74, 0x2e, 0x52, 0x6f, 0x77, 0x52, 0x0b, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x6f, 0x6e, 0x5f, 0x66, 0x69, 0x6e, 0x69,
+	0x74, 0x65, 0x18, 0x15, 0x20, 0x03, 0x28, 0x01, 0x52, 0x09, 0x6e, 0x6f, 0x6e, 0x46, 0x69, 0x6e,
+	0x69, 0x74, 0x65, 0x22, 0xe7, 0x01, 0x0a, 0x0e, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x2f, 0x0a, 0x08, 0x67, 0x72,
+	0x6f, 0x75, 0x70, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x62,
+	0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x69, 0x6e,
+	0x67, 0x52, 0x08, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x69, 0x6e, 0x67, 0x12, 0x1f, 0x0a, 0x0b, 0x78,
+	0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x78, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x13, 0x0a, 0x05,
+	0x79, 0x5f, 0x76, 0x61, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x79, 0x56, 0x61,
+	0x72, 0x12, 0x0c, 0x0a, 0x01, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x01, 0x61, 0x12,
+	0x0c, 0x0a, 0x01, 0x62, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x01, 0x62, 0x12, 0x16, 0x0a,
+	0x06, 0x66, 0x69, 0x74, 0x74, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66,
+	0x69, 0x74, 0x74, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x06, 0x6c, 0x61, 0x6d, 0x62, 0x64, 0x61, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x06, 0x6c, 0x61, 0x6d, 0x62, 0x64, 0x61, 0x88,
+	0x01, 0x01, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x6c, 0x61, 0x6d, 0x62, 0x64, 0x61, 0x22, 0x72, 0x0a,
+	0x11, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x6f, 0x65, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e,
+	0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x65, 0x74, 0x61,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x01, 0x52, 0x04, 0x62, 0x65, 0x74, 0x61, 0x12, 0x13, 0x0a, 0x05,
+	0x62, 0x5f, 0x69, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x03, 0x28, 0x01, 0x52, 0x04, 0x62, 0x49, 0x6e,
+	0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x69, 0x74, 0x79, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x69, 0x74,
+	0x79, 0x22, 0x66, 0x0a, 0x08, 0x43, 0x68, 0x6f, 0x77, 0x54, 0x65, 0x73, 0x74, 0x12, 0x0c, 0x0a,
+	0x01, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x01, 0x61, 0x12, 0x0c, 0x0a, 0x01, 0x62,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x01, 0x62, 0x12, 0x0c, 0x0a, 0x01, 0x66, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x66, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x66, 0x31, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x64, 0x66, 0x31, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x66, 0x32,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x64, 0x66, 0x32, 0x12, 0x0c, 0x0a, 0x01, 0x70,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x70, 0x22, 0x9b, 0x01, 0x0a, 0x09, 0x43, 0x72,
+	0x6f, 0x73, 0x73, 0x6f, 0x76, 0x65, 0x72, 0x12, 0x0c, 0x0a, 0x01, 0x61, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x01, 0x61, 0x12, 0x0c, 0x0a, 0x01, 0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x01, 0x62, 0x12, 0x0c, 0x0a, 0x01, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01,
+	0x6e, 0x12, 0x13, 0x0a, 0x05, 0x6e, 0x5f, 0x6c, 0x6f, 0x77, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x04, 0x6e, 0x4c, 0x6f, 0x77, 0x12, 0x15, 0x0a, 0x06, 0x6e, 0x5f, 0x68, 0x69, 0x67, 0x68,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x6e, 0x48, 0x69, 0x67, 0x68, 0x12, 0x14, 0x0a,
+	0x05, 0x6c, 0x6f, 0x77, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f,
+	0x77, 0x65, 0x72, 0x12, 0x22, 0x0a, 0x0c, 0x65, 0x78, 0x74, 0x72, 0x61, 0x70, 0x6f, 0x6c, 0x61,
+	0x74, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x65, 0x78, 0x74, 0x72, 0x61,
+	0x70, 0x6f, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x22, 0xd3, 0x01, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x70,
+	0x61, 0x72, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74,
+	0x65, 0x72, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x74, 0x65, 0x72, 0x6d,
+	0x73, 0x12, 0x40, 0x0a, 0x0c, 0x63, 0x6f, 0x65, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e, 0x74,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70,
+	0x6c, 0x6f, 0x74, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x6f, 0x65, 0x66, 0x66, 0x69, 0x63,
+	0x69, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x0c, 0x63, 0x6f, 0x65, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65,
+	0x6e, 0x74, 0x73, 0x12, 0x32, 0x0a, 0x0a, 0x63, 0x68, 0x6f, 0x77, 0x5f, 0x74, 0x65, 0x73, 0x74,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70,
+	0x6c, 0x6f, 0x74, 0x2e, 0x43, 0x68, 0x6f, 0x77, 0x54, 0x65, 0x73, 0x74, 0x52, 0x09, 0x63, 0x68,
+	0x6f, 0x77, 0x54, 0x65, 0x73, 0x74, 0x73, 0x12, 0x34, 0x0a, 0x0a, 0x63, 0x72, 0x6f, 0x73, 0x73,
+	0x6f, 0x76, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x62, 0x65,
+	0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x2e, 0x43, 0x72, 0x6f, 0x73, 0x73, 0x6f, 0x76, 0x65,
+	0x72, 0x52, 0x0a, 0x63, 0x72, 0x6f, 0x73, 0x73, 0x6f, 0x76, 0x65, 0x72, 0x73, 0x32, 0xbf, 0x01,
+	0x0a, 0x09, 0x42, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x12, 0x3a, 0x0a, 0x05, 0x50,
+	0x61, 0x72, 0x73, 0x65, 0x12, 0x17, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74,
+	0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e,
+	0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x03, 0x46, 0x69, 0x74, 0x12, 0x15,
+	0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x2e, 0x46, 0x69, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f,
+	0x74, 0x2e, 0x46, 0x69, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x40, 0x0a,
+	0x07, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x65, 0x12, 0x19, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68,
+	0x70, 0x6c, 0x6f, 0x74, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x2e,
+	0x43, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42,
+	0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6a, 0x6f,
+	0x6e, 0x6c, 0x61, 0x77, 0x6c, 0x6f, 0x72, 0x2f, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f,
+	0x74, 0x2f, 0x62, 0x65, 0x6e, 0x63, 0x68, 0x70, 0x6c, 0x6f, 0x74, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_benchplot_proto_rawDescOnce sync.Once
+	file_benchplot_proto_rawDescData = file_benchplot_proto_rawDesc
+)
+
+func file_benchplot_proto_rawDescGZIP() []byte {
+	file_benchplot_proto_rawDescOnce.Do(func() {
+		file_benchplot_proto_rawDescData = protoimpl.X.CompressGZIP(file_benchplot_proto_rawDescData)
+	})
+	return file_benchplot_proto_rawDescData
+}
+
+var file_benchplot_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_benchplot_proto_goTypes = []interface{}{
+	(*Benchmark)(nil),         // 0: benchplot.Benchmark
+	(*Grouping)(nil),          // 1: benchplot.Grouping
+	(*Point)(nil),             // 2: benchplot.Point
+	(*Group)(nil),             // 3: benchplot.Group
+	(*Warning)(nil),           // 4: benchplot.Warning
+	(*ParseRequest)(nil),      // 5: benchplot.ParseRequest
+	(*ParseResponse)(nil),     // 6: benchplot.ParseResponse
+	(*FitRequest)(nil),        // 7: benchplot.FitRequest
+	(*LinePoint)(nil),         // 8: benchplot.LinePoint
+	(*Term)(nil),              // 9: benchplot.Term
+	(*Row)(nil),               // 10: benchplot.Row
+	(*FitResponse)(nil),       // 11: benchplot.FitResponse
+	(*CompareRequest)(nil),    // 12: benchplot.CompareRequest
+	(*GroupCoefficients)(nil), // 13: benchplot.GroupCoefficients
+	(*ChowTest)(nil),          // 14: benchplot.ChowTest
+	(*Crossover)(nil),         // 15: benchplot.Crossover
+	(*CompareResponse)(nil),   // 16: benchplot.CompareResponse
+	nil,                       // 17: benchplot.ParseResponse.ConfigEntry
+}
+var file_benchplot_proto_depIdxs = []int32{
+	0,  // 0: benchplot.Point.benchmark:type_name -> benchplot.Benchmark
+	2,  // 1: benchplot.Group.points:type_name -> benchplot.Point
+	1,  // 2: benchplot.ParseRequest.grouping:type_name -> benchplot.Grouping
+	0,  // 3: benchplot.ParseResponse.benchmarks:type_name -> benchplot.Benchmark
+	17, // 4: benchplot.ParseResponse.config:type_name -> benchplot.ParseResponse.ConfigEntry
+	4,  // 5: benchplot.ParseResponse.warnings:type_name -> benchplot.Warning
+	3,  // 6: benchplot.ParseResponse.groups:type_name -> benchplot.Group
+	0,  // 7: benchplot.ParseResponse.unmatched:type_name -> benchplot.Benchmark
+	2,  // 8: benchplot.FitRequest.points:type_name -> benchplot.Point
+	8,  // 9: benchplot.FitResponse.line:type_name -> benchplot.LinePoint
+	9,  // 10: benchplot.FitResponse.model:type_name -> benchplot.Term
+	10, // 11: benchplot.FitResponse.covariance:type_name -> benchplot.Row
+	10, // 12: benchplot.FitResponse.correlation:type_name -> benchplot.Row
+	1,  // 13: benchplot.CompareRequest.grouping:type_name -> benchplot.Grouping
+	13, // 14: benchplot.CompareResponse.coefficients:type_name -> benchplot.GroupCoefficients
+	14, // 15: benchplot.CompareResponse.chow_tests:type_name -> benchplot.ChowTest
+	15, // 16: benchplot.CompareResponse.crossovers:type_name -> benchplot.Crossover
+	5,  // 17: benchplot.Benchplot.Parse:input_type -> benchplot.ParseRequest
+	7,  // 18: benchplot.Benchplot.Fit:input_type -> benchplot.FitRequest
+	12, // 19: benchplot.Benchplot.Compare:input_type -> benchplot.CompareRequest
+	6,  // 20: benchplot.Benchplot.Parse:output_type -> benchplot.ParseResponse
+	11, // 21: benchplot.Benchplot.Fit:output_type -> benchplot.FitResponse
+	16, // 22: benchplot.Benchplot.Compare:output_type -> benchplot.CompareResponse
+	20, // [20:23] is the sub-list for method output_type
+	17, // [17:20] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_benchplot_proto_init() }
+func file_benchplot_proto_init() {
+	if File_benchplot_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_benchplot_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Benchmark); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Grouping); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Point); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Group); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Warning); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ParseRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ParseResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FitRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LinePoint); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Term); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Row); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FitResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompareRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GroupCoefficients); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChowTest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Crossover); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_benchplot_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompareResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_benchplot_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	file_benchplot_proto_msgTypes[7].OneofWrappers = []interface{}{}
+	file_benchplot_proto_msgTypes[12].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_benchplot_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_benchplot_proto_goTypes,
+		DependencyIndexes: file_benchplot_proto_depIdxs,
+		MessageInfos:      file_benchplot_proto_msgTypes,
+	}.Build()
+	File_benchplot_proto = out.File
+	file_benchplot_proto_rawDesc = nil
+	file_benchplot_proto_goTypes = nil
+	file_benchplot_proto_depIdxs = nil
+}
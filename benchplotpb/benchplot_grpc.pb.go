@@ -0,0 +1,193 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: benchplot.proto
+
+package benchplotpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Benchplot_Parse_FullMethodName   = "/benchplot.Benchplot/Parse"
+	Benchplot_Fit_FullMethodName     = "/benchplot.Benchplot/Fit"
+	Benchplot_Compare_FullMethodName = "/benchplot.Benchplot/Compare"
+)
+
+// BenchplotClient is the client API for Benchplot service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BenchplotClient interface {
+	// Parse parses the output of go test -bench, and groups the benchmarks.
+	Parse(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error)
+	// Fit fits a model to a single group of benchmarks.
+	Fit(ctx context.Context, in *FitRequest, opts ...grpc.CallOption) (*FitResponse, error)
+	// Compare fits every group of benchmarks, and compares each pair of them.
+	Compare(ctx context.Context, in *CompareRequest, opts ...grpc.CallOption) (*CompareResponse, error)
+}
+
+type benchplotClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBenchplotClient(cc grpc.ClientConnInterface) BenchplotClient {
+	return &benchplotClient{cc}
+}
+
+func (c *benchplotClient) Parse(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error) {
+	out := new(ParseResponse)
+	err := c.cc.Invoke(ctx, Benchplot_Parse_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *benchplotClient) Fit(ctx context.Context, in *FitRequest, opts ...grpc.CallOption) (*FitResponse, error) {
+	out := new(FitResponse)
+	err := c.cc.Invoke(ctx, Benchplot_Fit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *benchplotClient) Compare(ctx context.Context, in *CompareRequest, opts ...grpc.CallOption) (*CompareResponse, error) {
+	out := new(CompareResponse)
+	err := c.cc.Invoke(ctx, Benchplot_Compare_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BenchplotServer is the server API for Benchplot service.
+// All implementations must embed UnimplementedBenchplotServer
+// for forward compatibility
+type BenchplotServer interface {
+	// Parse parses the output of go test -bench, and groups the benchmarks.
+	Parse(context.Context, *ParseRequest) (*ParseResponse, error)
+	// Fit fits a model to a single group of benchmarks.
+	Fit(context.Context, *FitRequest) (*FitResponse, error)
+	// Compare fits every group of benchmarks, and compares each pair of them.
+	Compare(context.Context, *CompareRequest) (*CompareResponse, error)
+	mustEmbedUnimplementedBenchplotServer()
+}
+
+// UnimplementedBenchplotServer must be embedded to have forward compatible implementations.
+type UnimplementedBenchplotServer struct {
+}
+
+func (UnimplementedBenchplotServer) Parse(context.Context, *ParseRequest) (*ParseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Parse not implemented")
+}
+func (UnimplementedBenchplotServer) Fit(context.Context, *FitRequest) (*FitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Fit not implemented")
+}
+func (UnimplementedBenchplotServer) Compare(context.Context, *CompareRequest) (*CompareResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Compare not implemented")
+}
+func (UnimplementedBenchplotServer) mustEmbedUnimplementedBenchplotServer() {}
+
+// UnsafeBenchplotServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BenchplotServer will
+// result in compilation errors.
+type UnsafeBenchplotServer interface {
+	mustEmbedUnimplementedBenchplotServer()
+}
+
+func RegisterBenchplotServer(s grpc.ServiceRegistrar, srv BenchplotServer) {
+	s.RegisterService(&Benchplot_ServiceDesc, srv)
+}
+
+func _Benchplot_Parse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BenchplotServer).Parse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Benchplot_Parse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BenchplotServer).Parse(ctx, req.(*ParseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Benchplot_Fit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BenchplotServer).Fit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Benchplot_Fit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BenchplotServer).Fit(ctx, req.(*FitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Benchplot_Compare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BenchplotServer).Compare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Benchplot_Compare_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BenchplotServer).Compare(ctx, req.(*CompareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Benchplot_ServiceDesc is the grpc.ServiceDesc for Benchplot service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Benchplot_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "benchplot.Benchplot",
+	HandlerType: (*BenchplotServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Parse",
+			Handler:    _Benchplot_Parse_Handler,
+		},
+		{
+			MethodName: "Fit",
+			Handler:    _Benchplot_Fit_Handler,
+		},
+		{
+			MethodName: "Compare",
+			Handler:    _Benchplot_Compare_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "benchplot.proto",
+}
@@ -0,0 +1,89 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sync"
+)
+
+// fitCacheSize is the number of fits which are kept, which is enough for
+// the plotter to toggle between a few settings with a few dozen groups.
+const fitCacheSize = 256
+
+// fitCache keeps the most recently used fits, so that switching the plotter
+// back to settings it has already used doesn't fit the same benchmarks
+// again.
+type fitCache struct {
+	mu    sync.Mutex
+	max   int
+	order *list.List // of *cachedFit, most recently used first
+	fits  map[string]*list.Element
+}
+
+type cachedFit struct {
+	key string
+	res fitResponse
+}
+
+func newFitCache(max int) *fitCache {
+	return &fitCache{max: max, order: list.New(), fits: make(map[string]*list.Element)}
+}
+
+// fitKey identifies a fit by its parameters and the benchmarks in it, as
+// they were sent.
+func fitKey(v url.Values, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(v.Encode()))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *fitCache) get(key string) (fitResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.fits[key]
+	if !ok {
+		return fitResponse{}, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*cachedFit).res, true
+}
+
+func (c *fitCache) add(key string, res fitResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.fits[key]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+	c.fits[key] = c.order.PushFront(&cachedFit{key, res})
+	for c.order.Len() > c.max {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.fits, e.Value.(*cachedFit).key)
+	}
+}
+
+// fitResults are the fits made by /fit and over the websocket.
+var fitResults = newFitCache(fitCacheSize)
+
+// fitCached is fit, with the result taken from the cache if the same fit
+// has already been made.  The response is shared, so it must not be changed.
+func fitCached(ctx context.Context, key string, benchSet []benchmarkResponse, req fitRequest) (fitResponse, error) {
+	if res, ok := fitResults.get(key); ok {
+		return res, nil
+	}
+	res, err := fit(ctx, benchSet, req)
+	if err == nil {
+		fitResults.add(key, res)
+	}
+	return res, err
+}
@@ -0,0 +1,72 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+func TestFitCache(t *testing.T) {
+	c := newFitCache(2)
+	c.add("a", fitResponse{R2: 1})
+	c.add("b", fitResponse{R2: 2})
+	if _, ok := c.get("a"); !ok {
+		t.Fatalf("a isn't cached")
+	}
+	// b is the least recently used, so it is evicted
+	c.add("c", fitResponse{R2: 3})
+	if _, ok := c.get("b"); ok {
+		t.Errorf("b wasn't evicted")
+	}
+	for key, want := range map[string]float64{"a": 1, "c": 3} {
+		if res, ok := c.get(key); !ok || res.R2 != want {
+			t.Errorf("got %v, %t for %s, want R2 = %v", res.R2, ok, key, want)
+		}
+	}
+}
+
+func TestFitKey(t *testing.T) {
+	a := fitKey(url.Values{"xtransform": {"N, 1.0"}, "yvar": {"NsPerOp"}}, []byte("[]"))
+	b := fitKey(url.Values{"yvar": {"NsPerOp"}, "xtransform": {"N, 1.0"}}, []byte("[]"))
+	if a != b {
+		t.Errorf("the key depends on the order of the parameters")
+	}
+	if c := fitKey(url.Values{"xtransform": {"N, 1.0"}, "yvar": {"MBPerS"}}, []byte("[]")); c == a {
+		t.Errorf("different parameters have the same key")
+	}
+	if c := fitKey(url.Values{"xtransform": {"N, 1.0"}, "yvar": {"NsPerOp"}}, []byte("[{}]")); c == a {
+		t.Errorf("different benchmarks have the same key")
+	}
+}
+
+func TestFitCached(t *testing.T) {
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"N, 1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := fitRequest{xTransform: q.xTransform, yVar: "NsPerOp", fitter: q.fitter, xlb: 1, xub: 10, nLineSteps: 10}
+	key := fitKey(url.Values{"test": {t.Name()}}, nil)
+	res, err := fitCached(context.Background(), key, linearBenchSet(3, 5), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached, ok := fitResults.get(key)
+	if !ok || cached.MSE != res.MSE {
+		t.Errorf("the fit wasn't cached")
+	}
+
+	// a fit which fails isn't cached
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	key = fitKey(url.Values{"test": {t.Name() + " cancelled"}}, nil)
+	if _, err := fitCached(ctx, key, linearBenchSet(3, 5), req); err == nil {
+		t.Fatal("a cancelled fit succeeded")
+	}
+	if _, ok := fitResults.get(key); ok {
+		t.Errorf("a cancelled fit was cached")
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// cacheSizesNone turns off the cache size annotations.
+const cacheSizesNone = "none"
+
+// cacheInfoDir is where linux describes the caches of the first CPU.
+const cacheInfoDir = "/sys/devices/system/cpu/cpu0/cache"
+
+// cacheLevel is the size of a level of the CPU's data caches.
+type cacheLevel struct {
+	Name  string // like L2
+	Bytes float64
+}
+
+type byCacheBytes []cacheLevel
+
+func (a byCacheBytes) Len() int           { return len(a) }
+func (a byCacheBytes) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byCacheBytes) Less(i, j int) bool { return a[i].Bytes < a[j].Bytes }
+
+// byteSuffixes are the multiples of a size, which are binary, as they are
+// for caches.
+var byteSuffixes = map[string]float64{"": 1, "K": 1 << 10, "M": 1 << 20, "G": 1 << 30}
+
+// parseByteSize parses a size like 32K, 1MiB, or 48KB.
+func parseByteSize(s string) (float64, error) {
+	t := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(s), "B"), "i")
+	i := strings.IndexFunc(t, func(r rune) bool { return r < '0' || r > '9' })
+	if i < 0 {
+		i = len(t)
+	}
+	mult, ok := byteSuffixes[strings.ToUpper(t[i:])]
+	n, err := strconv.ParseUint(t[:i], 10, 64)
+	if !ok || err != nil || n == 0 {
+		return 0, fmt.Errorf("invalid size: %s", s)
+	}
+	return float64(n) * mult, nil
+}
+
+// parseCacheSizes parses cache sizes like L1=32K,L2=1M,L3=8M.
+func parseCacheSizes(s string) ([]cacheLevel, error) {
+	var levels []cacheLevel
+	for _, kv := range strings.Split(s, ",") {
+		i := strings.Index(kv, "=")
+		if i < 1 {
+			return nil, fmt.Errorf("invalid cache size %q: should be like L2=1M", kv)
+		}
+		size, err := parseByteSize(kv[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache size %q: %v", kv, err)
+		}
+		levels = append(levels, cacheLevel{strings.TrimSpace(kv[:i]), size})
+	}
+	sort.Stable(byCacheBytes(levels))
+	return levels, nil
+}
+
+// detectCacheSizes reads the sizes of the data caches of the first CPU from
+// sysfs in dir, which only linux has, so it's empty elsewhere.
+func detectCacheSizes(dir string) []cacheLevel {
+	indexes, _ := filepath.Glob(filepath.Join(dir, "index*"))
+	var levels []cacheLevel
+	for _, index := range indexes {
+		read := func(name string) string {
+			b, _ := ioutil.ReadFile(filepath.Join(index, name))
+			return strings.TrimSpace(string(b))
+		}
+		if read("type") == "Instruction" {
+			continue
+		}
+		size, err := parseByteSize(read("size"))
+		if err != nil || read("level") == "" {
+			continue
+		}
+		levels = append(levels, cacheLevel{"L" + read("level"), size})
+	}
+	sort.Stable(byCacheBytes(levels))
+	return levels
+}
+
+// formatBytes formats a size in the largest binary unit which divides it,
+// like 48 KiB.
+func formatBytes(n float64) string {
+	for _, u := range []struct {
+		name string
+		size float64
+	}{{"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if n >= u.size && n/u.size == float64(int64(n/u.size)) {
+			return strconv.FormatFloat(n/u.size, 'f', -1, 64) + " " + u.name
+		}
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64) + " B"
+}
+
+// byteNameRE matches the names of variables which are numbers of bytes.
+var byteNameRE = regexp.MustCompile(`(?i)byte|size`)
+
+// byteSized reports whether N is a number of bytes in the N pattern, which
+// is when it's a name template whose N variable is named like one, like
+// BenchmarkCopy/{bytes:int}.  The plotter has a copy of this.
+func byteSized(nre string) bool {
+	name, numeric := "", 0
+	for _, m := range templateVarRE.FindAllStringSubmatch(nre, -1) {
+		if m[1] == "N" {
+			return false
+		}
+		if m[2] != "" && m[2] != "string" {
+			name = m[1]
+			numeric++
+		}
+	}
+	return numeric == 1 && byteNameRE.MatchString(name)
+}
+
+var (
+	cacheOnce        sync.Once
+	cacheMarks       []annotation
+	cacheAnnotateErr error
+)
+
+// cacheAnnotations returns the annotations of the cache sizes in
+// -cache-sizes, or those of this machine if it isn't set.  They're only
+// drawn on benchmarks which are parameterized by bytes.
+func cacheAnnotations() ([]annotation, error) {
+	cacheOnce.Do(func() {
+		var levels []cacheLevel
+		switch *cacheSizes {
+		case cacheSizesNone:
+			return
+		case "":
+			levels = detectCacheSizes(cacheInfoDir)
+		default:
+			if levels, cacheAnnotateErr = parseCacheSizes(*cacheSizes); cacheAnnotateErr != nil {
+				return
+			}
+		}
+		for _, l := range levels {
+			cacheMarks = append(cacheMarks, annotation{N: l.Bytes, Label: l.Name + " (" + formatBytes(l.Bytes) + ")", Bytes: true})
+		}
+	})
+	return cacheMarks, cacheAnnotateErr
+}
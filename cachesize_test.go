@@ -0,0 +1,77 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseCacheSizes(t *testing.T) {
+	got, err := parseCacheSizes("L3=8MiB,L1=32K, L2=1M")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []cacheLevel{{"L1", 32 << 10}, {"L2", 1 << 20}, {"L3", 8 << 20}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for _, bad := range []string{"L1", "L1=", "L1=32X", "L1=0", "=32K", "L1=-1K"} {
+		if _, err := parseCacheSizes(bad); err == nil {
+			t.Errorf("parseCacheSizes(%q) didn't fail", bad)
+		}
+	}
+}
+
+func TestDetectCacheSizes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for index, files := range map[string][3]string{
+		"index0": {"1", "Data", "48K"},
+		"index1": {"1", "Instruction", "32K"},
+		"index2": {"2", "Unified", "2048K"},
+		"index3": {"3", "Unified", "107520K"},
+	} {
+		if err := os.Mkdir(filepath.Join(dir, index), 0755); err != nil {
+			t.Fatal(err)
+		}
+		for i, name := range []string{"level", "type", "size"} {
+			if err := ioutil.WriteFile(filepath.Join(dir, index, name), []byte(files[i]+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	got := detectCacheSizes(dir)
+	want := []cacheLevel{{"L1", 48 << 10}, {"L2", 2 << 20}, {"L3", 105 << 20}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := formatBytes(got[2].Bytes); got != "105 MiB" {
+		t.Errorf("got %s, want 105 MiB", got)
+	}
+	if got := detectCacheSizes(filepath.Join(dir, "missing")); got != nil {
+		t.Errorf("got %v without any caches", got)
+	}
+}
+
+func TestByteSized(t *testing.T) {
+	for nre, want := range map[string]bool{
+		"BenchmarkCopy/{bytes:int}":            true,
+		"Benchmark{Group}/size={Size:int}-{P}": true,
+		"BenchmarkSort{N:int}":                 false,
+		"Benchmark{Group}/{n:int}":             false,
+		defaultNRE:                             false,
+	} {
+		if got := byteSized(nre); got != want {
+			t.Errorf("byteSized(%q) = %v, want %v", nre, got, want)
+		}
+	}
+}
@@ -0,0 +1,392 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// check output formats
+const (
+	formatText   = "text"
+	formatGitHub = "github"
+	formatJUnit  = "junit"
+)
+
+// checkReport compares the fits of the groups in an old and a new set of
+// benchmarks.
+type checkReport struct {
+	YVar      string
+	Unit      string // of Old and New, like ms/op
+	Threshold float64
+	Results   []checkResult
+	Missing   []string // groups which could only be fit in one of the sets
+}
+
+// checkResult compares a single group.  The old and new fits are compared at
+// N, the largest N that both of them cover, so that neither is extrapolated.
+type checkResult struct {
+	Group    string
+	N        float64
+	Old, New float64
+	Delta    float64 // relative change from old to new
+
+	OldComplexity, NewComplexity string
+
+	// Regression is set if the response got worse by more than the
+	// threshold, and ComplexityRegression if the complexity class grew,
+	// even if the new benchmarks are faster at N.
+	Regression           bool
+	ComplexityRegression bool
+}
+
+// failed reports whether any group regressed.
+func (r checkReport) failed() bool {
+	for _, res := range r.Results {
+		if res.Regression || res.ComplexityRegression {
+			return true
+		}
+	}
+	return false
+}
+
+// checkGroups fits every group in old and new, and compares the groups which
+// could be fit in both.  A change is a regression if it is worse than the
+// threshold, which is relative: 0.1 allows the response to get 10% worse.
+// For throughputs, like MB/s, larger is better.
+func checkGroups(q analysisQuery, old, new dataSet, threshold float64) checkReport {
+	rep := checkReport{YVar: q.yVar, Unit: q.unit.label(q.yVar), Threshold: threshold, Results: []checkResult{}}
+	oldFits := make(map[string]groupFit)
+	for _, gf := range q.fitGroups(old) {
+		oldFits[gf.Group] = gf
+	}
+	for _, nf := range q.fitGroups(new) {
+		of, ok := oldFits[nf.Group]
+		if !ok {
+			rep.Missing = append(rep.Missing, nf.Group)
+			continue
+		}
+		delete(oldFits, nf.Group)
+
+		res := checkResult{
+			Group:         nf.Group,
+			N:             math.Min(of.xMax, nf.xMax),
+			OldComplexity: complexity(of.benchSet, q.yVar),
+			NewComplexity: complexity(nf.benchSet, q.yVar),
+		}
+		res.Old, _ = of.predict(res.N)
+		res.New, _ = nf.predict(res.N)
+		if res.Old != 0 {
+			res.Delta = res.New/res.Old - 1
+		}
+		worse := res.Delta
+		if throughputs[q.yVar] {
+			worse = -worse
+		}
+		res.Regression = worse > threshold
+		res.ComplexityRegression = complexityRegression(res.OldComplexity, res.NewComplexity)
+		rep.Results = append(rep.Results, res)
+	}
+	for group := range oldFits {
+		rep.Missing = append(rep.Missing, group)
+	}
+	sort.Strings(rep.Missing)
+	return rep
+}
+
+// complexityChange describes the complexity classes of a result, like
+// "O(N log N) → O(N²)".
+func (res checkResult) complexityChange() string {
+	if res.OldComplexity == res.NewComplexity {
+		return res.OldComplexity
+	}
+	return res.OldComplexity + " → " + res.NewComplexity
+}
+
+// status is a short description of whether the result regressed.
+func (res checkResult) status() string {
+	switch {
+	case res.Regression && res.ComplexityRegression:
+		return "regression, complexity regression"
+	case res.Regression:
+		return "regression"
+	case res.ComplexityRegression:
+		return "complexity regression"
+	}
+	return "ok"
+}
+
+// writeCheckText writes the report as a table.
+func writeCheckText(w io.Writer, r checkReport) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "group\tN\told %[1]s\tnew %[1]s\tdelta\tcomplexity\tstatus\n", r.Unit)
+	for _, res := range r.Results {
+		fmt.Fprintf(tw, "%s\t%g\t%.4g\t%.4g\t%+.1f%%\t%s\t%s\n", res.Group, res.N, res.Old, res.New, 100*res.Delta, res.complexityChange(), res.status())
+	}
+	for _, group := range r.Missing {
+		fmt.Fprintf(tw, "%s\t\t\t\t\t\tonly in one set\n", group)
+	}
+	return tw.Flush()
+}
+
+// writeCheckMarkdown writes the report as a markdown table, for a GitHub
+// Actions job summary.
+func writeCheckMarkdown(w io.Writer, r checkReport) error {
+	var buf []string
+	status := "no regressions"
+	if r.failed() {
+		status = "regressions found"
+	}
+	buf = append(buf, "### benchplot: "+status, "")
+	buf = append(buf, fmt.Sprintf("| group | N | old %[1]s | new %[1]s | delta | complexity | status |", r.Unit))
+	buf = append(buf, "| --- | ---: | ---: | ---: | ---: | --- | --- |")
+	for _, res := range r.Results {
+		s := res.status()
+		if s != "ok" {
+			s = "**" + s + "**"
+		}
+		buf = append(buf, fmt.Sprintf("| %s | %g | %.4g | %.4g | %+.1f%% | %s | %s |", markdownEscape(res.Group), res.N, res.Old, res.New, 100*res.Delta, res.complexityChange(), s))
+	}
+	for _, group := range r.Missing {
+		buf = append(buf, fmt.Sprintf("| %s | | | | | | only in one set |", markdownEscape(group)))
+	}
+	_, err := io.WriteString(w, strings.Join(buf, "\n")+"\n")
+	return err
+}
+
+// markdownEscape escapes the characters in s which would break a markdown
+// table cell.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`).Replace(s)
+}
+
+// writeCheckAnnotations writes a GitHub Actions workflow command for every
+// regression, as an error, and for every group which could only be fit in
+// one of the sets, as a warning.
+func writeCheckAnnotations(w io.Writer, r checkReport) error {
+	for _, res := range r.Results {
+		if res.Regression {
+			msg := fmt.Sprintf("%s is %.1f%% worse at N=%g (%.4g to %.4g %s)", res.Group, 100*math.Abs(res.Delta), res.N, res.Old, res.New, r.Unit)
+			if _, err := fmt.Fprintf(w, "::error title=benchplot regression::%s\n", annotationEscape(msg)); err != nil {
+				return err
+			}
+		}
+		if res.ComplexityRegression {
+			msg := fmt.Sprintf("%s went from %s to %s", res.Group, res.OldComplexity, res.NewComplexity)
+			if _, err := fmt.Fprintf(w, "::error title=benchplot complexity regression::%s\n", annotationEscape(msg)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, group := range r.Missing {
+		msg := group + " could only be fit in one set of benchmarks"
+		if _, err := fmt.Fprintf(w, "::warning title=benchplot::%s\n", annotationEscape(msg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// junitSuite is a JUnit XML test suite, which most CI systems can display.
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failures  []junitResult `xml:"failure"`
+	Skipped   *junitResult  `xml:"skipped"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitResult struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+}
+
+// writeCheckJUnit writes the report as JUnit XML, with a test case for each
+// group.  A group fails for each kind of regression, and groups which could
+// only be fit in one of the sets are skipped.
+func writeCheckJUnit(w io.Writer, r checkReport) error {
+	suite := junitSuite{Name: "benchplot"}
+	for _, res := range r.Results {
+		c := junitCase{
+			Name:      res.Group,
+			ClassName: "benchplot",
+			SystemOut: fmt.Sprintf("%.4g to %.4g %s at N=%g (%+.1f%%), %s", res.Old, res.New, r.Unit, res.N, 100*res.Delta, res.complexityChange()),
+		}
+		if res.Regression {
+			c.Failures = append(c.Failures, junitResult{
+				Message: fmt.Sprintf("%.1f%% worse at N=%g, more than the threshold of %g%%", 100*math.Abs(res.Delta), res.N, 100*r.Threshold),
+				Type:    "regression",
+			})
+		}
+		if res.ComplexityRegression {
+			c.Failures = append(c.Failures, junitResult{
+				Message: fmt.Sprintf("went from %s to %s", res.OldComplexity, res.NewComplexity),
+				Type:    "complexity regression",
+			})
+		}
+		if len(c.Failures) > 0 {
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	for _, group := range r.Missing {
+		suite.Cases = append(suite.Cases, junitCase{
+			Name:      group,
+			ClassName: "benchplot",
+			Skipped:   &junitResult{Message: "could only be fit in one set of benchmarks"},
+		})
+		suite.Skipped++
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// annotationEscape escapes the message of a workflow command.
+func annotationEscape(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func checkUsage(fs *flag.FlagSet) func() {
+	return func() {
+		fmt.Fprintf(os.Stderr, "usage: benchplot check [options] old.txt new.txt\n")
+		fmt.Fprintf(os.Stderr, "compares the fits of two sets of benchmarks, and exits with status 1 if any group regressed\n")
+		fmt.Fprintf(os.Stderr, "example:\n")
+		fmt.Fprintf(os.Stderr, "   benchplot check -threshold=0.05 -format=github old.txt new.txt\n")
+		fmt.Fprintf(os.Stderr, "options:\n")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+}
+
+func checkMain(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	fs.Usage = checkUsage(fs)
+	format := fs.String("format", formatText, "output format: "+formatText+", "+formatGitHub+" for annotations and a job summary in GitHub Actions, or "+formatJUnit+" for JUnit XML")
+	threshold := fs.Float64("threshold", 0.1, "largest relative change in the response which isn't a regression")
+	yVar := fs.String("yvar", "NsPerOp", "response to fit")
+	fitterName := fs.String("fitter", defaultFitter, "estimator of the models: "+strings.Join(fitterNames(), ", "))
+	lambda := fs.String("lambda", "", "strength of regularization, for the ridge and lasso fitters (default "+strconv.FormatFloat(defaultLambda, 'g', -1, 64)+")")
+	xTransformValue := fs.String("xtransform", defaultXTransform, "comma separated terms of the model to fit, or the name of a preset like nlogn")
+	nreValue := fs.String("nre", defaultNRE, "regexp or name template, like Benchmark{Group}/{N:int}, matching the group and N in benchmark names")
+	aggregate := fs.String("aggregate", aggregateAll, "how to summarize repeated runs at the same N: "+strings.Join([]string{aggregateAll, aggregateMean, aggregateMedian, aggregateMin}, ", "))
+	cluster := fs.String("cluster", clusterNone, "what to cluster the confidence intervals by, so that runs in the same one aren't treated as independent: "+clusterFile+" or "+clusterMachine+" (default is not to cluster)")
+	unitName := fs.String("unit", "", "unit to report the response in, like ms or KiB (default is the unit of go test)")
+	perElement := fs.Bool("per-element", false, "report the response divided by N, per element rather than per op")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+	}
+	checkPatterns(fs.Args())
+
+	if _, ok := validYs[*yVar]; !ok {
+		fatalf("invalid yvar: %s", *yVar)
+	}
+	unit, err := parseUnit(*yVar, *unitName, *perElement)
+	if err != nil {
+		fatal(err)
+	}
+	fitter, err := parseFitter(url.Values{"fitter": {*fitterName}, "lambda": {*lambda}})
+	if err != nil {
+		fatal(err)
+	}
+	*xTransformValue = expandPreset(*xTransformValue)
+	xTransform, err := parseTransform(*xTransformValue)
+	if err != nil {
+		fatal(err)
+	}
+	nre, err := compileNRE(*nreValue)
+	if err != nil {
+		fatalf("invalid nre %s: %v", *nreValue, err)
+	}
+	switch *aggregate {
+	case aggregateAll, aggregateMean, aggregateMedian, aggregateMin:
+	default:
+		fatalf("invalid aggregate: %s", *aggregate)
+	}
+	switch *cluster {
+	case clusterNone, clusterFile, clusterMachine:
+	default:
+		fatalf("invalid cluster: %s", *cluster)
+	}
+	if *threshold < 0 {
+		fatalf("invalid threshold: %g", *threshold)
+	}
+
+	q := analysisQuery{
+		grouping:   grouping{nre: nre, unparameterized: unparamTable, yVar: *yVar, aggregate: *aggregate, unit: unit, cluster: *cluster},
+		xTransform: xTransform,
+		yVar:       *yVar,
+		fitter:     fitter,
+	}
+	rep := checkGroups(q, readDataSet(fs.Args()[:1]), readDataSet(fs.Args()[1:]), *threshold)
+
+	switch *format {
+	case formatText:
+		err = writeCheckText(os.Stdout, rep)
+	case formatGitHub:
+		if err = writeCheckAnnotations(os.Stdout, rep); err != nil {
+			break
+		}
+		err = writeGitHubSummary(rep)
+	case formatJUnit:
+		err = writeCheckJUnit(os.Stdout, rep)
+	default:
+		fatalf("invalid format: %s", *format)
+	}
+	if err != nil {
+		fatal(err)
+	}
+	if rep.failed() {
+		os.Exit(1)
+	}
+}
+
+// writeGitHubSummary appends the markdown report to the job summary, or
+// writes it to stdout when not running in GitHub Actions.
+func writeGitHubSummary(rep checkReport) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return writeCheckMarkdown(os.Stdout, rep)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	if err := writeCheckMarkdown(f, rep); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
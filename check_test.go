@@ -0,0 +1,128 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// benchLines generates the benchmarks of group, with the response f(N).
+func benchLines(group string, f func(n float64) float64) []string {
+	var in []string
+	for n := 10; n <= 100000; n *= 10 {
+		for i := 0; i < 3; i++ {
+			in = append(in, fmt.Sprintf("Benchmark%s%d-4\t1000\t%g ns/op", group, n, f(float64(n))*(1+0.001*float64(i))))
+		}
+	}
+	return in
+}
+
+func TestCheckGroups(t *testing.T) {
+	var old, new []string
+	// Faster is 20% faster, Slower is 20% slower, and Quadratic is faster
+	// for small N but has become O(N²).
+	old = append(old, benchLines("Faster", func(n float64) float64 { return 10*n + 100 })...)
+	new = append(new, benchLines("Faster", func(n float64) float64 { return 8*n + 80 })...)
+	old = append(old, benchLines("Slower", func(n float64) float64 { return 10*n + 100 })...)
+	new = append(new, benchLines("Slower", func(n float64) float64 { return 12*n + 120 })...)
+	old = append(old, benchLines("Quadratic", func(n float64) float64 { return 1e6 * n })...)
+	new = append(new, benchLines("Quadratic", func(n float64) float64 { return 0.5 * n * n })...)
+	old = append(old, benchLines("Gone", func(n float64) float64 { return n })...)
+
+	var ds []dataSet
+	for _, in := range [][]string{old, new} {
+		bf, err := parseBenchFile(strings.NewReader(strings.Join(in, "\n")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ds = append(ds, dataSet{Files: []benchFile{bf}})
+	}
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"N, 1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep := checkGroups(q, ds[0], ds[1], 0.1)
+	if len(rep.Missing) != 1 || rep.Missing[0] != "BenchmarkGone" {
+		t.Errorf("got missing groups %v, want BenchmarkGone", rep.Missing)
+	}
+	want := map[string]string{
+		"BenchmarkFaster":    "ok",
+		"BenchmarkSlower":    "regression",
+		"BenchmarkQuadratic": "complexity regression",
+	}
+	if len(rep.Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(rep.Results), len(want))
+	}
+	for _, res := range rep.Results {
+		if got := res.status(); got != want[res.Group] {
+			t.Errorf("got status %q for %s, want %q", got, res.Group, want[res.Group])
+		}
+	}
+	if !rep.failed() {
+		t.Errorf("check passed with regressions")
+	}
+
+	var buf bytes.Buffer
+	if err := writeCheckAnnotations(&buf, rep); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"::error title=benchplot regression::BenchmarkSlower is 20.0%25 worse at N=100000",
+		"::error title=benchplot complexity regression::BenchmarkQuadratic went from O(N) to O(N²)",
+		"::warning title=benchplot::BenchmarkGone could only be fit in one set of benchmarks",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("annotations are missing %q, got:\n%s", line, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := writeCheckJUnit(&buf, rep); err != nil {
+		t.Fatal(err)
+	}
+	var suite junitSuite
+	if err := xml.Unmarshal(buf.Bytes(), &suite); err != nil {
+		t.Fatal(err)
+	}
+	if suite.Tests != 4 || suite.Failures != 2 || suite.Skipped != 1 {
+		t.Errorf("got %d tests, %d failures, and %d skipped, want 4, 2, and 1", suite.Tests, suite.Failures, suite.Skipped)
+	}
+}
+
+func TestCheckThroughput(t *testing.T) {
+	// twice as fast is twice the throughput, which isn't a regression
+	var ds []dataSet
+	for _, f := range []func(n float64) float64{
+		func(n float64) float64 { return 200 },
+		func(n float64) float64 { return 100 },
+	} {
+		bf, err := parseBenchFile(strings.NewReader(strings.Join(benchLines("Get", f), "\n")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ds = append(ds, dataSet{Files: []benchFile{bf}})
+	}
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"1.0"}, "yvar": {"OpsPerS"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep := checkGroups(q, ds[0], ds[1], 0.1)
+	if len(rep.Results) != 1 {
+		t.Fatalf("got %d results, want 1", len(rep.Results))
+	}
+	res := rep.Results[0]
+	if math.Abs(res.Old-5e6)/5e6 > 0.01 || math.Abs(res.New-1e7)/1e7 > 0.01 {
+		t.Errorf("got %g to %g ops/s, want 5e6 to 1e7", res.Old, res.New)
+	}
+	if res.Regression {
+		t.Errorf("doubling the throughput was a regression")
+	}
+}
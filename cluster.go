@@ -0,0 +1,97 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// The ways that benchmarks can be clustered, for cluster robust standard
+// errors.
+const (
+	clusterNone    = ""        // every benchmark is independent
+	clusterFile    = "file"    // benchmarks from the same file are correlated
+	clusterMachine = "machine" // benchmarks from the same machine are correlated
+)
+
+// benchClusters returns the cluster of each benchmark, or nil if there are
+// fewer than two clusters, in which case they can't be used.
+func benchClusters(benchSet []benchmarkResponse) []string {
+	clusters := make([]string, len(benchSet))
+	distinct := make(map[string]bool)
+	for i, b := range benchSet {
+		clusters[i] = b.Cluster
+		distinct[b.Cluster] = true
+	}
+	if len(distinct) < 2 {
+		return nil
+	}
+	return clusters
+}
+
+// cluster replaces the confidence intervals of the fit with cluster robust
+// ones, which allow the errors of the benchmarks in each cluster, like the
+// runs in one file, to be correlated.  The covariance of the coefficients is
+//
+//	G/(G-1) (n-1)/(n-p) (X'X)^-1 (sum over clusters of X_g'e_g e_g'X_g) (X'X)^-1
+//
+// for G clusters, which replaces mse (X'X)^-1, so iXTX becomes it divided by
+// the mse.  It returns the degrees of freedom of the intervals, G-1, and
+// false if the intervals can't be clustered.
+func (st *fitStats) cluster(s samp, m model, clusters []string) (dof int, ok bool) {
+	n := len(s.y)
+	p := len(m)
+	index := make(map[string]int)
+	for _, c := range clusters {
+		if _, ok := index[c]; !ok {
+			index[c] = len(index)
+		}
+	}
+	g := len(index)
+	if g < 2 || n <= p || st.mse == 0 || st.iXTX == nil {
+		return 0, false
+	}
+
+	// the score of each cluster, X_g'e_g
+	scores := make([][]float64, g)
+	for i := range scores {
+		scores[i] = make([]float64, p)
+	}
+	for i, y := range s.y {
+		xi := s.x[i*p : (i+1)*p]
+		e := y - dot(m, xi)
+		u := scores[index[clusters[i]]]
+		for j, x := range xi {
+			u[j] += x * e
+		}
+	}
+	meat := make([]float64, p*p)
+	for _, u := range scores {
+		for j := range u {
+			for k := range u {
+				meat[j*p+k] += u[j] * u[k]
+			}
+		}
+	}
+
+	scale := float64(g) / float64(g-1) * float64(n-1) / float64(n-p) / st.mse
+	var bread, v mat64.Dense
+	bread.Mul(st.iXTX, mat64.NewDense(p, p, meat))
+	v.Mul(&bread, st.iXTX)
+	cov := make([]float64, p*p)
+	for j := 0; j < p; j++ {
+		for k := 0; k < p; k++ {
+			cov[j*p+k] = scale * v.At(j, k)
+		}
+	}
+	st.iXTX = mat64.NewDense(p, p, cov)
+	st.cint = make([]float64, p)
+	for j := range st.cint {
+		st.cint[j] = conf95(math.Sqrt(st.mse*st.iXTX.At(j, j)), g-1)
+	}
+	return g - 1, true
+}
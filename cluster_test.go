@@ -0,0 +1,59 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+func TestClusterRobust(t *testing.T) {
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"N, 1.0"}, "cluster": {clusterFile}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each file is shifted by its own amount, so the runs within a file
+	// are correlated, and the intercept is far less certain than the
+	// naive interval says.
+	var benchSet []benchmarkResponse
+	for i, file := range []string{"a.txt", "b.txt", "c.txt", "d.txt"} {
+		shift := []float64{40, -30, 25, -35}[i]
+		for _, x := range []float64{10, 20, 30, 40, 50} {
+			noise := 0.5 * float64(int(x)%3-1)
+			benchSet = append(benchSet, benchmarkResponse{
+				Benchmark: parse.Benchmark{NsPerOp: 3*x + 100 + shift + noise},
+				X:         x,
+				Cluster:   file,
+			})
+		}
+	}
+	clustered, ok := fitGroup(context.Background(), "sort", benchSet, q.xTransform, q.yVar, q.fitter)
+	if !ok {
+		t.Fatal("unable to fit")
+	}
+	if clustered.cdof != 3 || clustered.dof != len(benchSet)-2 {
+		t.Errorf("got %d degrees of freedom for the intervals and %d for the residuals, want 3 and %d", clustered.cdof, clustered.dof, len(benchSet)-2)
+	}
+
+	for i := range benchSet {
+		benchSet[i].Cluster = ""
+	}
+	naive, ok := fitGroup(context.Background(), "sort", benchSet, q.xTransform, q.yVar, q.fitter)
+	if !ok {
+		t.Fatal("unable to fit")
+	}
+	if clustered.bint[1] <= naive.bint[1] {
+		t.Errorf("got clustered interval %g of the intercept, want it wider than the naive %g", clustered.bint[1], naive.bint[1])
+	}
+	if clustered.beta[0] != naive.beta[0] {
+		t.Errorf("clustering changed the coefficients from %v to %v", naive.beta, clustered.beta)
+	}
+	if _, err := parseAnalysisQuery(url.Values{"cluster": {"benchmark"}}); err == nil {
+		t.Error("parseAnalysisQuery accepted an invalid cluster")
+	}
+}
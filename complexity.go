@@ -0,0 +1,77 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+
+	"github.com/jonlawlor/parsefloat"
+)
+
+// complexityClasses are the candidate models for classifying how a group of
+// benchmarks scales, in order of increasing growth.  Each is fit with an
+// intercept.
+var complexityClasses = []struct {
+	Label string
+	Term  string // empty for only the intercept
+}{
+	{"O(1)", ""},
+	{"O(log N)", "math.Log(N)"},
+	{"O(N)", "N"},
+	{"O(N log N)", "N * math.Log(N)"},
+	{"O(N²)", "N * N"},
+	{"O(N³)", "N * N * N"},
+}
+
+// complexityOrder returns the position of a complexity class label in order
+// of increasing growth, or -1 if it isn't one.
+func complexityOrder(label string) int {
+	for i, c := range complexityClasses {
+		if c.Label == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// complexityRegression reports whether the complexity class grew from old to
+// new, like O(N log N) to O(N²).  That is a regression even if the new
+// benchmarks are faster for small N.
+func complexityRegression(old, new string) bool {
+	o, n := complexityOrder(old), complexityOrder(new)
+	return o >= 0 && n > o
+}
+
+// complexity returns the label of the complexity class which best fits the
+// benchmarks, which is the one with the smallest mean squared error.  Classes
+// which would have the response decrease with N are ignored.  It returns an
+// empty string if none of the classes could be fit.
+func complexity(benchSet []benchmarkResponse, yVar string) string {
+	varNames := map[string]struct{}{"N": struct{}{}}
+	label, best := "", math.Inf(1)
+	for _, c := range complexityClasses {
+		terms := "1.0"
+		if c.Term != "" {
+			terms = c.Term + ", 1.0"
+		}
+		xTransform, err := parsefloat.NewSlice("float64{"+terms+"}", varNames)
+		if err != nil {
+			panic(err)
+		}
+		if !canFit(benchSet, len(xTransform)) {
+			continue
+		}
+		samp := sampleGroup(benchSet, xTransform, yVar)
+		beta := estimate(samp)
+		if beta == nil || (c.Term != "" && beta[0] <= 0) {
+			continue
+		}
+		_, mse, _, _ := stats(beta, samp)
+		if mse < best {
+			label, best = c.Label, mse
+		}
+	}
+	return label
+}
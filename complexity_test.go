@@ -0,0 +1,52 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+func TestComplexity(t *testing.T) {
+	for _, test := range []struct {
+		f    func(float64) float64
+		want string
+	}{
+		{func(n float64) float64 { return 50 }, "O(1)"},
+		{func(n float64) float64 { return 3*math.Log(n) + 20 }, "O(log N)"},
+		{func(n float64) float64 { return 2*n + 100 }, "O(N)"},
+		{func(n float64) float64 { return 20*n*math.Log(n) + 100 }, "O(N log N)"},
+		{func(n float64) float64 { return 0.5*n*n + 1000 }, "O(N²)"},
+	} {
+		var benchSet []benchmarkResponse
+		for i, n := range []float64{10, 100, 1000, 10000, 100000, 1000000} {
+			// a little noise, so that the intercept model isn't exact
+			noise := 1 + 0.001*float64(i%2)
+			benchSet = append(benchSet, benchmarkResponse{Benchmark: parse.Benchmark{NsPerOp: test.f(n) * noise}, X: n})
+		}
+		if got := complexity(benchSet, "NsPerOp"); got != test.want {
+			t.Errorf("got complexity %s, want %s", got, test.want)
+		}
+	}
+}
+
+func TestComplexityRegression(t *testing.T) {
+	for _, test := range []struct {
+		old, new string
+		want     bool
+	}{
+		{"O(N log N)", "O(N²)", true},
+		{"O(N²)", "O(N log N)", false},
+		{"O(N)", "O(N)", false},
+		{"", "O(N)", false},
+		{"O(N)", "", false},
+	} {
+		if got := complexityRegression(test.old, test.new); got != test.want {
+			t.Errorf("complexityRegression(%q, %q) = %v, want %v", test.old, test.new, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// gzipHandler compresses the responses of h for clients which accept gzip,
+// which makes a large /data many times smaller.
+func gzipHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipWriter{ResponseWriter: w}
+		defer gw.close()
+		h.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding includes gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		if i := strings.Index(enc, ";"); i >= 0 {
+			if strings.TrimSpace(enc[i+1:]) == "q=0" {
+				continue
+			}
+			enc = enc[:i]
+		}
+		if strings.TrimSpace(enc) == "gzip" {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipWriter compresses the body of a response, if it has one.
+type gzipWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer // nil if the body isn't compressed
+	wroteHeader bool
+}
+
+func (gw *gzipWriter) WriteHeader(status int) {
+	if gw.wroteHeader {
+		return
+	}
+	gw.wroteHeader = true
+	if status != http.StatusNotModified && status != http.StatusNoContent {
+		gw.Header().Del("Content-Length")
+		gw.Header().Set("Content-Encoding", "gzip")
+		gw.gz = gzip.NewWriter(gw.ResponseWriter)
+	}
+	gw.ResponseWriter.WriteHeader(status)
+}
+
+func (gw *gzipWriter) Write(b []byte) (int, error) {
+	if !gw.wroteHeader {
+		if gw.Header().Get("Content-Type") == "" {
+			gw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		gw.WriteHeader(http.StatusOK)
+	}
+	if gw.gz == nil {
+		return gw.ResponseWriter.Write(b)
+	}
+	return gw.gz.Write(b)
+}
+
+// Flush sends what has been compressed so far, for streamed responses.
+func (gw *gzipWriter) Flush() {
+	if gw.gz != nil {
+		gw.gz.Flush()
+	}
+	if f, ok := gw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// close flushes the compressed body.
+func (gw *gzipWriter) close() error {
+	if gw.gz == nil {
+		return nil
+	}
+	return gw.gz.Close()
+}
+
+// dataETag returns a strong ETag for the encoded data.
+func dataETag(b []byte) string {
+	sum := sha1.Sum(b)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// lastModified returns when the newest file in the data set was modified, or
+// the zero time if any of them is unknown, or more benchmarks are on the way.
+func lastModified(ds dataSet) time.Time {
+	var t time.Time
+	if ds.Live {
+		return t
+	}
+	for _, f := range ds.Files {
+		if f.ModTime.IsZero() {
+			return time.Time{}
+		}
+		if f.ModTime.After(t) {
+			t = f.ModTime
+		}
+	}
+	return t
+}
+
+// notModified reports whether the client's copy, described by the
+// conditional headers of the request, is still current.  If-None-Match
+// takes precedence over If-Modified-Since.
+func notModified(r *http.Request, etag string, modTime time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if modTime.IsZero() {
+		return false
+	}
+	t, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !modTime.Truncate(time.Second).After(t)
+}
@@ -0,0 +1,73 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fixedSource always has the same data.
+type fixedSource dataSet
+
+func (fs fixedSource) dataSet() dataSet { return dataSet(fs) }
+
+func TestServeData(t *testing.T) {
+	mod := time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+	h := gzipHandler(serveBenchmarksAsJSON(fixedSource{Version: dataVersion, Files: []benchFile{{Path: "bench.txt", ModTime: mod}}}))
+	get := func(header map[string]string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/data", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(map[string]string{"Accept-Encoding": "gzip, deflate"})
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("got Content-Encoding %q, want gzip", w.Header().Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ds dataSet
+	if err := json.NewDecoder(zr).Decode(&ds); err != nil || len(ds.Files) != 1 {
+		t.Fatalf("got data %+v, error %v", ds, err)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Last-Modified") != "Sat, 02 Jan 2016 15:04:05 GMT" {
+		t.Errorf("got ETag %q and Last-Modified %q", etag, w.Header().Get("Last-Modified"))
+	}
+
+	for _, header := range []map[string]string{
+		{"If-None-Match": etag, "Accept-Encoding": "gzip"},
+		{"If-Modified-Since": "Sat, 02 Jan 2016 15:04:05 GMT"},
+	} {
+		w := get(header)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("Content-Encoding") != "" {
+			t.Errorf("with %v, got status %d, %d bytes, encoding %q, want an empty 304", header, w.Code, w.Body.Len(), w.Header().Get("Content-Encoding"))
+		}
+	}
+	for _, header := range []map[string]string{
+		{"If-None-Match": `"stale"`},
+		{"If-Modified-Since": "Sat, 02 Jan 2016 15:04:04 GMT"},
+		{"Accept-Encoding": "gzip;q=0"},
+	} {
+		w := get(header)
+		if w.Code != http.StatusOK || w.Header().Get("Content-Encoding") != "" {
+			t.Errorf("with %v, got status %d and encoding %q, want uncompressed data", header, w.Code, w.Header().Get("Content-Encoding"))
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "math"
+
+// maxCondition and maxVIF are the limits above which the terms of a model are
+// nearly collinear, so that their coefficients can't be trusted.  They are
+// the usual rules of thumb, from Belsley, Kuh, and Welsch's "Regression
+// Diagnostics".
+const (
+	maxCondition = 100
+	maxVIF       = 10
+)
+
+// conditioning describes how close the terms of a model are to collinear,
+// like N and N log N over a narrow range of N.
+type conditioning struct {
+	cond float64   // condition number of X, with its columns scaled to unit length
+	vif  []float64 // variance inflation factor of each term, 0 for constant terms
+	rank int       // number of linearly independent terms
+}
+
+// collinear reports whether the terms are too close to collinear.
+func (c conditioning) collinear() bool {
+	if c.rank < len(c.vif) || c.cond > maxCondition {
+		return true
+	}
+	for _, v := range c.vif {
+		if v > maxVIF {
+			return true
+		}
+	}
+	return false
+}
+
+// condition finds the conditioning of the terms of the sample.  Infinite
+// values, from terms which are exactly collinear, are limited to the largest
+// float64 so that they can be encoded as JSON.
+func condition(s samp) conditioning {
+	n := len(s.y)
+	p := len(s.x) / n
+
+	// The decomposition scales the columns to unit length, so that the
+	// condition number doesn't depend on the units of the terms.
+	var c conditioning
+	c.cond = math.MaxFloat64
+	if d, ok := decompose(s); ok {
+		c.rank = d.rank
+		if n >= p && d.sv[p-1] != 0 {
+			c.cond = finite(d.sv[0] / d.sv[p-1])
+		}
+	}
+
+	c.vif = make([]float64, p)
+	if p == 1 {
+		c.vif[0] = 1
+		return c
+	}
+	for j := 0; j < p; j++ {
+		c.vif[j] = vif(s, j)
+	}
+	return c
+}
+
+// vif returns the variance inflation factor of the j'th term, 1/(1-R²), where
+// R² is from regressing the term on the others.  R² is centered if the other
+// terms include a constant.  Constant terms have a vif of 0.
+func vif(s samp, j int) float64 {
+	n := len(s.y)
+	p := len(s.x) / n
+	var other samp
+	constant, hasConstant := true, false
+	for i := 0; i < n; i++ {
+		xj := s.x[i*p+j]
+		if xj != s.x[j] {
+			constant = false
+		}
+		other.y = append(other.y, xj)
+		for k := 0; k < p; k++ {
+			if k != j {
+				other.x = append(other.x, s.x[i*p+k])
+			}
+		}
+	}
+	if constant {
+		return 0
+	}
+	for k := 0; k < p; k++ {
+		if k == j || s.x[k] == 0 {
+			continue
+		}
+		hasConstant = true
+		for i := 1; i < n; i++ {
+			if s.x[i*p+k] != s.x[k] {
+				hasConstant = false
+				break
+			}
+		}
+		if hasConstant {
+			break
+		}
+	}
+
+	beta := estimate(other)
+	if beta == nil {
+		return math.MaxFloat64
+	}
+	mean := 0.0
+	if hasConstant {
+		var sum kahan
+		for _, y := range other.y {
+			sum.add(y)
+		}
+		mean = sum.value() / float64(n)
+	}
+	var rss, tss kahan
+	for i, y := range other.y {
+		r := y - dot(beta, other.x[i*(p-1):(i+1)*(p-1)])
+		rss.add(r * r)
+		tss.add((y - mean) * (y - mean))
+	}
+	if rss.value() == 0 {
+		return math.MaxFloat64
+	}
+	// 1/(1-R²) = tss/rss
+	return finite(tss.value() / rss.value())
+}
+
+// finite limits infinite values to the largest float64.
+func finite(v float64) float64 {
+	if math.IsInf(v, 1) {
+		return math.MaxFloat64
+	}
+	return v
+}
@@ -0,0 +1,48 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCondition(t *testing.T) {
+	// N and N log N are nearly collinear over a narrow range of N, but N
+	// and a constant aren't.
+	var line, narrow samp
+	for i := 0; i < 20; i++ {
+		x := 1000 + 10*float64(i)
+		line.x = append(line.x, x, 1)
+		line.y = append(line.y, x)
+		narrow.x = append(narrow.x, x, x*math.Log(x), 1)
+		narrow.y = append(narrow.y, x)
+	}
+
+	c := condition(line)
+	if c.collinear() {
+		t.Errorf("N, 1.0: got collinear with condition %g and vif %v", c.cond, c.vif)
+	}
+	if math.Abs(c.vif[0]-1) > 1e-9 || c.vif[1] != 0 {
+		t.Errorf("N, 1.0: got vif %v, want [1 0]", c.vif)
+	}
+
+	c = condition(narrow)
+	if !c.collinear() || c.cond <= maxCondition || c.vif[0] <= maxVIF || c.vif[1] <= maxVIF {
+		t.Errorf("N, N log N, 1.0: got condition %g and vif %v, which should be collinear", c.cond, c.vif)
+	}
+
+	// exactly collinear terms are still finite
+	var dup samp
+	for i := 0; i < 5; i++ {
+		x := float64(i + 1)
+		dup.x = append(dup.x, x, 2*x)
+		dup.y = append(dup.y, x)
+	}
+	c = condition(dup)
+	if c.cond != math.MaxFloat64 && c.cond < 1e12 || math.IsInf(c.vif[0], 0) {
+		t.Errorf("N, 2N: got condition %g and vif %v", c.cond, c.vif)
+	}
+}
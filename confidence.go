@@ -1,5 +1,7 @@
 package main
 
+import "math"
+
 // 97.5 critical values from t distribution for varying degrees of freedom,
 // from   http://www.itl.nist.gov/div898/handbook/eda/section3/eda3672.htm
 var tcrit975 = map[int]float64{
@@ -116,3 +118,81 @@ func conf95(sigma float64, dof int) float64 {
 	}
 	return sigma * c
 }
+
+// fSurvival returns the probability that an F distributed variable with d1 and
+// d2 degrees of freedom is greater than f.
+func fSurvival(f float64, d1, d2 int) float64 {
+	if f <= 0 {
+		return 1
+	}
+	a, b := float64(d2)/2, float64(d1)/2
+	return incBeta(a, b, float64(d2)/(float64(d2)+float64(d1)*f))
+}
+
+// incBeta is the regularized incomplete beta function, evaluated with the
+// continued fraction in Numerical Recipes section 6.4.
+func incBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	// the continued fraction converges quickly on this side
+	if x < (a+1)/(a+b+2) {
+		return front * betaCF(a, b, x) / a
+	}
+	return 1 - front*betaCF(b, a, 1-x)/b
+}
+
+func betaCF(a, b, x float64) float64 {
+	const (
+		maxIter = 300
+		eps     = 3e-16
+		tiny    = 1e-300
+	)
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIter; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+		// even step
+		aa := fm * (b - fm) * x / ((a + m2 - 1) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		// odd step
+		aa = -(a + fm) * (a + b + fm) * x / ((a + m2) * (a + m2 + 1))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return h
+}
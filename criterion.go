@@ -0,0 +1,174 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+// Rust's Criterion writes the results of each benchmark to its own directory,
+// target/criterion/{group}/{function}/{value}/new, which holds its samples
+// in raw.csv, and its estimates in estimates.json, with the benchmark's name
+// in benchmark.json.  Either can be plotted, and a whole tree of them can be
+// read with -dir, like
+//
+//	benchplot -dir=target/criterion -include='**/new/raw.csv'
+
+// criterionCSVHeader is the first line of Criterion's raw.csv.
+const criterionCSVHeader = "group,function,value,throughput_num,throughput_type,sample_measured_value,unit,iteration_count"
+
+// criterionEstimatesFile is the name of the file of Criterion's estimates.
+const criterionEstimatesFile = "estimates.json"
+
+// isCriterionCSV reports whether rd starts with the header of Criterion's
+// raw.csv, without consuming it.
+func isCriterionCSV(rd *bufio.Reader) bool {
+	b, _ := rd.Peek(len(criterionCSVHeader))
+	return string(b) == criterionCSVHeader
+}
+
+// criterionName names a Criterion benchmark like a go benchmark, from the
+// parts of its id which aren't empty.
+func criterionName(parts ...string) string {
+	var name []string
+	for _, p := range parts {
+		if p != "" {
+			name = append(name, p)
+		}
+	}
+	return strings.Join(name, "/") + "-1"
+}
+
+// parseCriterionCSV parses Criterion's raw.csv.  Each sample is a run of the
+// benchmark, whose ns/op is its measured time divided by its iterations.
+// Samples with a throughput in bytes also have MB/s.  Rows which can't be
+// parsed are warnings.
+func parseCriterionCSV(r io.Reader) (benchFile, error) {
+	bf := benchFile{Config: make(map[string]string)}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return bf, nil
+		}
+		if err != nil {
+			return bf, err
+		}
+		if line == 1 {
+			continue
+		}
+		text := strings.Join(rec, ",")
+		if len(rec) != 8 {
+			bf.Warnings = append(bf.Warnings, parseWarning{line, text, "expected 8 fields"})
+			continue
+		}
+		measured, err1 := strconv.ParseFloat(rec[5], 64)
+		iters, err2 := strconv.ParseFloat(rec[7], 64)
+		scale, ok := timeUnits[rec[6]]
+		if err1 != nil || err2 != nil || !ok || iters <= 0 {
+			bf.Warnings = append(bf.Warnings, parseWarning{line, text, "invalid sample"})
+			continue
+		}
+		b := parse.Benchmark{
+			Name:     criterionName(rec[0], rec[1], rec[2]),
+			N:        int(iters),
+			NsPerOp:  measured * scale / iters,
+			Measured: parse.NsPerOp,
+			Ord:      len(bf.Benchmarks),
+		}
+		if n, err := strconv.ParseFloat(rec[3], 64); err == nil && rec[4] == "bytes" && b.NsPerOp > 0 {
+			b.MBPerS = n / b.NsPerOp * 1e3
+			b.Measured |= parse.MBPerS
+		}
+		bf.Benchmarks = append(bf.Benchmarks, &benchmark{Benchmark: b, Line: line})
+	}
+}
+
+// criterionEstimates is the part of Criterion's estimates.json which is
+// plotted.  Slope is only estimated for linear sampling, and is preferred
+// when it is there.
+type criterionEstimates struct {
+	Mean  *criterionEstimate `json:"mean"`
+	Slope *criterionEstimate `json:"slope"`
+}
+
+type criterionEstimate struct {
+	PointEstimate float64 `json:"point_estimate"`
+}
+
+// criterionID is the part of Criterion's benchmark.json which names the
+// benchmark.
+type criterionID struct {
+	FullID     string `json:"full_id"`
+	Throughput *struct {
+		Bytes float64 `json:"Bytes"`
+	} `json:"throughput"`
+}
+
+// readCriterionEstimates reads Criterion's estimates.json, as a single
+// benchmark.  Its name is from the benchmark.json next to it, or else the
+// directories it is in.
+func readCriterionEstimates(fn string) benchFile {
+	bf := benchFile{Path: fn, Config: make(map[string]string)}
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		bf.Err = err.Error()
+		return bf
+	}
+	if fi, err := os.Stat(fn); err == nil {
+		bf.ModTime = fi.ModTime()
+	}
+	var est criterionEstimates
+	if err := json.Unmarshal(data, &est); err != nil {
+		bf.Err = err.Error()
+		return bf
+	}
+	e := est.Slope
+	if e == nil {
+		e = est.Mean
+	}
+	if e == nil {
+		bf.Err = "no mean or slope estimate"
+		return bf
+	}
+
+	var id criterionID
+	dir := filepath.Dir(fn)
+	if data, err := ioutil.ReadFile(filepath.Join(dir, "benchmark.json")); err == nil {
+		json.Unmarshal(data, &id)
+	}
+	if id.FullID == "" {
+		// the benchmark is in {id}/new, under a criterion directory
+		var parts []string
+		for d := filepath.Dir(dir); filepath.Base(d) != "criterion" && filepath.Dir(d) != d; d = filepath.Dir(d) {
+			parts = append([]string{filepath.Base(d)}, parts...)
+		}
+		id.FullID = strings.Join(parts, "/")
+	}
+
+	b := parse.Benchmark{
+		Name:     criterionName(id.FullID),
+		N:        1,
+		NsPerOp:  e.PointEstimate,
+		Measured: parse.NsPerOp,
+	}
+	if id.Throughput != nil && id.Throughput.Bytes > 0 && b.NsPerOp > 0 {
+		b.MBPerS = id.Throughput.Bytes / b.NsPerOp * 1e3
+		b.Measured |= parse.MBPerS
+	}
+	bf.Benchmarks = []*benchmark{{Benchmark: b}}
+	return bf
+}
@@ -0,0 +1,78 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCriterionCSV(t *testing.T) {
+	in := criterionCSVHeader + `
+sort,std,1024,4096,bytes,200000,ns,10
+sort,std,1024,4096,bytes,420000,ns,20
+sort,std,2048,8192,bytes,bad,ns,10
+fib,,,,,5,us,1
+`
+	bf, err := parseBenchFile(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bf.Benchmarks) != 3 || len(bf.Warnings) != 1 || bf.Warnings[0].Line != 4 {
+		t.Fatalf("got %d benchmarks and warnings %v, want 3 and one on line 4", len(bf.Benchmarks), bf.Warnings)
+	}
+	for i, want := range []struct {
+		name            string
+		nsPerOp, mbPerS float64
+	}{
+		{"sort/std/1024-1", 20000, 204.8},
+		{"sort/std/1024-1", 21000, 4096.0 / 21000.0 * 1e3},
+		{"fib-1", 5000, 0},
+	} {
+		b := bf.Benchmarks[i]
+		if b.Name != want.name || b.NsPerOp != want.nsPerOp || math.Abs(b.MBPerS-want.mbPerS) > 1e-9 {
+			t.Errorf("got %s at %g ns/op and %g MB/s, want %s at %g ns/op and %g MB/s", b.Name, b.NsPerOp, b.MBPerS, want.name, want.nsPerOp, want.mbPerS)
+		}
+	}
+}
+
+func TestReadCriterionEstimates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for fn, contents := range map[string]string{
+		"criterion/sort/std/1024/new/estimates.json": `{"mean": {"point_estimate": 21000}, "slope": {"point_estimate": 20000}}`,
+		"criterion/sort/std/1024/new/benchmark.json": `{"full_id": "sort/std/1024", "throughput": {"Bytes": 4096}}`,
+		"criterion/fib/20/new/estimates.json":        `{"mean": {"point_estimate": 5000}}`,
+	} {
+		fn = filepath.Join(dir, filepath.FromSlash(fn))
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fn, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bf := readBenchFile(filepath.Join(dir, "criterion", "sort", "std", "1024", "new", "estimates.json"))
+	if bf.Err != "" || len(bf.Benchmarks) != 1 {
+		t.Fatalf("got %+v, want a benchmark", bf)
+	}
+	if b := bf.Benchmarks[0]; b.Name != "sort/std/1024-1" || b.NsPerOp != 20000 || b.MBPerS != 204.8 {
+		t.Errorf("got %s at %g ns/op and %g MB/s, want the slope of sort/std/1024", b.Name, b.NsPerOp, b.MBPerS)
+	}
+
+	// without benchmark.json, the name is from the directories
+	bf = readBenchFile(filepath.Join(dir, "criterion", "fib", "20", "new", "estimates.json"))
+	if len(bf.Benchmarks) != 1 || bf.Benchmarks[0].Name != "fib/20-1" || bf.Benchmarks[0].NsPerOp != 5000 {
+		t.Errorf("got %+v, want fib/20", bf.Benchmarks)
+	}
+}
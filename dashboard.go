@@ -0,0 +1,182 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// the size of each chart on the dashboard, in pixels, and the number of
+// points its fit is drawn with.
+const (
+	sparkWidth  = 240
+	sparkHeight = 80
+	sparkMargin = 4
+	sparkSteps  = 60
+)
+
+// sparkline is the chart of a group on the dashboard, with its points and
+// fit already scaled to the chart.
+type sparkline struct {
+	Group, Name string
+	Link        string
+	R2          float64
+	Points      [][2]float64
+	Fit         string // the points of the fit, as an SVG polyline
+	XMin, XMax  float64
+	YMin, YMax  float64
+	LogX        bool // whether N is on a log scale
+}
+
+// newSparkline draws the benchmarks of the fit as points, and the fit as a
+// line through them, scaled to the chart like writeTermPlot.  N is on a log
+// scale if it spans more than two orders of magnitude, since benchmarks are
+// usually run at powers of 10.
+func newSparkline(gf groupFit, yVar string) sparkline {
+	logX := gf.xMin > 0 && gf.xMax >= 100*gf.xMin
+	y := sampleGroup(gf.benchSet, nil, yVar).y
+	yMin, yMax := math.Inf(1), math.Inf(-1)
+	for _, v := range y {
+		yMin, yMax = math.Min(yMin, v), math.Max(yMax, v)
+	}
+	fitX := make([]float64, sparkSteps)
+	fitY := make([]float64, sparkSteps)
+	for i := range fitX {
+		fitX[i] = termX(gf.xMin, gf.xMax, i, sparkSteps, logX)
+		fitY[i], _ = gf.at(fitX[i])
+		if !math.IsNaN(fitY[i]) && !math.IsInf(fitY[i], 0) {
+			yMin, yMax = math.Min(yMin, fitY[i]), math.Max(yMax, fitY[i])
+		}
+	}
+	xLo, xHi, yLo, yHi := gf.xMin, gf.xMax, yMin, yMax
+	if xLo == xHi {
+		xLo, xHi = xLo-1, xHi+1
+	}
+	if yLo == yHi {
+		yLo, yHi = yLo-1, yHi+1
+	}
+	if logX {
+		xLo, xHi = math.Log(xLo), math.Log(xHi)
+	}
+	px := func(x float64) float64 {
+		if logX {
+			x = math.Log(x)
+		}
+		return sparkMargin + (x-xLo)/(xHi-xLo)*(sparkWidth-2*sparkMargin)
+	}
+	py := func(y float64) float64 {
+		return sparkMargin + (yHi-y)/(yHi-yLo)*(sparkHeight-2*sparkMargin)
+	}
+
+	s := sparkline{Group: gf.Group, R2: gf.r2, XMin: gf.xMin, XMax: gf.xMax, YMin: yMin, YMax: yMax, LogX: logX}
+	for i, b := range gf.benchSet {
+		s.Points = append(s.Points, [2]float64{math.Round(px(b.X)*10) / 10, math.Round(py(y[i])*10) / 10})
+	}
+	var fit []string
+	for i := range fitX {
+		if !math.IsNaN(fitY[i]) && !math.IsInf(fitY[i], 0) {
+			fit = append(fit, fmt.Sprintf("%.1f,%.1f", px(fitX[i]), py(fitY[i])))
+		}
+	}
+	s.Fit = strings.Join(fit, " ")
+	return s
+}
+
+// dashboardHTML shows a chart of every group, each of which links to the
+// plotter showing only that group.
+var dashboardHTML = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"num": func(v float64) string { return fmt.Sprintf("%.4g", v) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+	<head>
+		<meta charset="utf-8">
+		<title>go benchplot dashboard</title>
+		<style type="text/css">
+      body { font: 12px sans-serif; }
+      .chart { display: inline-block; margin: 0 12px 12px 0; vertical-align: top; }
+      .chart a { color: inherit; text-decoration: none; }
+      .chart svg { border: 1px solid #ddd; display: block; }
+      .chart .name { font-weight: bold; width: {{.Width}}px; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+      .chart .range { color: #777; }
+      circle { fill: steelblue; fill-opacity: 0.6; }
+      polyline { fill: none; stroke: #d62728; stroke-width: 1.5px; }
+		</style>
+	</head>
+	<body>
+		<h3>{{len .Charts}} benchmark groups, by {{.Unit}} <a href="{{.Plotter}}">(plot all)</a></h3>
+		{{range .Charts}}<div class="chart">
+			<a href="{{.Link}}" title="{{.Group}}">
+				<div class="name">{{.Name}}</div>
+				<svg width="{{$.Width}}" height="{{$.Height}}">
+					{{range .Points}}<circle cx="{{index . 0}}" cy="{{index . 1}}" r="1.5"></circle>{{end}}
+					<polyline points="{{.Fit}}"></polyline>
+				</svg>
+				<div class="range">{{if .LogX}}log {{end}}N {{num .XMin}} to {{num .XMax}}, {{num .YMin}} to {{num .YMax}}, R² {{printf "%.3f" .R2}}</div>
+			</a>
+		</div>{{end}}
+	</body>
+</html>
+`))
+
+// serveDashboard serves an index of the groups, with a small chart of each
+// of them and its fit, so that many families of benchmarks can be looked
+// over at once rather than piled into one plot.  They're grouped and fit
+// with the settings in the querystring, or those of the saved view in
+// view from views, and each chart links to the plotter showing only its
+// group.
+func serveDashboard(src dataSource, views *viewStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		settings := r.Form
+		link := url.Values{}
+		if name := r.Form.Get("view"); name != "" {
+			v, ok := views.get(name)
+			if !ok {
+				http.Error(w, "no view named "+name, http.StatusNotFound)
+				return
+			}
+			settings = v.Config.values()
+			link.Set("view", name)
+		}
+		q, err := parseAnalysisQuery(settings)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fits, err := q.fitGroupsContext(r.Context(), src.dataSet())
+		if err != nil {
+			fitError(w, err)
+			return
+		}
+
+		names, _ := displayNames()
+		page := struct {
+			Width, Height int
+			Unit          string
+			Plotter       string
+			Charts        []sparkline
+		}{Width: sparkWidth, Height: sparkHeight, Unit: validYs[q.yVar], Plotter: "./"}
+		if len(link) > 0 {
+			page.Plotter += "?" + link.Encode()
+		}
+		for _, gf := range fits {
+			s := newSparkline(gf, q.yVar)
+			s.Name = displayName(names, gf.Group)
+			link.Set("group", gf.Group)
+			s.Link = "./?" + link.Encode()
+			page.Charts = append(page.Charts, s)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		dashboardHTML.Execute(w, page)
+	}
+}
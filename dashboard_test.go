@@ -0,0 +1,101 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeDashboard(t *testing.T) {
+	var in []string
+	for _, g := range []string{"Sort", "StableSort"} {
+		for n := 10; n <= 10000; n *= 10 {
+			for i := 0; i < 3; i++ {
+				in = append(in, fmt.Sprintf("Benchmark%s%d-4\t1000\t%d ns/op", g, n, len(g)*n+i))
+			}
+		}
+	}
+	bf, err := parseBenchFile(strings.NewReader(strings.Join(in, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	views, err := openViews("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := serveDashboard(fixedSource{Files: []benchFile{bf}}, views)
+
+	tests := []struct {
+		query  string
+		code   int
+		charts int
+	}{
+		{"", http.StatusOK, 2},
+		{"group=BenchmarkStableSort", http.StatusOK, 1},
+		{"group=BenchmarkMissing", http.StatusOK, 0},
+		{"yvar=Furlongs", http.StatusBadRequest, 0},
+		{"view=missing", http.StatusNotFound, 0},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest("GET", "/dashboard?"+test.query, nil))
+		if w.Code != test.code {
+			t.Errorf("%s: got status %d, want %d", test.query, w.Code, test.code)
+			continue
+		}
+		if test.code != http.StatusOK {
+			continue
+		}
+		body := w.Body.String()
+		if got := strings.Count(body, "<polyline"); got != test.charts {
+			t.Errorf("%s: got %d charts, want %d", test.query, got, test.charts)
+		}
+		// N spans three orders of magnitude, so it's on a log scale
+		if test.charts > 0 && !strings.Contains(body, "log N 10 to 1e") {
+			t.Errorf("%s: N isn't on a log scale:\n%s", test.query, body)
+		}
+		if test.charts == 2 && !strings.Contains(body, `href="./?group=BenchmarkSort"`) {
+			t.Errorf("%s: the chart of BenchmarkSort doesn't link to the plotter:\n%s", test.query, body)
+		}
+	}
+}
+
+func TestNewSparkline(t *testing.T) {
+	var in []string
+	for n := 10; n <= 30; n += 10 {
+		in = append(in, fmt.Sprintf("BenchmarkSort%d-4\t1000\t%d ns/op", n, 2*n))
+	}
+	bf, err := parseBenchFile(strings.NewReader(strings.Join(in, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := parseAnalysisQuery(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.xTransform = mustParseTransform(t, "N, 1.0")
+	fits := q.fitGroups(dataSet{Files: []benchFile{bf}})
+	if len(fits) != 1 {
+		t.Fatalf("got %d fits, want 1", len(fits))
+	}
+	s := newSparkline(fits[0], q.yVar)
+	if len(s.Points) != 3 {
+		t.Fatalf("got %d points, want 3", len(s.Points))
+	}
+	// the points span the chart, from the bottom left to the top right
+	first, last := s.Points[0], s.Points[2]
+	if first[0] != sparkMargin || first[1] != sparkHeight-sparkMargin || last[0] != sparkWidth-sparkMargin || last[1] != sparkMargin {
+		t.Errorf("got points %v, want them to span the chart", s.Points)
+	}
+	// the fit goes through the points, so it doesn't widen the range
+	if s.XMin != 10 || s.XMax != 30 || math.Abs(s.YMin-20) > 1e-6 || math.Abs(s.YMax-60) > 1e-6 || s.LogX {
+		t.Errorf("got ranges %g to %g and %g to %g, want 10 to 30 and 20 to 60 on a linear scale", s.XMin, s.XMax, s.YMin, s.YMax)
+	}
+}
@@ -0,0 +1,505 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+// defaultNRE matches the group and explanatory variable in a benchmark name.
+// It is used by the plotter as well, so it has to be valid in both go and
+// javascript.
+const defaultNRE = `^(.*?)\/?(\d+)-\d+$`
+
+// defaultXTransform is the model that the plotter fits by default.
+const defaultXTransform = "math.Log(N) * N, 1.0"
+
+// procRE matches the name of a benchmark without its GOMAXPROCS suffix.
+var procRE = regexp.MustCompile(`^(.*?)(-\d+)?$`)
+
+// Ways of handling benchmarks whose names don't match the N pattern.
+const (
+	unparamTable = "table" // list them separately, without fitting them
+	unparamOne   = "one"   // plot them at N=1
+)
+
+// Ways of summarizing repeated runs of a benchmark at the same N, like those
+// from go test -count.
+const (
+	aggregateAll    = "all"    // plot and fit every run
+	aggregateMean   = "mean"   // the mean of the runs
+	aggregateMedian = "median" // the median of the runs
+	aggregateMin    = "min"    // the best of the runs, which is often the best summary of cpu bound benchmarks
+)
+
+// Ways of splitting the groups by the file their benchmarks are from, so
+// that the files can be told apart, like before.txt and after.txt.  The
+// groups are only split if it's asked for, so that their names don't change
+// when another file is loaded.
+const (
+	byFileNever  = ""     // mix the benchmarks of every file
+	byFileAlways = "true" // prefix every group with its file
+	byFileAuto   = "auto" // prefix the groups if more than one plotted file has benchmarks
+)
+
+// dataVersion is the version of the schema served at /data.  It should be
+// incremented whenever a change to dataSet would break existing consumers.
+const dataVersion = 1
+
+// dataSet is the benchmark data served at /data.
+type dataSet struct {
+	Version int
+	Files   []benchFile
+	Live    bool // more benchmarks are on the way
+	Rerun   bool // the benchmarks can be run again by posting to /rerun
+}
+
+// dataSource provides the benchmark data served at /data.
+type dataSource interface {
+	dataSet() dataSet
+}
+
+// globSource reads the files matching the glob patterns each time its data
+// is requested.
+type globSource []string
+
+func (patterns globSource) dataSet() dataSet { return readDataSet(patterns) }
+
+// benchFile contains the benchmarks parsed from a single file, along with
+// where they came from.
+type benchFile struct {
+	Path     string
+	Series   string // if set, the benchmarks are plotted as a separate series
+	ModTime  time.Time
+	Config   map[string]string // benchfmt configuration lines, e.g. goos: linux
+	Skipped  int               // lines which are not benchmarks, configuration, or package results
+	Warnings []parseWarning    // lines which look like benchmarks but could not be parsed
+	Err      string            // set if the file could not be read completely
+	Meta     []metaEntry       `json:",omitempty"` // notes from the file's sidecar, like bench.txt.meta.yaml
+
+	Benchmarks []*benchmark
+
+	// pending is the index of the first benchmark which has not been followed
+	// by a package result line.
+	pending int
+}
+
+// benchmark is a parsed benchmark along with the package it belongs to and
+// the machine it ran on, if they are known.
+type benchmark struct {
+	parse.Benchmark
+	Package string
+	Machine string
+	perfCounters
+
+	// Profiles are the kinds of profiles of the benchmark, like cpu.
+	Profiles []string `json:",omitempty"`
+
+	// Line is the 1 based line of the benchmark in its file, if it is known.
+	Line int `json:",omitempty"`
+}
+
+// machineName describes the machine from the benchfmt configuration, like
+// "amd64, Intel(R) Core(TM) i7-8650U CPU @ 1.90GHz".
+func machineName(config map[string]string) string {
+	var parts []string
+	for _, key := range []string{"goarch", "cpu"} {
+		if v := config[key]; v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// pkgResultRE matches the line that go test prints after running the
+// benchmarks in a package, like "ok  	github.com/jonlawlor/benchplot	138.860s".
+var pkgResultRE = regexp.MustCompile(`^(?:ok|FAIL)\s+(\S+)\s`)
+
+// clone returns a deep copy of the file, which can be used while the original
+// continues to be parsed.
+func (bf *benchFile) clone() benchFile {
+	c := *bf
+	c.Config = make(map[string]string, len(bf.Config))
+	for k, v := range bf.Config {
+		c.Config[k] = v
+	}
+	c.Warnings = append([]parseWarning(nil), bf.Warnings...)
+	c.Benchmarks = make([]*benchmark, len(bf.Benchmarks))
+	for i, b := range bf.Benchmarks {
+		bc := *b
+		c.Benchmarks[i] = &bc
+	}
+	return c
+}
+
+// parseWarning describes a line which could not be parsed.
+type parseWarning struct {
+	Line int // 1 based line number
+	Text string
+	Err  string
+}
+
+// readDataSet reads and parses all of the files matching the glob patterns.
+// The files are sorted by path.  Files which can't be read are included with
+// their error, so that one bad file doesn't prevent plotting the rest.
+func readDataSet(patterns []string) dataSet {
+	ds := dataSet{Version: dataVersion}
+	for _, pat := range patterns {
+		// we've already checked for validity, so err will be nil
+		fns, _ := filepath.Glob(pat)
+		for _, fn := range fns {
+			ds.Files = append(ds.Files, readBenchFile(fn))
+		}
+	}
+	sort.Sort(byPath(ds.Files))
+	return ds
+}
+
+// readBenchFile reads and parses a single file.
+func readBenchFile(fn string) benchFile {
+	if filepath.Base(fn) == criterionEstimatesFile {
+		return readCriterionEstimates(fn)
+	}
+	// This can only error if the path is invalid but glob should only return
+	// files that exist.  There's a race condition with the filesystem, which
+	// is reported in the file's Err.
+	f, err := os.Open(fn)
+	if err != nil {
+		return benchFile{Path: fn, Err: err.Error()}
+	}
+	defer f.Close()
+	bf, err := parseBenchFile(f)
+	bf.Path = fn
+	if err == nil {
+		err = bf.readPerfSidecar(fn)
+	}
+	if err == nil {
+		err = bf.readMetaSidecar(fn)
+	}
+	bf.findProfiles()
+	if err != nil {
+		bf.Err = err.Error()
+	}
+	if fi, err := f.Stat(); err == nil {
+		bf.ModTime = fi.ModTime()
+	}
+	return bf
+}
+
+type byPath []benchFile
+
+func (a byPath) Len() int           { return len(a) }
+func (a byPath) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byPath) Less(i, j int) bool { return a[i].Path < a[j].Path }
+
+// grouping describes how benchmarks are grouped.  It mirrors the settings in
+// the plotter.
+type grouping struct {
+	nre             *regexp.Regexp  // finds the group and N in benchmark names
+	unparameterized string          // how to handle names which don't match nre
+	pkg             string          // if set, only benchmarks in this package are included
+	byPackage       bool            // prefix groups with their package
+	machine         string          // if set, only benchmarks from this machine are included
+	yVar            string          // if set, only benchmarks with this response are included
+	byMachine       bool            // prefix groups with their machine
+	byFile          string          // whether to prefix groups with their file
+	aggregate       string          // how to summarize repeated runs at the same N
+	hidden          map[string]bool // paths of files whose benchmarks are left out
+	unit            unitConv        // units to convert the response to
+	cluster         string          // what the confidence intervals are clustered by, if anything
+	only            string          // if set, only this group is included
+	filter          *regexp.Regexp  // if set, only the groups it matches are included
+	hiddenGroups    map[string]bool // groups which are left out, from the plotter's legend
+}
+
+// groupBenchmarks groups the benchmarks in the same way that the plotter does,
+// using nre to find the group and explanatory variable in each benchmark name.
+// Benchmarks which don't match nre are either returned separately or plotted
+// at N=1, depending on unparameterized.  Repeated runs are summarized by the
+// aggregate.  It also returns the range of the explanatory variable over all
+// of the groups.
+func groupBenchmarks(ds dataSet, g grouping) (groups map[string][]benchmarkResponse, unmatched []*benchmark, xlb, xub float64) {
+	groups = make(map[string][]benchmarkResponse)
+	xlb, xub = math.Inf(1), math.Inf(-1)
+	byFile := g.splitsFiles(ds)
+	for _, f := range ds.Files {
+		if g.hidden[f.Path] {
+			continue
+		}
+		for _, b := range f.Benchmarks {
+			if g.pkg != "" && b.Package != g.pkg {
+				continue
+			}
+			if g.machine != "" && b.Machine != g.machine {
+				continue
+			}
+			if !b.has(g.yVar) {
+				continue
+			}
+			var group string
+			var x float64
+			if matches := g.nre.FindStringSubmatch(b.Name); len(matches) > 2 {
+				var err error
+				if x, err = strconv.ParseFloat(matches[2], 64); err != nil {
+					unmatched = append(unmatched, b)
+					continue
+				}
+				group = matches[1]
+			} else if g.unparameterized == unparamOne {
+				group = procRE.FindStringSubmatch(b.Name)[1]
+				x = 1
+			} else {
+				unmatched = append(unmatched, b)
+				continue
+			}
+			if g.byPackage && b.Package != "" {
+				group = b.Package + "." + group
+			}
+			if g.byMachine && b.Machine != "" {
+				group = "[" + b.Machine + "] " + group
+			}
+			if f.Series != "" {
+				group = f.Series + ": " + group
+			} else if byFile {
+				group = f.Path + ": " + group
+			}
+			if g.only != "" && group != g.only {
+				continue
+			}
+			if (g.filter != nil && !g.filter.MatchString(group)) || g.hiddenGroups[group] {
+				continue
+			}
+			br := benchmarkResponse{Benchmark: b.Benchmark, perfCounters: b.perfCounters, X: x}
+			switch g.cluster {
+			case clusterFile:
+				br.Cluster = f.Path
+			case clusterMachine:
+				br.Cluster = b.Machine
+			}
+			groups[group] = append(groups[group], br)
+			xlb = math.Min(xlb, x)
+			xub = math.Max(xub, x)
+		}
+	}
+	if g.aggregate != "" && g.aggregate != aggregateAll {
+		for group, benchSet := range groups {
+			groups[group] = aggregateRuns(benchSet, g.aggregate)
+		}
+	}
+	if g.unit.converts() {
+		for _, benchSet := range groups {
+			for i := range benchSet {
+				benchSet[i].divisor = g.unit.divisor(benchSet[i].X)
+			}
+		}
+	}
+	return groups, unmatched, xlb, xub
+}
+
+// splitsFiles returns whether the groups of ds are prefixed with the file
+// their benchmarks are from.  Files in a series, like the runs of -run, are
+// already prefixed with it instead, and hidden files aren't plotted, so
+// neither are counted.
+func (g grouping) splitsFiles(ds dataSet) bool {
+	switch g.byFile {
+	case byFileAlways:
+		return true
+	case byFileAuto:
+		n := 0
+		for _, f := range ds.Files {
+			if f.Series == "" && !g.hidden[f.Path] && len(f.Benchmarks) > 0 {
+				n++
+			}
+		}
+		return n > 1
+	}
+	return false
+}
+
+// aggregateRuns summarizes the runs of a group at each N, in the order that
+// each N first appears.  Each measurement is summarized separately, except
+// that the best run has the largest MB/s rather than the smallest.  N is the
+// total number of iterations of the runs.
+func aggregateRuns(benchSet []benchmarkResponse, aggregate string) []benchmarkResponse {
+	var xs []float64
+	runs := make(map[float64][]benchmarkResponse)
+	for _, b := range benchSet {
+		if _, ok := runs[b.X]; !ok {
+			xs = append(xs, b.X)
+		}
+		runs[b.X] = append(runs[b.X], b)
+	}
+
+	summary := func(v []float64, best func(a, b float64) float64) float64 {
+		switch aggregate {
+		case aggregateMean:
+			var sum kahan
+			for _, vi := range v {
+				sum.add(vi)
+			}
+			return sum.value() / float64(len(v))
+		case aggregateMedian:
+			sort.Float64s(v)
+			m := v[len(v)/2]
+			if len(v)%2 == 0 {
+				m = (v[len(v)/2-1] + m) / 2
+			}
+			return m
+		}
+		m := v[0]
+		for _, vi := range v[1:] {
+			m = best(m, vi)
+		}
+		return m
+	}
+
+	agg := make([]benchmarkResponse, len(xs))
+	for i, x := range xs {
+		r := runs[x]
+		ns := make([]float64, len(r))
+		bytes := make([]float64, len(r))
+		allocs := make([]float64, len(r))
+		mbs := make([]float64, len(r))
+		counters := make(map[string][]float64)
+		n := 0
+		for j, b := range r {
+			ns[j] = b.NsPerOp
+			bytes[j] = float64(b.AllocedBytesPerOp)
+			allocs[j] = float64(b.AllocsPerOp)
+			mbs[j] = b.MBPerS
+			// runs without a counter are left out of its summary
+			for _, yVar := range perfEvents {
+				if c, ok := b.count(yVar); ok {
+					counters[yVar] = append(counters[yVar], c)
+				}
+			}
+			n += b.N
+		}
+		a := r[0]
+		a.N = n
+		a.NsPerOp = summary(ns, math.Min)
+		a.AllocedBytesPerOp = uint64(summary(bytes, math.Min) + 0.5)
+		a.AllocsPerOp = uint64(summary(allocs, math.Min) + 0.5)
+		a.MBPerS = summary(mbs, math.Max)
+		for yVar, v := range counters {
+			a.setCount(yVar, summary(v, math.Min))
+		}
+		agg[i] = a
+	}
+	return agg
+}
+
+// canFit returns true if a model with nTerms terms can be fit to the
+// benchmarks, which requires more benchmarks than terms at more than one N.
+func canFit(benchSet []benchmarkResponse, nTerms int) bool {
+	if len(benchSet) <= nTerms {
+		return false
+	}
+	for _, b := range benchSet {
+		if b.X != benchSet[0].X {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBenchFile parses the output of go test -bench, line by line.  Lines
+// which can't be parsed are recorded as warnings rather than causing an error,
+// and a read error returns the benchmarks which were parsed before it.  The
+// Path and ModTime of the result are not set.  JSON documents are parsed by
+// parseJSONBenchFile instead, and Criterion's raw.csv by parseCriterionCSV,
+// for the output of other benchmark harnesses.
+func parseBenchFile(r io.Reader) (benchFile, error) {
+	rd := bufio.NewReader(r)
+	if isJSON(rd) {
+		return parseJSONBenchFile(rd)
+	}
+	if isCriterionCSV(rd) {
+		return parseCriterionCSV(rd)
+	}
+	bf := benchFile{Config: make(map[string]string)}
+	err := scanLines(rd, bf.parseLine)
+	return bf, err
+}
+
+// scanLines calls fn with each line read from r, without the line ending,
+// until r returns EOF or an error.  Lines are numbered starting from 1.
+func scanLines(r io.Reader, fn func(line int, text string)) error {
+	// bufio.Scanner can't handle arbitrarily long lines, which can show up
+	// when benchmarks print to stdout, so use a Reader instead.
+	rd := bufio.NewReader(r)
+	line := 0
+	for {
+		text, err := rd.ReadString('\n')
+		if text != "" {
+			line++
+			fn(line, strings.TrimRight(text, "\r\n"))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseLine adds a single line of go test -bench output to the file.
+// Benchmarks belong to the package named in the "pkg:" configuration line
+// until a package result line is found, which names the package of every
+// benchmark since the previous result line.
+func (bf *benchFile) parseLine(line int, text string) {
+	b, err := parse.ParseLine(text)
+	switch {
+	case err == nil:
+		b.Ord = len(bf.Benchmarks)
+		bf.Benchmarks = append(bf.Benchmarks, &benchmark{Benchmark: *b, Package: bf.Config["pkg"], Machine: machineName(bf.Config), Line: line})
+	case strings.HasPrefix(text, "Benchmark"):
+		bf.Warnings = append(bf.Warnings, parseWarning{line, text, err.Error()})
+	default:
+		if matches := pkgResultRE.FindStringSubmatch(text); matches != nil {
+			for _, b := range bf.Benchmarks[bf.pending:] {
+				b.Package = matches[1]
+			}
+			bf.pending = len(bf.Benchmarks)
+		} else if yVar, count, ok := parsePerfLine(text); ok && len(bf.Benchmarks) > 0 {
+			// perf stat output follows the benchmark that it counted
+			bf.Benchmarks[len(bf.Benchmarks)-1].addCounter(yVar, count)
+		} else if key, value, ok := parseConfigLine(text); ok {
+			bf.Config[key] = value
+		} else {
+			bf.Skipped++
+		}
+	}
+}
+
+// parseConfigLine parses a benchfmt configuration line, which has the form
+// "key: value", where key starts with a lower case letter and contains no
+// upper case or space characters.
+func parseConfigLine(text string) (key, value string, ok bool) {
+	i := strings.Index(text, ":")
+	if i < 1 {
+		return "", "", false
+	}
+	key = text[:i]
+	for j, r := range key {
+		if unicode.IsSpace(r) || unicode.IsUpper(r) || (j == 0 && !unicode.IsLower(r)) {
+			return "", "", false
+		}
+	}
+	return key, strings.TrimSpace(text[i+1:]), true
+}
@@ -0,0 +1,382 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseBenchFile(t *testing.T) {
+	in := `goos: linux
+goarch: amd64
+pkg: github.com/jonlawlor/benchplot
+PASS
+BenchmarkSort10-4            	 1000000	      1008 ns/op
+BenchmarkSort100-4           	  200000
+BenchmarkSort1000-4          	   10000	    152945 ns/op
+ok  	github.com/jonlawlor/benchplot	138.860s
+`
+	bf, err := parseBenchFile(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantConfig := map[string]string{
+		"goos":   "linux",
+		"goarch": "amd64",
+		"pkg":    "github.com/jonlawlor/benchplot",
+	}
+	if !reflect.DeepEqual(bf.Config, wantConfig) {
+		t.Errorf("got config %v, want %v", bf.Config, wantConfig)
+	}
+	if bf.Skipped != 1 {
+		t.Errorf("got %d skipped lines, want 1", bf.Skipped)
+	}
+	if len(bf.Warnings) != 1 || bf.Warnings[0].Line != 6 {
+		t.Errorf("got warnings %v, want one on line 6", bf.Warnings)
+	}
+	if len(bf.Benchmarks) != 2 {
+		t.Fatalf("got %d benchmarks, want 2", len(bf.Benchmarks))
+	}
+	for i, b := range bf.Benchmarks {
+		if b.Ord != i {
+			t.Errorf("benchmark %s has ord %d, want %d", b.Name, b.Ord, i)
+		}
+		if want := []int{5, 7}[i]; b.Line != want {
+			t.Errorf("benchmark %s is on line %d, want %d", b.Name, b.Line, want)
+		}
+	}
+}
+
+func TestParseConfigLine(t *testing.T) {
+	for _, test := range []struct {
+		text       string
+		key, value string
+		ok         bool
+	}{
+		{"goos: linux", "goos", "linux", true},
+		{"cpu: Intel(R) Xeon(R) CPU @ 2.20GHz", "cpu", "Intel(R) Xeon(R) CPU @ 2.20GHz", true},
+		{"PASS", "", "", false},
+		{"Goos: linux", "", "", false},
+		{"go os: linux", "", "", false},
+		{": linux", "", "", false},
+	} {
+		key, value, ok := parseConfigLine(test.text)
+		if key != test.key || value != test.value || ok != test.ok {
+			t.Errorf("parseConfigLine(%q) = %q, %q, %v, want %q, %q, %v",
+				test.text, key, value, ok, test.key, test.value, test.ok)
+		}
+	}
+}
+
+func TestParseBenchFileLongLine(t *testing.T) {
+	in := "BenchmarkSort10-4 1000000 1008 ns/op\n" +
+		strings.Repeat("x", 1<<20) + "\n" +
+		"BenchmarkSort100-4 200000 8224 ns/op"
+	bf, err := parseBenchFile(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bf.Benchmarks) != 2 {
+		t.Errorf("got %d benchmarks, want 2", len(bf.Benchmarks))
+	}
+	if bf.Skipped != 1 {
+		t.Errorf("got %d skipped lines, want 1", bf.Skipped)
+	}
+}
+
+func TestParseBenchFilePackages(t *testing.T) {
+	in := `BenchmarkSort10-4 1000000 1008 ns/op
+ok  	github.com/jonlawlor/a	1.234s
+pkg: github.com/jonlawlor/b
+BenchmarkSort10-4 1000000 1008 ns/op
+BenchmarkSort100-4 200000 8224 ns/op
+FAIL	github.com/jonlawlor/c	2.345s
+pkg: github.com/jonlawlor/d
+BenchmarkSort10-4 1000000 1008 ns/op
+`
+	bf, err := parseBenchFile(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"github.com/jonlawlor/a",
+		"github.com/jonlawlor/c",
+		"github.com/jonlawlor/c",
+		"github.com/jonlawlor/d",
+	}
+	if len(bf.Benchmarks) != len(want) {
+		t.Fatalf("got %d benchmarks, want %d", len(bf.Benchmarks), len(want))
+	}
+	for i, b := range bf.Benchmarks {
+		if b.Package != want[i] {
+			t.Errorf("benchmark %d has package %q, want %q", i, b.Package, want[i])
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	in := `BenchmarkSort10-4	1000	120 ns/op	16 B/op	1 allocs/op
+BenchmarkSort10-4	1000	100 ns/op	16 B/op	2 allocs/op
+BenchmarkSort10-4	1000	140 ns/op	16 B/op	2 allocs/op
+BenchmarkSort100-4	100	1000 ns/op	32 B/op	3 allocs/op
+`
+	bf, err := parseBenchFile(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := dataSet{Files: []benchFile{bf}}
+	for _, test := range []struct {
+		aggregate string
+		n         int
+		ns        float64
+		allocs    uint64
+	}{
+		{aggregateMean, 3000, 120, 2},
+		{aggregateMedian, 3000, 120, 2},
+		{aggregateMin, 3000, 100, 1},
+	} {
+		g := grouping{nre: regexp.MustCompile(defaultNRE), aggregate: test.aggregate}
+		groups, _, _, _ := groupBenchmarks(ds, g)
+		sort := groups["BenchmarkSort"]
+		if len(sort) != 2 {
+			t.Fatalf("%s: got %d benchmarks, want 2", test.aggregate, len(sort))
+		}
+		if b := sort[0]; b.X != 10 || b.N != test.n || b.NsPerOp != test.ns || b.AllocsPerOp != test.allocs {
+			t.Errorf("%s: got %+v at N=10, want %d iterations, %g ns/op and %d allocs/op", test.aggregate, b, test.n, test.ns, test.allocs)
+		}
+		if b := sort[1]; b.X != 100 || b.NsPerOp != 1000 {
+			t.Errorf("%s: got %+v at N=100", test.aggregate, b)
+		}
+	}
+
+	if _, err := parseGrouping(url.Values{"aggregate": {"max"}}); err == nil {
+		t.Error("parseGrouping accepted an invalid aggregate")
+	}
+}
+
+func TestGroupByMachine(t *testing.T) {
+	in := `goarch: amd64
+cpu: Intel(R) Core(TM) i7-8650U CPU @ 1.90GHz
+BenchmarkSort10-4	1000	120 ns/op
+goarch: arm64
+cpu: Apple M1
+BenchmarkSort10-8	1000	90 ns/op
+`
+	bf, err := parseBenchFile(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := bf.Benchmarks[1].Machine, "arm64, Apple M1"; got != want {
+		t.Errorf("got machine %q, want %q", got, want)
+	}
+	ds := dataSet{Files: []benchFile{bf}}
+	nre := regexp.MustCompile(defaultNRE)
+
+	groups, _, _, _ := groupBenchmarks(ds, grouping{nre: nre, byMachine: true})
+	if len(groups) != 2 || len(groups["[arm64, Apple M1] BenchmarkSort"]) != 1 {
+		t.Errorf("got groups %v, want one for each machine", groups)
+	}
+	groups, _, _, _ = groupBenchmarks(ds, grouping{nre: nre, machine: "arm64, Apple M1"})
+	if b := groups["BenchmarkSort"]; len(b) != 1 || b[0].NsPerOp != 90 {
+		t.Errorf("got %v from the arm64 machine", b)
+	}
+}
+
+func TestGroupHiddenFiles(t *testing.T) {
+	ds := dataSet{}
+	for i, in := range []string{"BenchmarkSort10-4\t1000\t100 ns/op\n", "BenchmarkSort10-4\t1000\t200 ns/op\n"} {
+		bf, err := parseBenchFile(strings.NewReader(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bf.Path = []string{"a.txt", "b.txt"}[i]
+		ds.Files = append(ds.Files, bf)
+	}
+	g, err := parseGrouping(url.Values{"hide": {"a.txt"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups, _, _, _ := groupBenchmarks(ds, g)
+	if b := groups["BenchmarkSort"]; len(b) != 1 || b[0].NsPerOp != 200 {
+		t.Errorf("got %v with a.txt hidden, want only the benchmark from b.txt", b)
+	}
+}
+
+func TestGroupByFile(t *testing.T) {
+	ds := dataSet{}
+	for i, in := range []string{"BenchmarkSort10-4\t1000\t100 ns/op\n", "BenchmarkSort10-4\t1000\t200 ns/op\n", "no benchmarks\n"} {
+		bf, err := parseBenchFile(strings.NewReader(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bf.Path = []string{"before.txt", "after.txt", "notes.txt"}[i]
+		ds.Files = append(ds.Files, bf)
+	}
+	tests := []struct {
+		byFile string
+		hide   []string
+		want   []string
+	}{
+		{"", nil, []string{"BenchmarkSort"}},
+		{"false", nil, []string{"BenchmarkSort"}},
+		{"true", nil, []string{"after.txt: BenchmarkSort", "before.txt: BenchmarkSort"}},
+		{"true", []string{"before.txt"}, []string{"after.txt: BenchmarkSort"}},
+		{"auto", nil, []string{"after.txt: BenchmarkSort", "before.txt: BenchmarkSort"}},
+		// only the plotted files are counted
+		{"auto", []string{"before.txt"}, []string{"BenchmarkSort"}},
+	}
+	for _, test := range tests {
+		g, err := parseGrouping(url.Values{"byfile": {test.byFile}, "hide": test.hide})
+		if err != nil {
+			t.Fatal(err)
+		}
+		groups, _, _, _ := groupBenchmarks(ds, g)
+		var got []string
+		for group := range groups {
+			got = append(got, group)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("byfile=%s hide=%q: got groups %q, want %q", test.byFile, test.hide, got, test.want)
+		}
+	}
+
+	// a single file with benchmarks isn't split automatically, and neither
+	// are files in a series, which are split by it instead
+	g, err := parseGrouping(url.Values{"byfile": {"auto"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.splitsFiles(dataSet{Files: ds.Files[1:]}) {
+		t.Error("split the groups of a single file with benchmarks")
+	}
+	ds.Files[0].Series = "run 1"
+	if g.splitsFiles(ds) {
+		t.Error("split the groups of a series by file")
+	}
+	if _, err := parseGrouping(url.Values{"byfile": {"sometimes"}}); err == nil {
+		t.Error("parseGrouping accepted an invalid byfile")
+	}
+}
+
+func TestGroupFilter(t *testing.T) {
+	in := "BenchmarkSort10-4\t1000\t100 ns/op\nBenchmarkStableSort10-4\t1000\t200 ns/op\nBenchmarkSearch10-4\t1000\t50 ns/op\n"
+	bf, err := parseBenchFile(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := dataSet{Files: []benchFile{bf}}
+	for _, test := range []struct {
+		v    url.Values
+		want []string
+	}{
+		{url.Values{}, []string{"BenchmarkSearch", "BenchmarkSort", "BenchmarkStableSort"}},
+		{url.Values{"filter": {"sort"}}, []string{"BenchmarkSort", "BenchmarkStableSort"}},
+		{url.Values{"filter": {"sort"}, "filterre": {"true"}}, nil},
+		{url.Values{"filter": {"^BenchmarkS(ort|earch)$"}, "filterre": {"true"}}, []string{"BenchmarkSearch", "BenchmarkSort"}},
+		{url.Values{"filter": {"(.*"}}, nil},
+		{url.Values{"filter": {"Sort"}, "group": {"BenchmarkStableSort"}}, []string{"BenchmarkStableSort"}},
+		// groups hidden in the legend are left out as well
+		{url.Values{"hidegroup": {"BenchmarkSort", "BenchmarkSearch"}}, []string{"BenchmarkStableSort"}},
+		{url.Values{"filter": {"sort"}, "hidegroup": {"BenchmarkSort"}}, []string{"BenchmarkStableSort"}},
+	} {
+		g, err := parseGrouping(test.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		groups, _, _, _ := groupBenchmarks(ds, g)
+		var got []string
+		for group := range groups {
+			got = append(got, group)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v: got groups %v, want %v", test.v, got, test.want)
+		}
+	}
+	if _, err := parseGrouping(url.Values{"filter": {"(.*"}, "filterre": {"true"}}); err == nil {
+		t.Errorf("an invalid filter regexp didn't return an error")
+	}
+}
+
+func TestGroupUnparameterized(t *testing.T) {
+	bf, err := parseBenchFile(strings.NewReader(`BenchmarkSort10-4	1000	100 ns/op
+BenchmarkSort/n=100-4	1000	1000 ns/op
+BenchmarkEncode-4	1000	50 ns/op
+BenchmarkDecode	1000	70 ns/op
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := dataSet{Files: []benchFile{bf}}
+	tests := []struct {
+		nre, unparameterized string
+		groups, unmatched    []string
+		xlb, xub             float64
+	}{
+		{"", "", []string{"BenchmarkSort", "BenchmarkSort/n="}, []string{"BenchmarkEncode-4", "BenchmarkDecode"}, 10, 100},
+		{"", unparamTable, []string{"BenchmarkSort", "BenchmarkSort/n="}, []string{"BenchmarkEncode-4", "BenchmarkDecode"}, 10, 100},
+		{"", unparamOne, []string{"BenchmarkDecode", "BenchmarkEncode", "BenchmarkSort", "BenchmarkSort/n="}, nil, 1, 100},
+		// a custom rule can match both forms of N
+		{"Benchmark{Group}/n={N:int}", "", []string{"Sort"}, []string{"BenchmarkSort10-4", "BenchmarkEncode-4", "BenchmarkDecode"}, 100, 100},
+		{`^Benchmark(\w+?)(?:/n=)?(\d+)-\d+$`, "", []string{"Sort"}, []string{"BenchmarkEncode-4", "BenchmarkDecode"}, 10, 100},
+	}
+	for _, test := range tests {
+		g, err := parseGrouping(url.Values{"nre": {test.nre}, "unparameterized": {test.unparameterized}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		groups, unmatched, xlb, xub := groupBenchmarks(ds, g)
+		var names []string
+		for group := range groups {
+			names = append(names, group)
+		}
+		sort.Strings(names)
+		var unmatchedNames []string
+		for _, b := range unmatched {
+			unmatchedNames = append(unmatchedNames, b.Name)
+		}
+		if !reflect.DeepEqual(names, test.groups) || !reflect.DeepEqual(unmatchedNames, test.unmatched) {
+			t.Errorf("nre %q unparameterized %q: got groups %q and unmatched %q, want %q and %q",
+				test.nre, test.unparameterized, names, unmatchedNames, test.groups, test.unmatched)
+		}
+		if xlb != test.xlb || xub != test.xub {
+			t.Errorf("nre %q unparameterized %q: got range [%v, %v], want [%v, %v]",
+				test.nre, test.unparameterized, xlb, xub, test.xlb, test.xub)
+		}
+	}
+}
+
+func TestCanFit(t *testing.T) {
+	at := func(xs ...float64) []benchmarkResponse {
+		var bs []benchmarkResponse
+		for _, x := range xs {
+			bs = append(bs, benchmarkResponse{X: x})
+		}
+		return bs
+	}
+	tests := []struct {
+		benchSet []benchmarkResponse
+		nTerms   int
+		want     bool
+	}{
+		{at(), 1, false},
+		{at(1, 2), 1, true},
+		{at(1, 2), 2, false},
+		{at(1, 2, 3), 2, true},
+		// benchmarks without N are all at N=1, so nothing can be fit to them
+		{at(1, 1, 1, 1), 1, false},
+	}
+	for _, test := range tests {
+		if got := canFit(test.benchSet, test.nTerms); got != test.want {
+			t.Errorf("canFit(%d benchmarks, %d terms): got %v, want %v", len(test.benchSet), test.nTerms, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// dumpVersion is the version of the history dump format.  It should be
+// incremented whenever a change would break importing older dumps.
+const dumpVersion = 1
+
+// historyDump is the portable form of the history database, written by
+// benchplot db export and read by benchplot db import.  It is a json object
+// like
+//
+//	{
+//	  "Version": 1,
+//	  "Runs": [
+//	    {
+//	      "ID": "20160102T150405Z-1a2b3c4d",
+//	      "Time": "2016-01-02T15:04:05Z",
+//	      "Labels": {"branch": "main", "commit": "abc123"},
+//	      "File": {"Config": {"goarch": "amd64"}, "Benchmarks": [...]}
+//	    }
+//	  ]
+//	}
+//
+// where each run's File has the same form as the files served at /data.
+// Runs are in the order that they were stored.
+type historyDump struct {
+	Version int
+	Runs    []storedRun
+}
+
+func dbUsage(fs *flag.FlagSet) func() {
+	return func() {
+		fmt.Fprintf(os.Stderr, "usage: benchplot db export [options]\n")
+		fmt.Fprintf(os.Stderr, "       benchplot db import [options] dump.json [dump2.json ...]\n")
+		fmt.Fprintf(os.Stderr, "exports the history database as json, or imports an export into it\n")
+		fmt.Fprintf(os.Stderr, "example:\n")
+		fmt.Fprintf(os.Stderr, "   benchplot db export -db=history -select=branch=main -o main.json\n")
+		fmt.Fprintf(os.Stderr, "options:\n")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+}
+
+func dbMain(args []string) {
+	fs := flag.NewFlagSet("db", flag.ExitOnError)
+	fs.Usage = dbUsage(fs)
+	dir := fs.String("db", *dbDir, "directory of the history database")
+	out := fs.String("o", "-", "output file of export, or - for stdout")
+	sel := fs.String("select", "", "selector of the runs to export (default is every run)")
+	replace := fs.Bool("replace", false, "replace stored runs with the same ID when importing (default is to keep them)")
+	if len(args) == 0 {
+		fs.Usage()
+	}
+	cmd := args[0]
+	fs.Parse(args[1:])
+	if *dir == "" {
+		fatal("missing -db")
+	}
+	db, err := openHistory(*dir)
+	if err != nil {
+		fatal(err)
+	}
+
+	switch cmd {
+	case "export":
+		if fs.NArg() != 0 {
+			fs.Usage()
+		}
+		s, err := parseSelector(*sel)
+		if err != nil {
+			fatal(err)
+		}
+		if *out == "-" {
+			err = exportHistory(os.Stdout, db, s)
+		} else {
+			var f *os.File
+			if f, err = os.Create(*out); err != nil {
+				fatal(err)
+			}
+			if err = exportHistory(f, db, s); err == nil {
+				err = f.Close()
+			}
+		}
+		if err != nil {
+			fatal(err)
+		}
+	case "import":
+		if fs.NArg() == 0 {
+			fs.Usage()
+		}
+		for _, fn := range fs.Args() {
+			f, err := os.Open(fn)
+			if err != nil {
+				fatal(err)
+			}
+			n, err := importHistory(db, f, *replace)
+			f.Close()
+			if err != nil {
+				fatalf("%s: %v", fn, err)
+			}
+			slog.Info("imported runs", "file", fn, "runs", n)
+		}
+	default:
+		fs.Usage()
+	}
+}
+
+// exportHistory writes the runs which match the selector as a historyDump.
+func exportHistory(w io.Writer, db *historyDB, sel selector) error {
+	runs, err := db.runs()
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(historyDump{Version: dumpVersion, Runs: selectRuns(runs, sel)}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// importHistory stores the runs in a historyDump, keeping their IDs.  Runs
+// which are already stored are skipped, unless replace is set.  It returns
+// the number of runs that were stored.
+func importHistory(db *historyDB, r io.Reader, replace bool) (int, error) {
+	var dump historyDump
+	if err := json.NewDecoder(r).Decode(&dump); err != nil {
+		return 0, err
+	}
+	if dump.Version < 1 || dump.Version > dumpVersion {
+		return 0, fmt.Errorf("unsupported version %d", dump.Version)
+	}
+	n := 0
+	for _, sr := range dump.Runs {
+		if !runIDRE.MatchString(sr.ID) {
+			return n, fmt.Errorf("invalid run ID %q", sr.ID)
+		}
+		if !replace && db.has(sr.ID) {
+			continue
+		}
+		if err := db.put(sr); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,64 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExportImport(t *testing.T) {
+	var dbs []*historyDB
+	for i := 0; i < 2; i++ {
+		dir, err := ioutil.TempDir("", "benchplot")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		db, err := openHistory(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dbs = append(dbs, db)
+	}
+	bf, err := parseBenchFile(strings.NewReader("BenchmarkSort10-4\t1000\t100 ns/op\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, branch := range []string{"main", "dev"} {
+		if _, err := dbs[0].add(bf, map[string]string{"branch": branch}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	sel, _ := parseSelector("branch=main")
+	if err := exportHistory(&buf, dbs[0], sel); err != nil {
+		t.Fatal(err)
+	}
+	dump := buf.String()
+	for i, want := range []int{1, 0} {
+		n, err := importHistory(dbs[1], strings.NewReader(dump), false)
+		if err != nil || n != want {
+			t.Errorf("import %d: got %d runs, error %v, want %d", i, n, err, want)
+		}
+	}
+	runs, err := dbs[1].runs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Labels["branch"] != "main" || len(runs[0].File.Benchmarks) != 1 {
+		t.Errorf("got imported runs %+v, want the run on main", runs)
+	}
+
+	for _, bad := range []string{`{"Version": 2}`, `{"Version": 1, "Runs": [{"ID": "../escape"}]}`} {
+		if _, err := importHistory(dbs[1], strings.NewReader(bad), false); err == nil {
+			t.Errorf("imported %s", bad)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// defaultMaxPoints is the most points that /points draws for a group, by
+// default.
+const defaultMaxPoints = 500
+
+// decimatedPoint summarizes the benchmarks of a group which are near the same
+// N, for plotting a group with too many points to draw.  Spread is the
+// minimum, quartiles, and maximum of their response, and Y is their median.
+type decimatedPoint struct {
+	X      float64
+	Y      float64
+	Runs   int
+	Spread [5]float64
+}
+
+// decimate summarizes the benchmarks in at most maxPoints points.  There is
+// a point for each N, if there are few enough of them.  Otherwise adjacent Ns
+// are combined into maxPoints bins of equal width, on a log scale if every N
+// is positive, and each point is at the median N of its bin.
+func decimate(benchSet []benchmarkResponse, yVar string, maxPoints int) []decimatedPoint {
+	y := sampleGroup(benchSet, nil, yVar).y
+	values := make(map[float64][]float64)
+	var xs []float64
+	for i, b := range benchSet {
+		if _, ok := values[b.X]; !ok {
+			xs = append(xs, b.X)
+		}
+		values[b.X] = append(values[b.X], y[i])
+	}
+	sort.Float64s(xs)
+
+	// the Ns in each bin
+	var bins [][]float64
+	if len(xs) <= maxPoints {
+		for _, x := range xs {
+			bins = append(bins, []float64{x})
+		}
+	} else {
+		scale := func(x float64) float64 { return x }
+		if xs[0] > 0 {
+			scale = math.Log
+		}
+		lo, hi := scale(xs[0]), scale(xs[len(xs)-1])
+		width := (hi - lo) / float64(maxPoints)
+		bins = make([][]float64, maxPoints)
+		for _, x := range xs {
+			i := int((scale(x) - lo) / width)
+			if i >= maxPoints {
+				i = maxPoints - 1
+			}
+			bins[i] = append(bins[i], x)
+		}
+	}
+
+	points := []decimatedPoint{}
+	for _, bin := range bins {
+		if len(bin) == 0 {
+			continue
+		}
+		var v []float64
+		var binXs []float64
+		for _, x := range bin {
+			v = append(v, values[x]...)
+			for range values[x] {
+				binXs = append(binXs, x)
+			}
+		}
+		sort.Float64s(v)
+		p := decimatedPoint{X: quantile(binXs, 0.5), Y: quantile(v, 0.5), Runs: len(v)}
+		for i, q := range []float64{0, 0.25, 0.5, 0.75, 1} {
+			p.Spread[i] = quantile(v, q)
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// quantile returns the p-quantile of the sorted values, interpolating
+// between them in the same way as d3.quantile.
+func quantile(v []float64, p float64) float64 {
+	h := float64(len(v)-1) * p
+	i := int(h)
+	if i+1 >= len(v) {
+		return v[len(v)-1]
+	}
+	return v[i] + (h-float64(i))*(v[i+1]-v[i])
+}
+
+// servePoints decimates every group with more benchmarks than maxpoints in
+// the querystring, for plotting, keyed by group.  Groups with few enough
+// benchmarks to draw are left out.  The groups are fit on all of their
+// benchmarks regardless.
+func servePoints(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q, err := parseAnalysisQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		maxPoints := defaultMaxPoints
+		if v := r.Form.Get("maxpoints"); v != "" {
+			if maxPoints, err = strconv.Atoi(v); err != nil || maxPoints < 1 {
+				http.Error(w, "invalid maxpoints: "+v, http.StatusBadRequest)
+				return
+			}
+		}
+
+		groups, _, _, _ := groupBenchmarks(src.dataSet(), q.grouping)
+		points := make(map[string][]decimatedPoint)
+		for group, benchSet := range groups {
+			if len(benchSet) > maxPoints {
+				points[group] = decimate(benchSet, q.yVar, maxPoints)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(points)
+	}
+}
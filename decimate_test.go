@@ -0,0 +1,47 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+func TestDecimate(t *testing.T) {
+	var benchSet []benchmarkResponse
+	for n := 1; n <= 1000; n++ {
+		for run := 0; run < 3; run++ {
+			benchSet = append(benchSet, benchmarkResponse{Benchmark: parse.Benchmark{NsPerOp: float64(n + run)}, X: float64(n)})
+		}
+	}
+
+	// few enough Ns keeps one point for each of them
+	points := decimate(benchSet, "NsPerOp", 1000)
+	if len(points) != 1000 {
+		t.Fatalf("got %d points, want 1000", len(points))
+	}
+	if p := points[9]; p.X != 10 || p.Y != 11 || p.Runs != 3 || p.Spread != [5]float64{10, 10.5, 11, 11.5, 12} {
+		t.Errorf("got point %+v at N=10", p)
+	}
+
+	points = decimate(benchSet, "NsPerOp", 50)
+	if len(points) > 50 {
+		t.Fatalf("got %d points, want at most 50", len(points))
+	}
+	runs := 0
+	for i, p := range points {
+		runs += p.Runs
+		if i > 0 && p.X <= points[i-1].X {
+			t.Errorf("points are not in order of N: %v then %v", points[i-1].X, p.X)
+		}
+		if p.Spread[0] > p.Y || p.Y > p.Spread[4] {
+			t.Errorf("median %v of point at N=%v is outside of its spread %v", p.Y, p.X, p.Spread)
+		}
+	}
+	if runs != len(benchSet) {
+		t.Errorf("points summarize %d runs, want %d", runs, len(benchSet))
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultInclude is the pattern of the files which are read from -dir if
+// there isn't any -include.
+const defaultInclude = "**/*.txt"
+
+// dirSource reads the files matching the glob patterns, like globSource, as
+// well as every file in the tree under dir which matches one of the include
+// patterns and none of the exclude patterns.  The tree is walked each time
+// its data is requested, so that new files are picked up.
+type dirSource struct {
+	globSource
+	dir     string
+	include []string
+	exclude []string
+}
+
+func (s dirSource) dataSet() dataSet {
+	ds := s.globSource.dataSet()
+	seen := make(map[string]bool)
+	for _, f := range ds.Files {
+		seen[f.Path] = true
+	}
+	for _, fn := range s.files() {
+		if !seen[fn] {
+			ds.Files = append(ds.Files, readBenchFile(fn))
+		}
+	}
+	sort.Sort(byPath(ds.Files))
+	return ds
+}
+
+// files returns the paths of the files under dir which are included.
+// Directories that can't be read are skipped.
+func (s dirSource) files() []string {
+	var fns []string
+	filepath.Walk(s.dir, func(fn string, fi os.FileInfo, err error) error {
+		if err != nil {
+			slog.Warn("reading directory", "error", err)
+			return nil
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, fn)
+		if err != nil {
+			return nil
+		}
+		if includedPath(filepath.ToSlash(rel), s.include, s.exclude) {
+			fns = append(fns, fn)
+		}
+		return nil
+	})
+	return fns
+}
+
+// includedPath reports whether the slash separated path matches one of the
+// include patterns and none of the exclude patterns.
+func includedPath(name string, include, exclude []string) bool {
+	for _, pat := range exclude {
+		if matchPath(pat, name) {
+			return false
+		}
+	}
+	for _, pat := range include {
+		if matchPath(pat, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath reports whether the slash separated path matches the pattern.
+// Each element of the pattern is matched against an element of the path
+// like path.Match, except that ** matches any number of elements, including
+// none, so that **/*.txt matches a.txt as well as x/y/a.txt.  Invalid
+// patterns don't match anything.
+func matchPath(pattern, name string) bool {
+	return matchElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElems(pat, elems []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(elems); i++ {
+				if matchElems(pat[1:], elems[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(elems) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pat[0], elems[0]); !ok || err != nil {
+			return false
+		}
+		pat, elems = pat[1:], elems[1:]
+	}
+	return len(elems) == 0
+}
+
+// checkDirPatterns exits if any of the include or exclude patterns are
+// invalid.
+func checkDirPatterns(patterns []string) {
+	for _, pat := range patterns {
+		for _, elem := range strings.Split(pat, "/") {
+			if _, err := path.Match(elem, ""); err != nil {
+				fatalf("invalid pattern: %s", pat)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	for _, test := range []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"**/*.txt", "a.txt", true},
+		{"**/*.txt", "x/y/a.txt", true},
+		{"**/*.txt", "x/a.json", false},
+		{"*.txt", "x/a.txt", false},
+		{"**/flaky/**", "flaky/a.txt", true},
+		{"**/flaky/**", "x/flaky/y/a.txt", true},
+		{"**/flaky/**", "x/notflaky/a.txt", false},
+		{"nightly/**/go*.txt", "nightly/2016/01/go1.6.txt", true},
+		{"nightly/**/go*.txt", "weekly/go1.6.txt", false},
+		{"[", "[", false},
+	} {
+		if got := matchPath(test.pattern, test.name); got != test.want {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", test.pattern, test.name, got, test.want)
+		}
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, fn := range []string{"a.txt", "x/b.txt", "x/flaky/c.txt", "x/d.json"} {
+		fn = filepath.Join(dir, filepath.FromSlash(fn))
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fn, []byte("BenchmarkSort10-4\t1000\t100 ns/op\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src := dirSource{dir: dir, include: []string{defaultInclude}, exclude: []string{"**/flaky/**"}}
+	ds := src.dataSet()
+	var got []string
+	for _, f := range ds.Files {
+		rel, _ := filepath.Rel(dir, f.Path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	if len(got) != 2 || got[0] != "a.txt" || got[1] != "x/b.txt" {
+		t.Errorf("got files %v, want [a.txt x/b.txt]", got)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// maxBins is the largest number of bins that a histogram can be split into.
+const maxBins = 1000
+
+// distribution is the spread of the repeated runs of a single benchmark, which
+// can show problems like a bimodal benchmark, from thermal throttling or
+// background load.
+type distribution struct {
+	Benchmark string
+	YVar      string
+	Values    []float64 // in increasing order
+	Bins      []bin
+}
+
+// bin is a single bar of a histogram, covering [Low, High).  The last bin
+// also includes High.
+type bin struct {
+	Low, High float64
+	Count     int
+}
+
+// histogram counts the values, which must be sorted, in nBins bins of equal
+// width.
+func histogram(values []float64, nBins int) []bin {
+	if len(values) == 0 {
+		return []bin{}
+	}
+	lo, hi := values[0], values[len(values)-1]
+	if lo == hi {
+		return []bin{{lo, hi, len(values)}}
+	}
+	width := (hi - lo) / float64(nBins)
+	bins := make([]bin, nBins)
+	for i := range bins {
+		bins[i].Low = lo + float64(i)*width
+		bins[i].High = lo + float64(i+1)*width
+	}
+	bins[nBins-1].High = hi
+	for _, v := range values {
+		i := int((v - lo) / width)
+		if i >= nBins {
+			i = nBins - 1
+		}
+		bins[i].Count++
+	}
+	return bins
+}
+
+// sturges returns the number of bins suggested by Sturges' rule.
+func sturges(n int) int {
+	return int(math.Ceil(math.Log2(float64(n)))) + 1
+}
+
+// serveDistributions returns a histogram of the runs of the benchmark named
+// in the querystring, of the response given by yvar.  The number of bins
+// defaults to Sturges' rule.
+func serveDistributions(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		name := r.Form.Get("benchmark")
+		if name == "" {
+			http.Error(w, "missing benchmark", http.StatusBadRequest)
+			return
+		}
+		yVar := r.Form.Get("yvar")
+		if yVar == "" {
+			yVar = "NsPerOp"
+		}
+		if _, ok := validYs[yVar]; !ok {
+			http.Error(w, "invalid yvar: "+yVar, http.StatusBadRequest)
+			return
+		}
+		pkg := r.Form.Get("pkg")
+
+		var benchSet []benchmarkResponse
+		for _, f := range src.dataSet().Files {
+			for _, b := range f.Benchmarks {
+				if b.Name == name && (pkg == "" || b.Package == pkg) {
+					benchSet = append(benchSet, benchmarkResponse{Benchmark: b.Benchmark, perfCounters: b.perfCounters})
+				}
+			}
+		}
+		if len(benchSet) == 0 {
+			http.Error(w, "no runs of "+name, http.StatusNotFound)
+			return
+		}
+
+		nBins := sturges(len(benchSet))
+		if v := r.Form.Get("bins"); v != "" {
+			var err error
+			if nBins, err = strconv.Atoi(v); err != nil || nBins < 1 || nBins > maxBins {
+				http.Error(w, "invalid bins: "+v, http.StatusBadRequest)
+				return
+			}
+		}
+
+		d := distribution{
+			Benchmark: name,
+			YVar:      yVar,
+			Values:    sampleGroup(benchSet, nil, yVar).y,
+		}
+		sort.Float64s(d.Values)
+		d.Bins = histogram(d.Values, nBins)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(d)
+	}
+}
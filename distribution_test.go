@@ -0,0 +1,24 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	got := histogram([]float64{1, 1.5, 2, 9, 10}, 3)
+	want := []bin{{1, 4, 3}, {4, 7, 0}, {7, 10, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := histogram([]float64{5, 5}, 3); !reflect.DeepEqual(got, []bin{{5, 5, 2}}) {
+		t.Errorf("got %v for identical values", got)
+	}
+	if got := sturges(100); got != 8 {
+		t.Errorf("got %d bins for 100 values, want 8", got)
+	}
+}
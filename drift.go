@@ -0,0 +1,124 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+)
+
+// driftLevel is the significance level below which a group is said to drift.
+const driftLevel = 0.05
+
+// drift is the change in a group's response over a long benchmark session,
+// like from thermal throttling, estimated by adding the position of each
+// benchmark in its file to the model as a nuisance term.
+type drift struct {
+	Group       string
+	PerRun      float64 // change in the response from one benchmark to the next in a file
+	PerRunInt   float64 // half width of its 95% confidence interval
+	Total       float64 // change over the span of the group's benchmarks
+	P           float64 // probability of a drift at least as large if there is none
+	Significant bool    // P is less than driftLevel
+
+	// Confounded is set if the position is nearly collinear with the terms
+	// of the model, like when every N is run once in increasing order, so
+	// that the drift can't be told apart from the effect of N.
+	Confounded bool
+}
+
+// fitDrift fits the model of a group again with the position of each of its
+// benchmarks as another term.  It returns false if there aren't enough
+// benchmarks, or they are all at the same position.
+func fitDrift(gf groupFit, yVar string) (drift, bool) {
+	s := sampleGroup(gf.benchSet, gf.xTransform, yVar)
+	p := len(gf.xTransform)
+	n := len(s.y)
+	if n <= p+1 {
+		return drift{}, false
+	}
+	first, last := gf.benchSet[0].Ord, gf.benchSet[0].Ord
+	var aug samp
+	aug.y = s.y
+	for i, b := range gf.benchSet {
+		aug.x = append(aug.x, s.x[i*p:(i+1)*p]...)
+		aug.x = append(aug.x, float64(b.Ord))
+		if b.Ord < first {
+			first = b.Ord
+		}
+		if b.Ord > last {
+			last = b.Ord
+		}
+	}
+	if first == last {
+		return drift{}, false
+	}
+
+	beta, st, err := gf.fitter.Fit(aug)
+	if err != nil {
+		return drift{}, false
+	}
+	dof := n - p - 1
+	if c := benchClusters(gf.benchSet); c != nil {
+		if cdof, ok := st.cluster(aug, beta, c); ok {
+			dof = cdof
+		}
+	}
+	d := drift{
+		Group:      gf.Group,
+		PerRun:     beta[p],
+		PerRunInt:  st.cint[p],
+		Total:      beta[p] * float64(last-first),
+		P:          1,
+		Confounded: vif(aug, p) > maxVIF,
+	}
+	if se := math.Sqrt(st.mse * st.iXTX.At(p, p)); se > 0 {
+		t := beta[p] / se
+		d.P = fSurvival(t*t, 1, dof)
+	} else if beta[p] != 0 {
+		d.P = 0
+	}
+	d.Significant = d.P < driftLevel
+	return d, true
+}
+
+// allDrifts estimates the drift of every group which can be fit, in order of
+// group name.
+func (q analysisQuery) allDrifts(ds dataSet) []drift {
+	drifts := []drift{}
+	for _, gf := range q.fitGroups(ds) {
+		if d, ok := fitDrift(gf, q.yVar); ok {
+			drifts = append(drifts, d)
+		}
+	}
+	sort.Sort(byDriftGroup(drifts))
+	return drifts
+}
+
+type byDriftGroup []drift
+
+func (a byDriftGroup) Len() int           { return len(a) }
+func (a byDriftGroup) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byDriftGroup) Less(i, j int) bool { return a[i].Group < a[j].Group }
+
+// serveDrifts estimates the drift of every group, with the grouping and model
+// in the querystring.
+func serveDrifts(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q, err := parseAnalysisQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(q.allDrifts(src.dataSet()))
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+func TestFitDrift(t *testing.T) {
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"N, 1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// three rounds of the same benchmarks, like go test -count=3, which
+	// slow down by slope every benchmark.
+	session := func(slope float64) []benchmarkResponse {
+		noise := []float64{0.3, -0.2, 0.1, -0.4, 0.2}
+		var benchSet []benchmarkResponse
+		for round := 0; round < 3; round++ {
+			for i, x := range []float64{10, 20, 30, 40, 50} {
+				ord := 5*round + i
+				benchSet = append(benchSet, benchmarkResponse{
+					Benchmark: parse.Benchmark{NsPerOp: 3*x + 5 + slope*float64(ord) + noise[(i+round)%5], Ord: ord},
+					X:         x,
+				})
+			}
+		}
+		return benchSet
+	}
+
+	for _, test := range []struct {
+		slope       float64
+		significant bool
+	}{
+		{2, true},
+		{0, false},
+	} {
+		gf, ok := fitGroup(context.Background(), "sort", session(test.slope), q.xTransform, q.yVar, q.fitter)
+		if !ok {
+			t.Fatal("unable to fit")
+		}
+		d, ok := fitDrift(gf, q.yVar)
+		if !ok {
+			t.Fatal("unable to fit the drift")
+		}
+		if d.Significant != test.significant || d.Confounded {
+			t.Errorf("slope %g: got drift %+v, want significant %v", test.slope, d, test.significant)
+		}
+		if math.Abs(d.PerRun-test.slope) > 0.1 || math.Abs(d.Total-14*test.slope) > 1.5 {
+			t.Errorf("slope %g: got %g per run and %g in total", test.slope, d.PerRun, d.Total)
+		}
+	}
+}
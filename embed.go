@@ -0,0 +1,102 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// embedVar is the declaration of whether the plotter is embedded.  Embedded
+// plotters only show the chart, without the controls or analyses.
+const embedVar = "var embed = false"
+
+// embedView returns the settings of an embedded chart from the querystring,
+// which starts from those of the saved view in view, if it is set.  The
+// files to plot are in file, by their path or name, and the others are
+// hidden.  The group to plot is in group, which the plotter reads itself.
+func embedView(v url.Values, ds dataSet, views *viewStore) (view, error) {
+	var e view
+	if name := v.Get("view"); name != "" {
+		var ok bool
+		if e, ok = views.get(name); !ok {
+			return e, fmt.Errorf("no view named %s", name)
+		}
+	}
+	c := &e.Config
+	if nre := v.Get("nre"); nre != "" {
+		c.NRE = nre
+	}
+	if yVar := v.Get("yvar"); yVar != "" {
+		c.YVar = yVar
+	}
+	if v.Get("xtransform") != "" || v.Get("model") != "" {
+		xt, err := modelValue(v, defaultXTransform)
+		if err != nil {
+			return e, err
+		}
+		c.XTransforms = []string{xt}
+	}
+	if fitter := v.Get("fitter"); fitter != "" {
+		if _, err := lookupFitter(fitter); err != nil {
+			return e, err
+		}
+		c.Fitter = fitter
+	}
+	if filter := v.Get("filter"); filter != "" {
+		c.Filter, c.FilterRegexp = filter, v.Get("filterre") == "true"
+	}
+	if files := v["file"]; len(files) > 0 {
+		shown := make(map[string]bool)
+		for _, fn := range files {
+			found := false
+			for _, f := range ds.Files {
+				if f.Path == fn || filepath.Base(f.Path) == fn {
+					shown[f.Path], found = true, true
+				}
+			}
+			if !found {
+				return e, fmt.Errorf("no benchmark file %s", fn)
+			}
+		}
+		c.HiddenFiles = nil
+		for _, f := range ds.Files {
+			if !shown[f.Path] {
+				c.HiddenFiles = append(c.HiddenFiles, f.Path)
+			}
+		}
+	}
+	return e, c.validate()
+}
+
+// serveEmbed serves the plotter with only its chart, so that it can be
+// embedded in other pages, like a wiki, in an iframe.  It is configured by
+// the querystring, which is read by embedView.
+func serveEmbed(src dataSource, views *viewStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		e, err := embedView(r.Form, src.dataSet(), views)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		b, err := json.Marshal(e)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		page := strings.Replace(plotHTML, viewVar, "var view = "+string(b), 1)
+		page = strings.Replace(page, embedVar, "var embed = true", 1)
+		io.CopyBuffer(w, strings.NewReader(page), nil)
+	}
+}
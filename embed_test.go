@@ -0,0 +1,68 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestServeEmbed(t *testing.T) {
+	ds := dataSet{Files: []benchFile{{Path: "results/old.txt"}, {Path: "results/new.txt"}}}
+	views, err := openViews("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := views.put(view{Name: "allocs", Config: viewConfig{YVar: "AllocsPerOp", NRE: `^(.*?)(\d+)$`}}); err != nil {
+		t.Fatal(err)
+	}
+	h := serveEmbed(fixedSource(ds), views)
+
+	tests := []struct {
+		query string
+		code  int
+		want  viewConfig
+	}{
+		{"", http.StatusOK, viewConfig{}},
+		{"file=new.txt&yvar=AllocedBytesPerOp&model=linear", http.StatusOK, viewConfig{
+			YVar:        "AllocedBytesPerOp",
+			XTransforms: []string{expandPreset("linear")},
+			HiddenFiles: []string{"results/old.txt"},
+		}},
+		{"view=allocs&filter=sort", http.StatusOK, viewConfig{YVar: "AllocsPerOp", NRE: `^(.*?)(\d+)$`, Filter: "sort"}},
+		{"file=results/old.txt&file=results/new.txt", http.StatusOK, viewConfig{}},
+		{"file=missing.txt", http.StatusBadRequest, viewConfig{}},
+		{"yvar=Furlongs", http.StatusBadRequest, viewConfig{}},
+		{"model=wobbly", http.StatusBadRequest, viewConfig{}},
+		{"fitter=guess", http.StatusBadRequest, viewConfig{}},
+		{"filter=(&filterre=true", http.StatusBadRequest, viewConfig{}},
+		{"view=missing", http.StatusBadRequest, viewConfig{}},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest("GET", "/embed?"+test.query, nil))
+		if w.Code != test.code {
+			t.Errorf("%s: got status %d, want %d", test.query, w.Code, test.code)
+			continue
+		}
+		if test.code != http.StatusOK {
+			continue
+		}
+		page := w.Body.String()
+		if strings.Contains(page, embedVar) || strings.Contains(page, viewVar) {
+			t.Errorf("%s: the plotter wasn't embedded with its settings", test.query)
+		}
+		v, err := embedView(httptest.NewRequest("GET", "/embed?"+test.query, nil).URL.Query(), ds, views)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(v.Config, test.want) {
+			t.Errorf("%s: got settings %+v, want %+v", test.query, v.Config, test.want)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// equations returns the fitted model as a plain text equation and as LaTeX,
+// like "NsPerOp = 14.2 * log(N) * N - 830".
+func equations(yVar string, terms []string, beta []float64) (plain, latex string) {
+	plain = yVar + " ="
+	latex = `\mathrm{` + yVar + `} =`
+	for i, term := range terms {
+		b, sign := beta[i], " "
+		switch {
+		case b < 0 && i == 0:
+			b, sign = -b, " -"
+		case b < 0:
+			b, sign = -b, " - "
+		case i > 0:
+			sign = " + "
+		}
+		plain += sign + strconv.FormatFloat(b, 'g', 4, 64)
+		latex += sign + latexNumber(b)
+
+		e, err := parser.ParseExpr(term)
+		if err != nil {
+			// parsefloat expressions are go expressions, so this shouldn't
+			// happen, but the term can still be shown as it is.
+			plain += " * (" + term + ")"
+			latex += ` \cdot \left(\mathtt{` + term + `}\right)`
+			continue
+		}
+		if isOne(e) {
+			continue
+		}
+		if isSum(e) {
+			plain += " * (" + plainExpr(e) + ")"
+			latex += ` \left(` + latexExpr(e) + `\right)`
+			continue
+		}
+		plain += " * " + plainExpr(e)
+		latex += " " + latexExpr(e)
+	}
+	return plain, latex
+}
+
+// latexNumber formats a coefficient in scientific notation, if it is needed.
+func latexNumber(v float64) string {
+	s := strconv.FormatFloat(v, 'g', 4, 64)
+	i := strings.IndexByte(s, 'e')
+	if i < 0 {
+		return s
+	}
+	exp, _ := strconv.Atoi(s[i+1:])
+	return s[:i] + ` \times 10^{` + strconv.Itoa(exp) + `}`
+}
+
+// isOne returns true if the term is the constant 1, which is the intercept.
+func isOne(e ast.Expr) bool {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok {
+		return false
+	}
+	v, err := strconv.ParseFloat(lit.Value, 64)
+	return err == nil && v == 1
+}
+
+// isSum returns true if the term needs parentheses when multiplied.
+func isSum(e ast.Expr) bool {
+	b, ok := e.(*ast.BinaryExpr)
+	return ok && (b.Op == token.ADD || b.Op == token.SUB)
+}
+
+// mathFunc returns the name of the function in package math that is called by
+// e, if there is one.
+func mathFunc(e *ast.CallExpr) (string, bool) {
+	sel, ok := e.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok || pkg.Name != "math" {
+		return "", false
+	}
+	return sel.Sel.Name, true
+}
+
+// plainExpr renders a term for people to read.
+func plainExpr(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		return e.Value
+	case *ast.Ident:
+		return e.Name
+	case *ast.ParenExpr:
+		return "(" + plainExpr(e.X) + ")"
+	case *ast.UnaryExpr:
+		return e.Op.String() + plainExpr(e.X)
+	case *ast.BinaryExpr:
+		return plainExpr(e.X) + " " + e.Op.String() + " " + plainExpr(e.Y)
+	case *ast.CallExpr:
+		var args []string
+		for _, arg := range e.Args {
+			args = append(args, plainExpr(arg))
+		}
+		name, ok := mathFunc(e)
+		switch {
+		case !ok:
+			return exprString(e.Fun) + "(" + strings.Join(args, ", ") + ")"
+		case name == "Pow" && len(args) == 2:
+			return plainOperand(e.Args[0]) + "^" + plainOperand(e.Args[1])
+		case name == "Abs" && len(args) == 1:
+			return "|" + args[0] + "|"
+		}
+		return strings.ToLower(name) + "(" + strings.Join(args, ", ") + ")"
+	}
+	return exprString(e)
+}
+
+// plainOperand renders an operand of ^, which needs parentheses unless it is
+// a single number or variable.
+func plainOperand(e ast.Expr) string {
+	switch e.(type) {
+	case *ast.BasicLit, *ast.Ident, *ast.ParenExpr:
+		return plainExpr(e)
+	}
+	return "(" + plainExpr(e) + ")"
+}
+
+// latexExpr renders a term as LaTeX.
+func latexExpr(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		return e.Value
+	case *ast.Ident:
+		return e.Name
+	case *ast.ParenExpr:
+		return `\left(` + latexExpr(e.X) + `\right)`
+	case *ast.UnaryExpr:
+		return e.Op.String() + latexExpr(e.X)
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.MUL:
+			return latexExpr(e.X) + ` \cdot ` + latexExpr(e.Y)
+		case token.QUO:
+			return `\frac{` + latexExpr(unparen(e.X)) + `}{` + latexExpr(unparen(e.Y)) + `}`
+		}
+		return latexExpr(e.X) + " " + e.Op.String() + " " + latexExpr(e.Y)
+	case *ast.CallExpr:
+		var args []string
+		for _, arg := range e.Args {
+			args = append(args, latexExpr(unparen(arg)))
+		}
+		name, ok := mathFunc(e)
+		if !ok {
+			return `\mathtt{` + exprString(e.Fun) + `}\left(` + strings.Join(args, ", ") + `\right)`
+		}
+		if len(args) == 1 {
+			switch name {
+			case "Log":
+				return `\log\left(` + args[0] + `\right)`
+			case "Log2":
+				return `\log_2\left(` + args[0] + `\right)`
+			case "Log10":
+				return `\log_{10}\left(` + args[0] + `\right)`
+			case "Sqrt":
+				return `\sqrt{` + args[0] + `}`
+			case "Cbrt":
+				return `\sqrt[3]{` + args[0] + `}`
+			case "Exp":
+				return `e^{` + args[0] + `}`
+			case "Abs":
+				return `\left|` + args[0] + `\right|`
+			}
+		}
+		if name == "Pow" && len(args) == 2 {
+			base := args[0]
+			if _, simple := unparen(e.Args[0]).(*ast.Ident); !simple {
+				base = `\left(` + base + `\right)`
+			}
+			return `{` + base + `}^{` + args[1] + `}`
+		}
+		return `\operatorname{` + strings.ToLower(name) + `}\left(` + strings.Join(args, ", ") + `\right)`
+	}
+	return exprString(e)
+}
+
+// unparen removes redundant parentheses, for places where LaTeX groups its
+// arguments anyway.
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
+
+// exprString renders anything else as go source.
+func exprString(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	}
+	return "?"
+}
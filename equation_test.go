@@ -0,0 +1,42 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+func TestEquations(t *testing.T) {
+	for _, test := range []struct {
+		terms        []string
+		beta         []float64
+		plain, latex string
+	}{
+		{
+			[]string{"math.Log(N) * N", "1.0"},
+			[]float64{14.2, -830},
+			"NsPerOp = 14.2 * log(N) * N - 830",
+			`\mathrm{NsPerOp} = 14.2 \log\left(N\right) \cdot N - 830`,
+		},
+		{
+			[]string{"math.Pow(N, 2)", "N / math.Log2(N)", "N + 1"},
+			[]float64{-2.5e-7, 3, 1e6},
+			"NsPerOp = -2.5e-07 * N^2 + 3 * N / log2(N) + 1e+06 * (N + 1)",
+			`\mathrm{NsPerOp} = -2.5 \times 10^{-7} {N}^{2} + 3 \frac{N}{\log_2\left(N\right)} + 1 \times 10^{6} \left(N + 1\right)`,
+		},
+		{
+			[]string{"math.Sqrt(N)"},
+			[]float64{7},
+			"NsPerOp = 7 * sqrt(N)",
+			`\mathrm{NsPerOp} = 7 \sqrt{N}`,
+		},
+	} {
+		plain, latex := equations("NsPerOp", test.terms, test.beta)
+		if plain != test.plain {
+			t.Errorf("got plain equation %q, want %q", plain, test.plain)
+		}
+		if latex != test.latex {
+			t.Errorf("got LaTeX %q, want %q", latex, test.latex)
+		}
+	}
+}
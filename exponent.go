@@ -0,0 +1,89 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+)
+
+// exponent is the empirical power law of a group, y = Constant * N^Exponent,
+// from fitting log(y) = log(Constant) + Exponent * log(N).  It answers "this
+// behaves like N^1.08" without choosing the terms of a model.
+type exponent struct {
+	Group       string
+	Exponent    float64
+	ExponentInt float64 // half width of the 95% confidence interval
+	Constant    float64
+}
+
+// powerLaw fits the power law of the benchmarks with the fitter.  Benchmarks
+// with a response or N that isn't positive are left out, since they have no
+// logarithm.  It returns false if there aren't enough benchmarks left to fit.
+func powerLaw(benchSet []benchmarkResponse, yVar string, fitter estimator) (exponent, bool) {
+	var s samp
+	var xs []benchmarkResponse
+	y := sampleGroup(benchSet, nil, yVar).y
+	for i, b := range benchSet {
+		if b.X > 0 && y[i] > 0 {
+			s.x = append(s.x, math.Log(b.X), 1)
+			s.y = append(s.y, math.Log(y[i]))
+			xs = append(xs, b)
+		}
+	}
+	if !canFit(xs, 2) {
+		return exponent{}, false
+	}
+	beta, st, err := fitter.Fit(s)
+	if err != nil {
+		return exponent{}, false
+	}
+	return exponent{
+		Exponent:    beta[0],
+		ExponentInt: st.cint[0],
+		Constant:    math.Exp(beta[1]),
+	}, true
+}
+
+// allExponents fits the power law of every group which can be fit, in order
+// of group name.
+func (q analysisQuery) allExponents(ds dataSet) []exponent {
+	groups, _, _, _ := groupBenchmarks(ds, q.grouping)
+	exps := []exponent{}
+	for group, benchSet := range groups {
+		if e, ok := powerLaw(benchSet, q.yVar, q.fitter); ok {
+			e.Group = group
+			exps = append(exps, e)
+		}
+	}
+	sort.Sort(byExponentGroup(exps))
+	return exps
+}
+
+type byExponentGroup []exponent
+
+func (a byExponentGroup) Len() int           { return len(a) }
+func (a byExponentGroup) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byExponentGroup) Less(i, j int) bool { return a[i].Group < a[j].Group }
+
+// serveExponents fits the power law of every group, with the grouping and
+// fitter in the querystring.  The model is ignored.
+func serveExponents(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q, err := parseAnalysisQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(q.allExponents(src.dataSet()))
+	}
+}
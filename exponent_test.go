@@ -0,0 +1,31 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+func TestPowerLaw(t *testing.T) {
+	// y = 3 N^1.5, with a benchmark at N = 0 which is left out
+	benchSet := []benchmarkResponse{{Benchmark: parse.Benchmark{NsPerOp: 7}, X: 0}}
+	for n := 1.0; n <= 1e6; n *= 10 {
+		benchSet = append(benchSet, benchmarkResponse{Benchmark: parse.Benchmark{NsPerOp: 3 * math.Pow(n, 1.5)}, X: n})
+	}
+	e, ok := powerLaw(benchSet, "NsPerOp", olsFitter{})
+	if !ok {
+		t.Fatal("unable to fit")
+	}
+	if math.Abs(e.Exponent-1.5) > 1e-9 || math.Abs(e.Constant-3) > 1e-6 {
+		t.Errorf("got %g N^%g, want 3 N^1.5", e.Constant, e.Exponent)
+	}
+
+	if _, ok := powerLaw(benchSet[:2], "NsPerOp", olsFitter{}); ok {
+		t.Error("fit a power law to a single benchmark")
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// costFuncs writes a Go function for each fit, which evaluates the fitted
+// response at N.  If pkg is not empty, the functions are written as a
+// complete source file in that package.
+func costFuncs(fits []groupFit, yVar, pkg string) ([]byte, error) {
+	var buf bytes.Buffer
+	usesMath := false
+	names := make(map[string]bool)
+	for _, gf := range fits {
+		name := costFuncName(gf.Group)
+		for i := 2; names[name]; i++ {
+			name = costFuncName(gf.Group) + strconv.Itoa(i)
+		}
+		names[name] = true
+
+		expr := ""
+		for i, x := range gf.xTransform {
+			term := x.String()
+			if strings.Contains(term, "math.") {
+				usesMath = true
+			}
+			coef := strconv.FormatFloat(math.Abs(gf.beta[i]), 'g', 6, 64)
+			if _, err := strconv.ParseFloat(term, 64); err != nil {
+				coef += " * (" + term + ")"
+			} else if term != "1" && term != "1.0" {
+				coef += " * " + term
+			}
+			switch {
+			case i == 0 && gf.beta[i] < 0:
+				expr = "-" + coef
+			case i == 0:
+				expr = coef
+			case gf.beta[i] < 0:
+				expr += " - " + coef
+			default:
+				expr += " + " + coef
+			}
+		}
+		if expr == "" {
+			expr = "0"
+		}
+
+		fmt.Fprintf(&buf, "// %s is the fitted %s of %s, from benchmarks with N from %g to %g.\n",
+			name, yVar, gf.Group, gf.xMin, gf.xMax)
+		fmt.Fprintf(&buf, "func %s(N float64) float64 {\n\treturn %s\n}\n\n", name, expr)
+	}
+
+	if pkg != "" {
+		if !isIdentifier(pkg) {
+			return nil, fmt.Errorf("invalid package name: %s", pkg)
+		}
+		header := "// Code generated by benchplot. DO NOT EDIT.\n\npackage " + pkg + "\n\n"
+		if usesMath {
+			header += "import \"math\"\n\n"
+		}
+		return format.Source(append([]byte(header), buf.Bytes()...))
+	}
+	return format.Source(buf.Bytes())
+}
+
+// isIdentifier returns true if s can be used as a Go identifier.
+func isIdentifier(s string) bool {
+	if s == "" || token.Lookup(s).IsKeyword() {
+		return false
+	}
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// costFuncName turns a group into an exported Go identifier, like SortCost for
+// BenchmarkSort.
+func costFuncName(group string) string {
+	var name []rune
+	upper := true
+	for _, r := range strings.Replace(group, "Benchmark", "", -1) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name = append(name, r)
+	}
+	if len(name) == 0 || !unicode.IsLetter(name[0]) {
+		name = append([]rune("Bench"), name...)
+	}
+	return string(name) + "Cost"
+}
+
+// serveCostFuncs returns a handler which fits every group with the model in
+// the querystring, and writes the fits as Go functions.
+func serveCostFuncs(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q, err := parseAnalysisQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fits, err := q.fitGroupsContext(r.Context(), src.dataSet())
+		if err != nil {
+			fitError(w, err)
+			return
+		}
+		b, err := costFuncs(fits, q.yVar, r.Form.Get("package"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(b)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCostFuncs(t *testing.T) {
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"math.Log(N) * N, 1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gf := groupFit{
+		Group:      "BenchmarkSort",
+		xTransform: q.xTransform,
+		beta:       model{14.2, -830},
+		xMin:       10,
+		xMax:       1e6,
+	}
+	b, err := costFuncs([]groupFit{gf, gf}, "NsPerOp", "cost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(b)
+	for _, want := range []string{
+		"package cost\n",
+		"import \"math\"\n",
+		"func SortCost(N float64) float64 {\n\treturn 14.2*(math.Log(N)*N) - 830\n}",
+		"func SortCost2(N float64) float64 {",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("cost functions do not contain %q:\n%s", want, src)
+		}
+	}
+	if _, err := costFuncs([]groupFit{gf}, "NsPerOp", "func"); err == nil {
+		t.Error("costFuncs accepted a keyword as the package name")
+	}
+}
+
+func TestCostFuncName(t *testing.T) {
+	for group, want := range map[string]string{
+		"BenchmarkSort":                    "SortCost",
+		"run 1: BenchmarkStableSort":       "Run1StableSortCost",
+		"github.com/a/b.BenchmarkSort_int": "GithubComABSortIntCost",
+		"Benchmark2D":                      "Bench2DCost",
+	} {
+		if got := costFuncName(group); got != want {
+			t.Errorf("costFuncName(%q) = %q, want %q", group, got, want)
+		}
+	}
+}
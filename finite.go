@@ -0,0 +1,55 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/jonlawlor/parsefloat"
+)
+
+// finiteTerms splits the benchmarks into those where every term of the model
+// is finite, which can be fit, and the N of those where one isn't, like
+// math.Exp(N) at large N, which would otherwise make the whole fit NaN.
+func finiteTerms(benchSet []benchmarkResponse, xTransform []parsefloat.Expression) (finite []benchmarkResponse, nonFinite []float64) {
+	vars := map[string]float64{"N": 0}
+	for i, b := range benchSet {
+		vars["N"] = b.X
+		ok := true
+		for _, x := range xTransform {
+			if v := x.Eval(vars); math.IsNaN(v) || math.IsInf(v, 0) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			if nonFinite != nil {
+				finite = append(finite, b)
+			}
+			continue
+		}
+		if nonFinite == nil {
+			// the benchmarks are only copied if some are left out
+			finite = append([]benchmarkResponse(nil), benchSet[:i]...)
+		}
+		nonFinite = append(nonFinite, b.X)
+	}
+	if nonFinite == nil {
+		return benchSet, nil
+	}
+	return finite, nonFinite
+}
+
+// finiteRow returns true if every value in row i of m is finite.
+func finiteRow(m *mat64.Dense, i int) bool {
+	_, c := m.Dims()
+	for j := 0; j < c; j++ {
+		if v := m.At(i, j); math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+func TestFiniteTerms(t *testing.T) {
+	var benchSet []benchmarkResponse
+	for _, n := range []float64{1, 10, 100, 1000, 10000} {
+		benchSet = append(benchSet, benchmarkResponse{Benchmark: parse.Benchmark{NsPerOp: n}, X: n})
+	}
+	xTransform, err := parseTransform("math.Exp(N), 1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	finite, nonFinite := finiteTerms(benchSet, xTransform)
+	if len(finite) != 3 {
+		t.Errorf("got %d finite benchmarks, want 3", len(finite))
+	}
+	if want := []float64{1000, 10000}; !reflect.DeepEqual(nonFinite, want) {
+		t.Errorf("got non finite N %v, want %v", nonFinite, want)
+	}
+
+	// the fit leaves them out, along with the line where it overflows
+	res, err := fit(context.Background(), benchSet, fitRequest{xTransform: xTransform, yVar: "NsPerOp", fitter: fitters[defaultFitter], xlb: 1, xub: 10000, nLineSteps: 11})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.NonFinite, nonFinite) {
+		t.Errorf("got NonFinite %v, want %v", res.NonFinite, nonFinite)
+	}
+	if len(res.ResultLine) != 1 {
+		t.Errorf("got %d points on the line, want 1", len(res.ResultLine))
+	}
+
+	xTransform, err = parseTransform("N, 1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finite, nonFinite := finiteTerms(benchSet, xTransform); len(finite) != len(benchSet) || nonFinite != nil {
+		t.Errorf("got %d finite benchmarks and %v, want all of them", len(finite), nonFinite)
+	}
+}
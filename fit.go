@@ -5,11 +5,11 @@
 package main
 
 import (
-	"log"
 	"math"
 
 	"github.com/gonum/blas"
 	"github.com/gonum/blas/blas64"
+	"github.com/gonum/lapack"
 	"github.com/gonum/lapack/lapack64"
 	"github.com/gonum/matrix/mat64"
 	"github.com/jonlawlor/parsefloat"
@@ -43,8 +43,24 @@ func sampleGroup(benchSet []benchmarkResponse, xExprs []parsefloat.Expression, y
 		for _, b := range benchSet {
 			y = append(y, b.MBPerS)
 		}
+	case "OpsPerS":
+		// each run is converted before fitting, so that the errors of
+		// the fit are those of the throughput, rather than of its inverse.
+		for _, b := range benchSet {
+			y = append(y, 1e9/b.NsPerOp)
+		}
+	case "InstructionsPerOp", "CacheMissesPerOp", "BranchMissesPerOp":
+		for _, b := range benchSet {
+			c, _ := b.count(yVar)
+			y = append(y, c)
+		}
 	default:
-		log.Fatal("unknown YVar:", yVar)
+		fatal("unknown YVar:", yVar)
+	}
+	for i, b := range benchSet {
+		if b.divisor != 0 {
+			y[i] /= b.divisor
+		}
 	}
 
 	// construct the explanatory variable
@@ -61,11 +77,20 @@ func sampleGroup(benchSet []benchmarkResponse, xExprs []parsefloat.Expression, y
 	return samp{x, y}
 }
 
+// epsilon is the machine epsilon of float64.
+const epsilon = 1.0 / (1 << 52)
+
 // model contains the model parameters
 type model []float64
 
-// estimate parameters via least squares.  Returns nil if it could not converge.
+// estimate parameters via least squares.  If X is rank deficient, the minimum
+// norm estimate is used instead.  Returns nil if it could not converge.
 func estimate(s samp) model {
+	d, ok := decompose(s)
+	if ok && d.rank < len(d.scale) {
+		return d.solve(s.y)
+	}
+
 	y := blas64.General{
 		Rows:   len(s.y),
 		Cols:   1,
@@ -87,7 +112,7 @@ func estimate(s samp) model {
 	lapack64.Gels(blas.NoTrans, x, y, work, -1)
 
 	work = make([]float64, int(work[0]))
-	ok := lapack64.Gels(blas.NoTrans, x, y, work, len(work))
+	ok = lapack64.Gels(blas.NoTrans, x, y, work, len(work))
 
 	if !ok {
 		return nil
@@ -95,28 +120,161 @@ func estimate(s samp) model {
 	return y.Data[:x.Cols]
 }
 
+// decomposition is the thin singular value decomposition of X, after its
+// columns have been scaled to unit length, so that the rank doesn't depend on
+// the units of the terms.  Z = X diag(1/scale) = U diag(sv) V'.
+type decomposition struct {
+	u, vt blas64.General
+	sv    []float64 // in decreasing order
+	scale []float64 // length of each column of X
+	rank  int       // number of singular values which aren't negligible
+}
+
+// decompose finds the decomposition of X.  It returns false if the SVD does
+// not converge.
+func decompose(s samp) (decomposition, bool) {
+	n := len(s.y)
+	p := len(s.x) / n
+	k, most := n, p
+	if p < n {
+		k, most = p, n
+	}
+	d := decomposition{
+		u:     blas64.General{Rows: n, Cols: k, Stride: k, Data: make([]float64, n*k)},
+		vt:    blas64.General{Rows: k, Cols: p, Stride: p, Data: make([]float64, k*p)},
+		sv:    make([]float64, k),
+		scale: make([]float64, p),
+	}
+	z := blas64.General{Rows: n, Cols: p, Stride: p, Data: make([]float64, len(s.x))}
+	copy(z.Data, s.x)
+	for j := range d.scale {
+		for i := 0; i < n; i++ {
+			d.scale[j] += z.Data[i*p+j] * z.Data[i*p+j]
+		}
+		d.scale[j] = math.Sqrt(d.scale[j])
+		if d.scale[j] == 0 {
+			d.scale[j] = 1
+		}
+		for i := 0; i < n; i++ {
+			z.Data[i*p+j] /= d.scale[j]
+		}
+	}
+
+	work := make([]float64, 1)
+	lapack64.Gesvd(lapack.SVDStore, lapack.SVDStore, z, d.u, d.vt, d.sv, work, -1)
+	work = make([]float64, int(work[0]))
+	if !lapack64.Gesvd(lapack.SVDStore, lapack.SVDStore, z, d.u, d.vt, d.sv, work, len(work)) {
+		return decomposition{}, false
+	}
+
+	// singular values are negligible below the same tolerance that numpy
+	// and matlab use for the rank of a matrix.
+	tol := float64(most) * epsilon * d.sv[0]
+	for _, v := range d.sv {
+		if v > tol {
+			d.rank++
+		}
+	}
+	return d, true
+}
+
+// solve returns the least squares estimate of y whose scaled coefficients have
+// the minimum norm, so that it doesn't depend on the units of the terms.
+func (d decomposition) solve(y []float64) model {
+	// beta = diag(1/scale) V diag(1/sv) U' y, using only the first rank
+	// singular values.
+	c := make([]float64, d.rank)
+	for k := range c {
+		for i, yi := range y {
+			c[k] += d.u.Data[i*d.u.Stride+k] * yi
+		}
+		c[k] /= d.sv[k]
+	}
+	beta := make(model, len(d.scale))
+	for j := range beta {
+		for k, ck := range c {
+			beta[j] += d.vt.Data[k*d.vt.Stride+j] * ck
+		}
+		beta[j] /= d.scale[j]
+	}
+	return beta
+}
+
+// iXTX returns the (pseudo) inverse of X'X.  It is the inverse if X has full
+// rank.
+func (d decomposition) iXTX() *mat64.Dense {
+	p := len(d.scale)
+	data := make([]float64, p*p)
+	for i := 0; i < p; i++ {
+		for j := 0; j < p; j++ {
+			v := 0.0
+			for k := 0; k < d.rank; k++ {
+				v += d.vt.Data[k*d.vt.Stride+i] * d.vt.Data[k*d.vt.Stride+j] / (d.sv[k] * d.sv[k])
+			}
+			data[i*p+j] = v / (d.scale[i] * d.scale[j])
+		}
+	}
+	return mat64.NewDense(p, p, data)
+}
+
+// kahan is a compensated sum, which keeps the precision of small terms after
+// the sum has become large.  Benchmarks can take anywhere from 1e3 to 1e10
+// ns/op, and their squares span twice as many orders of magnitude, so naive
+// sums of them lose most of their precision.
+type kahan struct {
+	sum, c float64
+}
+
+// add adds v to the sum, with Neumaier's variant of Kahan summation, which
+// also works when v is larger than the sum.
+func (k *kahan) add(v float64) {
+	t := k.sum + v
+	if math.Abs(k.sum) >= math.Abs(v) {
+		k.c += (k.sum - t) + v
+	} else {
+		k.c += (v - t) + k.sum
+	}
+	k.sum = t
+}
+
+func (k kahan) value() float64 {
+	return k.sum + k.c
+}
+
+// dot returns the compensated dot product of a and b.
+func dot(a, b []float64) float64 {
+	var k kahan
+	for i, v := range a {
+		k.add(v * b[i])
+	}
+	return k.value()
+}
+
 // calculate R squared
 func stats(m model, s samp) (r2, mse float64, cint []float64, iXTX *mat64.Dense) {
-	RSS := 0.0
-	YSS := 0.0
+	var RSS, YSS kahan
 
 	// also consumed degrees of freedom
 	stride := len(s.x) / len(s.y)
 	for i, y := range s.y {
-		YSS += y * y
-		yHat := 0.0
-		for j, x := range s.x[i*stride : (i+1)*stride] {
-			yHat += m[j] * x
-		}
-		RSS += (yHat - y) * (yHat - y)
+		YSS.add(y * y)
+		r := dot(m, s.x[i*stride:(i+1)*stride]) - y
+		RSS.add(r * r)
 	}
-	r2 = 1.0 - RSS/YSS
+	r2 = 1.0 - RSS.value()/YSS.value()
 
-	mse = RSS / float64(len(s.y)-stride)
-	X := mat64.NewDense(len(s.y), stride, s.x)
-	iXTX = mat64.NewDense(stride, stride, make([]float64, stride*stride))
-	iXTX.Mul(X.T(), X)
-	iXTX.Inverse(iXTX)
+	mse = RSS.value() / float64(len(s.y)-stride)
+
+	// The inverse of X'X is found from the SVD of X, which still works if
+	// X is singular.
+	if d, ok := decompose(s); ok {
+		iXTX = d.iXTX()
+	} else {
+		X := mat64.NewDense(len(s.y), stride, s.x)
+		iXTX = mat64.NewDense(stride, stride, make([]float64, stride*stride))
+		iXTX.Mul(X.T(), X)
+		iXTX.Inverse(iXTX)
+	}
 	cint = make([]float64, stride)
 	for i := 0; i < stride; i++ {
 		cint[i] = conf95(math.Sqrt(iXTX.At(i, i)*mse), len(s.y)-stride)
@@ -125,6 +283,88 @@ func stats(m model, s samp) (r2, mse float64, cint []float64, iXTX *mat64.Dense)
 	return
 }
 
+// modelSummary returns R² adjusted for the p terms of a model fit to n
+// benchmarks, and the F statistic of the fit and its p-value, which test
+// whether all of the coefficients are zero.  Like R², they're uncentered,
+// since models don't need a constant term.  A perfect fit has the largest
+// F, rather than an infinite one, so that it can be encoded as JSON.
+func modelSummary(r2 float64, n, p int) (adjR2, f, pValue float64) {
+	df := n - p
+	if df < 1 || p < 1 {
+		return r2, 0, 1
+	}
+	adjR2 = 1 - (1-r2)*float64(n)/float64(df)
+	f = finite((r2 / float64(p)) / ((1 - r2) / float64(df)))
+	if f < 0 || math.IsNaN(f) {
+		f = 0
+	}
+	return adjR2, f, fSurvival(f, p, df)
+}
+
+// covariance returns the covariance matrix of the coefficients, mse (X'X)^-1.
+// Infinite variances, from terms which are exactly collinear, are limited to
+// the largest float64 so that they can be encoded as JSON.
+func covariance(mse float64, iXTX *mat64.Dense) [][]float64 {
+	p, _ := iXTX.Dims()
+	cov := make([][]float64, p)
+	for j := range cov {
+		cov[j] = make([]float64, p)
+		for k := range cov[j] {
+			cov[j][k] = finite(mse * iXTX.At(j, k))
+			if math.IsInf(cov[j][k], -1) {
+				cov[j][k] = -math.MaxFloat64
+			}
+			if math.IsNaN(cov[j][k]) {
+				cov[j][k] = 0
+			}
+		}
+	}
+	return cov
+}
+
+// correlation scales a covariance matrix to correlations.  Terms with no
+// variance are uncorrelated with every other term.
+func correlation(cov [][]float64) [][]float64 {
+	corr := make([][]float64, len(cov))
+	for j := range cov {
+		corr[j] = make([]float64, len(cov))
+		for k := range cov {
+			if d := math.Sqrt(cov[j][j]) * math.Sqrt(cov[k][k]); d > 0 && !math.IsInf(d, 0) {
+				corr[j][k] = cov[j][k] / d
+			}
+		}
+	}
+	return corr
+}
+
+// standardizedCoefficients returns the coefficients of the model in standard
+// deviations of the response per standard deviation of each term, so that
+// terms with very different scales, like N*N and a constant, can be compared.
+// Constant terms have a standardized coefficient of 0.
+func standardizedCoefficients(m model, s samp) []float64 {
+	std := make([]float64, len(m))
+	n := len(s.y)
+	if n == 0 {
+		return std
+	}
+	var sum, ss kahan
+	for _, y := range s.y {
+		sum.add(y)
+	}
+	mean := sum.value() / float64(n)
+	for _, y := range s.y {
+		ss.add((y - mean) * (y - mean))
+	}
+	sdY := math.Sqrt(ss.value() / float64(n))
+	if sdY == 0 {
+		return std
+	}
+	for j, scale := range standardize(s).scale {
+		std[j] = m[j] * scale / sdY
+	}
+	return std
+}
+
 // evaluate the given expression at the given points, returning values in a
 // matrix.
 func evaluate(xExprs []parsefloat.Expression, points []float64) *mat64.Dense {
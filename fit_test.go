@@ -0,0 +1,180 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jonlawlor/parsefloat"
+)
+
+func TestRankDeficient(t *testing.T) {
+	// N and 2N are the same term once they are scaled, so the minimum norm
+	// fit splits the slope of 3 evenly between them.
+	var s samp
+	for i := 0; i < 10; i++ {
+		x := float64(i + 1)
+		s.x = append(s.x, x, 2*x, 1)
+		s.y = append(s.y, 3*x+5)
+	}
+	beta, st, err := olsFitter{}.Fit(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{1.5, 0.75, 5}
+	for i := range want {
+		if math.Abs(beta[i]-want[i]) > 1e-9 {
+			t.Fatalf("got coefficients %v, want %v", beta, want)
+		}
+	}
+	for i, c := range st.cint {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			t.Errorf("got confidence interval %g for coefficient %d", c, i)
+		}
+	}
+	if c := condition(s); c.rank != 2 || !c.collinear() {
+		t.Errorf("got rank %d, collinear %v, want rank 2 and collinear", c.rank, c.collinear())
+	}
+}
+
+func TestKahan(t *testing.T) {
+	// a naive sum loses all of the small terms
+	var k kahan
+	naive := 0.0
+	for _, v := range []float64{1e20, 1, -1e20, 1, 1} {
+		k.add(v)
+		naive += v
+	}
+	if got := k.value(); got != 3 {
+		t.Errorf("got compensated sum %g, want 3 (the naive sum is %g)", got, naive)
+	}
+
+	// R² is still exact for a perfect fit of responses spanning many orders
+	// of magnitude.
+	var s samp
+	for x := 1e3; x <= 1e10; x *= 10 {
+		s.x = append(s.x, x, 1)
+		s.y = append(s.y, x+1)
+	}
+	if r2, _, _, _ := stats(model{1, 1}, s); r2 != 1 {
+		t.Errorf("got r2 %.17g, want 1", r2)
+	}
+}
+
+func TestStandardizedCoefficients(t *testing.T) {
+	// y = 2*x1 + 0.001*x2 + 5, where x2 is a thousand times larger than x1,
+	// so its tiny coefficient matters half as much as x1's.
+	s := samp{
+		x: []float64{1, 1000, 1, 2, 3000, 1, 3, 2000, 1, 4, 4000, 1},
+		y: []float64{8, 12, 13, 17},
+	}
+	std := standardizedCoefficients(model{2, 0.001, 5}, s)
+	if std[2] != 0 {
+		t.Errorf("got standardized constant %g, want 0", std[2])
+	}
+	if std[0] <= std[1] || std[1] <= 0 {
+		t.Errorf("got standardized coefficients %v, want the first to be the largest", std)
+	}
+	if math.Abs(std[1]/std[0]-0.001*1000/2) > 1e-12 {
+		t.Errorf("got ratio %g of standardized coefficients, want 0.5", std[1]/std[0])
+	}
+}
+
+func TestCovariance(t *testing.T) {
+	benchSet := linearBenchSet(3, 5)
+	xTransform, err := parsefloat.NewSlice("float64{N, 1.0}", modelVars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := fit(context.Background(), benchSet, fitRequest{
+		xlb:        10,
+		xub:        60,
+		xTransform: xTransform,
+		yVar:       "NsPerOp",
+		nLineSteps: 2,
+		fitter:     fitters[defaultFitter],
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Covariance) != 2 || len(res.Correlation) != 2 {
+		t.Fatalf("got covariance %v, want 2 by 2", res.Covariance)
+	}
+	dof := len(benchSet) - 2
+	for j, m := range res.ResultModel {
+		if bint := conf95(math.Sqrt(res.Covariance[j][j]), dof); math.Abs(bint-m.BInt) > 1e-9*m.BInt {
+			t.Errorf("term %d: got interval %g from the covariance, want %g", j, bint, m.BInt)
+		}
+		if math.Abs(res.Correlation[j][j]-1) > 1e-12 {
+			t.Errorf("term %d: got correlation %g with itself", j, res.Correlation[j][j])
+		}
+	}
+	if res.Covariance[0][1] != res.Covariance[1][0] || res.Correlation[0][1] >= 0 {
+		t.Errorf("got correlation %v, want the slope and intercept negatively correlated", res.Correlation)
+	}
+}
+
+func TestModelSummary(t *testing.T) {
+	// with two terms, the survival function of F is (1 + 2F/df)^(-df/2)
+	adjR2, f, p := modelSummary(0.9, 12, 2)
+	if math.Abs(adjR2-0.88) > 1e-12 {
+		t.Errorf("got adjusted R² %g, want 0.88", adjR2)
+	}
+	if math.Abs(f-45) > 1e-9 {
+		t.Errorf("got F %g, want 45", f)
+	}
+	if math.Abs(p-1e-5)/1e-5 > 1e-6 {
+		t.Errorf("got p-value %g, want 1e-5", p)
+	}
+
+	// a perfect fit has the largest F, which can be encoded as JSON
+	if _, f, p := modelSummary(1, 12, 2); f != math.MaxFloat64 || p != 0 {
+		t.Errorf("got F %g with p-value %g for a perfect fit", f, p)
+	}
+
+	// there isn't a test without any residual degrees of freedom
+	if _, f, p := modelSummary(1, 2, 2); f != 0 || p != 1 {
+		t.Errorf("got F %g with p-value %g without any residual degrees of freedom", f, p)
+	}
+}
+
+func TestFitHandleFunc(t *testing.T) {
+	const good = "xlb=10&xub=1000&xtransform=N,1.0&yvar=NsPerOp&nlinesteps=10"
+	const body = `[{"Name": "BenchmarkSort10", "N": 1000, "NsPerOp": 100, "X": 10},
+		{"Name": "BenchmarkSort100", "N": 1000, "NsPerOp": 1000, "X": 100},
+		{"Name": "BenchmarkSort1000", "N": 1000, "NsPerOp": 10500, "X": 1000}]`
+	tests := []struct {
+		query, body string
+		code        int
+	}{
+		{good, body, http.StatusOK},
+		{strings.Replace(good, "xlb=10", "xlb=x", 1), body, http.StatusBadRequest},
+		{strings.Replace(good, "xub=1000", "xub=", 1), body, http.StatusBadRequest},
+		{strings.Replace(good, "N,1.0", "N%2B", 1), body, http.StatusBadRequest},
+		{strings.Replace(good, "N,1.0", "os.Exit(1)", 1), body, http.StatusBadRequest},
+		{strings.Replace(good, "N,1.0", strings.Repeat("(", 40)+"N"+strings.Repeat(")", 40), 1), body, http.StatusBadRequest},
+		{strings.Replace(good, "NsPerOp", "Furlongs", 1), body, http.StatusBadRequest},
+		{strings.Replace(good, "nlinesteps=10", "nlinesteps=0", 1), body, http.StatusBadRequest},
+		{strings.Replace(good, "nlinesteps=10", "nlinesteps=1000000000", 1), body, http.StatusBadRequest},
+		{good, "[" + strings.Repeat(" ", maxFitBytes) + "]", http.StatusBadRequest},
+		{good + "&fitter=guess", body, http.StatusBadRequest},
+		{good, "[{", http.StatusBadRequest},
+		{"%zz", body, http.StatusBadRequest},
+		// the server keeps answering after the bad requests
+		{good, body, http.StatusOK},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		fitHandleFunc(w, httptest.NewRequest("POST", "/fit?"+test.query, strings.NewReader(test.body)))
+		if w.Code != test.code {
+			t.Errorf("%s %s: got status %d, want %d: %s", test.query, test.body, w.Code, test.code, w.Body.String())
+		}
+	}
+}
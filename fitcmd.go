@@ -0,0 +1,143 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// The ways that benchplot fit can plot the fits.
+const (
+	plotNone = "none" // only write the fitted models
+	plotTerm = "term" // draw each group in the terminal
+)
+
+// writeFits writes the fitted model of each group, followed by a plot of the
+// group if plot is plotTerm.
+func writeFits(w io.Writer, fits []groupFit, yVar string, unit unitConv, plot string, width, height int, logX bool) error {
+	for i, gf := range fits {
+		if i > 0 && plot == plotTerm {
+			fmt.Fprintln(w)
+		}
+		var expr string
+		for j, x := range gf.xTransform {
+			term := strconv.FormatFloat(math.Abs(gf.beta[j]), 'g', 4, 64)
+			if _, err := strconv.ParseFloat(x.String(), 64); err != nil {
+				term += "*(" + x.String() + ")"
+			}
+			switch {
+			case j == 0 && gf.beta[j] < 0:
+				expr = "-" + term
+			case j == 0:
+				expr = term
+			case gf.beta[j] < 0:
+				expr += " - " + term
+			default:
+				expr += " + " + term
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s = %s  (R² %.4f)\n", gf.Group, unit.label(yVar), expr, gf.r2); err != nil {
+			return err
+		}
+		if plot == plotTerm {
+			if err := writeTermPlot(w, gf, yVar, unit.label(yVar), width, height, logX); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func fitUsage(fs *flag.FlagSet) func() {
+	return func() {
+		fmt.Fprintf(os.Stderr, "usage: benchplot fit [options] bench1.txt [bench2.txt ...]\n")
+		fmt.Fprintf(os.Stderr, "writes the fitted model of each group, and can plot them in the terminal\n")
+		fmt.Fprintf(os.Stderr, "example:\n")
+		fmt.Fprintf(os.Stderr, "   benchplot fit -plot=term -xtransform='N, 1.0' bench.txt\n")
+		fmt.Fprintf(os.Stderr, "options:\n")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+}
+
+func fitMain(args []string) {
+	fs := flag.NewFlagSet("fit", flag.ExitOnError)
+	fs.Usage = fitUsage(fs)
+	plot := fs.String("plot", plotNone, "how to plot each group: "+plotNone+", or "+plotTerm+" to draw the benchmarks and fit in the terminal")
+	width := fs.Int("width", 72, "width of the plots, in characters")
+	height := fs.Int("height", 16, "height of the plots, in characters")
+	logX := fs.Bool("logx", false, "plot N on a log scale")
+	yVar := fs.String("yvar", "NsPerOp", "response to fit")
+	fitterName := fs.String("fitter", defaultFitter, "estimator of the models: "+strings.Join(fitterNames(), ", "))
+	lambda := fs.String("lambda", "", "strength of regularization, for the ridge and lasso fitters (default "+strconv.FormatFloat(defaultLambda, 'g', -1, 64)+")")
+	xTransformValue := fs.String("xtransform", defaultXTransform, "comma separated terms of the model to fit, or the name of a preset like nlogn")
+	nreValue := fs.String("nre", defaultNRE, "regexp or name template, like Benchmark{Group}/{N:int}, matching the group and N in benchmark names")
+	aggregate := fs.String("aggregate", aggregateAll, "how to summarize repeated runs at the same N: "+strings.Join([]string{aggregateAll, aggregateMean, aggregateMedian, aggregateMin}, ", "))
+	unitName := fs.String("unit", "", "unit to fit the response in, like ms or KiB (default is the unit of go test)")
+	perElement := fs.Bool("per-element", false, "fit the response divided by N, per element rather than per op")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+	}
+	checkPatterns(fs.Args())
+
+	if *plot != plotNone && *plot != plotTerm {
+		fatalf("invalid plot: %s", *plot)
+	}
+	if *width < 8 || *height < 2 {
+		fatalf("invalid plot size: %dx%d", *width, *height)
+	}
+	if _, ok := validYs[*yVar]; !ok {
+		fatalf("invalid yvar: %s", *yVar)
+	}
+	unit, err := parseUnit(*yVar, *unitName, *perElement)
+	if err != nil {
+		fatal(err)
+	}
+	fitter, err := parseFitter(url.Values{"fitter": {*fitterName}, "lambda": {*lambda}})
+	if err != nil {
+		fatal(err)
+	}
+	*xTransformValue = expandPreset(*xTransformValue)
+	xTransform, err := parseTransform(*xTransformValue)
+	if err != nil {
+		fatal(err)
+	}
+	nre, err := compileNRE(*nreValue)
+	if err != nil {
+		fatalf("invalid nre %s: %v", *nreValue, err)
+	}
+	switch *aggregate {
+	case aggregateAll, aggregateMean, aggregateMedian, aggregateMin:
+	default:
+		fatalf("invalid aggregate: %s", *aggregate)
+	}
+
+	q := analysisQuery{
+		grouping:   grouping{nre: nre, unparameterized: unparamTable, yVar: *yVar, aggregate: *aggregate, unit: unit},
+		xTransform: xTransform,
+		yVar:       *yVar,
+		fitter:     fitter,
+	}
+	fits := q.fitGroups(readDataSet(fs.Args()))
+	if *logX {
+		for _, gf := range fits {
+			if gf.xMin <= 0 {
+				fatalf("-logx needs every N to be positive, but %s has N=%g", gf.Group, gf.xMin)
+			}
+		}
+	}
+	if err := writeFits(os.Stdout, fits, *yVar, unit, *plot, *width, *height, *logX); err != nil {
+		fatal(err)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// estimator estimates the coefficients of a model from a sample, along with
+// the statistics of the fit.  New estimators can be added by registering them
+// in an init function, like those in regularize.go, and are then available
+// by name wherever a model is fit.
+type estimator interface {
+	Fit(s samp) (model, fitStats, error)
+}
+
+// fitStats are the statistics of a fitted model, which are used for its
+// confidence intervals.
+type fitStats struct {
+	r2, mse float64
+	cint    []float64    // half widths of the 95% confidence intervals of the coefficients
+	iXTX    *mat64.Dense // inverse of X'X
+}
+
+// defaultFitter is the name of the estimator that is used if none is given.
+const defaultFitter = "ols"
+
+// fitters are the registered Fitters, keyed by name.
+var fitters = map[string]estimator{
+	"ols":    olsFitter{},
+	"huber":  huberFitter{},
+	"median": quantileFitter{0.5},
+	"p90":    quantileFitter{0.9},
+}
+
+// registerFitter makes an estimator available by name.  It panics if the
+// name is already taken.
+func registerFitter(name string, f estimator) {
+	if _, dup := fitters[name]; dup {
+		panic("benchplot: registerFitter called twice for " + name)
+	}
+	fitters[name] = f
+}
+
+// lookupFitter returns the estimator registered with the name, or the default
+// estimator if the name is empty.
+func lookupFitter(name string) (estimator, error) {
+	if name == "" {
+		name = defaultFitter
+	}
+	f, ok := fitters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown fitter: %s", name)
+	}
+	return f, nil
+}
+
+// parseFitter returns the estimator named by the fitter parameter of a
+// querystring, or its alias method.  Regularized fitters also take a lambda.
+func parseFitter(v url.Values) (estimator, error) {
+	name := v.Get("fitter")
+	if name == "" {
+		name = v.Get("method")
+	}
+	f, err := lookupFitter(name)
+	if err != nil {
+		return nil, err
+	}
+	if lambdaValue := v.Get("lambda"); lambdaValue != "" {
+		r, ok := f.(regularized)
+		if !ok {
+			return nil, fmt.Errorf("fitter %s does not take a lambda", name)
+		}
+		lambda, err := strconv.ParseFloat(lambdaValue, 64)
+		if err != nil || lambda < 0 || math.IsInf(lambda, 1) {
+			return nil, fmt.Errorf("invalid lambda: %s", lambdaValue)
+		}
+		f = r.withLambda(lambda)
+	}
+	return f, nil
+}
+
+// fitterInfo describes a registered estimator, for the plotter.
+type fitterInfo struct {
+	Name        string
+	Regularized bool // takes a lambda
+}
+
+// fitterInfos describes the registered Fitters, in order of name.
+func fitterInfos() []fitterInfo {
+	var infos []fitterInfo
+	for _, name := range fitterNames() {
+		_, ok := fitters[name].(regularized)
+		infos = append(infos, fitterInfo{name, ok})
+	}
+	return infos
+}
+
+// fitterNames returns the names of the registered Fitters, in order.
+func fitterNames() []string {
+	var names []string
+	for name := range fitters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// olsFitter fits by ordinary least squares.
+type olsFitter struct{}
+
+func (olsFitter) Fit(s samp) (model, fitStats, error) {
+	beta := estimate(s)
+	if beta == nil {
+		return nil, fitStats{}, errors.New("unable to estimate the model")
+	}
+	var st fitStats
+	st.r2, st.mse, st.cint, st.iXTX = stats(beta, s)
+	return beta, st, nil
+}
+
+// huberFitter fits with Huber's M-estimator, which gives less weight to
+// benchmarks that are far from the fit, like those disturbed by other
+// processes.
+type huberFitter struct{}
+
+func (huberFitter) Fit(s samp) (model, fitStats, error) {
+	return irls(s, func(r, scale float64) float64 {
+		// 1.345 gives 95% efficiency for normally distributed noise.
+		k := 1.345 * scale
+		if math.Abs(r) <= k {
+			return 1
+		}
+		return k / math.Abs(r)
+	})
+}
+
+// quantileFitter fits the tau'th quantile of the response, rather than its
+// mean.  The median is much less sensitive to outliers than the mean.
+type quantileFitter struct {
+	tau float64
+}
+
+func (q quantileFitter) Fit(s samp) (model, fitStats, error) {
+	return irls(s, func(r, scale float64) float64 {
+		// the weights of residuals near 0 have to be limited
+		a := math.Max(math.Abs(r), 1e-6*scale+1e-300)
+		if r > 0 {
+			return q.tau / a
+		}
+		return (1 - q.tau) / a
+	})
+}
+
+// irlsIterations is the maximum number of iterations of irls.
+const irlsIterations = 100
+
+// irls fits by iteratively reweighted least squares.  The weight of each
+// benchmark is a function of its residual and the scale of the residuals.
+// The statistics are those of least squares with the final coefficients,
+// which are approximate.
+func irls(s samp, weight func(r, scale float64) float64) (model, fitStats, error) {
+	beta, _, err := olsFitter{}.Fit(s)
+	if err != nil {
+		return nil, fitStats{}, err
+	}
+	stride := len(s.x) / len(s.y)
+	resid := make([]float64, len(s.y))
+	ws := samp{x: make([]float64, len(s.x)), y: make([]float64, len(s.y))}
+	for iter := 0; iter < irlsIterations; iter++ {
+		for i, y := range s.y {
+			yhat := 0.0
+			for j, x := range s.x[i*stride : (i+1)*stride] {
+				yhat += beta[j] * x
+			}
+			resid[i] = y - yhat
+		}
+		scale := mad(resid)
+		if scale == 0 {
+			// the fit is exact for at least half of the benchmarks
+			break
+		}
+		for i := range s.y {
+			w := math.Sqrt(weight(resid[i], scale))
+			ws.y[i] = w * s.y[i]
+			for j := i * stride; j < (i+1)*stride; j++ {
+				ws.x[j] = w * s.x[j]
+			}
+		}
+		next := estimate(ws)
+		if next == nil {
+			return nil, fitStats{}, errors.New("unable to estimate the model")
+		}
+		change, size := 0.0, 0.0
+		for j := range beta {
+			change = math.Max(change, math.Abs(next[j]-beta[j]))
+			size = math.Max(size, math.Abs(beta[j]))
+		}
+		beta = next
+		if change <= 1e-10*size {
+			break
+		}
+	}
+	var st fitStats
+	st.r2, st.mse, st.cint, st.iXTX = stats(beta, s)
+	return beta, st, nil
+}
+
+// mad returns the median absolute deviation of the residuals, scaled to be
+// a consistent estimate of the standard deviation of normally distributed
+// noise.
+func mad(resid []float64) float64 {
+	a := make([]float64, len(resid))
+	for i, r := range resid {
+		a[i] = math.Abs(r)
+	}
+	sort.Float64s(a)
+	m := a[len(a)/2]
+	if len(a)%2 == 0 {
+		m = (a[len(a)/2-1] + m) / 2
+	}
+	return m / 0.6745
+}
@@ -0,0 +1,51 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFitters(t *testing.T) {
+	// y = 2x + 10 with a little noise, and one benchmark that was disturbed.
+	var s samp
+	for i := 0; i < 20; i++ {
+		x := float64(i + 1)
+		y := 2*x + 10 + 0.1*float64(i%3-1)
+		if i == 15 {
+			y *= 5
+		}
+		s.x = append(s.x, x, 1)
+		s.y = append(s.y, y)
+	}
+	for _, test := range []struct {
+		name   string
+		robust bool
+	}{
+		{"ols", false},
+		{"huber", true},
+		{"median", true},
+	} {
+		f, err := lookupFitter(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		beta, st, err := f.Fit(s)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(st.cint) != 2 || st.iXTX == nil {
+			t.Errorf("%s: got incomplete stats %v", test.name, st)
+		}
+		close := math.Abs(beta[0]-2) < 0.05 && math.Abs(beta[1]-10) < 0.5
+		if close != test.robust {
+			t.Errorf("%s: got coefficients %v, which should be close to [2 10] only for robust fitters", test.name, beta)
+		}
+	}
+	if _, err := lookupFitter("bogus"); err == nil {
+		t.Error("lookupFitter returned an unknown fitter")
+	}
+}
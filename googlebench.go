@@ -0,0 +1,141 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+// googleBenchmarkFile is the output of a Google Benchmark (C++) binary run
+// with --benchmark_format=json or --benchmark_out.
+type googleBenchmarkFile struct {
+	Context    *googleBenchmarkContext `json:"context"`
+	Benchmarks []googleBenchmark       `json:"benchmarks"`
+}
+
+type googleBenchmarkContext struct {
+	Date             string  `json:"date"`
+	HostName         string  `json:"host_name"`
+	Executable       string  `json:"executable"`
+	NumCPUs          int     `json:"num_cpus"`
+	MHzPerCPU        float64 `json:"mhz_per_cpu"`
+	LibraryBuildType string  `json:"library_build_type"`
+}
+
+type googleBenchmark struct {
+	Name           string  `json:"name"`
+	RunType        string  `json:"run_type"` // iteration, or aggregate for the mean, median, and stddev of repetitions
+	Iterations     int     `json:"iterations"`
+	RealTime       float64 `json:"real_time"`
+	TimeUnit       string  `json:"time_unit"`
+	BytesPerSecond float64 `json:"bytes_per_second"`
+	ErrorOccurred  bool    `json:"error_occurred"`
+	ErrorMessage   string  `json:"error_message"`
+}
+
+// timeUnits are the sizes of the time units that other harnesses write, in ns.
+var timeUnits = map[string]float64{"": 1, "ns": 1, "us": 1e3, "ms": 1e6, "s": 1e9}
+
+// googleOptions are the parts of a Google Benchmark name which describe how
+// it was run, rather than its arguments.
+var googleOptions = map[string]bool{
+	"iterations": true, "repeats": true, "min_time": true, "min_warmup_time": true,
+	"real_time": true, "process_time": true, "manual_time": true,
+}
+
+// parseGoogleBenchmark parses the JSON output of Google Benchmark.  Each
+// run is a benchmark, with its real time as ns/op and its bytes per second
+// as MB/s, and aggregates of repetitions are left out, since the runs are
+// already there.  A name like BM_Sort/1024/threads:4 is named BM_Sort/1024-4,
+// and named arguments like size:1024 become size=1024, as in go.  The
+// benchmarks are in the package of the executable.  Runs with errors are
+// warnings, whose line is their position in the benchmarks.
+func parseGoogleBenchmark(data []byte) (benchFile, bool, error) {
+	var f googleBenchmarkFile
+	if err := json.Unmarshal(data, &f); err != nil || f.Context == nil || f.Benchmarks == nil {
+		return benchFile{}, false, nil
+	}
+	bf := benchFile{Config: make(map[string]string)}
+	ctx := f.Context
+	for key, v := range map[string]string{
+		"date":       ctx.Date,
+		"host":       ctx.HostName,
+		"executable": ctx.Executable,
+		"build":      ctx.LibraryBuildType,
+	} {
+		if v != "" {
+			bf.Config[key] = v
+		}
+	}
+	if ctx.NumCPUs > 0 {
+		bf.Config["cpu"] = fmt.Sprintf("%d x %g MHz", ctx.NumCPUs, ctx.MHzPerCPU)
+	}
+	pkg := path.Base(strings.Replace(ctx.Executable, `\`, "/", -1))
+	if ctx.Executable == "" {
+		pkg = ""
+	}
+
+	for i, gb := range f.Benchmarks {
+		if gb.RunType == "aggregate" {
+			continue
+		}
+		name := googleBenchmarkName(gb.Name)
+		scale, ok := timeUnits[gb.TimeUnit]
+		switch {
+		case gb.ErrorOccurred:
+			bf.Warnings = append(bf.Warnings, parseWarning{i + 1, gb.Name, gb.ErrorMessage})
+			continue
+		case !ok:
+			bf.Warnings = append(bf.Warnings, parseWarning{i + 1, gb.Name, "unknown time unit " + gb.TimeUnit})
+			continue
+		}
+		b := parse.Benchmark{
+			Name:     name,
+			N:        gb.Iterations,
+			NsPerOp:  gb.RealTime * scale,
+			Measured: parse.NsPerOp,
+			Ord:      len(bf.Benchmarks),
+		}
+		if gb.BytesPerSecond > 0 {
+			b.MBPerS = gb.BytesPerSecond / 1e6
+			b.Measured |= parse.MBPerS
+		}
+		bf.Benchmarks = append(bf.Benchmarks, &benchmark{Benchmark: b, Package: pkg, Machine: machineName(bf.Config)})
+	}
+	return bf, true, nil
+}
+
+// googleBenchmarkName converts the name of a Google Benchmark to the form
+// of a go benchmark name.
+func googleBenchmarkName(name string) string {
+	var parts []string
+	procs := "1"
+	for i, part := range strings.Split(name, "/") {
+		key, value := part, ""
+		if j := strings.Index(part, ":"); j >= 0 {
+			key, value = part[:j], part[j+1:]
+		}
+		switch {
+		case i == 0:
+			parts = append(parts, part)
+		case key == "threads":
+			if _, err := strconv.Atoi(value); err == nil {
+				procs = value
+			}
+		case googleOptions[key]:
+		case value != "":
+			parts = append(parts, key+"="+value)
+		default:
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/") + "-" + procs
+}
@@ -0,0 +1,70 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const googleBenchmarkJSON = `
+{
+  "context": {
+    "date": "2016-01-02T15:04:05+00:00",
+    "host_name": "builder",
+    "executable": "./build/sort_bench",
+    "num_cpus": 8,
+    "mhz_per_cpu": 3000,
+    "library_build_type": "release"
+  },
+  "benchmarks": [
+    {"name": "BM_Sort/1024", "run_type": "iteration", "iterations": 1000, "real_time": 12.5, "cpu_time": 12.4, "time_unit": "us"},
+    {"name": "BM_Sort/2048", "run_type": "iteration", "iterations": 500, "real_time": 27000, "cpu_time": 26000, "time_unit": "ns"},
+    {"name": "BM_Sort/2048_mean", "run_type": "aggregate", "aggregate_name": "mean", "iterations": 3, "real_time": 27000, "time_unit": "ns"},
+    {"name": "BM_Copy/size:4096/threads:4/real_time", "run_type": "iteration", "iterations": 100, "real_time": 2, "time_unit": "ms", "bytes_per_second": 2.048e9},
+    {"name": "BM_Broken/1", "run_type": "iteration", "error_occurred": true, "error_message": "out of memory"}
+  ]
+}`
+
+func TestParseGoogleBenchmark(t *testing.T) {
+	bf, err := parseBenchFile(strings.NewReader(googleBenchmarkJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bf.Benchmarks) != 3 {
+		t.Fatalf("got %d benchmarks, want 3", len(bf.Benchmarks))
+	}
+	for i, want := range []struct {
+		name    string
+		nsPerOp float64
+		mbPerS  float64
+	}{
+		{"BM_Sort/1024-1", 12500, 0},
+		{"BM_Sort/2048-1", 27000, 0},
+		{"BM_Copy/size=4096-4", 2e6, 2048},
+	} {
+		b := bf.Benchmarks[i]
+		if b.Name != want.name || b.NsPerOp != want.nsPerOp || b.MBPerS != want.mbPerS {
+			t.Errorf("got benchmark %s at %g ns/op and %g MB/s, want %s at %g ns/op and %g MB/s", b.Name, b.NsPerOp, b.MBPerS, want.name, want.nsPerOp, want.mbPerS)
+		}
+		if b.Package != "sort_bench" || b.Machine != "8 x 3000 MHz" {
+			t.Errorf("got package %q and machine %q", b.Package, b.Machine)
+		}
+	}
+	if len(bf.Warnings) != 1 || bf.Warnings[0].Err != "out of memory" {
+		t.Errorf("got warnings %v, want the failed benchmark", bf.Warnings)
+	}
+
+	// the default N pattern groups them like go benchmarks
+	groups, _, _, _ := groupBenchmarks(dataSet{Files: []benchFile{bf}}, grouping{nre: regexp.MustCompile(defaultNRE)})
+	if len(groups["BM_Sort"]) != 2 || len(groups["BM_Copy/size="]) != 1 {
+		t.Errorf("got groups %v", groups)
+	}
+
+	if _, err := parseBenchFile(strings.NewReader(`{"results": []}`)); err != errUnknownJSON {
+		t.Errorf("got error %v for unknown JSON, want %v", err, errUnknownJSON)
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jonlawlor/benchplot/benchplotpb"
+	"golang.org/x/tools/benchmark/parse"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// serveGRPC serves the gRPC interface on the listeners.  It only returns if
+// one of them fails.
+func serveGRPC(lns []net.Listener, src dataSource) error {
+	s := grpc.NewServer()
+	benchplotpb.RegisterBenchplotServer(s, &grpcServer{src: src})
+	errc := make(chan error, len(lns))
+	for _, ln := range lns {
+		go func(ln net.Listener) { errc <- s.Serve(ln) }(ln)
+	}
+	return <-errc
+}
+
+// grpcServer implements the gRPC interface, by translating its requests into
+// the same form as the http endpoints.
+type grpcServer struct {
+	benchplotpb.UnimplementedBenchplotServer
+	src dataSource
+}
+
+func (s *grpcServer) Parse(ctx context.Context, req *benchplotpb.ParseRequest) (*benchplotpb.ParseResponse, error) {
+	g, err := parseGrouping(groupingValues(req.Grouping))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	bf, err := parseBenchFile(strings.NewReader(req.Text))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res := &benchplotpb.ParseResponse{
+		Config:  bf.Config,
+		Skipped: int32(bf.Skipped),
+	}
+	for _, b := range bf.Benchmarks {
+		res.Benchmarks = append(res.Benchmarks, benchmarkToPB(b.Benchmark, b.perfCounters, b.Package, b.Machine))
+	}
+	for _, w := range bf.Warnings {
+		res.Warnings = append(res.Warnings, &benchplotpb.Warning{Line: int32(w.Line), Text: w.Text, Err: w.Err})
+	}
+
+	groups, unmatched, _, _ := groupBenchmarks(dataSet{Files: []benchFile{bf}}, g)
+	var names []string
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pg := &benchplotpb.Group{Name: name}
+		for _, b := range groups[name] {
+			pg.Points = append(pg.Points, &benchplotpb.Point{Benchmark: benchmarkToPB(b.Benchmark, b.perfCounters, "", ""), X: b.X, Cluster: b.Cluster})
+		}
+		res.Groups = append(res.Groups, pg)
+	}
+	for _, b := range unmatched {
+		res.Unmatched = append(res.Unmatched, benchmarkToPB(b.Benchmark, b.perfCounters, b.Package, b.Machine))
+	}
+	return res, nil
+}
+
+func (s *grpcServer) Fit(ctx context.Context, req *benchplotpb.FitRequest) (*benchplotpb.FitResponse, error) {
+	var benchSet []benchmarkResponse
+	for _, p := range req.Points {
+		benchSet = append(benchSet, pointFromPB(p))
+	}
+
+	// Unset fields take the same defaults as the plotter.
+	var xlb, xub float64
+	for i, b := range benchSet {
+		if i == 0 || b.X < xlb {
+			xlb = b.X
+		}
+		if i == 0 || b.X > xub {
+			xub = b.X
+		}
+	}
+	if req.Xlb != nil {
+		xlb = *req.Xlb
+	}
+	if req.Xub != nil {
+		xub = *req.Xub
+	}
+	v := url.Values{
+		"xlb":        {strconv.FormatFloat(xlb, 'g', -1, 64)},
+		"xub":        {strconv.FormatFloat(xub, 'g', -1, 64)},
+		"xtransform": {req.XTransform},
+		"yvar":       {req.YVar},
+		"nlinesteps": {strconv.Itoa(int(req.NLineSteps))},
+		"fitter":     {req.Fitter},
+	}
+	if req.XTransform == "" {
+		v.Set("xtransform", defaultXTransform)
+	}
+	if req.YVar == "" {
+		v.Set("yvar", "NsPerOp")
+	}
+	if req.NLineSteps == 0 {
+		v.Set("nlinesteps", "1000")
+	}
+	if req.Lambda != nil {
+		v.Set("lambda", strconv.FormatFloat(*req.Lambda, 'g', -1, 64))
+	}
+	fr, err := parseFitRequest(v)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !canFit(benchSet, len(fr.xTransform)) {
+		return nil, status.Error(codes.InvalidArgument, "not enough benchmarks to fit")
+	}
+
+	ctx, release, err := acquireFit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	f, err := fit(ctx, benchSet, fr)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	res := &benchplotpb.FitResponse{
+		R2:         f.R2,
+		Mse:        f.MSE,
+		XMin:       f.XMin,
+		XMax:       f.XMax,
+		Equation:   f.Equation,
+		Latex:      f.LaTeX,
+		Complexity: f.Complexity,
+		Condition:  f.Condition,
+		Collinear:  f.Collinear,
+		Rank:       int32(f.Rank),
+		Clusters:   int32(f.Clusters),
+		Df:         int32(f.DF),
+		AdjR2:      f.AdjR2,
+		F:          f.F,
+		PValue:     f.PValue,
+		K:          f.K,
+		NonFinite:  f.NonFinite,
+	}
+	for _, p := range f.ResultLine {
+		res.Line = append(res.Line, &benchplotpb.LinePoint{X: p.X, Yhat: p.Yhat, ConfWidth: p.ConfWidth, Extrapolated: p.Extrapolated})
+	}
+	for _, m := range f.ResultModel {
+		res.Model = append(res.Model, &benchplotpb.Term{XTrans: m.XTrans, Beta: m.Beta, BInt: m.BInt, Vif: m.VIF, Std: m.Std})
+	}
+	res.Covariance = matrixToPB(f.Covariance)
+	res.Correlation = matrixToPB(f.Correlation)
+	return res, nil
+}
+
+func matrixToPB(m [][]float64) []*benchplotpb.Row {
+	var rows []*benchplotpb.Row
+	for _, r := range m {
+		rows = append(rows, &benchplotpb.Row{Values: r})
+	}
+	return rows
+}
+
+func (s *grpcServer) Compare(ctx context.Context, req *benchplotpb.CompareRequest) (*benchplotpb.CompareResponse, error) {
+	v := groupingValues(req.Grouping)
+	v.Set("xtransform", req.XTransform)
+	v.Set("yvar", req.YVar)
+	v.Set("fitter", req.Fitter)
+	if req.Lambda != nil {
+		v.Set("lambda", strconv.FormatFloat(*req.Lambda, 'g', -1, 64))
+	}
+	q, err := parseAnalysisQuery(v)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Compare the benchmarks in the request, or the server's own.
+	var ds dataSet
+	if req.Text != "" {
+		bf, err := parseBenchFile(strings.NewReader(req.Text))
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		ds.Files = []benchFile{bf}
+	} else {
+		ds = s.src.dataSet()
+	}
+
+	ctx, release, err := acquireFit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	fits, err := q.fitGroupsContext(ctx, ds)
+	if err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	if fits, err = selectPair(fits, req.A, req.B); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res := &benchplotpb.CompareResponse{}
+	for _, x := range q.xTransform {
+		res.Terms = append(res.Terms, x.String())
+	}
+	for _, gf := range fits {
+		res.Coefficients = append(res.Coefficients, &benchplotpb.GroupCoefficients{
+			Group:      gf.Group,
+			Beta:       gf.beta,
+			BInt:       gf.bint,
+			Complexity: complexity(gf.benchSet, q.yVar),
+		})
+	}
+	for _, ct := range allChowTests(fits, q.yVar) {
+		res.ChowTests = append(res.ChowTests, &benchplotpb.ChowTest{
+			A: ct.A, B: ct.B, F: ct.F, Df1: int32(ct.DF1), Df2: int32(ct.DF2), P: ct.P,
+		})
+	}
+	for _, c := range allCrossovers(fits, nil, nil) {
+		res.Crossovers = append(res.Crossovers, &benchplotpb.Crossover{
+			A: c.A, B: c.B, N: c.N, NLow: c.NLow, NHigh: c.NHigh, Lower: c.Lower, Extrapolated: c.Extrapolated,
+		})
+	}
+	return res, nil
+}
+
+// acquireFit waits for a slot in the fit pool, like the fits of the http
+// endpoints, and gives the fit at most -fit-timeout.  The returned context
+// is done when the fit runs out of time, and release must be called when it
+// is finished.
+func acquireFit(ctx context.Context) (context.Context, func(), error) {
+	ctx, cancel := withFitTimeout(ctx)
+	if err := fitLimit().acquire(ctx); err != nil {
+		cancel()
+		if err == errOverloaded {
+			return nil, nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return nil, nil, status.FromContextError(err).Err()
+	}
+	return ctx, func() {
+		fitLimit().release()
+		cancel()
+	}, nil
+}
+
+// groupingValues translates a grouping into the querystring used by the http
+// endpoints.
+func groupingValues(g *benchplotpb.Grouping) url.Values {
+	v := make(url.Values)
+	if g == nil {
+		return v
+	}
+	v.Set("nre", g.Nre)
+	v.Set("unparameterized", g.Unparameterized)
+	v.Set("pkg", g.Pkg)
+	v.Set("bypkg", strconv.FormatBool(g.ByPackage))
+	v.Set("aggregate", g.Aggregate)
+	v.Set("machine", g.Machine)
+	v.Set("bymachine", strconv.FormatBool(g.ByMachine))
+	v.Set("cluster", g.Cluster)
+	return v
+}
+
+func benchmarkToPB(b parse.Benchmark, c perfCounters, pkg, machine string) *benchplotpb.Benchmark {
+	return &benchplotpb.Benchmark{
+		Name:              b.Name,
+		N:                 int64(b.N),
+		NsPerOp:           b.NsPerOp,
+		AllocedBytesPerOp: b.AllocedBytesPerOp,
+		AllocsPerOp:       b.AllocsPerOp,
+		MbPerS:            b.MBPerS,
+		Package:           pkg,
+		Machine:           machine,
+		InstructionsPerOp: c.InstructionsPerOp,
+		CacheMissesPerOp:  c.CacheMissesPerOp,
+		BranchMissesPerOp: c.BranchMissesPerOp,
+	}
+}
+
+func pointFromPB(p *benchplotpb.Point) benchmarkResponse {
+	br := benchmarkResponse{X: p.X, Cluster: p.Cluster}
+	if b := p.Benchmark; b != nil {
+		br.Benchmark = parse.Benchmark{
+			Name:              b.Name,
+			N:                 int(b.N),
+			NsPerOp:           b.NsPerOp,
+			AllocedBytesPerOp: b.AllocedBytesPerOp,
+			AllocsPerOp:       b.AllocsPerOp,
+			MBPerS:            b.MbPerS,
+		}
+		// the counts aren't changed in place, so they can be shared
+		br.perfCounters = perfCounters{
+			InstructionsPerOp: b.InstructionsPerOp,
+			CacheMissesPerOp:  b.CacheMissesPerOp,
+			BranchMissesPerOp: b.BranchMissesPerOp,
+		}
+	}
+	return br
+}
@@ -0,0 +1,244 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonlawlor/benchplot/benchplotpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const grpcTestBench = `BenchmarkSort10-4 1000000 1008 ns/op
+BenchmarkSort100-4 200000 8224 ns/op
+BenchmarkSort1000-4 10000 152945 ns/op
+BenchmarkSort10000-4 1000 1950999 ns/op
+BenchmarkStableSort10-4 1000000 1260 ns/op
+BenchmarkStableSort100-4 100000 16730 ns/op
+BenchmarkStableSort1000-4 5000 362024 ns/op
+BenchmarkStableSort10000-4 300 5731738 ns/op
+BenchmarkHash-4 5000000 300 ns/op
+`
+
+func TestGRPC(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go serveGRPC([]net.Listener{ln}, globSource(nil))
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := benchplotpb.NewBenchplotClient(conn)
+	ctx := context.Background()
+
+	parsed, err := client.Parse(ctx, &benchplotpb.ParseRequest{Text: grpcTestBench})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Benchmarks) != 9 || len(parsed.Groups) != 2 || len(parsed.Unmatched) != 1 {
+		t.Fatalf("got %d benchmarks, %d groups, and %d unmatched, want 9, 2, and 1",
+			len(parsed.Benchmarks), len(parsed.Groups), len(parsed.Unmatched))
+	}
+	if parsed.Groups[0].Name != "BenchmarkSort" || len(parsed.Groups[0].Points) != 4 {
+		t.Errorf("got first group %s with %d points, want BenchmarkSort with 4", parsed.Groups[0].Name, len(parsed.Groups[0].Points))
+	}
+
+	fitted, err := client.Fit(ctx, &benchplotpb.FitRequest{Points: parsed.Groups[0].Points, NLineSteps: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fitted.Line) != 10 || len(fitted.Model) != 2 || fitted.Equation == "" {
+		t.Errorf("got %d line points, %d terms, and equation %q", len(fitted.Line), len(fitted.Model), fitted.Equation)
+	}
+	if _, err := client.Fit(ctx, &benchplotpb.FitRequest{Points: parsed.Groups[0].Points, YVar: "Bogus"}); err == nil {
+		t.Error("fit with an invalid yvar did not return an error")
+	}
+	if _, err := client.Fit(ctx, &benchplotpb.FitRequest{Points: parsed.Groups[0].Points, NLineSteps: 1e9}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v fitting a billion line steps, want an invalid argument", err)
+	}
+
+	compared, err := client.Compare(ctx, &benchplotpb.CompareRequest{Text: grpcTestBench})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compared.Coefficients) != 2 || len(compared.ChowTests) != 1 {
+		t.Errorf("got %d coefficients and %d chow tests, want 2 and 1", len(compared.Coefficients), len(compared.ChowTests))
+	}
+}
+
+func TestGRPCCountersAndClusters(t *testing.T) {
+	s := &grpcServer{src: globSource(nil)}
+	ctx := context.Background()
+	parsed, err := s.Parse(ctx, &benchplotpb.ParseRequest{
+		Text: `cpu: Xeon
+BenchmarkSort10-4 1000 1000 ns/op
+2000000,,instructions,1000000,100.00,,
+BenchmarkSort100-4 1000 10000 ns/op
+20000000,,instructions,1000000,100.00,,
+BenchmarkSort1000-4 1000 100000 ns/op
+cpu: Epyc
+BenchmarkSort10-4 1000 1100 ns/op
+BenchmarkSort100-4 1000 11000 ns/op
+BenchmarkSort1000-4 1000 110000 ns/op
+`,
+		Grouping: &benchplotpb.Grouping{Cluster: clusterMachine},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := parsed.Benchmarks[0]; b.GetInstructionsPerOp() != 2000 || b.CacheMissesPerOp != nil || b.Machine == "" {
+		t.Errorf("got %v, want the instructions and machine of the first benchmark", b)
+	}
+	points := parsed.Groups[0].Points
+	if len(points) != 6 || points[0].Cluster == "" || points[0].Cluster == points[5].Cluster {
+		t.Fatalf("got points %v, want each clustered by its machine", points)
+	}
+
+	fitted, err := s.Fit(ctx, &benchplotpb.FitRequest{Points: points, XTransform: "N", NLineSteps: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fitted.Clusters != 2 {
+		t.Errorf("got %d clusters, want 2", fitted.Clusters)
+	}
+
+	// only the counted benchmarks are fit
+	fitted, err = s.Fit(ctx, &benchplotpb.FitRequest{Points: points, XTransform: "N", YVar: "InstructionsPerOp", NLineSteps: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if beta := fitted.Model[0].Beta; math.Abs(beta-200) > 1e-6 {
+		t.Errorf("got %g instructions per N, want 200", beta)
+	}
+}
+
+func TestGRPCFitMatchesHTTP(t *testing.T) {
+	s := &grpcServer{src: globSource(nil)}
+	ctx := context.Background()
+	parsed, err := s.Parse(ctx, &benchplotpb.ParseRequest{Text: grpcTestBench})
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := parsed.Groups[0].Points
+	var benchSet []benchmarkResponse
+	for _, p := range points {
+		benchSet = append(benchSet, pointFromPB(p))
+	}
+	body, err := json.Marshal(benchSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zero, upper := 0.0, 20000.0
+	tests := []struct {
+		req   *benchplotpb.FitRequest
+		query string
+	}{
+		// a bound of zero is used, rather than the range of the points
+		{&benchplotpb.FitRequest{Points: points, XTransform: "N, 1.0", NLineSteps: 5, Xlb: &zero, Xub: &upper},
+			"xlb=0&xub=20000&xtransform=N,1.0&yvar=NsPerOp&nlinesteps=5"},
+		// each bound that isn't set is the range of the points
+		{&benchplotpb.FitRequest{Points: points, XTransform: "N, 1.0", NLineSteps: 5, Xub: &upper},
+			"xlb=10&xub=20000&xtransform=N,1.0&yvar=NsPerOp&nlinesteps=5"},
+		{&benchplotpb.FitRequest{Points: points, XTransform: "N / (K + N), 1.0", NLineSteps: 3, Fitter: "huber"},
+			"xlb=10&xub=10000&xtransform=N/(K%2BN),1.0&yvar=NsPerOp&nlinesteps=3&fitter=huber"},
+	}
+	for _, test := range tests {
+		got, err := s.Fit(ctx, test.req)
+		if err != nil {
+			t.Fatalf("%s: %v", test.query, err)
+		}
+		w := httptest.NewRecorder()
+		fitHandleFunc(w, httptest.NewRequest("POST", "/fit?"+test.query, strings.NewReader(string(body))))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d: %s", test.query, w.Code, w.Body)
+		}
+		var want fitResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &want); err != nil {
+			t.Fatal(err)
+		}
+
+		// the response of /fit, from the fields of the gRPC response
+		res := fitResponse{
+			R2: got.R2, MSE: got.Mse, DF: int(got.Df), AdjR2: got.AdjR2, F: got.F, PValue: got.PValue,
+			XMin: got.XMin, XMax: got.XMax, Equation: got.Equation, LaTeX: got.Latex,
+			Complexity: got.Complexity, Condition: got.Condition, Collinear: got.Collinear,
+			Rank: int(got.Rank), K: got.K, Clusters: int(got.Clusters), NonFinite: got.NonFinite,
+		}
+		for _, p := range got.Line {
+			res.ResultLine = append(res.ResultLine, resultPoint{p.X, p.Yhat, p.ConfWidth, p.Extrapolated})
+		}
+		for _, m := range got.Model {
+			res.ResultModel = append(res.ResultModel, resultModel{m.XTrans, m.Beta, m.BInt, m.Vif, m.Std})
+		}
+		for _, r := range got.Covariance {
+			res.Covariance = append(res.Covariance, r.Values)
+		}
+		for _, r := range got.Correlation {
+			res.Correlation = append(res.Correlation, r.Values)
+		}
+		if !reflect.DeepEqual(res, want) {
+			t.Errorf("%s: got\n%+v\nover gRPC, want\n%+v", test.query, res, want)
+		}
+	}
+}
+
+func TestGRPCFitPool(t *testing.T) {
+	s := &grpcServer{src: globSource(nil)}
+	fitLimit()
+	defer func(p *fitPool) { fits = p }(fits)
+
+	// every slot is taken, and nothing can wait for one
+	fits = &fitPool{slots: make(chan struct{}, 1)}
+	fits.slots <- struct{}{}
+	tests := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{"Fit", func(ctx context.Context) error {
+			parsed, err := s.Parse(ctx, &benchplotpb.ParseRequest{Text: grpcTestBench})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = s.Fit(ctx, &benchplotpb.FitRequest{Points: parsed.Groups[0].Points})
+			return err
+		}},
+		{"Compare", func(ctx context.Context) error {
+			_, err := s.Compare(ctx, &benchplotpb.CompareRequest{Text: grpcTestBench})
+			return err
+		}},
+	}
+	for _, test := range tests {
+		fits.maxQueue = 0
+		if err := test.call(context.Background()); status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("%s: got %v from an overloaded pool, want %v", test.name, err, codes.ResourceExhausted)
+		}
+
+		// the request's context is used while waiting
+		fits.maxQueue = 1
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		if err := test.call(ctx); status.Code(err) != codes.DeadlineExceeded {
+			t.Errorf("%s: got %v after the deadline, want %v", test.name, err, codes.DeadlineExceeded)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// readiness tracks whether the server is ready to serve the plotter, which is
+// after the benchmarks have been parsed for the first time.
+type readiness struct {
+	ready int32 // set to 1 atomically
+}
+
+func (r *readiness) set()          { atomic.StoreInt32(&r.ready, 1) }
+func (r *readiness) isReady() bool { return atomic.LoadInt32(&r.ready) == 1 }
+
+// warmUp parses the benchmarks from src, and marks the server as ready once
+// it is done.
+func (r *readiness) warmUp(src dataSource) {
+	src.dataSet()
+	r.set()
+}
+
+// healthzHandleFunc reports that the server is running.
+func healthzHandleFunc(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, "ok\n")
+}
+
+// readyzHandleFunc reports whether the server is ready, with a 503 until it
+// is, so that load balancers don't send it requests while it is starting.
+func (r *readiness) readyzHandleFunc(w http.ResponseWriter, req *http.Request) {
+	if !r.isReady() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, "ok\n")
+}
@@ -0,0 +1,31 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadyz(t *testing.T) {
+	var ready readiness
+	readyz := func() int {
+		req, err := http.NewRequest("GET", "/readyz", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		ready.readyzHandleFunc(w, req)
+		return w.Code
+	}
+	if code := readyz(); code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d before warming up, want %d", code, http.StatusServiceUnavailable)
+	}
+	ready.warmUp(globSource(nil))
+	if code := readyz(); code != http.StatusOK {
+		t.Errorf("got status %d after warming up, want %d", code, http.StatusOK)
+	}
+}
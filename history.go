@@ -0,0 +1,425 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// storedRun is a run of benchmarks in the history database, along with labels
+// describing it, like the commit and branch it was run on.
+type storedRun struct {
+	ID     string
+	Time   time.Time // when it was stored
+	Labels map[string]string
+	File   benchFile
+}
+
+// series is how the run is plotted: by its commit, if it has one.
+func (sr storedRun) series() string {
+	if c := sr.Labels["commit"]; c != "" {
+		return c
+	}
+	return sr.ID
+}
+
+// labelRE matches the valid names of labels.
+var labelRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// historyDB stores runs of benchmarks in a directory, one json file per run,
+// so that it can be inspected, backed up, and copied with ordinary tools.
+type historyDB struct {
+	dir string
+
+	// mu serializes writes, so that readers never see a partial run.
+	mu sync.Mutex
+}
+
+// openHistory opens the history database in dir, creating it if it doesn't
+// exist.
+func openHistory(dir string) (*historyDB, error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+	return &historyDB{dir: dir}, nil
+}
+
+// newRunID returns a new run ID.  IDs sort in the order that they were
+// created, and are unique without coordinating between servers.
+func newRunID(t time.Time) (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return t.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b), nil
+}
+
+// runIDRE matches valid run IDs, which are also file names.
+var runIDRE = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]*$`)
+
+// has reports whether a run with the ID is stored.
+func (db *historyDB) has(id string) bool {
+	_, err := os.Stat(filepath.Join(db.dir, id+".json"))
+	return err == nil
+}
+
+// add stores the run, and returns it with its new ID.
+func (db *historyDB) add(bf benchFile, labels map[string]string) (storedRun, error) {
+	now := time.Now()
+	id, err := newRunID(now)
+	if err != nil {
+		return storedRun{}, err
+	}
+	sr := storedRun{ID: id, Time: now, Labels: labels, File: bf}
+	sr.File.Path = "runs/" + id
+	sr.File.Series = sr.series()
+	if sr.File.ModTime.IsZero() {
+		sr.File.ModTime = now
+	}
+	return sr, db.put(sr)
+}
+
+// put writes the run, replacing any run with the same ID.  It is written to
+// a temporary file first, so that a crash can't leave a partial run behind.
+func (db *historyDB) put(sr storedRun) error {
+	b, err := json.Marshal(sr)
+	if err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	fn := filepath.Join(db.dir, sr.ID+".json")
+	tmp := fn + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fn)
+}
+
+// runs returns every stored run, in the order they were stored.
+func (db *historyDB) runs() ([]storedRun, error) {
+	fns, err := filepath.Glob(filepath.Join(db.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	runs := []storedRun{}
+	for _, fn := range fns {
+		b, err := ioutil.ReadFile(fn)
+		if err != nil {
+			return nil, err
+		}
+		var sr storedRun
+		if err := json.Unmarshal(b, &sr); err != nil {
+			return nil, fmt.Errorf("%s: %v", fn, err)
+		}
+		runs = append(runs, sr)
+	}
+	sort.Sort(byRunID(runs))
+	return runs, nil
+}
+
+type byRunID []storedRun
+
+func (a byRunID) Len() int           { return len(a) }
+func (a byRunID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byRunID) Less(i, j int) bool { return a[i].ID < a[j].ID }
+
+// historySource adds the runs in the history database to the benchmarks of
+// another source.
+type historySource struct {
+	dataSource
+	db  *historyDB
+	sel selector // picks which of the runs are included
+}
+
+func (hs historySource) dataSet() dataSet {
+	ds := hs.dataSource.dataSet()
+	runs, err := hs.db.runs()
+	if err != nil {
+		ds.Files = append(ds.Files, benchFile{Path: hs.db.dir, Err: err.Error()})
+		return ds
+	}
+	for _, sr := range selectRuns(runs, hs.sel) {
+		ds.Files = append(ds.Files, sr.File)
+	}
+	return ds
+}
+
+// runSummary describes a stored run without its benchmarks.
+type runSummary struct {
+	ID         string
+	Time       time.Time
+	Labels     map[string]string
+	Benchmarks int
+}
+
+// serveRuns stores the output of go test -bench which is posted to it in the
+// history database, and returns its ID.  Every parameter in the querystring
+// is a label of the run, like commit=abc123&branch=main.  The machine label
+// defaults to the machine in the benchmarks' configuration.  A GET lists the
+// stored runs, which can be limited by a selector in the select parameter.
+// The new data is published to hub, and if check isn't nil, the run is
+// checked against the baseline in the background.
+func serveRuns(db *historyDB, src dataSource, hub *eventHub, check *regressionCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			sel, err := parseSelector(r.URL.Query().Get("select"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			runs, err := db.runs()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			sums := []runSummary{}
+			for _, sr := range selectRuns(runs, sel) {
+				sums = append(sums, runSummary{sr.ID, sr.Time, sr.Labels, len(sr.File.Benchmarks)})
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(sums)
+			return
+		case "POST":
+		default:
+			http.Error(w, "runs requires a GET or POST", http.StatusMethodNotAllowed)
+			return
+		}
+
+		labels := make(map[string]string)
+		for k, vs := range r.URL.Query() {
+			if !labelRE.MatchString(k) {
+				http.Error(w, "invalid label: "+k, http.StatusBadRequest)
+				return
+			}
+			if len(vs) != 1 {
+				http.Error(w, "label "+k+" has more than one value", http.StatusBadRequest)
+				return
+			}
+			labels[k] = vs[0]
+		}
+		bf, err := parseBenchFile(http.MaxBytesReader(w, r.Body, maxParseBytes))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(bf.Benchmarks) == 0 {
+			http.Error(w, "no benchmarks in the run", http.StatusBadRequest)
+			return
+		}
+		if _, ok := labels["machine"]; !ok {
+			if m := machineName(bf.Config); m != "" {
+				labels["machine"] = m
+			}
+		}
+		for _, b := range bf.Benchmarks {
+			if b.Machine == "" {
+				b.Machine = labels["machine"]
+			}
+		}
+
+		sr, err := db.add(bf, labels)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		ds := src.dataSet()
+		hub.publish(event{Type: eventData, Data: &ds})
+		if check != nil {
+			go func() {
+				for _, err := range check.checkStored(db, sr) {
+					slog.Warn("webhook failed", "run", sr.ID, "err", err)
+				}
+			}()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(struct{ ID string }{sr.ID})
+	}
+}
+
+// historyPoint is the response of a benchmark at a single N in a stored run.
+// Repeated runs of the benchmark are summarized.
+type historyPoint struct {
+	ID     string
+	Time   time.Time
+	Labels map[string]string
+	N      float64
+
+	Mean, Min, Max float64
+	Runs           int
+}
+
+// historyParams are the querystring parameters of /history which aren't label
+// filters.
+var historyParams = map[string]bool{"benchmark": true, "n": true, "since": true, "yvar": true, "nre": true, "select": true}
+
+// history returns the time series of the response of the benchmark group in
+// the runs, at N = n, or at every N if n is NaN.  Only the runs stored after
+// since with all of the labels are included.
+func history(runs []storedRun, benchmark string, n float64, yVar string, nre *regexp.Regexp, since time.Time, labels map[string]string) []historyPoint {
+	g := grouping{nre: nre, unparameterized: unparamOne, yVar: yVar}
+	points := []historyPoint{}
+	for _, sr := range runs {
+		if sr.Time.Before(since) || !hasLabels(sr.Labels, labels) {
+			continue
+		}
+		bf := sr.File
+		bf.Series = ""
+		groups, _, _, _ := groupBenchmarks(dataSet{Files: []benchFile{bf}}, g)
+		benchSet, ok := groups[benchmark]
+		if !ok {
+			benchSet = groups["Benchmark"+benchmark]
+		}
+
+		// the values at each N, in the order they first appear
+		var xs []float64
+		values := make(map[float64][]float64)
+		y := sampleGroup(benchSet, nil, yVar).y
+		for i, b := range benchSet {
+			if !math.IsNaN(n) && b.X != n {
+				continue
+			}
+			if _, ok := values[b.X]; !ok {
+				xs = append(xs, b.X)
+			}
+			values[b.X] = append(values[b.X], y[i])
+		}
+		for _, x := range xs {
+			p := historyPoint{ID: sr.ID, Time: sr.Time, Labels: sr.Labels, N: x, Min: math.Inf(1), Max: math.Inf(-1)}
+			var sum kahan
+			for _, v := range values[x] {
+				sum.add(v)
+				p.Min = math.Min(p.Min, v)
+				p.Max = math.Max(p.Max, v)
+			}
+			p.Runs = len(values[x])
+			p.Mean = sum.value() / float64(p.Runs)
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// hasLabels reports whether labels has every one of want.
+func hasLabels(labels, want map[string]string) bool {
+	for k, v := range want {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSince parses the start of a history query, which is either a time
+// like 2016-01-02 or 2016-01-02T15:04:05Z, or how long ago it is, like 30d
+// or 12h.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(s, "d"), 64)
+		if err != nil || days < 0 {
+			return time.Time{}, fmt.Errorf("invalid since: %s", s)
+		}
+		return now.Add(-time.Duration(days * 24 * float64(time.Hour))), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid since: %s", s)
+	}
+	return now.Add(-d), nil
+}
+
+// serveHistory returns the time series of a benchmark in the history
+// database, for the benchmark group and N in the querystring.  If N is
+// missing, every N is returned.  The series can be limited to the runs
+// stored since a time, and to those matching the selector in select.  Every
+// other parameter is a label that the runs must have, like branch=main.
+func serveHistory(db *historyDB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		benchmark := r.Form.Get("benchmark")
+		if benchmark == "" {
+			http.Error(w, "missing benchmark", http.StatusBadRequest)
+			return
+		}
+		n := math.NaN()
+		if v := r.Form.Get("n"); v != "" {
+			var err error
+			if n, err = strconv.ParseFloat(v, 64); err != nil {
+				http.Error(w, "invalid n: "+v, http.StatusBadRequest)
+				return
+			}
+		}
+		since, err := parseSince(r.Form.Get("since"), time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		yVar := r.Form.Get("yvar")
+		if yVar == "" {
+			yVar = "NsPerOp"
+		}
+		if _, ok := validYs[yVar]; !ok {
+			http.Error(w, "invalid yvar: "+yVar, http.StatusBadRequest)
+			return
+		}
+		nreValue := r.Form.Get("nre")
+		if nreValue == "" {
+			nreValue = defaultNRE
+		}
+		nre, err := compileNRE(nreValue)
+		if err != nil {
+			http.Error(w, "invalid nre: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		sel, err := parseSelector(r.Form.Get("select"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		labels := make(map[string]string)
+		for k := range r.Form {
+			if !historyParams[k] {
+				labels[k] = r.Form.Get(k)
+			}
+		}
+
+		runs, err := db.runs()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(history(selectRuns(runs, sel), benchmark, n, yVar, nre, since, labels))
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeRuns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := openHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := historySource{globSource(nil), db, nil}
+	h := serveRuns(db, src, newEventHub(), nil)
+
+	in := "goarch: amd64\nBenchmarkSort10-4\t1000000\t1008 ns/op\nBenchmarkSort100-4\t200000\t8224 ns/op\n"
+	req, err := http.NewRequest("POST", "/runs?commit=abc123&branch=main", strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	h(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d: %s", w.Code, w.Body)
+	}
+	var res struct{ ID string }
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil || res.ID == "" {
+		t.Fatalf("got response %+v, error %v", res, err)
+	}
+
+	// the run is stored, and plotted by its commit
+	runs, err := db.runs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].ID != res.ID {
+		t.Fatalf("got runs %+v, want %s", runs, res.ID)
+	}
+	want := map[string]string{"commit": "abc123", "branch": "main", "machine": "amd64"}
+	for k, v := range want {
+		if runs[0].Labels[k] != v {
+			t.Errorf("got label %s=%q, want %q", k, runs[0].Labels[k], v)
+		}
+	}
+	ds := src.dataSet()
+	if len(ds.Files) != 1 || ds.Files[0].Series != "abc123" || len(ds.Files[0].Benchmarks) != 2 {
+		t.Errorf("got data set %+v, want the stored run", ds)
+	}
+
+	for _, url := range []string{"/runs?bad-label=1", "/runs?commit=a&commit=b"} {
+		req, _ := http.NewRequest("POST", url, strings.NewReader(in))
+		w := httptest.NewRecorder()
+		h(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("got status %d for %s, want %d", w.Code, url, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestServeRunsWebhook(t *testing.T) {
+	got := make(chan webhookPayload, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Error(err)
+		}
+		got <- p
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := openHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := parseAnalysisQuery(url.Values{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &regressionCheck{q: q, threshold: 0.1, notifier: newNotifier([]string{ts.URL}, "")}
+	h := serveRuns(db, historySource{globSource(nil), db, nil}, newEventHub(), c)
+	post := func(commit string, scale float64) {
+		var in string
+		for _, n := range []int{10, 100, 1000, 10000} {
+			in += fmt.Sprintf("BenchmarkSort%d-4\t1000\t%g ns/op\n", n, scale*float64(n)+50)
+		}
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest("POST", "/runs?commit="+commit, strings.NewReader(in)))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("got status %d posting %s: %s", w.Code, commit, w.Body)
+		}
+	}
+
+	// neither the first run nor one as fast as it regresses
+	post("abc123", 10)
+	post("def456", 10)
+	post("fed789", 20)
+	select {
+	case p := <-got:
+		if p.Baseline != "def456" || p.Run != "fed789" || len(p.Regressions) != 1 || p.Regressions[0].Group != "BenchmarkSort" {
+			t.Errorf("got notification %+v, want BenchmarkSort regressing in fed789 against def456", p)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the regression wasn't posted to the webhook")
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d more notifications, want only the regression", len(got))
+	}
+}
+
+func TestHistory(t *testing.T) {
+	now := time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC)
+	var runs []storedRun
+	for i, branch := range []string{"main", "dev", "main"} {
+		in := fmt.Sprintf("BenchmarkSort10-4\t1000\t%d ns/op\nBenchmarkSort10-4\t1000\t%d ns/op\nBenchmarkSort100-4\t100\t5000 ns/op\n", 100+i, 110+i)
+		bf, err := parseBenchFile(strings.NewReader(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bf.Series = "ignored"
+		runs = append(runs, storedRun{
+			ID:     fmt.Sprint(i),
+			Time:   now.AddDate(0, 0, -10*(2-i)),
+			Labels: map[string]string{"branch": branch},
+			File:   bf,
+		})
+	}
+
+	since, err := parseSince("15d", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nre := regexp.MustCompile(defaultNRE)
+	got := history(runs, "Sort", 10, "NsPerOp", nre, since, map[string]string{"branch": "main"})
+	if len(got) != 1 || got[0].ID != "2" || got[0].Mean != 107 || got[0].Min != 102 || got[0].Max != 112 || got[0].Runs != 2 {
+		t.Errorf("got history %+v, want the mean of 102 and 112 from run 2", got)
+	}
+	if got := history(runs, "BenchmarkSort", math.NaN(), "NsPerOp", nre, time.Time{}, nil); len(got) != 6 {
+		t.Errorf("got %d points at every N, want 6", len(got))
+	}
+
+	for _, s := range []string{"2016-05-20", "2016-05-20T00:00:00Z", "12d", "288h"} {
+		if got, err := parseSince(s, now); err != nil || !got.Equal(now.AddDate(0, 0, -12)) {
+			t.Errorf("parseSince(%q) = %v, %v, want 2016-05-20", s, got, err)
+		}
+	}
+	if _, err := parseSince("yesterday", now); err == nil {
+		t.Errorf("parseSince(\"yesterday\") succeeded")
+	}
+}
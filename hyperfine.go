@@ -0,0 +1,84 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+// hyperfineFile is hyperfine's --export-json output.
+type hyperfineFile struct {
+	Results []hyperfineResult `json:"results"`
+}
+
+type hyperfineResult struct {
+	Command    string            `json:"command"`
+	Mean       *float64          `json:"mean"`
+	Times      []float64         `json:"times"` // of each run, in seconds
+	ExitCodes  []*int            `json:"exit_codes"`
+	Parameters map[string]string `json:"parameters"`
+}
+
+// parseHyperfine parses hyperfine's JSON output.  Each timed run of a
+// command is a run of its benchmark, or the mean if the times weren't
+// exported, and runs which failed are left out.  Commands from
+// --parameter-scan or --parameter-list are named with the parameter in
+// braces, followed by its value, so that
+//
+//	hyperfine --parameter-scan size 1000 4000 -D 1000 'sort -n data_{size}.txt'
+//
+// has the benchmarks "sort -n data_{size}.txt/size=1000-1" and so on, which
+// the default N pattern plots against size.
+func parseHyperfine(data []byte) (benchFile, bool, error) {
+	var f hyperfineFile
+	if err := json.Unmarshal(data, &f); err != nil || len(f.Results) == 0 || f.Results[0].Command == "" {
+		return benchFile{}, false, nil
+	}
+	bf := benchFile{Config: make(map[string]string)}
+	for i, res := range f.Results {
+		var params []string
+		for k := range res.Parameters {
+			params = append(params, k)
+		}
+		sort.Strings(params)
+		name := res.Command
+		for _, k := range params {
+			if v := res.Parameters[k]; v != "" {
+				name = strings.Replace(name, v, "{"+k+"}", -1)
+			}
+		}
+		for _, k := range params {
+			name += "/" + k + "=" + res.Parameters[k]
+		}
+		name += "-1"
+
+		times := res.Times
+		if len(times) == 0 && res.Mean != nil {
+			times = []float64{*res.Mean}
+		}
+		failed := 0
+		for j, t := range times {
+			if j < len(res.ExitCodes) && res.ExitCodes[j] != nil && *res.ExitCodes[j] != 0 {
+				failed++
+				continue
+			}
+			bf.Benchmarks = append(bf.Benchmarks, &benchmark{Benchmark: parse.Benchmark{
+				Name:     name,
+				N:        1,
+				NsPerOp:  t * 1e9,
+				Measured: parse.NsPerOp,
+				Ord:      len(bf.Benchmarks),
+			}})
+		}
+		if failed > 0 {
+			bf.Warnings = append(bf.Warnings, parseWarning{i + 1, res.Command, "left out runs with a non-zero exit code"})
+		}
+	}
+	return bf, true, nil
+}
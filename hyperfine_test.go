@@ -0,0 +1,52 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const hyperfineJSON = `{
+  "results": [
+    {
+      "command": "sort -n data_1000.txt",
+      "mean": 0.0015,
+      "times": [0.001, 0.002, 0.003],
+      "exit_codes": [0, 0, 1],
+      "parameters": {"size": "1000"}
+    },
+    {
+      "command": "sort -n data_2000.txt",
+      "mean": 0.004,
+      "times": [0.004],
+      "exit_codes": [0],
+      "parameters": {"size": "2000"}
+    },
+    {
+      "command": "make",
+      "mean": 2.5
+    }
+  ]
+}`
+
+func TestParseHyperfine(t *testing.T) {
+	bf, err := parseBenchFile(strings.NewReader(hyperfineJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bf.Benchmarks) != 4 || len(bf.Warnings) != 1 {
+		t.Fatalf("got %d benchmarks and warnings %v, want 4 and one for the failed run", len(bf.Benchmarks), bf.Warnings)
+	}
+	if b := bf.Benchmarks[3]; b.Name != "make-1" || b.NsPerOp != 2.5e9 {
+		t.Errorf("got %s at %g ns/op, want make-1 at its mean", b.Name, b.NsPerOp)
+	}
+	groups, _, _, _ := groupBenchmarks(dataSet{Files: []benchFile{bf}}, grouping{nre: regexp.MustCompile(defaultNRE)})
+	benchSet := groups["sort -n data_{size}.txt/size="]
+	if len(benchSet) != 3 || benchSet[0].X != 1000 || benchSet[2].X != 2000 || benchSet[2].NsPerOp != 4e6 {
+		t.Errorf("got groups %v, want the sort runs by size", groups)
+	}
+}
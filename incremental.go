@@ -0,0 +1,207 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"hash"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/jonlawlor/parsefloat"
+)
+
+// streamingQR is the QR factorization of X, which is updated a row at a time
+// with Givens rotations, along with Q'y and the residual sum of squares.  A
+// least squares fit can be found from it in O(p²), so while benchmarks are
+// streaming in, each refit only has to add the new ones rather than start
+// from scratch.
+type streamingQR struct {
+	p        int
+	n        int
+	r        []float64 // p×p upper triangular R, row major
+	qty      []float64 // the first p elements of Q'y
+	rss, yss kahan
+}
+
+func newStreamingQR(p int) *streamingQR {
+	return &streamingQR{p: p, r: make([]float64, p*p), qty: make([]float64, p)}
+}
+
+// add adds the rows of a sample to the factorization.
+func (q *streamingQR) add(s samp) {
+	row := make([]float64, q.p)
+	for i, y := range s.y {
+		copy(row, s.x[i*q.p:(i+1)*q.p])
+		for k := 0; k < q.p; k++ {
+			if row[k] == 0 {
+				continue
+			}
+			// rotate the row into row k of R, zeroing its kth element
+			rkk := q.r[k*q.p+k]
+			h := math.Hypot(rkk, row[k])
+			c, s := rkk/h, row[k]/h
+			for j := k; j < q.p; j++ {
+				rkj := q.r[k*q.p+j]
+				q.r[k*q.p+j] = c*rkj + s*row[j]
+				row[j] = c*row[j] - s*rkj
+			}
+			q.qty[k], y = c*q.qty[k]+s*y, c*y-s*q.qty[k]
+		}
+		// what is left of y is orthogonal to every column of X
+		q.rss.add(y * y)
+		q.yss.add(s.y[i] * s.y[i])
+		q.n++
+	}
+}
+
+// fit returns the least squares fit, with the same statistics as olsFitter.
+// It returns false if there are too few rows, or if X is so close to rank
+// deficient that the minimum norm fit of olsFitter is needed.
+func (q *streamingQR) fit() (model, fitStats, bool) {
+	p := q.p
+	if q.n <= p {
+		return nil, fitStats{}, false
+	}
+	largest := 0.0
+	for k := 0; k < p; k++ {
+		largest = math.Max(largest, math.Abs(q.r[k*p+k]))
+	}
+	for k := 0; k < p; k++ {
+		if math.Abs(q.r[k*p+k]) <= float64(q.n)*epsilon*largest {
+			return nil, fitStats{}, false
+		}
+	}
+
+	// beta = R^-1 Q'y, and (X'X)^-1 = R^-1 R^-T
+	rInv := make([]float64, p*p)
+	for j := p - 1; j >= 0; j-- {
+		rInv[j*p+j] = 1 / q.r[j*p+j]
+		for i := j - 1; i >= 0; i-- {
+			var v float64
+			for k := i + 1; k <= j; k++ {
+				v += q.r[i*p+k] * rInv[k*p+j]
+			}
+			rInv[i*p+j] = -v / q.r[i*p+i]
+		}
+	}
+	beta := make(model, p)
+	for i := range beta {
+		beta[i] = dot(rInv[i*p:(i+1)*p], q.qty)
+	}
+	data := make([]float64, p*p)
+	for i := 0; i < p; i++ {
+		for j := 0; j < p; j++ {
+			data[i*p+j] = dot(rInv[i*p:(i+1)*p], rInv[j*p:(j+1)*p])
+		}
+	}
+
+	st := fitStats{
+		r2:   1 - q.rss.value()/q.yss.value(),
+		mse:  q.rss.value() / float64(q.n-p),
+		cint: make([]float64, p),
+		iXTX: mat64.NewDense(p, p, data),
+	}
+	for i := range st.cint {
+		st.cint[i] = conf95(math.Sqrt(data[i*p+i]*st.mse), q.n-p)
+	}
+	return beta, st, true
+}
+
+// streamFitter fits with a streamingQR that already has every row of the
+// sample, and falls back to olsFitter if it can't.
+type streamFitter struct {
+	qr *streamingQR
+}
+
+func (f streamFitter) Fit(s samp) (model, fitStats, error) {
+	if beta, st, ok := f.qr.fit(); ok {
+		return beta, st, nil
+	}
+	return olsFitter{}.Fit(s)
+}
+
+// maxStreams is the most factorizations which are kept for a plotter.
+const maxStreams = 256
+
+// fitStreams are the factorizations of the fits which a plotter has asked
+// for, keyed by the model and response, and a digest of the benchmarks which
+// were fit, so that a fit of the same benchmarks with more appended to them
+// only has to add the new ones.
+type fitStreams struct {
+	mu      sync.Mutex
+	streams map[string]*streamingQR
+}
+
+func newFitStreams() *fitStreams {
+	return &fitStreams{streams: make(map[string]*streamingQR)}
+}
+
+// fitter returns the estimator to use for a fit request.  Fits by ordinary
+// least squares, of models without K and without clustered intervals, are
+// made with a streamingQR, which is extended from the longest prefix of the
+// benchmarks that was fit before.  Other fits use the request's estimator.
+func (ss *fitStreams) fitter(req fitRequest, benchSet []benchmarkResponse) estimator {
+	if ss == nil || benchClusters(benchSet) != nil {
+		return req.fitter
+	}
+	if _, ok := req.fitter.(olsFitter); !ok {
+		return req.fitter
+	}
+	terms := make([]string, len(req.xTransform))
+	for i, x := range req.xTransform {
+		if kRE.MatchString(x.String()) {
+			return req.fitter
+		}
+		terms[i] = x.String()
+	}
+	prefix := req.yVar + "\x00" + strings.Join(terms, "\x00") + "\x00"
+
+	// the digest of every prefix of the benchmarks
+	digests := make([]string, len(benchSet)+1)
+	h := sha256.New()
+	digests[0] = streamKey(prefix, h)
+	for i, b := range benchSet {
+		enc, _ := json.Marshal(b)
+		h.Write(enc)
+		digests[i+1] = streamKey(prefix, h)
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	n := len(benchSet)
+	qr, ok := ss.streams[digests[n]]
+	if !ok {
+		for n = len(benchSet) - 1; n > 0; n-- {
+			if qr, ok = ss.streams[digests[n]]; ok {
+				break
+			}
+		}
+		if !ok {
+			qr = newStreamingQR(len(req.xTransform))
+		}
+		qr.add(finiteSample(benchSet[n:], req.xTransform, req.yVar))
+		delete(ss.streams, digests[n])
+		if len(ss.streams) >= maxStreams {
+			ss.streams = make(map[string]*streamingQR)
+		}
+		ss.streams[digests[len(benchSet)]] = qr
+	}
+	return streamFitter{qr}
+}
+
+func streamKey(prefix string, h hash.Hash) string {
+	return prefix + string(h.Sum(nil))
+}
+
+// finiteSample is the sample of the benchmarks where the terms are finite,
+// which are the ones that fit uses.
+func finiteSample(benchSet []benchmarkResponse, xTransform []parsefloat.Expression, yVar string) samp {
+	benchSet, _ = finiteTerms(benchSet, xTransform)
+	return sampleGroup(benchSet, xTransform, yVar)
+}
@@ -0,0 +1,113 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"net/url"
+	"testing"
+
+	"github.com/jonlawlor/parsefloat"
+	"golang.org/x/tools/benchmark/parse"
+)
+
+// quadraticBenchSet returns noisy benchmarks of a quadratic, with n of them.
+func quadraticBenchSet(n int) []benchmarkResponse {
+	var benchSet []benchmarkResponse
+	for i := 0; i < n; i++ {
+		x := 16 * math.Pow(2, float64(i%8))
+		noise := float64((i*7919)%13) - 6
+		benchSet = append(benchSet, benchmarkResponse{Benchmark: parse.Benchmark{NsPerOp: 0.5*x*x + 30*x + 1000 + noise}, X: x})
+	}
+	return benchSet
+}
+
+func TestStreamingQR(t *testing.T) {
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"N * N, N, 1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sampleGroup(quadraticBenchSet(40), q.xTransform, q.yVar)
+	want, wantSt, err := olsFitter{}.Fit(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// add the rows a few at a time
+	qr := newStreamingQR(len(q.xTransform))
+	for i := 0; i < len(s.y); i += 7 {
+		j := i + 7
+		if j > len(s.y) {
+			j = len(s.y)
+		}
+		qr.add(samp{x: s.x[i*3 : j*3], y: s.y[i:j]})
+	}
+	got, st, ok := qr.fit()
+	if !ok {
+		t.Fatal("unable to fit")
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) <= 1e-6*math.Max(math.Abs(a), math.Abs(b)) }
+	for i := range want {
+		if !near(got[i], want[i]) || !near(st.cint[i], wantSt.cint[i]) {
+			t.Errorf("term %d: got %v ± %v, want %v ± %v", i, got[i], st.cint[i], want[i], wantSt.cint[i])
+		}
+		for j := range want {
+			if !near(st.iXTX.At(i, j), wantSt.iXTX.At(i, j)) {
+				t.Errorf("(X'X)^-1 at %d, %d: got %v, want %v", i, j, st.iXTX.At(i, j), wantSt.iXTX.At(i, j))
+			}
+		}
+	}
+	if !near(st.mse, wantSt.mse) || !near(st.r2, wantSt.r2) {
+		t.Errorf("got mse %v and r2 %v, want %v and %v", st.mse, st.r2, wantSt.mse, wantSt.r2)
+	}
+
+	// collinear terms need the minimum norm fit
+	qr = newStreamingQR(2)
+	qr.add(sampleGroup(quadraticBenchSet(10), mustParseTransform(t, "N, 2 * N"), q.yVar))
+	if _, _, ok := qr.fit(); ok {
+		t.Errorf("fit a rank deficient model")
+	}
+}
+
+func TestFitStreams(t *testing.T) {
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"N * N, N, 1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := fitRequest{xTransform: q.xTransform, yVar: q.yVar, fitter: q.fitter}
+	benchSet := quadraticBenchSet(30)
+	ss := newFitStreams()
+	first, ok := ss.fitter(req, benchSet[:20]).(streamFitter)
+	if !ok {
+		t.Fatal("an ordinary least squares fit isn't streamed")
+	}
+
+	// more benchmarks are added to the same factorization
+	next := ss.fitter(req, benchSet).(streamFitter)
+	if next.qr != first.qr || next.qr.n != len(benchSet) {
+		t.Errorf("got a factorization of %d benchmarks, want the first one extended to %d", next.qr.n, len(benchSet))
+	}
+
+	// but benchmarks which were changed are fit from scratch
+	changed := append([]benchmarkResponse(nil), benchSet...)
+	changed[0].NsPerOp++
+	if again := ss.fitter(req, changed).(streamFitter); again.qr == first.qr {
+		t.Errorf("changed benchmarks were added to the old factorization")
+	}
+
+	// other fitters aren't streamed
+	req.fitter = fitters["huber"]
+	if _, ok := ss.fitter(req, benchSet).(streamFitter); ok {
+		t.Errorf("a huber fit was streamed")
+	}
+}
+
+func mustParseTransform(t *testing.T, xTransform string) []parsefloat.Expression {
+	x, err := parseTransform(xTransform)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
@@ -0,0 +1,59 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/ioutil"
+	"unicode"
+)
+
+// jsonFormats parse the JSON output of benchmark harnesses other than go
+// test, in the order that they are tried.  Each returns false if the
+// document isn't in its format.  Their benchmarks are named like go's, with
+// a -P suffix, so that they are grouped by the default N pattern.
+var jsonFormats = []func(data []byte) (benchFile, bool, error){
+	parseGoogleBenchmark,
+	parseJMH,
+	parseHyperfine,
+	parsePytestBenchmark,
+}
+
+// errUnknownJSON is returned for JSON which isn't in any of the jsonFormats.
+var errUnknownJSON = errors.New("unrecognized JSON benchmark format")
+
+// isJSON reports whether the first character of rd, other than white space,
+// starts a JSON object or array, without consuming it.
+func isJSON(rd *bufio.Reader) bool {
+	for n := 1; ; n++ {
+		b, err := rd.Peek(n)
+		if len(b) < n {
+			return false
+		}
+		c := rune(b[n-1])
+		if !unicode.IsSpace(c) {
+			return c == '{' || c == '['
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+// parseJSONBenchFile parses a JSON document in one of the jsonFormats.
+func parseJSONBenchFile(r io.Reader) (benchFile, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return benchFile{Config: make(map[string]string)}, err
+	}
+	for _, parse := range jsonFormats {
+		if bf, ok, err := parse(data); ok {
+			return bf, err
+		}
+	}
+	return benchFile{Config: make(map[string]string)}, errUnknownJSON
+}
@@ -0,0 +1,131 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+// jmhResult is a single benchmark in JMH's JSON results, from -rf json.
+type jmhResult struct {
+	JMHVersion       string               `json:"jmhVersion"`
+	Benchmark        string               `json:"benchmark"`
+	Mode             string               `json:"mode"` // thrpt, avgt, sample, or ss
+	Threads          int                  `json:"threads"`
+	VMName           string               `json:"vmName"`
+	JDKVersion       string               `json:"jdkVersion"`
+	Params           map[string]string    `json:"params"`
+	PrimaryMetric    *jmhMetric           `json:"primaryMetric"`
+	SecondaryMetrics map[string]jmhMetric `json:"secondaryMetrics"`
+}
+
+type jmhMetric struct {
+	Score     float64     `json:"score"`
+	ScoreUnit string      `json:"scoreUnit"`
+	RawData   [][]float64 `json:"rawData"` // each measurement iteration, by fork
+}
+
+// jmhAllocMetric is the secondary metric of the bytes allocated per op, from
+// -prof gc.
+const jmhAllocMetric = "·gc.alloc.rate.norm"
+
+// parseJMH parses JMH's JSON results.  Each measurement iteration is a run
+// of its benchmark, so that their spread is plotted, and throughputs like
+// ops/ms are converted to ns/op.  A benchmark like
+// org.example.SortBench.sort with @Param size=1024, run on 4 threads, is
+// named sort/size=1024-4, in the package org.example.SortBench.  Params are
+// in alphabetical order.  The bytes allocated per op are included if the
+// benchmarks were run with -prof gc.
+func parseJMH(data []byte) (benchFile, bool, error) {
+	var results []jmhResult
+	if err := json.Unmarshal(data, &results); err != nil || len(results) == 0 || results[0].JMHVersion == "" || results[0].PrimaryMetric == nil {
+		return benchFile{}, false, nil
+	}
+	bf := benchFile{Config: make(map[string]string)}
+	for key, v := range map[string]string{
+		"jmh": results[0].JMHVersion,
+		"vm":  results[0].VMName,
+		"jdk": results[0].JDKVersion,
+	} {
+		if v != "" {
+			bf.Config[key] = v
+		}
+	}
+
+	for i, res := range results {
+		if res.PrimaryMetric == nil {
+			continue
+		}
+		nsPerOp, ok := jmhNsPerOp(res.PrimaryMetric.ScoreUnit)
+		if !ok {
+			bf.Warnings = append(bf.Warnings, parseWarning{i + 1, res.Benchmark, "unknown unit " + res.PrimaryMetric.ScoreUnit})
+			continue
+		}
+		pkg, name := "", res.Benchmark
+		if j := strings.LastIndex(name, "."); j >= 0 {
+			pkg, name = name[:j], name[j+1:]
+		}
+		var params []string
+		for k := range res.Params {
+			params = append(params, k)
+		}
+		sort.Strings(params)
+		for _, k := range params {
+			name += "/" + k + "=" + res.Params[k]
+		}
+		threads := res.Threads
+		if threads < 1 {
+			threads = 1
+		}
+		name += "-" + strconv.Itoa(threads)
+
+		scores := []float64{res.PrimaryMetric.Score}
+		if len(res.PrimaryMetric.RawData) > 0 {
+			scores = nil
+			for _, fork := range res.PrimaryMetric.RawData {
+				scores = append(scores, fork...)
+			}
+		}
+		for _, score := range scores {
+			b := parse.Benchmark{
+				Name:     name,
+				N:        1,
+				NsPerOp:  nsPerOp(score),
+				Measured: parse.NsPerOp,
+				Ord:      len(bf.Benchmarks),
+			}
+			if m, ok := res.SecondaryMetrics[jmhAllocMetric]; ok {
+				b.AllocedBytesPerOp = uint64(math.Max(0, math.Floor(m.Score+0.5)))
+				b.Measured |= parse.AllocedBytesPerOp
+			}
+			bf.Benchmarks = append(bf.Benchmarks, &benchmark{Benchmark: b, Package: pkg})
+		}
+	}
+	return bf, true, nil
+}
+
+// jmhNsPerOp returns the conversion from a score in the unit, like us/op or
+// ops/ms, to ns/op.
+func jmhNsPerOp(unit string) (func(score float64) float64, bool) {
+	parts := strings.Split(unit, "/")
+	if len(parts) != 2 {
+		return nil, false
+	}
+	if parts[1] == "op" {
+		scale, ok := timeUnits[parts[0]]
+		return func(score float64) float64 { return score * scale }, ok
+	}
+	if parts[0] == "ops" {
+		scale, ok := timeUnits[parts[1]]
+		return func(score float64) float64 { return scale / score }, ok
+	}
+	return nil, false
+}
@@ -0,0 +1,64 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const jmhJSON = `[
+  {
+    "jmhVersion": "1.36",
+    "benchmark": "org.example.SortBench.sort",
+    "mode": "avgt",
+    "threads": 1,
+    "vmName": "OpenJDK 64-Bit Server VM",
+    "params": {"size": "1024", "algo": "quick"},
+    "primaryMetric": {"score": 12.5, "scoreUnit": "us/op", "rawData": [[12.0, 13.0], [12.5]]},
+    "secondaryMetrics": {"·gc.alloc.rate.norm": {"score": 4120.2, "scoreUnit": "B/op"}}
+  },
+  {
+    "jmhVersion": "1.36",
+    "benchmark": "org.example.SortBench.copy",
+    "mode": "thrpt",
+    "threads": 4,
+    "primaryMetric": {"score": 2000, "scoreUnit": "ops/ms"}
+  },
+  {
+    "jmhVersion": "1.36",
+    "benchmark": "org.example.SortBench.odd",
+    "mode": "avgt",
+    "primaryMetric": {"score": 1, "scoreUnit": "furlongs"}
+  }
+]`
+
+func TestParseJMH(t *testing.T) {
+	bf, err := parseBenchFile(strings.NewReader(jmhJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bf.Benchmarks) != 4 || len(bf.Warnings) != 1 {
+		t.Fatalf("got %d benchmarks and warnings %v, want 4 and 1", len(bf.Benchmarks), bf.Warnings)
+	}
+	for i, want := range []struct {
+		name    string
+		nsPerOp float64
+		bytes   uint64
+	}{
+		{"sort/algo=quick/size=1024-1", 12000, 4120},
+		{"sort/algo=quick/size=1024-1", 13000, 4120},
+		{"sort/algo=quick/size=1024-1", 12500, 4120},
+		{"copy-4", 500, 0},
+	} {
+		b := bf.Benchmarks[i]
+		if b.Name != want.name || b.NsPerOp != want.nsPerOp || b.AllocedBytesPerOp != want.bytes || b.Package != "org.example.SortBench" {
+			t.Errorf("got %s.%s at %g ns/op and %d B/op, want %s at %g ns/op and %d B/op", b.Package, b.Name, b.NsPerOp, b.AllocedBytesPerOp, want.name, want.nsPerOp, want.bytes)
+		}
+	}
+	if bf.Config["vm"] != "OpenJDK 64-Bit Server VM" {
+		t.Errorf("got configuration %v", bf.Config)
+	}
+}
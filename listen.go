@@ -0,0 +1,78 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// unixPrefix marks an address as the path of a unix socket.
+const unixPrefix = "unix:"
+
+// listen listens on each of the comma separated addresses, which are either
+// TCP addresses like ":6060", or unix sockets like "unix:/tmp/benchplot.sock".
+// If any of the addresses can't be listened on, the listeners which were
+// already opened are closed.
+func listen(addrs string) ([]net.Listener, error) {
+	var lns []net.Listener
+	for _, addr := range strings.Split(addrs, ",") {
+		addr = strings.TrimSpace(addr)
+		ln, err := listenAddr(addr)
+		if err != nil {
+			for _, ln := range lns {
+				ln.Close()
+			}
+			return nil, fmt.Errorf("listen %s: %v", addr, err)
+		}
+		lns = append(lns, ln)
+	}
+	return lns, nil
+}
+
+func listenAddr(addr string) (net.Listener, error) {
+	if !strings.HasPrefix(addr, unixPrefix) {
+		return net.Listen("tcp", addr)
+	}
+	path := strings.TrimPrefix(addr, unixPrefix)
+
+	// Remove a stale socket left behind by a previous instance, but don't
+	// clobber anything else.
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	return net.Listen("unix", path)
+}
+
+// listenerURL returns the URL that a listener can be reached at, with the
+// port that was chosen for it if it was listening on port 0.  Listeners on
+// every interface are reached at localhost.
+func listenerURL(ln net.Listener) string {
+	addr, ok := ln.Addr().(*net.TCPAddr)
+	if !ok {
+		return unixPrefix + ln.Addr().String()
+	}
+	host := "localhost"
+	if !addr.IP.IsUnspecified() {
+		host = addr.IP.String()
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(addr.Port)) + "/"
+}
+
+// serve serves handler on all of the listeners, and returns the first error
+// encountered by any of them.
+func serve(lns []net.Listener, handler http.Handler) error {
+	errc := make(chan error, len(lns))
+	for _, ln := range lns {
+		go func(ln net.Listener) {
+			errc <- http.Serve(ln, handler)
+		}(ln)
+	}
+	return <-errc
+}
@@ -0,0 +1,122 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListenPortZero(t *testing.T) {
+	lns, err := listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lns[0].Close()
+	u := listenerURL(lns[0])
+	if !strings.HasPrefix(u, "http://127.0.0.1:") || strings.HasSuffix(u, ":0/") {
+		t.Fatalf("got %s, want the chosen port", u)
+	}
+	go http.Serve(lns[0], http.HandlerFunc(healthzHandleFunc))
+	resp, err := http.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got status %d from %s", resp.StatusCode, u)
+	}
+}
+
+func TestListen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sock := filepath.Join(dir, "benchplot.sock")
+	stale := filepath.Join(dir, "stale.sock")
+	if ln, err := net.Listen("unix", stale); err != nil {
+		t.Skip(err)
+	} else {
+		// leave the socket file behind, like a previous instance would
+		ln.(*net.UnixListener).SetUnlinkOnClose(false)
+		ln.Close()
+	}
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, []byte("not a socket"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		addrs string
+		urls  []string // prefixes of the urls, or nil if it fails
+	}{
+		{"127.0.0.1:0", []string{"http://127.0.0.1:"}},
+		{"127.0.0.1:0, 127.0.0.1:0", []string{"http://127.0.0.1:", "http://127.0.0.1:"}},
+		{"127.0.0.1:0,unix:" + sock, []string{"http://127.0.0.1:", "unix:" + sock}},
+		{"unix:" + stale, []string{"unix:" + stale}},
+		// anything else at the path isn't removed
+		{"unix:" + file, nil},
+		{"127.0.0.1:0,127.0.0.1:x", nil},
+	}
+	for _, test := range tests {
+		lns, err := listen(test.addrs)
+		if test.urls == nil {
+			if err == nil {
+				t.Errorf("%s: listened, want an error", test.addrs)
+				for _, ln := range lns {
+					ln.Close()
+				}
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.addrs, err)
+			continue
+		}
+		if len(lns) != len(test.urls) {
+			t.Errorf("%s: got %d listeners, want %d", test.addrs, len(lns), len(test.urls))
+		}
+		go serve(lns, http.HandlerFunc(healthzHandleFunc))
+		for i, ln := range lns {
+			u := listenerURL(ln)
+			if i >= len(test.urls) || !strings.HasPrefix(u, test.urls[i]) {
+				t.Errorf("%s: got url %s", test.addrs, u)
+				continue
+			}
+			client := http.Client{}
+			if strings.HasPrefix(u, unixPrefix) {
+				path := strings.TrimPrefix(u, unixPrefix)
+				client.Transport = &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", path)
+				}}
+				u = "http://benchplot/"
+			}
+			resp, err := client.Get(u)
+			if err != nil {
+				t.Errorf("%s: %v", test.addrs, err)
+				continue
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("%s: got status %d from %s", test.addrs, resp.StatusCode, u)
+			}
+		}
+		for _, ln := range lns {
+			ln.Close()
+		}
+	}
+	if b, err := ioutil.ReadFile(file); err != nil || string(b) != "not a socket" {
+		t.Errorf("got %q, %v, want the file to be left alone", b, err)
+	}
+}
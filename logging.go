@@ -0,0 +1,111 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Formats of the log.
+const (
+	logText = "text"
+	logJSON = "json"
+)
+
+// newLogger returns a logger writing to stderr in the format, text or json,
+// which logs messages at the level, one of debug, info, warn, or error, and
+// above.
+func newLogger(level, format string) (*slog.Logger, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level: %s", level)
+	}
+	opts := &slog.HandlerOptions{Level: l}
+	switch strings.ToLower(format) {
+	case logText:
+		return slog.New(slog.NewTextHandler(os.Stderr, opts)), nil
+	case logJSON:
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts)), nil
+	}
+	return nil, fmt.Errorf("invalid log format: %s", format)
+}
+
+// debugEnabled reports whether debug messages are logged.
+func debugEnabled() bool {
+	return slog.Default().Enabled(context.Background(), slog.LevelDebug)
+}
+
+// fatal logs the error and exits.
+func fatal(v ...interface{}) {
+	slog.Error(fmt.Sprint(v...))
+	os.Exit(1)
+}
+
+// fatalf logs the formatted error and exits.
+func fatalf(format string, v ...interface{}) {
+	slog.Error(fmt.Sprintf(format, v...))
+	os.Exit(1)
+}
+
+// loggingHandler logs every request at the debug level, once it has been
+// served.
+func loggingHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sw, req)
+		slog.Debug("request",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"query", req.URL.RawQuery,
+			"remote", req.RemoteAddr,
+			"status", sw.status,
+			"bytes", sw.bytes,
+			"duration", time.Since(start))
+	})
+}
+
+// statusWriter records the status and size of a response.  It can still be
+// hijacked, for the websocket, and flushed.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	n, err := sw.ResponseWriter.Write(b)
+	sw.bytes += n
+	return n, err
+}
+
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (sw *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := sw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response can't be hijacked")
+	}
+	sw.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
@@ -0,0 +1,48 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoggingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	h := loggingHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusTeapot)
+	}))
+	req, err := http.NewRequest("GET", "/fit?xlb=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var rec map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("%v in %q", err, buf.String())
+	}
+	for k, want := range map[string]interface{}{"level": "DEBUG", "method": "GET", "path": "/fit", "query": "xlb=1", "status": float64(http.StatusTeapot)} {
+		if rec[k] != want {
+			t.Errorf("got %s %v, want %v", k, rec[k], want)
+		}
+	}
+	if _, ok := rec["duration"]; !ok {
+		t.Errorf("missing duration in %v", rec)
+	}
+
+	for _, test := range []struct{ level, format string }{{"verbose", logText}, {"info", "xml"}} {
+		if _, err := newLogger(test.level, test.format); err == nil {
+			t.Errorf("newLogger(%q, %q) succeeded", test.level, test.format)
+		}
+	}
+}
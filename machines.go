@@ -0,0 +1,118 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+)
+
+// machineComparison compares the fits of the same groups of benchmarks on two
+// machines, term by term, so that "B is 1.8x slower on the N log N term" can
+// be read off directly.
+type machineComparison struct {
+	A, B   string // the machines
+	Terms  []string
+	Groups []machineRatio
+}
+
+// machineRatio is the comparison of a single group which was run on both
+// machines.  ComplexityRegression is set if the group's complexity class is
+// higher on B than on A, even if B is faster for small N.
+type machineRatio struct {
+	Group string
+	Terms []termRatio
+
+	ComplexityA, ComplexityB string
+	ComplexityRegression     bool
+}
+
+// termRatio compares the coefficient of one term on both machines.  Ratio is
+// B's coefficient over A's, or 0 if A's is 0, and RatioInt is the half width
+// of its approximate 95% confidence interval.
+type termRatio struct {
+	A, B       float64
+	AInt, BInt float64
+	Ratio      float64
+	RatioInt   float64
+}
+
+// compareMachines fits every group on machines a and b, and compares the
+// groups which could be fit on both.
+func compareMachines(q analysisQuery, ds dataSet, a, b string) machineComparison {
+	mc := machineComparison{A: a, B: b, Groups: []machineRatio{}}
+	for _, x := range q.xTransform {
+		mc.Terms = append(mc.Terms, x.String())
+	}
+
+	// each machine is fit separately, so the groups have the same names on
+	// both of them.
+	q.grouping.byMachine = false
+	qa, qb := q, q
+	qa.grouping.machine, qb.grouping.machine = a, b
+	fitsA := make(map[string]groupFit)
+	for _, gf := range qa.fitGroups(ds) {
+		fitsA[gf.Group] = gf
+	}
+	for _, gb := range qb.fitGroups(ds) {
+		ga, ok := fitsA[gb.Group]
+		if !ok {
+			continue
+		}
+		mr := machineRatio{
+			Group:       gb.Group,
+			ComplexityA: complexity(ga.benchSet, q.yVar),
+			ComplexityB: complexity(gb.benchSet, q.yVar),
+		}
+		mr.ComplexityRegression = complexityRegression(mr.ComplexityA, mr.ComplexityB)
+		for i := range ga.beta {
+			mr.Terms = append(mr.Terms, ratio(ga.beta[i], ga.bint[i], gb.beta[i], gb.bint[i]))
+		}
+		mc.Groups = append(mc.Groups, mr)
+	}
+	return mc
+}
+
+// ratio compares two coefficients.  The confidence interval of the ratio is
+// from the delta method, which assumes that the coefficients are independent
+// and not too close to 0.
+func ratio(a, aInt, b, bInt float64) termRatio {
+	tr := termRatio{A: a, B: b, AInt: aInt, BInt: bInt}
+	if a == 0 {
+		return tr
+	}
+	tr.Ratio = b / a
+	relA, relB := aInt/a, 0.0
+	if b != 0 {
+		relB = bInt / b
+	}
+	tr.RatioInt = math.Abs(tr.Ratio) * math.Hypot(relA, relB)
+	return tr
+}
+
+// serveMachineComparisons fits the groups with the settings in the
+// querystring on each of the machines a and b, and returns the ratios of
+// their coefficients.
+func serveMachineComparisons(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q, err := parseAnalysisQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		a, b := r.Form.Get("a"), r.Form.Get("b")
+		if a == "" || b == "" {
+			http.Error(w, "both machines a and b are required", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(compareMachines(q, src.dataSet(), a, b))
+	}
+}
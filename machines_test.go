@@ -0,0 +1,50 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCompareMachines(t *testing.T) {
+	// the arm64 machine is 1.8 times slower
+	var in []string
+	for _, m := range []struct {
+		goarch string
+		slope  float64
+	}{{"amd64", 2}, {"arm64", 3.6}} {
+		in = append(in, "goarch: "+m.goarch)
+		for n := 10; n <= 1000; n *= 10 {
+			for i := 0; i < 3; i++ {
+				ns := m.slope*float64(n) + 50 + float64(i)
+				in = append(in, fmt.Sprintf("BenchmarkFoo%d-4\t1000\t%g ns/op", n, ns))
+			}
+		}
+	}
+	bf, err := parseBenchFile(strings.NewReader(strings.Join(in, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"N, 1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mc := compareMachines(q, dataSet{Files: []benchFile{bf}}, "amd64", "arm64")
+	if len(mc.Groups) != 1 || mc.Groups[0].Group != "BenchmarkFoo" {
+		t.Fatalf("got groups %+v, want BenchmarkFoo", mc.Groups)
+	}
+	slope := mc.Groups[0].Terms[0]
+	if math.Abs(slope.Ratio-1.8) > 1e-3 || slope.RatioInt <= 0 || slope.RatioInt > 0.01 {
+		t.Errorf("got ratio %g ± %g, want 1.8", slope.Ratio, slope.RatioInt)
+	}
+
+	if tr := ratio(0, 1, 2, 1); tr.Ratio != 0 || tr.RatioInt != 0 {
+		t.Errorf("got ratio %+v with a zero coefficient", tr)
+	}
+}
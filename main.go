@@ -11,6 +11,48 @@
 // The input bench.txt file(s) should contain the output of a number of runs of
 // ``go test -bench.'' Benchmarks that match the regexp in the ``vars'' flag
 // will be collected into a sample for fitting a least squares regression.
+// The output of other benchmark harnesses can be plotted as well: Google
+// Benchmark's --benchmark_format=json, JMH's -rf json, hyperfine's
+// --export-json, where --parameter-scan is N, pytest-benchmark's
+// --benchmark-json, and Criterion's raw.csv or estimates.json, which
+// -dir=target/criterion -include='**/new/raw.csv' reads.
+//
+// Hardware counters from ``perf stat -x,'' can be plotted and fit as the
+// instructions, cache-misses, and branch-misses per op.  The perf stat lines
+// can follow the benchmark that they counted in the file, or be in a sidecar
+// file like bench.txt.perf, where each line is the benchmark's name, a comma,
+// and a line of perf stat output.  The counts are divided by the benchmark's
+// iterations, so they include the runs that go test uses to choose them.
+// Benchmarks which weren't counted are left out of the plots and fits of a
+// counter, and out of its summary of repeated runs, rather than being
+// treated as zero.
+//
+// Notes about a run, like its environment and flags, can be kept in a
+// sidecar file like bench.txt.meta.yaml, which has keys of plain text or
+// lists, nested by indenting them.  They're shown above the plot, in the
+// tooltips of the file's benchmarks, and in reports.
+//
+// The plotted groups can be narrowed by typing in the filter box, which
+// matches the groups containing it, ignoring case, or which match it as a
+// regexp.  The analysis endpoints take it as filter=, with filterre=true for
+// a regexp.
+//
+// /table lists every parsed benchmark in an HTML table, with its N, ns/op,
+// B/op, allocs/op, and file, which can be sorted by any of them and filtered
+// by name.  It's an alternative to the plot, and shows how the files were
+// parsed.
+//
+// A single chart can be embedded in another page, like a wiki, in an iframe
+// of /embed, which only shows the chart.  It's configured by the querystring,
+// with file= for each file to plot, by its path or name, group= for the one
+// group to plot, filter=, yvar=, nre=, xtransform= or model=, and fitter=.
+// It starts from the settings of a saved view with view=name.
+//
+// When many families of benchmarks are loaded, /dashboard shows a small
+// chart of each group and its fit, rather than piling them into one plot.
+// Each chart links to the plotter with ?group=, which only plots that group.
+// The dashboard takes the same settings in its querystring as the analysis
+// endpoints, or view=name for those of a saved view.
 //
 // Example
 //
@@ -41,25 +83,221 @@
 // the relationship between the number of elements to sort and how long it
 // takes to perform the sort.
 //
+// The N pattern, which finds the group and N in each benchmark name, is a
+// regexp whose first two submatches are the group and N.  It can also be a
+// name template like BenchmarkSort{N:int}-{P:int} or Benchmark{Group}/{size:int},
+// where each variable in braces is an int, float, or string.
+//
+// The terms of a model are expressions of N, and they can also use K, which
+// is estimated along with the coefficients.  Throughputs like MB/s are fit
+// with N / (K + N) by default in the plotter, which levels off rather than
+// growing without bound.  Models of the common complexity classes can be
+// named rather than written out: constant, sqrt, linear, nlogn, quadratic,
+// cubic, and exp, like model=nlogn in the querystring or -xtransform=nlogn.
+// /models lists them along with their terms, and /validate?xtransform=
+// checks a model without fitting it, returning either its terms or the error
+// and its position in the model.
+// Models are limited to arithmetic and a few functions in the math package,
+// like math.Log, math.Sqrt, and math.Pow, with at most 16 terms, and they
+// are rejected if they take more than a second to evaluate over a range of N.
+//
+// The benchmarks of every file are mixed together in their groups, unless
+// group by file is checked in the plotter, or byfile=true is in the
+// querystring.  Then each group is split by the file its benchmarks are
+// from, like before.txt and after.txt, and each file's points have their
+// own marker, so that the files can be compared.  With byfile=auto, the
+// groups are only split if more than one of the plotted files has
+// benchmarks.  The groups keep their names otherwise, so that loading
+// another file doesn't change them.
+//
+// Runs in the same file, or on the same machine, are often correlated, so the
+// confidence intervals can be clustered by either, with cluster=file or
+// cluster=machine in the querystring or -cluster in report and check, rather
+// than treating every run as independent.
+// /drift fits each group's model again with the position of every benchmark
+// in its file as another term, and reports the groups whose response drifted
+// significantly over the session, like from thermal throttling.
+//
 // Options are:
-//    -http=addr
-//       HTTP service address (e.g., '127.0.0.1:6060' or just ':6060')
+//    -http=addr[,addr...]
+//       HTTP service addresses (e.g., '127.0.0.1:6060' or just ':6060').  An
+//       address of the form 'unix:/path/to.sock' listens on a unix socket.
+//       A port of 0, like ':0', picks a free port, and the address that is
+//       being served is logged once it has been bound.
+//    -grpc=addr[,addr...]
+//       gRPC service addresses, for the Parse, Fit, and Compare calls defined
+//       in benchplotpb/benchplot.proto.  Fit returns the same statistics
+//       as /fit, and the fits share the pool and timeout of the http
+//       endpoints.  By default, gRPC is not served.
+//    -dir=dir, -include=pattern, -exclude=pattern
+//       reads every file in the tree under dir which matches one of the
+//       include patterns, by default '**/*.txt', and none of the exclude
+//       patterns, along with the files on the command line.  Patterns are
+//       relative to dir, and ** matches any number of directories, like
+//       -dir=benchresults -exclude='**/flaky/**'.  Both can be repeated.
+//    -db=dir
+//       directory of the history database.  The output of go test -bench
+//       can be posted to /runs, with labels like commit and branch in the
+//       querystring, and is stored there and plotted with the other files.
+//       /history?benchmark=Sort&n=100000&since=30d&branch=main returns the
+//       time series of a benchmark in the stored runs, which the plotter
+//       draws below the distribution of a clicked point.
+//    -select=selector
+//       picks which of the stored runs are plotted and analyzed, by their
+//       labels and benchfmt configuration, like 'branch=main, cpu=~"Xeon"'.
+//       The operators are =, !=, =~, and !~, and regexps aren't anchored.
+//       /runs and /history also take a selector in their select parameter.
+//    -webhook=url
+//       URL to POST to when a new run regresses, which can be repeated.  A
+//       run posted to /runs is checked against the latest stored run that
+//       -baseline selects, or the previous run if it isn't set, like
+//       benchplot check with -threshold.  The notification is JSON which
+//       Slack's incoming webhooks accept, and with -url, each regression
+//       links to the plotter showing only its group.
+//    -projects=dir
+//       serves each subdirectory of dir as a separate project, so that one
+//       server can be shared without mixing benchmarks.  The project in
+//       dir/{project} is plotted at /p/{project}/, from the *.txt files in
+//       it, its history database is dir/{project}/history, and its views
+//       are kept in dir/{project}/views.json.  /projects lists them.
+//    -pprof, -pprof-http=addr[,addr...]
+//       serves the profiles of the server from net/http/pprof at
+//       /debug/pprof/, either along with the plotter, or on separate
+//       addresses, to diagnose slow fits or memory growth.
+//    -profiles=dir
+//       links the plotted benchmarks to the profiles that go test wrote for
+//       them with -cpuprofile, -memprofile, -blockprofile, or
+//       -mutexprofile, which are named after the benchmark with slashes
+//       replaced by underscores, and the kind of profile, like
+//       BenchmarkSort_n=100.cpu.prof.  They are looked for next to the
+//       benchmark files if dir isn't set.  Clicking a point opens its
+//       profiles as a flame graph from go tool pprof -http, which is only
+//       served on localhost, or downloads them.  Up to 4 of them are kept
+//       running, and they are stopped along with the server.
+//    -annotations=file
+//       draws labeled vertical lines at values of N across all of the
+//       groups, like where the data stops fitting in a cache, from a JSON
+//       file like [{"N": 32768, "Label": "fits in L1"}].  More can be posted
+//       to /annotations?n=4096&label=page+size, and removed by a DELETE
+//       with the same n, and optionally label.  They're drawn in reports
+//       as well.
+//    -names=file
+//       labels groups in the legend, tooltips, and tables of the plotter and
+//       reports with display names, from a JSON file like
+//       {"BenchmarkStableSortParallel": "stable sort (parallel)"}.  Groups
+//       are still found and fit by their own names.
+//    -cache-sizes=L1=size,L2=size,...
+//       annotates benchmarks which are parameterized by bytes with the
+//       sizes of the caches, like 'L1=32K,L2=1M,L3=8M', since their
+//       response often changes where the data stops fitting in one.  The
+//       sizes of this machine's data caches are used if it isn't set, on
+//       linux, and none turns them off.  Benchmarks are parameterized by
+//       bytes if N is named like bytes or size in the N pattern, like
+//       BenchmarkCopy/{bytes:int}.
+//    -views=file
+//       keeps the views which are saved from the plotter in the JSON file,
+//       rather than only in memory.  A view is a named set of the plotter's
+//       filters, models, range, and annotations, which is saved by a POST to
+//       /views?name=name, listed at /views, and opened with /?view=name, so
+//       that a team can curate a set of standard dashboards.
+//    -readonly
+//       refuses every request which would change the server, like posting
+//       runs to /runs, /rerun, and /reload, so that a dashboard of published
+//       results can be exposed to a wider audience.  Reading, fitting, and
+//       parsing still work, but /profile is refused, since it starts go
+//       tool pprof.
+//    -frontend=d3|plotly
+//       the plotter to serve.  plotly is a simpler page drawn with Plotly.js,
+//       which has zooming, hovering, and image export built in, but none of
+//       the d3 plotter's analyses.
+//    -max-fits=n
+//       the most fits to run at once, which is the number of CPUs by
+//       default.  Four times as many can wait for one to finish, and after
+//       that they are refused with a 429, so that the server stays
+//       responsive on a small machine.  0 is no limit.
+//    -fit-timeout=duration
+//       the longest that a request can spend fitting, 30s by default, after
+//       which it is abandoned with a 503.  Fits are also abandoned when the
+//       plotter that asked for them goes away.  0 is no limit.
+//    -log-level=level, -log-format=text|json
+//       the least severe messages to log, one of debug, info, warn, or
+//       error, and whether to log them as text or json.  Each request is
+//       logged at debug, with its method, path, status, and duration.
+//
+// The server reports that it is running at /healthz, and at /readyz, whether
+// the benchmarks have been parsed for the first time.
+//
+// The benchmarks are read again on SIGHUP, or when /reload is posted to, and
+// sent to the open plotters, so that they show files which were written since
+// they were loaded.
+//
+// Commands
+//
+// Instead of serving the interactive plotter, benchplot can also run one of
+// the following commands:
+//
+//   benchplot report [-o report.html] bench1.txt [bench2.txt ...]
+//      writes a standalone html report, including the data and fitted models,
+//      which can be viewed without running a server.  Like check, it can
+//      convert the response with -unit=ms or -unit=KiB, and -per-element
+//      divides it by N, which applies to the axes, fits, and tables alike.
+//      With -wasm=benchplot.wasm, a WebAssembly build of benchplot made with
+//      GOOS=js GOARCH=wasm go build -o benchplot.wasm is included, so that
+//      the report can be filtered by file and refit with other fitters in
+//      the browser.
+//
+//   benchplot check [-threshold=0.1] [-format=text|github|junit] old.txt new.txt
+//      compares the fits of each group in two sets of benchmarks at the
+//      largest N that both cover, and exits with status 1 if any got worse
+//      than the threshold or moved to a higher complexity class.  With
+//      -format=github, regressions are written as GitHub Actions annotations
+//      and the table is appended to the job summary.  With -format=junit,
+//      each group is written as a JUnit XML test case.
+//
+//   benchplot fit [-plot=term] [-logx] bench1.txt [bench2.txt ...]
+//      writes the fitted model of each group, and with -plot=term, draws
+//      the benchmarks and the fit in the terminal with braille characters,
+//      for sessions without a browser.
+//
+//   benchplot convert [-o out.txt] [-aggregate=min] bench1.json [bench2.csv ...]
+//      writes the benchmarks in the standard benchmark format, with their
+//      configuration, so that the output of other harnesses can be compared
+//      with benchstat or uploaded to perf.golang.org.  /benchfmt serves the
+//      same from the plotter's benchmarks.
+//
+//   benchplot db export [-db=dir] [-select=selector] [-o dump.json]
+//   benchplot db import [-db=dir] [-replace] dump.json [dump2.json ...]
+//      exports the runs in the history database as json, or imports them,
+//      so that the history can be moved between machines or checked into a
+//      repository.  The format is described by historyDump in db.go.
+//
+//   benchplot [options] run [-bench=regexp] [packages]
+//      runs go test -bench on the packages, and plots the benchmarks as
+//      they are completed.  With -webhook=url, each rerun is checked against
+//      the first run like benchplot check, or with -db and -baseline, the
+//      latest stored run that -baseline selects, and any regressions are
+//      posted to the webhook as JSON, which Slack's incoming webhooks
+//      accept.  With -url, each regression links to the plotter showing
+//      only its group.
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"math"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gonum/matrix/mat64"
 	"github.com/jonlawlor/parsefloat"
@@ -68,6 +306,12 @@ import (
 
 func usage() {
 	fmt.Fprintf(os.Stderr, "usage: benchplot [options] bench1.txt [bench2.txt ...]\n")
+	fmt.Fprintf(os.Stderr, "       benchplot report [options] bench1.txt [bench2.txt ...]\n")
+	fmt.Fprintf(os.Stderr, "       benchplot check [options] old.txt new.txt\n")
+	fmt.Fprintf(os.Stderr, "       benchplot convert [options] bench1.json [bench2.csv ...]\n")
+	fmt.Fprintf(os.Stderr, "       benchplot fit [-plot=term] [options] bench1.txt [bench2.txt ...]\n")
+	fmt.Fprintf(os.Stderr, "       benchplot db export|import [options] [dump.json ...]\n")
+	fmt.Fprintf(os.Stderr, "       benchplot [options] run [-bench=regexp] [packages]\n")
 	fmt.Fprintf(os.Stderr, "interactively fits and displays a least squares fit on parameterized benchmarks\n")
 	fmt.Fprintf(os.Stderr, "example:\n")
 	fmt.Fprintf(os.Stderr, "   benchplot -http=:8080 bench.txt")
@@ -81,165 +325,652 @@ const (
 )
 
 var (
-	httpAddr = flag.String("http", defaultAddr, "HTTP service address (e.g., '"+defaultAddr+"')")
-	verbose  = flag.Bool("v", false, "verbose mode")
+	httpAddr    = flag.String("http", defaultAddr, "comma separated HTTP service addresses (e.g., '"+defaultAddr+"' or '127.0.0.1:6060,unix:/tmp/benchplot.sock')")
+	grpcAddr    = flag.String("grpc", "", "comma separated gRPC service addresses, in the same form as http (default is not to serve gRPC)")
+	dbDir       = flag.String("db", "", "directory of the history database, which stores the runs posted to /runs (default is not to store runs)")
+	projectsDir = flag.String("projects", "", "directory of projects, each a subdirectory with its own benchmarks and history, served under /p/{project}/")
+	runSel      = flag.String("select", "", "selector of the stored runs to plot, like 'branch=main, cpu=~\"Xeon\"' (default is every run)")
+	verbose     = flag.Bool("v", false, "verbose mode, which is the same as -log-level=debug")
+	pprofOn     = flag.Bool("pprof", false, "serve the profiles of the server at "+pprofPrefix)
+	pprofAddr   = flag.String("pprof-http", "", "serve the profiles of the server on these addresses instead, in the same form as http")
+	logLevel    = flag.String("log-level", "info", "least severe level of messages to log: debug, info, warn, or error.  Requests are logged at debug")
+	logFormat   = flag.String("log-format", logText, "format of the log: "+logText+" or "+logJSON)
+	inputDir    = flag.String("dir", "", "directory tree of benchmark files to read, along with the files on the command line")
+	readOnly    = flag.Bool("readonly", false, "refuse every request which changes the server, like posting runs, rerunning, or reloading, to serve published results publicly")
+	frontend    = flag.String("frontend", frontendD3, "plotter to serve: "+frontendD3+", the interactive d3 plotter, or "+frontendPlotly+", a simpler Plotly.js page with zooming and image export built in")
+	maxFits     = flag.Int("max-fits", runtime.NumCPU(), "most fits to run at once, with a few times as many queued before more are refused, or 0 for no limit")
+	fitTimeout  = flag.Duration("fit-timeout", 30*time.Second, "longest that a request can spend fitting before it is abandoned, or 0 for no limit")
+	annotations = flag.String("annotations", "", "JSON file of labeled vertical lines to draw at values of N, like [{\"N\": 32768, \"Label\": \"fits in L1\"}]")
+	cacheSizes  = flag.String("cache-sizes", "", "sizes of the caches to annotate benchmarks parameterized by bytes with, like 'L1=32K,L2=1M,L3=8M', or "+cacheSizesNone+" (default is this machine's caches, on linux)")
+	viewsFile   = flag.String("views", "", "JSON file to keep the views saved from the plotter in (default is in memory)")
+	namesFile   = flag.String("names", "", "JSON file of display names of groups, like {\"BenchmarkStableSortParallel\": \"stable sort (parallel)\"}")
+	profileDir  = flag.String("profiles", "", "directory of the profiles of the benchmarks, like BenchmarkSort_n=100.cpu.prof (default is the directory of each benchmark file)")
+
+	threshold   = flag.Float64("threshold", 0.1, "largest relative change in the response which isn't a regression, for -webhook")
+	plotURL     = flag.String("url", "", "URL of the plotter, to link to in webhook notifications")
+	baselineSel = flag.String("baseline", "", "selector of the stored run that new runs are checked against for -webhook, the latest it matches (default is the first run of benchplot run, or the previous stored run)")
+
+	includes, excludes, webhooks stringsFlag
 )
 
+func init() {
+	flag.Var(&includes, "include", "pattern of the files in -dir to read, like '**/*.txt', which can be repeated (default "+defaultInclude+")")
+	flag.Var(&excludes, "exclude", "pattern of the files in -dir not to read, like '**/flaky/**', which can be repeated")
+	flag.Var(&webhooks, "webhook", "URL to POST to when a new run regresses against the baseline, which can be repeated")
+}
+
+// commands are the subcommands of benchplot, keyed by name.  Each is called
+// with the remaining command line arguments.
+var commands = map[string]func(args []string){
+	"check":   checkMain,
+	"convert": convertMain,
+	"db":      dbMain,
+	"fit":     fitMain,
+	"report":  reportMain,
+	"run":     runMain,
+}
+
 // validYs has the Y name as keys and a human readable name as the value.
 var validYs = map[string]string{
 	"NsPerOp":           "ns/op",
 	"AllocedBytesPerOp": "B/op",
 	"AllocsPerOp":       "allocs/op",
-	"MBPerS":            "MB/s"}
+	"MBPerS":            "MB/s",
+	"OpsPerS":           "ops/s",
+	"InstructionsPerOp": "instructions/op",
+	"CacheMissesPerOp":  "cache-misses/op",
+	"BranchMissesPerOp": "branch-misses/op"}
+
+// throughputs are the responses which are rates, where larger is better.
+var throughputs = map[string]bool{"MBPerS": true, "OpsPerS": true}
 
 func main() {
-	log.SetPrefix("benchplot: ")
-	log.SetFlags(0)
+	// The WebAssembly build only fits for the plotter in a report.
+	if runtime.GOOS == "js" {
+		serveWASM()
+		return
+	}
+
 	flag.Usage = usage
 	flag.Parse()
 
-	// Evaluate the glob args to see if any of them are malformed.  We don't read
-	// any of the files at this time.  This is the only error that Glob can return,
-	// so this allows benchplot to fail fast.
-	for _, arg := range flag.Args() {
-		if _, err := filepath.Glob(arg); err != nil {
-			log.Fatalf("invalid benchmark filename: %s", arg)
+	level := *logLevel
+	if *verbose {
+		level = "debug"
+	}
+	logger, err := newLogger(level, *logFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "benchplot: %v\n", err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+
+	if args := flag.Args(); len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			cmd(args[1:])
+			return
 		}
 	}
 
-	dataHandleFunc := serveBenchmarksAsJSON(flag.Args())
+	checkPatterns(flag.Args())
+	if _, err := initialAnnotations(); err != nil {
+		fatal(err)
+	}
+	if _, err := displayNames(); err != nil {
+		fatal(err)
+	}
+	if _, err := regressionChecks(); err != nil {
+		fatal(err)
+	}
 
-	var handler http.Handler = http.DefaultServeMux
-	if *verbose {
-		log.Printf("version = %s", runtime.Version())
-		log.Printf("address = %s", *httpAddr)
+	var src dataSource = globSource(flag.Args())
+	if *inputDir != "" {
+		if fi, err := os.Stat(*inputDir); err != nil || !fi.IsDir() {
+			fatalf("invalid benchmark directory: %s", *inputDir)
+		}
+		if len(includes) == 0 {
+			includes = stringsFlag{defaultInclude}
+		}
+		checkDirPatterns(includes)
+		checkDirPatterns(excludes)
+		src = dirSource{globSource(flag.Args()), *inputDir, includes, excludes}
+	}
+	runServer(src)
+}
+
+// runServer serves the plotter, using the benchmark data from src.  It only
+// returns if the server can't be started.
+func runServer(src dataSource) {
+	if _, err := frontendPage(*frontend); err != nil {
+		fatal(err)
+	}
+	views, err := openViews(*viewsFile)
+	if err != nil {
+		fatal(err)
+	}
+
+	// Listen before anything else, so that the addresses which are logged
+	// are the ones being served, including the port chosen for :0.
+	lns, err := listen(*httpAddr)
+	if err != nil {
+		fatal(err)
+	}
+
+	// The profiles of the server are either served with the plotter, or
+	// on their own addresses, so that they can be kept private.
+	var handler http.Handler = withPprof(http.DefaultServeMux, *pprofOn && *pprofAddr == "")
+	if *pprofAddr != "" {
+		plns, err := listen(*pprofAddr)
+		if err != nil {
+			fatal(err)
+		}
+		for _, ln := range plns {
+			slog.Info("serving profiles", "url", strings.TrimSuffix(listenerURL(ln), "/")+pprofPrefix)
+		}
+		go func() {
+			fatalf("Serve pprof %s: %v", *pprofAddr, serve(plns, pprofMux()))
+		}()
+	}
+	if *readOnly {
+		handler = readOnlyHandler(handler)
+	}
+	if debugEnabled() {
+		slog.Debug("starting", "version", runtime.Version())
 		handler = loggingHandler(handler)
 	}
 
+	// Add the history database.  Runs which are posted to /runs are
+	// stored in it, and plotted along with the other benchmarks.
+	var db *historyDB
+	if *dbDir != "" {
+		var err error
+		if db, err = openHistory(*dbDir); err != nil {
+			fatal(err)
+		}
+		sel, err := parseSelector(*runSel)
+		if err != nil {
+			fatal(err)
+		}
+		src = historySource{src, db, sel}
+	}
+	http.Handle("/", plotterMux(src, db, events, views))
+
+	// Healthz reports that the server is running, and readyz reports
+	// whether the benchmarks have been parsed yet, for load balancers and
+	// Kubernetes probes.
+	var ready readiness
+	go ready.warmUp(src)
+	http.HandleFunc("/healthz", healthzHandleFunc)
+	http.HandleFunc("/readyz", ready.readyzHandleFunc)
+
+	// Reload reads the benchmarks again and sends them to the plotters,
+	// on SIGHUP as well.
+	rl := reloader{src, events}
+	rl.reloadOnSignal()
+	stopPprofOnSignal()
+	http.HandleFunc("/reload", rl.reloadHandleFunc)
+
+	// Add the projects.  Each has its own plotter, benchmarks, and
+	// history under /p/{project}/.
+	if *projectsDir != "" {
+		ps := newProjectServer(*projectsDir)
+		http.Handle("/p/", ps)
+		http.HandleFunc("/projects", ps.listHandleFunc)
+	}
+
+	// The same parsing and fitting is also available over gRPC.
+	if *grpcAddr != "" {
+		glns, err := listen(*grpcAddr)
+		if err != nil {
+			fatal(err)
+		}
+		for _, ln := range glns {
+			slog.Info("serving gRPC", "address", ln.Addr().String())
+		}
+		go func() {
+			fatalf("Serve gRPC %s: %v", *grpcAddr, serveGRPC(glns, src))
+		}()
+	}
+
+	for _, ln := range lns {
+		slog.Info("serving", "url", listenerURL(ln))
+	}
+	if err := serve(lns, handler); err != nil {
+		fatalf("Serve %s: %v", *httpAddr, err)
+	}
+
+}
+
+// plotterMux returns the handlers of the plotter, which use the benchmark
+// data from src.  If db isn't nil, runs can be stored in it.  Changes to the
+// data are sent to the plotters over hub, and views are saved in views.
+func plotterMux(src dataSource, db *historyDB, hub *eventHub, views *viewStore) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	// Add the history database.  Runs which are posted to /runs are
+	// stored in it.
+	if db != nil {
+		// checked at startup
+		check, _ := regressionChecks()
+		mux.Handle("/runs", serveRuns(db, src, hub, check))
+
+		// History returns the time series of a benchmark in the stored
+		// runs.
+		mux.Handle("/history", serveHistory(db))
+	}
+
 	// Add the benchmark data handler.   It serves up the benchmark data in json
-	// form at /data
-	http.Handle("/data", dataHandleFunc)
+	// form at /data, along with metadata about each file.  It and /fit are
+	// compressed, since they can be large.
+	mux.Handle("/data", gzipHandler(serveBenchmarksAsJSON(src)))
+
+	// Add the event stream.  It sends the benchmark data to the plotter over
+	// a websocket at /ws, followed by any changes to it, and answers fit
+	// requests.  Least squares fits over it are updated with only the new
+	// benchmarks while they stream in from run or reload.
+	mux.Handle("/ws", serveEvents(src, hub))
+
+	// Names are the display names of the groups.
+	mux.HandleFunc("/names", serveDisplayNames)
+
+	// Annotations are labeled vertical lines drawn across all of the
+	// groups, which start with those in the -annotations file and the
+	// cache sizes.
+	anns, _ := initialAnnotations()
+	mux.Handle("/annotations", serveAnnotations(newAnnotationStore(anns)))
+
+	// Table lists every parsed benchmark, as an alternative to the plot.
+	mux.Handle("/table", gzipHandler(serveTable(src)))
+
+	// Dashboard shows a small chart of each group and its fit, which links
+	// to the plotter showing only that group.
+	mux.Handle("/dashboard", fitHandler(serveDashboard(src, views)))
+
+	// Views are named configurations of the plotter.  The plotter starts
+	// with the settings of the one in ?view=name.
+	mux.Handle("/views", serveViews(views))
+
+	// Embed serves the plotter with only its chart, configured by the
+	// querystring, to be embedded in other pages.
+	mux.Handle("/embed", serveEmbed(src, views))
 
 	// Add the plotter.  It fetches data from /data, filters it, sends it to
 	// /fit, and displays the results.
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		io.CopyBuffer(w, strings.NewReader(plotHTML), nil)
+	page, _ := frontendPage(*frontend)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		p := page
+		if name := r.URL.Query().Get("view"); name != "" {
+			var err error
+			if p, err = viewPage(page, views, name); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+		}
+		io.CopyBuffer(w, strings.NewReader(p), nil)
 	})
 
 	// Fit takes requests with a querystring describing the function to fit,
 	// and a set of data within a put, along with desired bounds for the estimation.
 	// It returns a set of points and the 95% confidence interval in JSON.
-	http.HandleFunc("/fit", fitHandleFunc)
+	// It and the other handlers which fit run at most -max-fits at once,
+	// and are abandoned after -fit-timeout, or if the plotter goes away.
+	// Its fits are cached by their parameters and benchmarks, so that
+	// switching back to earlier settings doesn't fit them again.
+	mux.Handle("/fit", gzipHandler(fitHandler(http.HandlerFunc(fitHandleFunc))))
 
-	if err := http.ListenAndServe(*httpAddr, handler); err != nil {
-		log.Fatalf("ListenAndServe %s: %v", *httpAddr, err)
-	}
+	// Fitters lists the estimators which can be used by /fit.
+	mux.HandleFunc("/fitters", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(fitterInfos())
+	})
 
+	// Models lists the named models, which can be used in place of their
+	// terms in xtransform, or named by model, like /fit?model=nlogn.
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(modelPresets)
+	})
+
+	// Validate parses the model in xtransform without fitting it, and
+	// returns either its terms or where the error in it is.  Models are
+	// evaluated to check them, so it shares the pool and timeout of /fit.
+	mux.Handle("/validate", fitHandler(http.HandlerFunc(serveValidate)))
+
+	// Predict fits every group with the settings in the querystring, and
+	// returns the estimate and prediction interval of each at a given N.
+	mux.Handle("/predict", fitHandler(servePredictions(src)))
+
+	// Solve is the inverse of predict: it returns the largest N at which
+	// each group is within the budget in the querystring.
+	mux.Handle("/solve", fitHandler(serveSolutions(src)))
+
+	// Crossover finds the N at which the fits of two groups cross.
+	mux.Handle("/crossover", fitHandler(serveCrossovers(src)))
+
+	// Ratio evaluates the ratio of the fits of two groups, with its
+	// confidence interval.
+	mux.Handle("/ratio", fitHandler(serveRatios(src)))
+
+	// Coefficients returns the fitted coefficients of every group side by
+	// side.
+	mux.Handle("/coefficients", fitHandler(serveCoefficients(src)))
+
+	// Machines compares the coefficients of each group on two machines.
+	mux.Handle("/machines", serveMachineComparisons(src))
+
+	// Exponent fits a power law to every group, to find how it scales
+	// without choosing a model.
+	mux.Handle("/exponent", serveExponents(src))
+
+	// Drift estimates how much each group's response changed over the
+	// benchmark session, like from thermal throttling.
+	mux.Handle("/drift", serveDrifts(src))
+
+	// Partial returns the partial residual plots of a group, to check
+	// whether each term of its model is well specified.
+	mux.Handle("/partial", fitHandler(servePartialResiduals(src)))
+
+	// Chow tests whether pairs of groups have the same coefficients.
+	mux.Handle("/chow", fitHandler(serveChowTests(src)))
+
+	// Points summarizes groups with too many benchmarks to draw.
+	mux.Handle("/points", servePoints(src))
+
+	// Distribution returns a histogram of the runs of a single benchmark.
+	mux.Handle("/distribution", serveDistributions(src))
+
+	// Profile opens the profile of a benchmark in go tool pprof.
+	mux.Handle("/profile", serveProfiles(src))
+
+	// Costfunc writes the fit of every group as a Go function, which can be
+	// used to estimate costs in other programs.
+	mux.Handle("/costfunc", fitHandler(serveCostFuncs(src)))
+
+	// Vega writes the plot as a Vega-Lite spec, which can be restyled or
+	// embedded in notebooks.
+	mux.Handle("/vega", gzipHandler(fitHandler(serveVega(src))))
+
+	// Benchfmt writes the benchmarks in the standard benchmark format, so
+	// that the output of other harnesses can be compared with benchstat.
+	mux.Handle("/benchfmt", gzipHandler(serveBenchfmt(src)))
+
+	// Parse parses the output of go test -bench which is posted to it, so
+	// that other tools can use the same parser and grouping as the plotter.
+	mux.HandleFunc("/parse", parseHandleFunc)
+	return mux
 }
 
-func loggingHandler(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		log.Printf("%s\t%s", req.RemoteAddr, req.URL)
-		h.ServeHTTP(w, req)
-	})
+// checkPatterns evaluates the glob args to see if any of them are malformed.
+// We don't read any of the files at this time.  This is the only error that
+// Glob can return, so this allows benchplot to fail fast.
+func checkPatterns(patterns []string) {
+	for _, pat := range patterns {
+		if _, err := filepath.Glob(pat); err != nil {
+			fatalf("invalid benchmark filename: %s", pat)
+		}
+	}
 }
 
-func serveBenchmarksAsJSON(patterns []string) http.HandlerFunc {
+// serveBenchmarksAsJSON serves the data from src.  It has an ETag, and the
+// time the newest file was modified, so that a plotter which is reloaded
+// doesn't have to download the data again if it hasn't changed.  With
+// format=ndjson, it is streamed instead, by writeNDJSON.
+func serveBenchmarksAsJSON(src dataSource) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		benchSets := make(map[string][]*parse.Benchmark)
-		for _, pat := range patterns {
-			// we've already checked for validity, so err will be nil
-			fns, _ := filepath.Glob(pat)
-			for _, fn := range fns {
-				// This can only error if the path is invalid but glob should only return
-				// files that exist.  There's a race condition with the filesystem, but
-				// we'll ignore it.
-				f, err := os.Open(fn)
-				if err != nil {
-					continue
-				}
-				benchSet, err := parse.ParseSet(f)
-
-				if err != nil {
-					// TODO(jonlawlor): determine if and when this can occur?
-					log.Fatal(err)
-				}
-				var benchMarks []*parse.Benchmark
-				for _, b := range benchSet {
-					benchMarks = append(benchMarks, b...)
-				}
-				benchSets[fn] = benchMarks
-			}
+		ds := src.dataSet()
+		if r.URL.Query().Get("format") == "ndjson" {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			writeNDJSON(w, ds)
+			return
 		}
-		enc := json.NewEncoder(w)
-		enc.Encode(benchSets)
+		b, err := json.Marshal(ds)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		etag, modTime := dataETag(b), lastModified(ds)
+		w.Header().Set("ETag", etag)
+		if !modTime.IsZero() {
+			w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+		}
+		if notModified(r, etag, modTime) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(append(b, '\n'))
 	})
 }
 
 type benchmarkResponse struct {
 	parse.Benchmark
+	perfCounters
 	X float64 // explanatory variable
+
+	// Cluster is the file or machine the benchmark came from, if the
+	// confidence intervals should be robust to correlation within them.
+	Cluster string `json:",omitempty"`
+
+	// divisor converts the response to other units, if it isn't zero.
+	divisor float64
 }
 
-func fitHandleFunc(w http.ResponseWriter, r *http.Request) {
-	// TODO(jonlawlor): do something better than fatal logging when there is
-	// an invalid input?  Ideally the javascript would never provide invalid data.
+// maxLineSteps is the most points a regression line can be evaluated at, and
+// maxFitBytes is the largest set of benchmarks which can be sent to be fit,
+// so that a single request can't take all of the server's memory.
+const (
+	maxLineSteps = 10000
+	maxFitBytes  = 32 << 20
+)
 
+func fitHandleFunc(w http.ResponseWriter, r *http.Request) {
 	// pull out the fitting parameters from the url querystring
 	if err := r.ParseForm(); err != nil {
-		log.Fatal(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req, err := parseFitRequest(r.Form)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
+	// Unmarshal the data set
+	var benchSet []benchmarkResponse
+	b, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxFitBytes))
+	if err != nil {
+		http.Error(w, "unable to read request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := json.Unmarshal(b, &benchSet); err != nil {
+		http.Error(w, "invalid benchmarks: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := fitCached(r.Context(), fitKey(r.Form, b), benchSet, req)
+	if err != nil {
+		fitError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/javascript")
+	json.NewEncoder(w).Encode(res)
+}
+
+// parseFitRequest parses the fitting parameters from a querystring.
+func parseFitRequest(v url.Values) (fitRequest, error) {
 	// lower bound
-	xlbValue := r.FormValue("xlb")
+	xlbValue := v.Get("xlb")
 	xlb, err := strconv.ParseFloat(xlbValue, 64)
 	if err != nil {
-		log.Fatal("Invalid x lower bound:", xlbValue)
+		return fitRequest{}, fmt.Errorf("invalid x lower bound: %s", xlbValue)
 	}
 
 	// upper bound
-	xubValue := r.FormValue("xub")
+	xubValue := v.Get("xub")
 	xub, err := strconv.ParseFloat(xubValue, 64)
 	if err != nil {
-		log.Fatal("Invalid x upper bound:", xubValue)
+		return fitRequest{}, fmt.Errorf("invalid x upper bound: %s", xubValue)
 	}
 
-	// x transform
-	xTransformValue := r.FormValue("xtransform")
+	// x transform, which can be a named preset
+	xTransformValue, err := modelValue(v, "")
+	if err != nil {
+		return fitRequest{}, err
+	}
 
 	// create the x expression
-	varNames := map[string]struct{}{"N": struct{}{}}
-	xTransform, err := parsefloat.NewSlice("float64{"+xTransformValue+"}", varNames)
+	xTransform, err := parseTransform(xTransformValue)
 	if err != nil {
-		log.Fatal("invalid xTransform", xTransformValue)
+		return fitRequest{}, err
 	}
 
 	// response
-	yVar := r.FormValue("yvar")
+	yVar := v.Get("yvar")
+	if _, ok := validYs[yVar]; !ok {
+		return fitRequest{}, fmt.Errorf("invalid yvar: %s", yVar)
+	}
 
 	// number of steps to evaluate
-	nLineStepsValue := r.FormValue("nlinesteps")
+	nLineStepsValue := v.Get("nlinesteps")
 	nLineSteps, err := strconv.Atoi(nLineStepsValue)
-	if err != nil || nLineSteps < 1 {
-		log.Fatal("invalid number of line steps:", nLineStepsValue)
+	if err != nil || nLineSteps < 1 || nLineSteps > maxLineSteps {
+		return fitRequest{}, fmt.Errorf("invalid number of line steps: %s", nLineStepsValue)
 	}
 
-	// Unmarshal the data set
-	var benchSet []benchmarkResponse
-	b, err := ioutil.ReadAll(r.Body)
+	// estimator, which is least squares by default
+	fitter, err := parseFitter(v)
 	if err != nil {
-		log.Fatal("Unable to read request body:", r)
+		return fitRequest{}, err
+	}
+
+	return fitRequest{
+		xlb:        xlb,
+		xub:        xub,
+		xTransform: xTransform,
+		yVar:       yVar,
+		nLineSteps: nLineSteps,
+		fitter:     fitter,
+	}, nil
+}
+
+// fitRequest describes how to fit a group of benchmarks and where to evaluate
+// the resulting regression line.
+type fitRequest struct {
+	xlb, xub   float64                 // bounds of the regression line
+	xTransform []parsefloat.Expression // terms of the model
+	yVar       string                  // response
+	nLineSteps int                     // number of points on the regression line
+	fitter     estimator               // estimator of the model
+}
+
+// resultPoint is a point on the regression line.
+type resultPoint struct {
+	X            float64
+	Yhat         float64
+	ConfWidth    float64
+	Extrapolated bool // X is outside of the range of the benchmarks
+}
+
+// resultModel is a single term of the fitted model.
+type resultModel struct {
+	XTrans string
+	Beta   float64
+	BInt   float64
+	VIF    float64 // variance inflation factor, 0 for constant terms
+	Std    float64 // standardized coefficient, 0 for constant terms
+}
+
+// fitResponse is the result of fitting a group of benchmarks.
+type fitResponse struct {
+	ResultLine  []resultPoint
+	ResultModel []resultModel
+	R2          float64
+	MSE         float64
+
+	// DF is the residual degrees of freedom, the benchmarks less the terms.
+	// AdjR2 is R2 adjusted for the number of terms, and F is the F statistic
+	// that all of the coefficients are zero, with its p-value PValue.
+	DF     int
+	AdjR2  float64
+	F      float64
+	PValue float64
+
+	// XMin and XMax are the range of the benchmarks that were fit.
+	XMin, XMax float64
+
+	// Equation and LaTeX are the fitted model, for people to read.
+	Equation string
+	LaTeX    string
+
+	// Complexity is the complexity class which best fits the benchmarks,
+	// regardless of the model, like O(N log N).
+	Complexity string
+
+	// Condition is the condition number of the terms, with each scaled to
+	// unit length.  Collinear is set if it or any term's VIF is so large
+	// that the coefficients can't be trusted.
+	Condition float64
+	Collinear bool
+
+	// Rank is the number of linearly independent terms.  If it is less
+	// than the number of terms, the model is rank deficient, and the
+	// minimum norm fit is used.
+	Rank int
+
+	// K is the estimate of K, if the model uses it, which is already
+	// substituted into the terms.
+	K float64 `json:",omitempty"`
+
+	// Clusters is the number of clusters that the confidence intervals
+	// allow to be correlated, if they are cluster robust.
+	Clusters int `json:",omitempty"`
+
+	// Covariance is the covariance matrix of the coefficients, in the order
+	// of the terms, and Correlation is the same scaled to correlations, so
+	// that uncertainty can be propagated into quantities derived from more
+	// than one coefficient.
+	Covariance  [][]float64
+	Correlation [][]float64
+
+	// NonFinite is the N of the benchmarks that were left out of the fit
+	// because a term overflowed or was NaN there.  The points of the line
+	// where one does are left out of ResultLine as well.
+	NonFinite []float64 `json:",omitempty"`
+}
+
+// fit performs a regression on the benchmarks with the requested estimator,
+// and evaluates the regression line and its 95% confidence interval.
+// Benchmarks without the response, like a perf counter that wasn't counted,
+// are left out.
+func fit(ctx context.Context, benchSet []benchmarkResponse, req fitRequest) (fitResponse, error) {
+	benchSet = counted(benchSet, req.yVar)
+	xTransform, k, err := resolveK(ctx, benchSet, req.xTransform, req.yVar, req.fitter)
+	if err != nil {
+		return fitResponse{}, err
+	}
+	nLineSteps := req.nLineSteps
+
+	// leave out the benchmarks where the terms overflow
+	benchSet, nonFinite := finiteTerms(benchSet, xTransform)
+	if !canFit(benchSet, len(xTransform)) {
+		return fitResponse{}, fmt.Errorf("too few benchmarks where the terms are finite, out of %d", len(benchSet)+len(nonFinite))
 	}
-	json.Unmarshal(b, &benchSet)
 
 	// evaluate the regression
-	samp := sampleGroup(benchSet, xTransform, yVar)
-	regModel := estimate(samp)
+	samp := sampleGroup(benchSet, xTransform, req.yVar)
+	regModel, st, err := req.fitter.Fit(samp)
+	if err != nil {
+		return fitResponse{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return fitResponse{}, err
+	}
 
 	// generate the regression line and the confidence interval
-	evalStep := (xub - xlb) / float64(nLineSteps-1)
+	evalStep := (req.xub - req.xlb) / float64(nLineSteps-1)
 	evalPoints := make([]float64, nLineSteps)
-	point := xlb
+	point := req.xlb
 	for i := 0; i < nLineSteps; i++ {
 		evalPoints[i] = point
 		point += evalStep
@@ -250,48 +981,71 @@ func fitHandleFunc(w http.ResponseWriter, r *http.Request) {
 	var regLine mat64.Dense
 	regLine.Mul(regX, betas)
 
-	// generate the regression stats
-	r2, mse, bint, iXTX := stats(regModel, samp)
+	// the regression stats, which are cluster robust if the benchmarks
+	// come from more than one cluster
+	dof := len(benchSet) - len(xTransform)
+	clusters := 0
+	if c := benchClusters(benchSet); c != nil {
+		if cdof, ok := st.cluster(samp, regModel, c); ok {
+			dof, clusters = cdof, cdof+1
+		}
+	}
+	r2, mse, bint, iXTX := st.r2, st.mse, st.cint, st.iXTX
 
 	// evaluate the confidence interval
 	confWidth := make([]float64, nLineSteps)
-	dof := len(benchSet) - len(xTransform)
 	for i := range confWidth {
 		xi := regX.RowView(i)
 		confWidth[i] = conf95(math.Sqrt(mse*mat64.Inner(xi, iXTX, xi)), dof)
 	}
 
-	// pack up the results and respond
-	type resultPoint struct {
-		X         float64
-		Yhat      float64
-		ConfWidth float64
+	// pack up the results
+	xMin, xMax := math.Inf(1), math.Inf(-1)
+	for _, b := range benchSet {
+		xMin = math.Min(xMin, b.X)
+		xMax = math.Max(xMax, b.X)
 	}
-	resultLine := make([]resultPoint, nLineSteps)
+	resultLine := make([]resultPoint, 0, nLineSteps)
 	for i, x := range evalPoints {
-		resultLine[i] = resultPoint{x, regLine.At(i, 0), confWidth[i]}
+		if !finiteRow(regX, i) {
+			continue
+		}
+		resultLine = append(resultLine, resultPoint{x, regLine.At(i, 0), confWidth[i], x < xMin || x > xMax})
 	}
 
-	type resultModel struct {
-		XTrans string
-		Beta   float64
-		BInt   float64
-	}
+	cov := covariance(mse, iXTX)
+	cond := condition(samp)
+	std := standardizedCoefficients(regModel, samp)
 	resModel := make([]resultModel, len(xTransform))
+	terms := make([]string, len(xTransform))
 	for i, x := range xTransform {
-		resModel[i] = resultModel{x.String(), betas.At(i, 0), bint[i]}
+		terms[i] = x.String()
+		resModel[i] = resultModel{terms[i], betas.At(i, 0), bint[i], cond.vif[i], std[i]}
 	}
+	equation, latex := equations(req.yVar, terms, regModel)
+	adjR2, fStat, pValue := modelSummary(r2, len(benchSet), len(xTransform))
 
-	w.Header().Set("Content-Type", "application/javascript")
-	json.NewEncoder(w).Encode(struct {
-		ResultLine  []resultPoint
-		ResultModel []resultModel
-		R2          float64
-		MSE         float64
-	}{
-		resultLine,
-		resModel,
-		r2,
-		mse,
-	})
+	return fitResponse{
+		ResultLine:  resultLine,
+		ResultModel: resModel,
+		R2:          r2,
+		MSE:         mse,
+		DF:          len(benchSet) - len(xTransform),
+		AdjR2:       adjR2,
+		F:           fStat,
+		PValue:      pValue,
+		XMin:        xMin,
+		XMax:        xMax,
+		Equation:    equation,
+		LaTeX:       latex,
+		Complexity:  complexity(benchSet, req.yVar),
+		Condition:   cond.cond,
+		Collinear:   cond.collinear(),
+		Rank:        cond.rank,
+		K:           k,
+		Clusters:    clusters,
+		Covariance:  cov,
+		Correlation: correlation(cov),
+		NonFinite:   nonFinite,
+	}, nil
 }
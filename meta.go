@@ -0,0 +1,162 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// metaSuffix names the sidecar file of notes about a benchmark file, like
+// bench.txt.meta.yaml.
+const metaSuffix = ".meta.yaml"
+
+// metaEntry is a single note about the run of a benchmark file.  Nested keys
+// are joined by dots, like environment.turbo.
+type metaEntry struct {
+	Key   string
+	Value string
+}
+
+// readMetaSidecar reads the notes in the sidecar file of the named benchmark
+// file, if there is one.
+func (bf *benchFile) readMetaSidecar(fn string) error {
+	f, err := os.Open(fn + metaSuffix)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	bf.Meta, err = parseMeta(f)
+	if err != nil {
+		return fmt.Errorf("%s: %v", fn+metaSuffix, err)
+	}
+	return nil
+}
+
+// parseMeta parses the subset of YAML that notes are written in: mappings
+// of keys to plain or quoted scalars, which can be nested by indenting them,
+// lists of scalars, and block scalars introduced by | or >, like
+//
+//	notes: |
+//	  turbo was disabled
+//	environment:
+//	  governor: performance
+//	flags:
+//	  - -benchtime=5s
+//
+// The entries are in the order they appear, and the items of a list are
+// joined by commas.
+func parseMeta(r io.Reader) ([]metaEntry, error) {
+	type level struct {
+		indent int
+		key    string
+	}
+	var (
+		entries []metaEntry
+		stack   []level
+		block   *metaEntry // the block scalar being read, if any
+		fold    bool       // whether the block's lines are joined by spaces
+		bIndent int        // indent of the block scalar's parent key
+		lines   []string
+		perr    error
+	)
+	endBlock := func() {
+		if block == nil {
+			return
+		}
+		sep := "\n"
+		if fold {
+			sep = " "
+		}
+		block.Value = strings.TrimSpace(strings.Join(lines, sep))
+		entries = append(entries, *block)
+		block, lines = nil, nil
+	}
+	err := scanLines(r, func(line int, text string) {
+		if perr != nil {
+			return
+		}
+		trimmed := strings.TrimLeft(text, " ")
+		indent := len(text) - len(trimmed)
+		if block != nil {
+			if trimmed == "" || indent > bIndent {
+				lines = append(lines, strings.TrimSpace(trimmed))
+				return
+			}
+			endBlock()
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			return
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			perr = fmt.Errorf("line %d: tabs can't indent YAML", line)
+			return
+		}
+		for len(stack) > 0 && indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		prefix := ""
+		if len(stack) > 0 {
+			prefix = stack[len(stack)-1].key
+		}
+
+		if trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+			if prefix == "" {
+				perr = fmt.Errorf("line %d: list item outside of a key", line)
+				return
+			}
+			item := metaScalar(strings.TrimPrefix(trimmed, "-"))
+			if n := len(entries); n > 0 && entries[n-1].Key == prefix {
+				entries[n-1].Value += ", " + item
+			} else {
+				entries = append(entries, metaEntry{prefix, item})
+			}
+			return
+		}
+
+		i := strings.Index(trimmed, ":")
+		if i < 1 || (i+1 < len(trimmed) && trimmed[i+1] != ' ') {
+			perr = fmt.Errorf("line %d: expected key: value, got %q", line, trimmed)
+			return
+		}
+		key := metaScalar(trimmed[:i])
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		value := strings.TrimSpace(trimmed[i+1:])
+		switch {
+		case value == "":
+			stack = append(stack, level{indent, key})
+		case value[0] == '|' || value[0] == '>':
+			block = &metaEntry{Key: key}
+			fold = value[0] == '>'
+			bIndent = indent
+		default:
+			entries = append(entries, metaEntry{key, metaScalar(value)})
+		}
+	})
+	endBlock()
+	if err == nil {
+		err = perr
+	}
+	return entries, err
+}
+
+// metaScalar returns the value of a plain or quoted scalar.
+func metaScalar(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s
+}
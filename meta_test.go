@@ -0,0 +1,58 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMeta(t *testing.T) {
+	got, err := parseMeta(strings.NewReader(`# laptop run
+notes: |
+  turbo was disabled
+
+  on battery
+summary: >
+  a quiet
+  machine
+environment:
+  governor: performance
+  cpu:
+    model: "i7-8650U"
+    smt: off # hyperthreading
+flags:
+  - -benchtime=5s
+  - '-count=10'
+host: ci-3
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []metaEntry{
+		{"notes", "turbo was disabled\n\non battery"},
+		{"summary", "a quiet machine"},
+		{"environment.governor", "performance"},
+		{"environment.cpu.model", "i7-8650U"},
+		{"environment.cpu.smt", "off"},
+		{"flags", "-benchtime=5s, -count=10"},
+		{"host", "ci-3"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{
+		"just some text\n",
+		"- item\n",
+		"key:value\n",
+		"env:\n\tturbo: off\n",
+	} {
+		if _, err := parseMeta(strings.NewReader(bad)); err == nil {
+			t.Errorf("parseMeta(%q) didn't fail", bad)
+		}
+	}
+}
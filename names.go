@@ -0,0 +1,75 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// readDisplayNames reads the display names of groups in the file, which is
+// a JSON object like {"BenchmarkStableSortParallel": "stable sort (parallel)"}.
+func readDisplayNames(fn string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	var names map[string]string
+	if err := json.Unmarshal(b, &names); err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+	for raw, name := range names {
+		if raw == "" || name == "" {
+			return nil, fmt.Errorf("%s: names can't be empty, got %q: %q", fn, raw, name)
+		}
+	}
+	return names, nil
+}
+
+var (
+	namesOnce  sync.Once
+	groupNames map[string]string
+	namesErr   error
+)
+
+// displayNames returns the display names in the -names file, which label
+// groups in the legend, tooltips, and tables of the plotter and reports.
+// The groups are still found and fit by their names, so the display names
+// only change how they're shown.  A name also labels the groups which end
+// with it after a package, machine, or series, like pkg.BenchmarkSort.
+func displayNames() (map[string]string, error) {
+	namesOnce.Do(func() {
+		groupNames = map[string]string{}
+		if *namesFile != "" {
+			groupNames, namesErr = readDisplayNames(*namesFile)
+		}
+	})
+	return groupNames, namesErr
+}
+
+// serveDisplayNames serves the display names of the groups.
+func serveDisplayNames(w http.ResponseWriter, r *http.Request) {
+	names, _ := displayNames()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(names)
+}
+
+// displayName returns the display name of the group, which is the same as
+// the plotter's displayName.
+func displayName(names map[string]string, group string) string {
+	if name, ok := names[group]; ok {
+		return name
+	}
+	for raw, name := range names {
+		i := len(group) - len(raw)
+		if i > 0 && group[i:] == raw && (group[i-1] == '.' || group[i-1] == ' ') {
+			return group[:i] + name
+		}
+	}
+	return group
+}
@@ -0,0 +1,66 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadDisplayNames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		contents string
+		want     map[string]string
+		ok       bool
+	}{
+		{`{"BenchmarkStableSortParallel": "stable sort (parallel)"}`, map[string]string{"BenchmarkStableSortParallel": "stable sort (parallel)"}, true},
+		{`{}`, map[string]string{}, true},
+		{`{"BenchmarkSort": ""}`, nil, false},
+		{`["BenchmarkSort"]`, nil, false},
+	}
+	for i, test := range tests {
+		fn := filepath.Join(dir, "names.json")
+		if err := ioutil.WriteFile(fn, []byte(test.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readDisplayNames(fn)
+		if (err == nil) != test.ok {
+			t.Errorf("%d: got error %v, want ok %v", i, err, test.ok)
+			continue
+		}
+		if test.ok && !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d: got %v, want %v", i, got, test.want)
+		}
+	}
+	if _, err := readDisplayNames(filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("reading a missing file didn't fail")
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	names := map[string]string{"BenchmarkSort": "sort"}
+	for group, want := range map[string]string{
+		"BenchmarkSort":             "sort",
+		"sort.BenchmarkSort":        "sort.sort",
+		"[amd64] BenchmarkSort":     "[amd64] sort",
+		"BenchmarkStableSort":       "BenchmarkStableSort",
+		"BenchmarkSortParallel":     "BenchmarkSortParallel",
+		"xBenchmarkSort":            "xBenchmarkSort",
+		"old: sort.BenchmarkSortNo": "old: sort.BenchmarkSortNo",
+	} {
+		if got := displayName(names, group); got != want {
+			t.Errorf("%s: got %q, want %q", group, got, want)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !js
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// hangup is the signal which reloads the benchmarks.
+var hangup os.Signal = syscall.SIGHUP
+
+// terminate are the signals which stop the server.
+var terminate = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// serveWASM is only used by the WebAssembly build.
+func serveWASM() {}
@@ -0,0 +1,47 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// ndjsonFlush is how many benchmarks are written between flushes of a
+// streamed data set.
+const ndjsonFlush = 1000
+
+// writeNDJSON streams the data set as newline delimited json events, the same
+// as the ones sent over the websocket.  The first is a data event with every
+// file, but none of their benchmarks, which are each sent in a parse event.
+// This lets the plotter show a very large data set as it arrives.
+func writeNDJSON(w io.Writer, ds dataSet) error {
+	enc := json.NewEncoder(w)
+	head := ds
+	head.Files = make([]benchFile, len(ds.Files))
+	for i, f := range ds.Files {
+		head.Files[i] = f
+		head.Files[i].Benchmarks = []*benchmark{}
+	}
+	if err := enc.Encode(event{Type: eventData, Data: &head}); err != nil {
+		return err
+	}
+	n := 0
+	for i, f := range ds.Files {
+		for _, b := range f.Benchmarks {
+			if err := enc.Encode(event{Type: eventParse, File: i, Benchmark: b}); err != nil {
+				return err
+			}
+			n++
+			if n%ndjsonFlush == 0 {
+				if fl, ok := w.(http.Flusher); ok {
+					fl.Flush()
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteNDJSON(t *testing.T) {
+	var files []benchFile
+	for _, in := range []string{
+		"BenchmarkSort10-4\t1000\t100 ns/op\nBenchmarkSort100-4\t100\t1000 ns/op\n",
+		"BenchmarkSort10-4\t1000\t110 ns/op\n",
+	} {
+		bf, err := parseBenchFile(strings.NewReader(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, bf)
+	}
+	ds := dataSet{Version: dataVersion, Files: files}
+	var buf bytes.Buffer
+	if err := writeNDJSON(&buf, ds); err != nil {
+		t.Fatal(err)
+	}
+
+	// replaying the events rebuilds the data set
+	var got *dataSet
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var e event
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		switch e.Type {
+		case eventData:
+			got = e.Data
+		case eventParse:
+			got.Files[e.File].Benchmarks = append(got.Files[e.File].Benchmarks, e.Benchmark)
+		}
+	}
+	if len(got.Files) != 2 || len(got.Files[0].Benchmarks) != 2 || len(got.Files[1].Benchmarks) != 1 || got.Files[1].Benchmarks[0].NsPerOp != 110 {
+		t.Errorf("got data set %+v, want the original", got)
+	}
+	if len(ds.Files[0].Benchmarks) != 2 {
+		t.Errorf("writeNDJSON changed the data set")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// partialResidual is the component plus residual plot of one term of a fit.
+// Each benchmark is plotted at the value of the term, against the term's
+// part of the fit plus the benchmark's residual, so that a term which is
+// well specified lies along a straight line through the origin with slope
+// Beta, and curvature which the model misses shows up as a bend.
+type partialResidual struct {
+	Term   string
+	Beta   float64
+	Points []partialPoint
+	Means  []partialPoint // the mean at each N, in increasing order, which shows the bend
+}
+
+// partialPoint is a benchmark, or the mean of the benchmarks at an N, in a
+// partial residual plot.
+type partialPoint struct {
+	N float64
+	X float64 // the value of the term at N
+	Y float64 // the term's part of the fit, plus the residual
+}
+
+// partialResiduals returns the partial residual plot of every term of the
+// fit which isn't constant.
+func partialResiduals(gf groupFit, yVar string) []partialResidual {
+	s := sampleGroup(gf.benchSet, gf.xTransform, yVar)
+	p := len(gf.xTransform)
+	n := len(s.y)
+	residuals := make([]float64, n)
+	for i, y := range s.y {
+		residuals[i] = y - dot(gf.beta, s.x[i*p:(i+1)*p])
+	}
+
+	prs := []partialResidual{}
+	for j, term := range gf.xTransform {
+		constant := true
+		for i := 1; i < n; i++ {
+			if s.x[i*p+j] != s.x[j] {
+				constant = false
+				break
+			}
+		}
+		if constant {
+			continue
+		}
+		pr := partialResidual{Term: term.String(), Beta: gf.beta[j]}
+		sums := make(map[float64]*partialPoint)
+		counts := make(map[float64]int)
+		for i, b := range gf.benchSet {
+			x := s.x[i*p+j]
+			pt := partialPoint{b.X, x, gf.beta[j]*x + residuals[i]}
+			pr.Points = append(pr.Points, pt)
+			if m, ok := sums[b.X]; ok {
+				m.Y += pt.Y
+			} else {
+				sums[b.X] = &pt
+			}
+			counts[b.X]++
+		}
+		for n, m := range sums {
+			pr.Means = append(pr.Means, partialPoint{n, m.X, m.Y / float64(counts[n])})
+		}
+		sort.Sort(byPartialN(pr.Means))
+		prs = append(prs, pr)
+	}
+	return prs
+}
+
+type byPartialN []partialPoint
+
+func (a byPartialN) Len() int           { return len(a) }
+func (a byPartialN) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byPartialN) Less(i, j int) bool { return a[i].N < a[j].N }
+
+// servePartialResiduals returns the partial residual plots of the group in
+// the querystring, fit with its model.
+func servePartialResiduals(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q, err := parseAnalysisQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		group := r.Form.Get("group")
+		fits, err := q.fitGroupsContext(r.Context(), src.dataSet())
+		if err != nil {
+			fitError(w, err)
+			return
+		}
+		for _, gf := range fits {
+			if gf.Group == group {
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(partialResiduals(gf, q.yVar))
+				return
+			}
+		}
+		http.Error(w, "unable to fit group: "+group, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+func TestPartialResiduals(t *testing.T) {
+	var benchSet []benchmarkResponse
+	for _, x := range []float64{10, 20, 30, 40, 50} {
+		for _, e := range []float64{-1, 1} {
+			benchSet = append(benchSet, benchmarkResponse{Benchmark: parse.Benchmark{NsPerOp: x*x + 5 + e}, X: x})
+		}
+	}
+	// bend measures how far the means of the only non-constant term are
+	// from its fitted line.
+	bend := func(xtransform string) float64 {
+		q, err := parseAnalysisQuery(url.Values{"xtransform": {xtransform}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		gf, ok := fitGroup(context.Background(), "square", benchSet, q.xTransform, q.yVar, q.fitter)
+		if !ok {
+			t.Fatal("unable to fit")
+		}
+		prs := partialResiduals(gf, q.yVar)
+		if len(prs) != 1 || len(prs[0].Points) != len(benchSet) || len(prs[0].Means) != 5 {
+			t.Fatalf("%s: got %+v, want one term with 10 points and 5 means", xtransform, prs)
+		}
+		var dev float64
+		for _, m := range prs[0].Means {
+			dev = math.Max(dev, math.Abs(m.Y-prs[0].Beta*m.X))
+		}
+		return dev
+	}
+	if d := bend("N*N, 1.0"); d > 1e-6 {
+		t.Errorf("got bend %g of a well specified term, want none", d)
+	}
+	if d := bend("N, 1.0"); d < 10 {
+		t.Errorf("got bend %g of a misspecified term, want a large one", d)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// perfSuffix names the sidecar file of hardware counters for a benchmark
+// file, like bench.txt.perf.
+const perfSuffix = ".perf"
+
+// perfCounters are hardware event counts from perf stat, per op.  They are
+// nil if they weren't counted, so that a count of zero is kept.
+type perfCounters struct {
+	InstructionsPerOp *float64 `json:",omitempty"`
+	CacheMissesPerOp  *float64 `json:",omitempty"`
+	BranchMissesPerOp *float64 `json:",omitempty"`
+}
+
+// perfEvents maps the perf events to the responses that they are counted in.
+var perfEvents = map[string]string{
+	"instructions":  "InstructionsPerOp",
+	"cache-misses":  "CacheMissesPerOp",
+	"branch-misses": "BranchMissesPerOp",
+}
+
+// counter returns the count of the named response, or nil if yVar isn't a
+// counter.
+func (c *perfCounters) counter(yVar string) **float64 {
+	switch yVar {
+	case "InstructionsPerOp":
+		return &c.InstructionsPerOp
+	case "CacheMissesPerOp":
+		return &c.CacheMissesPerOp
+	case "BranchMissesPerOp":
+		return &c.BranchMissesPerOp
+	}
+	return nil
+}
+
+// count returns the count of the named response, and whether it was counted.
+func (c *perfCounters) count(yVar string) (float64, bool) {
+	if p := c.counter(yVar); p != nil && *p != nil {
+		return **p, true
+	}
+	return 0, false
+}
+
+// setCount sets the count of the named response.  The counts are shared by
+// copies of the counters, so they're replaced rather than changed.
+func (c *perfCounters) setCount(yVar string, count float64) {
+	if p := c.counter(yVar); p != nil {
+		*p = &count
+	}
+}
+
+// has reports whether the response yVar was measured, which it always was
+// unless it is a counter that wasn't counted.  The benchmarks without it
+// are left out of the plots and fits, rather than treated as zero.
+func (c *perfCounters) has(yVar string) bool {
+	p := c.counter(yVar)
+	return p == nil || *p != nil
+}
+
+// counted returns the benchmarks of benchSet which have the response yVar.
+func counted(benchSet []benchmarkResponse, yVar string) []benchmarkResponse {
+	var bs []benchmarkResponse
+	for i := range benchSet {
+		if benchSet[i].has(yVar) {
+			bs = append(bs, benchSet[i])
+		}
+	}
+	return bs
+}
+
+// parsePerfLine parses a line of perf stat -x, output, like
+// "123456,,instructions:u,1000000,100.00,,".  Counters which perf could not
+// count, and events which aren't plotted, are not ok.
+func parsePerfLine(text string) (yVar string, count float64, ok bool) {
+	fields := strings.Split(text, ",")
+	if len(fields) < 4 {
+		return "", 0, false
+	}
+	count, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || count < 0 {
+		return "", 0, false
+	}
+	yVar, ok = perfEvents[perfEventName(fields[2])]
+	return yVar, count, ok
+}
+
+// perfEventName strips the PMU and modifiers from an event, so that
+// "cpu_core/instructions/u" and "instructions:u" are both "instructions".
+func perfEventName(event string) string {
+	if i := strings.Index(event, "/"); i >= 0 {
+		event = strings.TrimSuffix(event[i+1:], "/")
+		if j := strings.LastIndex(event, "/"); j >= 0 {
+			event = event[:j]
+		}
+	}
+	if i := strings.Index(event, ":"); i >= 0 {
+		event = event[:i]
+	}
+	return event
+}
+
+// addCounter attaches the total count of a run of the benchmark, which is
+// divided by its iterations.  perf stat counts the whole process, including
+// the runs go test uses to choose the iterations, so it is an over estimate
+// of the count of each op, which is most accurate when the benchmark is run
+// for a long -benchtime.
+func (b *benchmark) addCounter(yVar string, count float64) {
+	if b.N <= 0 {
+		return
+	}
+	b.setCount(yVar, count/float64(b.N))
+}
+
+// readPerfSidecar attaches the counters in the sidecar file of the named
+// benchmark file, if there is one.  Each line of the sidecar is the name of
+// a benchmark, followed by a line of perf stat -x, output from running it,
+// like "BenchmarkSort/n=100-8,123456,,instructions,1000000,100.00,,".  The
+// counters of repeated runs of a benchmark are attached to its runs in
+// order.
+func (bf *benchFile) readPerfSidecar(fn string) error {
+	f, err := os.Open(fn + perfSuffix)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	type key struct{ name, yVar string }
+	runs := make(map[string][]*benchmark)
+	for _, b := range bf.Benchmarks {
+		runs[b.Name] = append(runs[b.Name], b)
+	}
+	seen := make(map[key]int)
+	return scanLines(f, func(line int, text string) {
+		i := strings.Index(text, ",")
+		if i < 0 {
+			return
+		}
+		name := text[:i]
+		yVar, count, ok := parsePerfLine(text[i+1:])
+		if !ok {
+			return
+		}
+		k := key{name, yVar}
+		if n := seen[k]; n < len(runs[name]) {
+			runs[name][n].addCounter(yVar, count)
+		}
+		seen[k]++
+	})
+}
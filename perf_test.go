@@ -0,0 +1,191 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePerfLine(t *testing.T) {
+	tests := []struct {
+		text  string
+		yVar  string
+		count float64
+		ok    bool
+	}{
+		{"123456,,instructions,1000000,100.00,,", "InstructionsPerOp", 123456, true},
+		{"789,,cache-misses:u,1000000,100.00,,", "CacheMissesPerOp", 789, true},
+		{"42,,cpu_core/branch-misses/,1000000,100.00,,", "BranchMissesPerOp", 42, true},
+		{"<not counted>,,cache-misses,0,0.00,,", "", 0, false},
+		{"1000,,cycles,1000000,100.00,,", "", 0, false},
+		{"ok  	github.com/jonlawlor/benchplot	1.0s", "", 0, false},
+	}
+	for _, test := range tests {
+		yVar, count, ok := parsePerfLine(test.text)
+		if ok != test.ok || (ok && (yVar != test.yVar || count != test.count)) {
+			t.Errorf("parsePerfLine(%q) = %q, %g, %v, want %q, %g, %v", test.text, yVar, count, ok, test.yVar, test.count, test.ok)
+		}
+	}
+}
+
+func TestPerfInterleaved(t *testing.T) {
+	bf, err := parseBenchFile(strings.NewReader(`BenchmarkSort/n=10-8   	1000	      1008 ns/op
+2000000,,instructions,1000000,100.00,,
+3000,,cache-misses,1000000,100.00,,
+BenchmarkSort/n=100-8  	100	      8224 ns/op
+PASS
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bf.Benchmarks) != 2 {
+		t.Fatalf("got %d benchmarks, want 2", len(bf.Benchmarks))
+	}
+	b := bf.Benchmarks[0]
+	if b.InstructionsPerOp == nil || *b.InstructionsPerOp != 2000 || b.CacheMissesPerOp == nil || *b.CacheMissesPerOp != 3 || b.BranchMissesPerOp != nil {
+		t.Errorf("got counters %+v of the first benchmark", b.perfCounters)
+	}
+	if c := bf.Benchmarks[1].perfCounters; c != (perfCounters{}) {
+		t.Errorf("got counters %+v of the second benchmark, want none", c)
+	}
+	if bf.Skipped != 1 {
+		t.Errorf("got %d skipped lines, want 1", bf.Skipped)
+	}
+}
+
+func TestPerfSidecar(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "bench.txt")
+	if err := ioutil.WriteFile(fn, []byte(`BenchmarkSort/n=10-8   	1000	      1008 ns/op
+BenchmarkSort/n=10-8   	2000	      1010 ns/op
+`), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(fn+perfSuffix, []byte(`BenchmarkSort/n=10-8,500000,,branch-misses,1000000,100.00,,
+BenchmarkSort/n=10-8,600000,,branch-misses,1000000,100.00,,
+BenchmarkSort/n=10-8,1,,branch-misses,1000000,100.00,,
+`), 0666); err != nil {
+		t.Fatal(err)
+	}
+	bf := readBenchFile(fn)
+	if bf.Err != "" {
+		t.Fatal(bf.Err)
+	}
+	if got, _ := bf.Benchmarks[0].count("BranchMissesPerOp"); got != 500 {
+		t.Errorf("got %g branch-misses/op of the first run, want 500", got)
+	}
+	if got, _ := bf.Benchmarks[1].count("BranchMissesPerOp"); got != 300 {
+		t.Errorf("got %g branch-misses/op of the second run, want 300", got)
+	}
+
+	benchSet := []benchmarkResponse{
+		{perfCounters: bf.Benchmarks[0].perfCounters, X: 10},
+		{perfCounters: bf.Benchmarks[1].perfCounters, X: 10},
+	}
+	s := sampleGroup(benchSet, mustParseTransform(t, "1"), "BranchMissesPerOp")
+	if s.y[0] != 500 || s.y[1] != 300 {
+		t.Errorf("got responses %v, want [500 300]", s.y)
+	}
+}
+
+func TestUncounted(t *testing.T) {
+	// the benchmarks at N=1000 weren't counted
+	bf, err := parseBenchFile(strings.NewReader(`BenchmarkSort10-8   	1000	      100 ns/op
+10000,,instructions,1000000,100.00,,
+BenchmarkSort100-8   	1000	      1000 ns/op
+100000,,instructions,1000000,100.00,,
+BenchmarkSort200-8   	1000	      2000 ns/op
+200000,,instructions,1000000,100.00,,
+0,,branch-misses,1000000,100.00,,
+BenchmarkSort1000-8   	1000	      10000 ns/op
+BenchmarkSort1000-8   	1000	      10000 ns/op
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := dataSet{Files: []benchFile{bf}}
+	tests := []struct {
+		yVar string
+		n    int
+	}{
+		{"", 5},
+		{"NsPerOp", 5},
+		{"InstructionsPerOp", 3},
+		{"CacheMissesPerOp", 0},
+		// a count of zero is still a count
+		{"BranchMissesPerOp", 1},
+	}
+	for _, test := range tests {
+		g, err := parseGrouping(url.Values{"yvar": {test.yVar}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		groups, _, _, _ := groupBenchmarks(ds, g)
+		if got := len(groups["BenchmarkSort"]); got != test.n {
+			t.Errorf("yvar %q: got %d benchmarks, want %d", test.yVar, got, test.n)
+		}
+		if got := len(counted(groups["BenchmarkSort"], test.yVar)); got != test.n {
+			t.Errorf("yvar %q: counted %d benchmarks, want %d", test.yVar, got, test.n)
+		}
+	}
+
+	// the fit isn't pulled towards zero by the benchmarks without counts
+	g, err := parseGrouping(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups, _, _, _ := groupBenchmarks(ds, g)
+	res, err := fit(context.Background(), groups["BenchmarkSort"], fitRequest{
+		xlb: 10, xub: 1000, xTransform: mustParseTransform(t, "N"),
+		yVar: "InstructionsPerOp", nLineSteps: 2, fitter: olsFitter{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.ResultLine[1].Yhat; math.Abs(got-1000) > 1e-6 {
+		t.Errorf("got %g instructions/op at N=1000, want 1000", got)
+	}
+}
+
+func TestAggregateCounters(t *testing.T) {
+	count := func(c float64) *float64 { return &c }
+	benchSet := []benchmarkResponse{
+		{perfCounters: perfCounters{InstructionsPerOp: count(2000), BranchMissesPerOp: count(0)}, X: 10},
+		{X: 10},
+		{perfCounters: perfCounters{InstructionsPerOp: count(1000)}, X: 10},
+		{X: 20},
+	}
+	for _, aggregate := range []string{aggregateMean, aggregateMedian, aggregateMin} {
+		agg := aggregateRuns(benchSet, aggregate)
+		if len(agg) != 2 {
+			t.Fatalf("%s: got %d summaries, want 2", aggregate, len(agg))
+		}
+		// the uncounted run doesn't pull the summary to zero
+		if c, ok := agg[0].count("InstructionsPerOp"); !ok || c < 1000 {
+			t.Errorf("%s: got %g instructions/op, counted %v", aggregate, c, ok)
+		}
+		if c, ok := agg[0].count("BranchMissesPerOp"); !ok || c != 0 {
+			t.Errorf("%s: got %g branch-misses/op, counted %v, want a count of 0", aggregate, c, ok)
+		}
+		if agg[0].has("CacheMissesPerOp") || agg[1].has("InstructionsPerOp") {
+			t.Errorf("%s: got counters %+v and %+v which weren't counted", aggregate, agg[0].perfCounters, agg[1].perfCounters)
+		}
+	}
+	// the runs' counts aren't changed
+	if *benchSet[0].InstructionsPerOp != 2000 {
+		t.Errorf("got %g instructions/op of the first run after aggregating, want 2000", *benchSet[0].InstructionsPerOp)
+	}
+}
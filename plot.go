@@ -15,13 +15,25 @@ package main
 // TODO(jonlawlor): serve d3.js locally so that benchplot works without an
 // internet connection.
 
+// d3URL is where the plotter loads d3 from.
+const d3URL = "http://d3js.org/d3.v3.min.js"
+
+// d3Script is the script element which loads d3.  Reports replace it with
+// an inline copy of d3.
+const d3Script = `<script src="` + d3URL + `" charset="utf-8"></script>`
+
+// reportVar is the declaration of the embedded report in the plotter.  Reports
+// replace it with the data and fitted models, so that the plotter does not
+// need to make any requests to the server.
+const reportVar = "var report = null"
+
 const plotHTML = `
 <!DOCTYPE html>
 <html lang="en">
 	<head>
 		<meta charset="utf-8">
 		<title>go benchplot</title>
-    <script src="http://d3js.org/d3.v3.min.js" charset="utf-8"></script>
+    ` + d3Script + `
 		<style type="text/css">
 
 			.axis path,
@@ -44,17 +56,132 @@ const plotHTML = `
         stroke: #000;
       }
 
+      .std {
+        color: #777;
+      }
+
+      .distribution rect {
+        fill: steelblue;
+      }
+
+      .spread line, .spread rect {
+        stroke-width: 1px;
+        fill-opacity: 0.3;
+      }
+
       .line {
         fill: none;
         stroke: steelblue;
         stroke-width: 1.5px;
       }
-      .boundline {
+      .extrapolated {
+        opacity: 0.4;
+      }
+      .band {
+        fill: steelblue;
+        fill-opacity: 0.2;
+        stroke: none;
+      }
+      .panels .hover, .hover {
+        stroke: #888;
+        stroke-dasharray: 2,2;
+        pointer-events: none;
+      }
+      .crossover {
+        stroke: gray;
+        stroke-dasharray: 4,4;
+      }
+      .crossoverband {
+        fill: gray;
+        fill-opacity: 0.15;
+      }
+
+      .warnings div {
+        background: #fff3cd;
+        border: 1px solid #e0c060;
+        margin: 2px 0;
+        padding: 4px;
+      }
+
+      .controls {
+        margin-bottom: 4px;
+      }
+
+      .annotation line {
+        stroke: #888;
+        stroke-dasharray: 4, 3;
+      }
+      .annotation text {
+        fill: #555;
+        font-size: 10px;
+      }
+
+      .notes div {
+        color: #555;
+        margin: 2px 0;
+        white-space: pre-wrap;
+      }
+
+      .files {
+        float: right;
+        max-width: 300px;
+        max-height: 500px;
+        overflow-y: auto;
+        border-left: 1px solid #ccc;
+        padding-left: 4px;
+      }
+      .files label {
+        display: block;
+        white-space: nowrap;
+      }
+
+      .fits tr.collinear td {
+        background: #fff3cd;
+      }
+      .fits .nonfinite {
+        color: #a94442;
+      }
+      .machines tr.regression td {
+        background: #f8d7da;
+      }
+      .ratio .band {
+        fill: steelblue;
+        fill-opacity: 0.2;
+      }
+      .ratio .line {
         fill: none;
         stroke: steelblue;
-        stroke-dasharray: 10,10;
         stroke-width: 1.5px;
       }
+      .ratio .one {
+        stroke: #888;
+        stroke-dasharray: 4, 3;
+      }
+      .controls input.invalid {
+        background: #f8d7da;
+      }
+
+      .unparameterized td, .unparameterized th {
+        padding: 0 8px;
+        text-align: left;
+      }
+
+      .fits td, .fits th, .coefficients td, .coefficients th, .chow td, .chow th {
+        padding: 0 8px;
+        text-align: left;
+      }
+
+      .predictions .extrapolated, .crossovers .extrapolated {
+        opacity: 1;
+        font-style: italic;
+      }
+
+      body.embed {
+        margin: 0;
+      }
+      body.embed > div:not(.tooltip) {
+        display: none !important;
+      }
 
       .tooltip {
         position: absolute;
@@ -66,6 +193,18 @@ const plotHTML = `
 	</head>
 	<body>
 		<script type="text/javascript">
+      // the data and fitted models, when this is a standalone report.
+      ` + reportVar + `
+      // the WebAssembly build of benchplot, in base64, if the report can be
+      // refit without a server.
+      ` + wasmVar + `
+      // whether the plotter is embedded in another page, from /embed, in
+      // which case only the chart is shown.
+      ` + embedVar + `
+      if (embed) {
+        d3.select("body").classed("embed", true)
+      }
+
       var w = 600
       var h = 400
       // TODO(jonlawlor): automatically change the lefthand scale from ns/op
@@ -75,19 +214,353 @@ const plotHTML = `
         width = w - margin.left - margin.right,
         height = h - margin.top - margin.bottom;
 
-      // TODO(jonlawlor): allow user to specify response
+      // the response to plot and fit
       var yVar = 'NsPerOp'
 
-      // regex to match the explanatory variable.
-      // TODO(jonlawlor): allow user to specify X variable and grouping regexp
-      var nre = /^(.*?)\/?(\d+)-\d+$/
+      // the layout of the plot: either a single chart of the response, or
+      // "panels", where a panel of each of the other responses in panelYs is
+      // stacked below it, with the same N axis, so that every measurement of
+      // the benchmarks can be reviewed together.
+      var layout = "single"
+      var panelYs = {AllocedBytesPerOp: true, AllocsPerOp: true}
+      var panelHeight = 150
+
+      // human readable units of the responses, which matches validYs.
+      var yUnits = {
+        NsPerOp: "ns/op",
+        AllocedBytesPerOp: "B/op",
+        AllocsPerOp: "allocs/op",
+        MBPerS: "MB/s",
+        OpsPerS: "ops/s",
+        InstructionsPerOp: "instructions/op",
+        CacheMissesPerOp: "cache-misses/op",
+        BranchMissesPerOp: "branch-misses/op"
+      }
+
+      // the responses which are rates, where larger is better
+      var throughputs = {MBPerS: true, OpsPerS: true}
+
+      // the responses which are perf counters.  Benchmarks which weren't
+      // counted don't have them, and aren't plotted or fit rather than being
+      // zero.
+      var perfCounters = {InstructionsPerOp: true, CacheMissesPerOp: true, BranchMissesPerOp: true}
+
+      // regex to match the group and explanatory variable.  It can be changed
+      // by the user.
+      var nre = /` + defaultNRE + `/
+
+      // compileNRE compiles the N pattern, which is either a regexp or a name
+      // template like Benchmark{Group}/{N:int}.  It is the same as the
+      // server's compileNRE, and throws an Error if the pattern is invalid.
+      function compileNRE(s) {
+        var varre = /\{([A-Za-z_][A-Za-z0-9_]*)(?::([A-Za-z]+))?\}/g
+        if (!s.match(varre)) {
+          return new RegExp(s)
+        }
+        var types = {
+          "int": "\\d+",
+          "float": "\\d+(?:\\.\\d*)?(?:[eE][-+]?\\d+)?",
+          "string": ".*?"
+        }
+        var quote = function(t) { return t.replace(/[\\.+*?()|[\]{}^$]/g, "\\$&") }
+        var vars = []
+        var m
+        while ((m = varre.exec(s)) !== null) {
+          var v = {name: m[1], type: m[2] || "string", start: m.index, end: varre.lastIndex}
+          if (!types[v.type]) {
+            throw new Error("unknown type " + v.type + " of " + v.name)
+          }
+          vars.push(v)
+        }
+        var n = vars.map(function(v) { return v.name }).indexOf("N")
+        if (n < 0) {
+          var numeric = []
+          vars.forEach(function(v, i) {
+            if (v.type != "string") {
+              numeric.push(i)
+            }
+          })
+          if (numeric.length != 1) {
+            throw new Error("no variable named N, or single int or float variable, to plot against")
+          }
+          n = numeric[0]
+        }
+        var re = ""
+        var prev = 0
+        var hasGroup = false
+        vars.forEach(function(v, i) {
+          re += quote(s.slice(prev, v.start))
+          prev = v.end
+          if (i == n) {
+            if (!hasGroup) {
+              // the group is everything before N
+              re = "(" + re + ")"
+            }
+            re += "(" + types[v.type] + ")"
+          } else if (v.name == "Group") {
+            if (i > n) {
+              throw new Error("Group has to come before N")
+            }
+            hasGroup = true
+            re += "(" + types[v.type] + ")"
+          } else {
+            re += "(?:" + types[v.type] + ")"
+          }
+        })
+        re += quote(s.slice(prev))
+        var compiled = new RegExp("^" + re + "(?:-\\d+)?$")
+        compiled.nName = vars[n].name
+        return compiled
+      }
+
+      // regex to strip the GOMAXPROCS suffix from benchmarks which don't match
+      // nre, when they are plotted at N=1.
+      var procre = /^(.*?)(-\d+)?$/
+
+      // how to handle benchmarks which don't match nre: either list them in a
+      // table, or plot them at N=1.
+      var unparameterized = "` + unparamTable + `"
+
+      // the package to plot, or "" for all of them, and whether to group
+      // benchmarks by package as well as by name.
+      var pkgFilter = ""
+      var groupByPackage = false
+
+      // the filter of the groups to plot, which either matches the groups
+      // containing it, ignoring case, or is a regexp.  groupMatcher is the
+      // compiled filter, or null if there isn't one.
+      var groupSearch = ""
+      var searchRegexp = false
+      var groupMatcher = null
+
+      // compileFilter compiles the filter of the groups into a function which
+      // reports whether it matches a group.  It is the same as the server's
+      // compileFilter, and throws an Error if the regexp is invalid.
+      function compileFilter(s, isRegexp) {
+        if (s == "") {
+          return null
+        }
+        if (isRegexp) {
+          var re = new RegExp(s)
+          return function(group) { return re.test(group) }
+        }
+        s = s.toLowerCase()
+        return function(group) { return group.toLowerCase().indexOf(s) >= 0 }
+      }
+
+      // the groups which were hidden by clicking them in the legend.  They're
+      // left out of the fits and analyses as well as the plot, and are still
+      // listed in the legend, so that they can be shown again.
+      var hiddenGroups = {}
+
+      // plotsGroup reports whether the group is plotted, rather than being
+      // left out by the filter or ?group=.
+      function plotsGroup(group) {
+        return (groupFilter == "" || group == groupFilter) && (!groupMatcher || groupMatcher(group))
+      }
+
+      // the only group to plot, or "" for all of them, from ?group= in the
+      // querystring, which the charts of the dashboard link to.
+      var groupFilter = ""
+      if (!report) {
+        var groupParam = /[?&]group=([^&]*)/.exec(location.search)
+        if (groupParam) {
+          groupFilter = decodeURIComponent(groupParam[1].replace(/\+/g, " "))
+        }
+        // the webhooks link to the plotter filtered to a regressed group
+        var filterParam = /[?&]filter=([^&]*)/.exec(location.search)
+        if (filterParam) {
+          groupSearch = decodeURIComponent(filterParam[1].replace(/\+/g, " "))
+          searchRegexp = /[?&]filterre=true(&|$)/.test(location.search)
+          try {
+            groupMatcher = compileFilter(groupSearch, searchRegexp)
+          } catch (e) {
+            console.log("invalid filter " + groupSearch + ":", e.message)
+          }
+        }
+      }
+
+      // the machine to plot, or "" for all of them, and whether to group
+      // benchmarks by machine, so that the same benchmarks run on different
+      // hardware can be told apart.
+      var machineFilter = ""
+      var groupByMachine = false
+
+      // whether to group benchmarks by the file they're from, so that files
+      // like before.txt and after.txt can be told apart.  Each file's points
+      // are drawn with their own marker then.
+      var groupByFile = false
+
+      // the two machines to compare, if both are set.  Only their benchmarks
+      // are plotted, grouped by machine.  This needs the server, so it isn't
+      // available in reports.
+      var compareA = ""
+      var compareB = ""
 
-      // TODO(jonlawlor): allow user to specify the explanatory function to fit on.
-      var xTransform = "math.Log(N) * N, 1.0"
+      // the group that every group is divided by, at the same N, so that
+      // their relative performance can be read directly.  "" plots the
+      // responses themselves.  The baseline is the first model's fit, from
+      // the server, so it isn't available in reports.
+      var baselineGroup = ""
+
+      // comparing reports whether two machines are being compared.
+      function comparing() {
+        return !report && compareA != "" && compareB != "" && compareA != compareB
+      }
+
+      // groups with more points than this are drawn from a summary by the
+      // server, although they are still fit on every point.  0 draws every
+      // point.
+      var maxPoints = 500
+
+      // the paths of the files which are left out of the plot and the
+      // analysis, which are unchecked in the files panel
+      var hiddenFiles = {}
+
+      // how to summarize repeated runs of a benchmark at the same N
+      var aggregation = "` + aggregateAll + `"
+
+      // what the confidence intervals are clustered by, so that runs in the
+      // same file or on the same machine aren't treated as independent
+      var clusterBy = "` + clusterNone + `"
+
+      // the explanatory functions to fit on.  Each of them is fit to every
+      // group, so that candidate models can be compared, and they are drawn
+      // with the corresponding line style.
+      var xTransforms = ["` + defaultXTransform + `"]
+      var maxTransforms = 3
+      var transformDashes = [null, "8,4", "2,3"]
+
+      // the range to evaluate the regressions over, which can extend beyond
+      // the benchmarks to extrapolate.  null means the range of the data.
+      var xlbSetting = null
+      var xubSetting = null
 
       // the number of points to evaluate for the regressions
       var nLineSteps = 1000
 
+      // the estimator of the models, from the server's fitters
+      var fitterName = "` + defaultFitter + `"
+
+      // the strength of regularization, for fitters which take one.  The
+      // server's default is used if it is empty.
+      var lambdaSetting = ""
+
+      // applySettings sets the settings of the plotter from those of a saved
+      // view, or an earlier state.  The filters, fit range, and lambda which
+      // aren't in c are cleared, and the other settings are left as they
+      // are.  The controls are shown with them by showSettings.
+      function applySettings(c) {
+        if (c.NRE) {
+          try {
+            nre = compileNRE(c.NRE)
+          } catch (e) {
+            console.log("invalid N pattern " + c.NRE + ":", e.message)
+          }
+        }
+        unparameterized = c.Unparameterized || unparameterized
+        pkgFilter = c.Package || ""
+        groupByPackage = !!c.GroupByPackage
+        machineFilter = c.Machine || ""
+        groupByMachine = !!c.GroupByMachine
+        groupByFile = !!c.GroupByFile
+        groupSearch = c.Filter || ""
+        searchRegexp = !!c.FilterRegexp
+        try {
+          groupMatcher = compileFilter(groupSearch, searchRegexp)
+        } catch (e) {
+          console.log("invalid filter " + groupSearch + ":", e.message)
+        }
+        baselineGroup = c.Baseline || ""
+        yVar = c.YVar || yVar
+        aggregation = c.Aggregate || aggregation
+        clusterBy = c.Cluster || clusterBy
+        hiddenFiles = {}
+        if (c.HiddenFiles) {
+          c.HiddenFiles.forEach(function(path) { hiddenFiles[path] = true })
+        }
+        hiddenGroups = {}
+        if (c.HiddenGroups) {
+          c.HiddenGroups.forEach(function(group) { hiddenGroups[group] = true })
+        }
+        if (c.XTransforms && c.XTransforms.length > 0) {
+          xTransforms = c.XTransforms.slice()
+        }
+        xlbSetting = c.XLB === undefined ? null : c.XLB
+        xubSetting = c.XUB === undefined ? null : c.XUB
+        fitterName = c.Fitter || fitterName
+        lambdaSetting = c.Lambda || ""
+      }
+
+      // the saved view which the plotter was opened with, from /views.  Its
+      // settings replace the defaults.
+      ` + viewVar + `
+      if (view) {
+        applySettings(view.Config)
+        if (view.Name) {
+          document.title = "go benchplot: " + view.Name
+        }
+      }
+
+      // viewConfig returns the settings of the plotter, to save as a view.
+      function viewConfig() {
+        var c = {
+          NRE: controls.select("input.nre").property("value"),
+          Unparameterized: unparameterized,
+          Package: pkgFilter,
+          GroupByPackage: groupByPackage,
+          Machine: machineFilter,
+          GroupByMachine: groupByMachine,
+          GroupByFile: groupByFile,
+          Filter: groupSearch,
+          FilterRegexp: searchRegexp,
+          Baseline: baselineGroup,
+          YVar: yVar,
+          Aggregate: aggregation,
+          Cluster: clusterBy,
+          HiddenFiles: Object.keys(hiddenFiles),
+          HiddenGroups: Object.keys(hiddenGroups),
+          XTransforms: xTransforms,
+          Fitter: fitterName,
+          Lambda: lambdaSetting,
+          Annotations: annotations
+        }
+        if (xlbSetting !== null) {
+          c.XLB = xlbSetting
+        }
+        if (xubSetting !== null) {
+          c.XUB = xubSetting
+        }
+        return c
+      }
+
+      // reports are fit with their own settings
+      if (report) {
+        yVar = report.YVar
+        xTransforms = report.XTransforms
+        xlbSetting = report.XLB
+        xubSetting = report.XUB
+        nLineSteps = report.NLineSteps
+        fitterName = report.Fitter || fitterName
+        lambdaSetting = report.Lambda || ""
+        nre = new RegExp(report.NRE)
+        unparameterized = report.Unparameterized
+        aggregation = report.Aggregate || aggregation
+        clusterBy = report.Cluster || clusterBy
+        if (report.UnitLabel) {
+          yUnits[yVar] = report.UnitLabel
+        }
+      }
+
+      // divisor converts the response of a benchmark to the units of the
+      // report, which its fits are already in.
+      function divisor(d) {
+        if (!report || !report.Unit || !report.Unit.Scale) {
+          return 1
+        }
+        return report.Unit.Scale * (report.Unit.PerElement ? d.X : 1)
+      }
+
       // setup x
       var xValue = function(d) { return d.X;}, // data -> value
           xScale = d3.scale.linear().range([0, width]), // value -> display
@@ -95,35 +568,1405 @@ const plotHTML = `
           xAxis = d3.svg.axis().scale(xScale).orient("bottom");
 
       // setup y
-      var yValue = function(d) { return d[yVar];}, // data -> value
+      var yValue = function(d) { return d[yVar] / divisor(d);}, // data -> value
           yScale = d3.scale.linear().range([height, 0]), // value -> display
           yMap = function(d) { return yScale(yValue(d));}, // data -> display
           yMap = function(d) { return yScale(yValue(d));}, // data -> display
           yAxis = d3.svg.axis().scale(yScale).orient("left");
 
-      // setup regression line, lower bound, upper bound
-      var regLine = d3.svg.line()
-          .x(function(d) { return xScale(d.X); })
-          .y(function(d) { console.log("regLine"); return yScale(d.Yhat); });
+      // setup regression line, and the band between its lower and upper
+      // bounds
+      var regLine = d3.svg.line()
+          .x(function(d) { return xScale(d.X); })
+          .y(function(d) { return yScale(d.Yhat); });
+
+      var confBand = d3.svg.area()
+          .x(function(d) { return xScale(d.X); })
+          .y0(function(d) { return yScale(d.Yhat - d.ConfWidth); })
+          .y1(function(d) { return yScale(d.Yhat + d.ConfWidth); });
+
+      // setup fill color
+      var cValue = function(d) { return d.Group;},
+          color = d3.scale.category10();
+
+      // add the controls to the webpage
+      var controls = d3.select("body").append("div")
+          .attr("class", "controls");
+
+      controls.append("label").text("N pattern: ");
+      controls.append("input")
+          .attr("class", "nre")
+          .attr("type", "text")
+          .attr("size", 30)
+          .property("value", view && view.Config.NRE ? view.Config.NRE : nre.source)
+          .on("change", function() {
+            try {
+              nre = compileNRE(this.value)
+            } catch (e) {
+              d3.select(this).classed("invalid", true).attr("title", e.message)
+              return
+            }
+            d3.select(this).classed("invalid", false).attr("title", null)
+            replot()
+          });
+
+      controls.append("label").text(" unmatched benchmarks: ");
+      var unparamSelect = controls.append("select")
+          .on("change", function() {
+            unparameterized = this.value
+            replot()
+          });
+      unparamSelect.append("option").attr("value", "` + unparamTable + `").text("list in a table");
+      unparamSelect.append("option").attr("value", "` + unparamOne + `").text("plot at N=1");
+      unparamSelect.property("value", unparameterized);
+
+      controls.append("label").text(" package: ");
+      var pkgSelect = controls.append("select")
+          .on("change", function() {
+            pkgFilter = this.value
+            replot()
+          });
+
+      controls.append("label").text(" group by package ");
+      var byPackageBox = controls.append("input")
+          .attr("type", "checkbox")
+          .property("checked", groupByPackage)
+          .on("change", function() {
+            groupByPackage = this.checked
+            replot()
+          });
+
+      controls.append("label").text(" machine: ");
+      var machineSelect = controls.append("select")
+          .on("change", function() {
+            machineFilter = this.value
+            replot()
+          });
+
+      controls.append("label").text(" group by machine ");
+      var byMachineBox = controls.append("input")
+          .attr("type", "checkbox")
+          .property("checked", groupByMachine)
+          .on("change", function() {
+            groupByMachine = this.checked
+            replot()
+          });
+
+      controls.append("label").text(" group by file ");
+      var byFileBox = controls.append("input")
+          .attr("type", "checkbox")
+          .property("checked", groupByFile)
+          .property("disabled", !!report && !localFit)
+          .on("change", function() {
+            groupByFile = this.checked
+            replot()
+          });
+
+      controls.append("label").text(" normalize to: ");
+      var baselineSelect = controls.append("select")
+          .property("disabled", !!report)
+          .on("change", function() {
+            baselineGroup = this.value
+            replot()
+          });
+
+      controls.append("label").text(" compare machines: ");
+      var compareASelect = controls.append("select")
+          .property("disabled", !!report)
+          .on("change", function() {
+            compareA = this.value
+            replot()
+          });
+      controls.append("label").text(" with ");
+      var compareBSelect = controls.append("select")
+          .property("disabled", !!report)
+          .on("change", function() {
+            compareB = this.value
+            replot()
+          });
+
+      controls.append("label").text(" response: ");
+      var yVarSelect = controls.append("select")
+          .property("disabled", !!report)
+          .on("change", function() {
+            // throughputs level off rather than growing, so they are
+            // fit with the saturating model, unless the model was changed.
+            var model = throughputs[yVar] ? "` + saturatingModel + `" : "` + defaultXTransform + `"
+            yVar = this.value
+            if (xTransforms[0] == model) {
+              xTransforms[0] = throughputs[yVar] ? "` + saturatingModel + `" : "` + defaultXTransform + `"
+              controls.select("input.transform").property("value", xTransforms[0])
+              showPresets()
+            }
+            replot()
+          });
+      yVarSelect.selectAll("option")
+          .data(Object.keys(yUnits))
+        .enter().append("option")
+          .attr("value", function(d) { return d })
+          .property("selected", function(d) { return d == yVar })
+          .text(function(d) { return yUnits[d] });
+
+      controls.append("label").text(" layout: ");
+      var layoutSelect = controls.append("select")
+          .on("change", function() {
+            layout = this.value
+            showPanelChoices()
+            replot()
+          });
+      layoutSelect.append("option").attr("value", "single").text("single chart");
+      layoutSelect.append("option").attr("value", "panels").text("a panel per response");
+      var panelChoices = controls.append("span");
+      panelChoices.selectAll("label")
+          .data(Object.keys(yUnits))
+        .enter().append("label")
+          .text(function(d) { return " " + yUnits[d] })
+        .insert("input", ":first-child")
+          .attr("type", "checkbox")
+          .property("checked", function(d) { return !!panelYs[d] })
+          .on("change", function(d) {
+            if (this.checked) {
+              panelYs[d] = true
+            } else {
+              delete panelYs[d]
+            }
+            replot()
+          });
+
+      // showPanelChoices only shows the responses to draw panels of in the
+      // panels layout.
+      function showPanelChoices() {
+        layoutSelect.property("value", layout)
+        panelChoices.style("display", layout == "panels" ? null : "none")
+        panelChoices.selectAll("input").property("checked", function(d) { return !!panelYs[d] })
+      }
+      showPanelChoices()
+
+      controls.append("label").text(" markers: ");
+      var markerSelect = controls.append("select")
+          .on("change", function() {
+            markerBy = this.value
+            replot()
+          });
+      markerSelect.selectAll("option")
+          .data([["auto", "auto"], ["group", "a shape per group"], ["circle", "circles"]])
+        .enter().append("option")
+          .attr("value", function(d) { return d[0] })
+          .text(function(d) { return d[1] });
+
+      controls.append("label").text(" repeated runs: ");
+      var aggregateSelect = controls.append("select")
+          .classed("refit", true)
+          .property("disabled", !!report)
+          .on("change", function() {
+            aggregation = this.value
+            replot()
+          });
+      aggregateSelect.append("option").attr("value", "` + aggregateAll + `").text("plot all");
+      aggregateSelect.append("option").attr("value", "` + aggregateMean + `").text("mean");
+      aggregateSelect.append("option").attr("value", "` + aggregateMedian + `").text("median");
+      aggregateSelect.append("option").attr("value", "` + aggregateMin + `").text("best");
+      aggregateSelect.property("value", aggregation);
+
+      controls.append("label").text(" intervals: ");
+      var clusterSelect = controls.append("select")
+          .classed("refit", true)
+          .property("disabled", !!report)
+          .on("change", function() {
+            clusterBy = this.value
+            replot()
+          });
+      clusterSelect.append("option").attr("value", "` + clusterNone + `").text("independent runs");
+      clusterSelect.append("option").attr("value", "` + clusterFile + `").text("clustered by file");
+      clusterSelect.append("option").attr("value", "` + clusterMachine + `").text("clustered by machine");
+      clusterSelect.property("value", clusterBy);
+
+      controls.append("label").text(" max points: ");
+      var maxPointsInput = controls.append("input")
+          .attr("type", "text")
+          .attr("size", 5)
+          .attr("placeholder", "all")
+          .property("disabled", !!report)
+          .property("value", maxPoints || "")
+          .on("change", function() {
+            var v = this.value.trim() == "" ? 0 : Number(this.value)
+            if (isNaN(v) || v < 0 || v != Math.floor(v)) {
+              d3.select(this).classed("invalid", true)
+              return
+            }
+            d3.select(this).classed("invalid", false)
+            maxPoints = v
+            replot()
+          });
+
+      // setSearch compiles the filter of the groups, and replots once it
+      // stops being typed.
+      function setSearch() {
+        var input = searchInput.node()
+        clearTimeout(input.searchTimer)
+        input.searchTimer = setTimeout(function() {
+          try {
+            groupMatcher = compileFilter(input.value.trim(), searchRegexp)
+          } catch (e) {
+            searchInput.classed("invalid", true).attr("title", e.message)
+            return
+          }
+          searchInput.classed("invalid", false).attr("title", null)
+          groupSearch = input.value.trim()
+          replot()
+        }, 200)
+      }
+      controls.append("br");
+      controls.append("label").text("filter groups: ");
+      var searchInput = controls.append("input")
+          .attr("type", "search")
+          .attr("size", 20)
+          .attr("placeholder", "name or regexp")
+          .property("value", groupSearch)
+          .on("input", setSearch);
+      controls.append("label").text(" regexp ");
+      var searchRegexpBox = controls.append("input")
+          .attr("type", "checkbox")
+          .property("checked", searchRegexp)
+          .on("change", function() {
+            searchRegexp = this.checked
+            setSearch()
+          });
+
+      controls.append("br");
+      // setTransforms sets the models from the model inputs, skipping the
+      // blank ones.
+      function setTransforms() {
+        xTransforms = []
+        controls.selectAll("input.transform").each(function() {
+          if (this.value.trim() != "") {
+            xTransforms.push(this.value)
+          }
+        })
+        showPresets()
+        replot()
+      }
+
+      // validateTransform marks a model input as invalid while it is being
+      // typed, with the error and where it is in the title.
+      function validateTransform() {
+        var input = this
+        clearTimeout(input.validateTimer)
+        input.validateTimer = setTimeout(function() {
+          var value = input.value
+          if (report || value.trim() == "") {
+            d3.select(input).classed("invalid", false).attr("title", null)
+            return
+          }
+          d3.json("validate?xtransform=" + encodeURIComponent(value), function(error, v) {
+            if (error || input.value != value) {
+              return
+            }
+            d3.select(input)
+                .classed("invalid", !v.Valid)
+                .attr("title", v.Valid ? v.Terms.join("\n") : (v.Pos >= 0 ? "at " + (v.Pos + 1) + ": " : "") + v.Error)
+          })
+        }, 200)
+      }
+
+      // the named models from the server, which are offered in place of
+      // typing in the terms.
+      var presets = []
+
+      // showPresets selects the preset matching each model input, and only
+      // shows the inputs of the custom models.
+      function showPresets() {
+        controls.selectAll("select.preset").each(function(d, t) {
+          var input = controls.selectAll("input.transform")[0][t]
+          var value = input.value.trim()
+          var name = t > 0 && value == "" ? "-" : ""
+          presets.forEach(function(p) {
+            if (p.XTransform == value) {
+              name = p.Name
+            }
+          })
+          d3.select(this).property("value", name)
+          d3.select(input).style("display", name == "" || report ? null : "none")
+        })
+      }
+
+      for (var t = 0; t < maxTransforms; t++) {
+        controls.append("label").text((t > 0 ? " " : "") + "model " + (t + 1) + ": ");
+        controls.append("select")
+            .attr("class", "preset")
+            .datum(t)
+            .style("display", report ? "none" : null)
+            .on("change", function(t) {
+              var input = controls.selectAll("input.transform")[0][t]
+              var name = this.value
+              if (name == "") {
+                // custom, so the terms are typed in
+                d3.select(input).style("display", null)
+                input.focus()
+                return
+              }
+              input.value = ""
+              presets.forEach(function(p) {
+                if (p.Name == name) {
+                  input.value = p.XTransform
+                }
+              })
+              setTransforms()
+            });
+        controls.append("input")
+            .attr("class", "transform")
+            .attr("type", "text")
+            .attr("size", 25)
+            .property("value", xTransforms[t] || "")
+            .on("input", validateTransform)
+            .on("change", setTransforms);
+      }
+      controls.append("br");
+
+      // fillPresets fills in the preset selects with the named models.
+      function fillPresets(models) {
+        presets = models
+        controls.selectAll("select.preset").each(function(t) {
+          var options = (t > 0 ? [{Name: "-", Label: "none"}] : [])
+              .concat(presets.map(function(p) { return {Name: p.Name, Label: p.Name + " " + p.Label} }))
+              .concat([{Name: "", Label: "custom"}])
+          d3.select(this).selectAll("option")
+              .data(options)
+            .enter().append("option")
+              .attr("value", function(d) { return d.Name })
+              .text(function(d) { return d.Label })
+        })
+        showPresets()
+      }
+      if (!report) {
+        d3.json("models", function(error, models) {
+          fillPresets(error ? [] : models)
+        })
+      }
+
+      // rangeInput adds an input for one end of the fit range, which calls set
+      // with the new value, or null if it is blank.
+      function rangeInput(value, set) {
+        return controls.append("input")
+            .attr("type", "text")
+            .attr("size", 10)
+            .attr("placeholder", "data")
+            .property("value", value === null ? "" : value)
+            .on("change", function() {
+              var v = this.value.trim() == "" ? null : Number(this.value)
+              if (isNaN(v)) {
+                d3.select(this).classed("invalid", true)
+                return
+              }
+              d3.select(this).classed("invalid", false)
+              set(v)
+              replot()
+            });
+      }
+      controls.append("label").text("fit range: ");
+      var xlbInput = rangeInput(xlbSetting, function(v) { xlbSetting = v });
+      controls.append("label").text(" to ");
+      var xubInput = rangeInput(xubSetting, function(v) { xubSetting = v });
+      controls.append("label").text(" fitter: ");
+      var fitterSelect = controls.append("select")
+          .classed("refit", true)
+          .property("disabled", !!report)
+          .on("change", function() {
+            fitterName = this.value
+            showLambda()
+            replot()
+          });
+      var lambdaLabel = controls.append("label").text(" lambda: ");
+      var lambdaInput = controls.append("input")
+          .attr("type", "text")
+          .attr("size", 6)
+          .attr("placeholder", "default")
+          .property("value", lambdaSetting)
+          .classed("refit", true)
+          .property("disabled", !!report)
+          .on("change", function() {
+            lambdaSetting = this.value.trim()
+            replot()
+          });
+
+      // the fitters which take a lambda
+      var regularized = {}
+
+      // showLambda only shows the lambda input for fitters which take one.
+      function showLambda() {
+        var show = !!regularized[fitterName]
+        lambdaLabel.style("display", show ? null : "none")
+        lambdaInput.style("display", show ? null : "none")
+      }
+
+      // showFitters fills in the fitter select with the available fitters.
+      function showFitters(fitters) {
+        fitters.forEach(function(f) { regularized[f.Name] = f.Regularized })
+        fitterSelect.selectAll("option")
+            .data(fitters)
+          .enter().append("option")
+            .attr("value", function(d) { return d.Name; })
+            .text(function(d) { return d.Name; })
+        fitterSelect.property("value", fitterName)
+        showLambda()
+      }
+      if (report) {
+        showFitters([{Name: fitterName, Regularized: lambdaSetting !== ""}])
+      } else {
+        d3.json("fitters", function(error, fitters) {
+          showFitters(error ? [{Name: fitterName, Regularized: false}] : fitters)
+        })
+      }
+
+      // localFit fits in the browser with the WebAssembly build of benchplot,
+      // which reports can include so that they can be refit without a
+      // server.  The other analyses stay as they were when the report was
+      // made.
+      var localFit = null
+      if (report && benchplotWasm) {
+        var wasmBytes = atob(benchplotWasm)
+        var wasmBuffer = new Uint8Array(wasmBytes.length)
+        for (var i = 0; i < wasmBytes.length; i++) {
+          wasmBuffer[i] = wasmBytes.charCodeAt(i)
+        }
+        var go = new Go()
+        WebAssembly.instantiate(wasmBuffer, go.importObject).then(function(result) {
+          go.run(result.instance)
+          localFit = function(query, benchmarks, callback) {
+            // the fits are in the units of the report, like the data
+            var scaled = benchmarks.map(function(d) {
+              var c = {}
+              for (var k in d) {
+                c[k] = d[k]
+              }
+              c[yVar] = yValue(d)
+              return c
+            })
+            var res = JSON.parse(benchplotFit(query, JSON.stringify(scaled)))
+            if (res.Error) {
+              callback(new Error(res.Error))
+            } else {
+              callback(null, res)
+            }
+          }
+          d3.selectAll(".refit").property("disabled", false)
+        }, function(error) {
+          console.log("unable to load the WebAssembly build:", error)
+        })
+      }
+
+      // fitterQuery returns the querystring for the estimator.
+      function fitterQuery() {
+        var q = "&fitter=" + encodeURIComponent(fitterName)
+        if (regularized[fitterName] && lambdaSetting !== "") {
+          q += "&lambda=" + encodeURIComponent(lambdaSetting)
+        }
+        return q
+      }
+      controls.append("br");
+
+      // predict the response of every group at a given N.  This needs the
+      // server, so it isn't available in reports.
+      if (!report) {
+        controls.append("label").text("predict at N = ");
+        var predictInput = controls.append("input")
+            .attr("type", "text")
+            .attr("size", 10)
+            .on("change", predict);
+        controls.append("label").text(" largest N within budget = ");
+        var budgetInput = controls.append("input")
+            .attr("type", "text")
+            .attr("size", 10)
+            .attr("placeholder", "e.g. 100ms")
+            .on("change", predict);
+        controls.append("br");
+      }
+
+      // plotState returns the settings which can be undone, which are those
+      // of a view, less the annotations, which are kept by the server.
+      function plotState() {
+        var c = viewConfig()
+        delete c.Annotations
+        c.CompareA = compareA
+        c.CompareB = compareB
+        c.MaxPoints = maxPoints
+        c.Layout = layout
+        c.Panels = Object.keys(panelYs)
+        c.Markers = markerBy
+        return c
+      }
+
+      // showSettings shows the settings in the controls, after they've been
+      // changed by undo or redo.
+      function showSettings(c) {
+        controls.select("input.nre").property("value", c.NRE).classed("invalid", false).attr("title", null)
+        unparamSelect.property("value", unparameterized)
+        pkgSelect.property("value", pkgFilter)
+        byPackageBox.property("checked", groupByPackage)
+        machineSelect.property("value", machineFilter)
+        byMachineBox.property("checked", groupByMachine)
+        byFileBox.property("checked", groupByFile)
+        baselineSelect.property("value", baselineGroup)
+        compareASelect.property("value", compareA)
+        compareBSelect.property("value", compareB)
+        yVarSelect.property("value", yVar)
+        aggregateSelect.property("value", aggregation)
+        clusterSelect.property("value", clusterBy)
+        maxPointsInput.property("value", maxPoints || "").classed("invalid", false)
+        controls.selectAll("input.transform")
+            .property("value", function(d, t) { return xTransforms[t] || "" })
+            .classed("invalid", false)
+            .attr("title", null)
+        showPresets()
+        xlbInput.property("value", xlbSetting === null ? "" : xlbSetting).classed("invalid", false)
+        xubInput.property("value", xubSetting === null ? "" : xubSetting).classed("invalid", false)
+        fitterSelect.property("value", fitterName)
+        lambdaInput.property("value", lambdaSetting)
+        showLambda()
+        searchInput.property("value", groupSearch).classed("invalid", false).attr("title", null)
+        searchRegexpBox.property("checked", searchRegexp)
+        if (loaded) {
+          showFiles(loaded)
+        }
+      }
+
+      // the states of the plot before and after the current one, which are
+      // returned to by undo and redo.  Every change to the settings is kept,
+      // up to maxUndo of them.
+      var undoStack = []
+      var redoStack = []
+      var maxUndo = 100
+      var currentState = JSON.stringify(plotState())
+
+      // recordState adds the previous state to the undo stack if the
+      // settings have changed, which is checked whenever the plot is redrawn.
+      function recordState() {
+        var state = JSON.stringify(plotState())
+        if (state == currentState) {
+          return
+        }
+        undoStack.push(currentState)
+        if (undoStack.length > maxUndo) {
+          undoStack.shift()
+        }
+        redoStack = []
+        currentState = state
+        showUndo()
+      }
+
+      // restoreState returns to a state from the undo or redo stack, after
+      // pushing the current one on to the other.
+      function restoreState(from, to) {
+        if (from.length == 0) {
+          return
+        }
+        to.push(currentState)
+        currentState = from.pop()
+        var c = JSON.parse(currentState)
+        applySettings(c)
+        compareA = c.CompareA
+        compareB = c.CompareB
+        maxPoints = c.MaxPoints
+        layout = c.Layout
+        panelYs = {}
+        c.Panels.forEach(function(m) { panelYs[m] = true })
+        markerBy = c.Markers
+        markerSelect.property("value", markerBy)
+        showPanelChoices()
+        showSettings(c)
+        showUndo()
+        replot()
+      }
+
+      function undo() { restoreState(undoStack, redoStack) }
+      function redo() { restoreState(redoStack, undoStack) }
+
+      var undoButton = controls.append("button")
+          .text("undo")
+          .attr("title", "ctrl+z")
+          .on("click", undo);
+      var redoButton = controls.append("button")
+          .text("redo")
+          .attr("title", "ctrl+shift+z")
+          .on("click", redo);
+
+      // showUndo only enables the buttons which have a state to return to.
+      function showUndo() {
+        undoButton.property("disabled", undoStack.length == 0)
+        redoButton.property("disabled", redoStack.length == 0)
+      }
+      showUndo()
+
+      // ctrl+z undoes, and ctrl+shift+z or ctrl+y redoes, except while typing,
+      // where they undo the typing.
+      d3.select(window).on("keydown.undo", function() {
+        var e = d3.event
+        var typing = e.target.tagName == "TEXTAREA" || (e.target.tagName == "INPUT" && e.target.type != "checkbox")
+        if (!(e.ctrlKey || e.metaKey) || typing) {
+          return
+        }
+        var key = e.key.toLowerCase()
+        if (key == "z" && !e.shiftKey) {
+          e.preventDefault()
+          undo()
+        } else if (key == "y" || (key == "z" && e.shiftKey)) {
+          e.preventDefault()
+          redo()
+        }
+      });
+
+      // the settings can be saved as a named view on the server, which is
+      // listed at /views.  This needs the server, so it isn't available in
+      // reports.
+      if (!report) {
+        controls.append("button")
+            .text("save view")
+            .on("click", function() {
+              var name = prompt("name of the view:", view ? view.Name : "")
+              if (!name) {
+                return
+              }
+              d3.xhr("views?name=" + encodeURIComponent(name))
+                  .header("Content-Type", "application/json")
+                  .post(JSON.stringify(viewConfig()), function(error) {
+                    if (error) {
+                      alert("unable to save the view: " + error.responseText)
+                      return
+                    }
+                    history.replaceState(null, "", "?view=" + encodeURIComponent(name))
+                  })
+            });
+        controls.append("a")
+            .attr("href", "views")
+            .text(" views");
+        controls.append("a")
+            .attr("href", "dashboard")
+            .text(" dashboard");
+        controls.append("a")
+            .attr("href", "table")
+            .text(" table");
+        if (groupFilter != "") {
+          var groupLabel = controls.append("label")
+              .text(" only " + groupFilter + " ")
+          groupLabel.append("a")
+              .attr("href", "#")
+              .text("(plot all)")
+              .on("click", function() {
+                d3.event.preventDefault()
+                groupFilter = ""
+                groupLabel.remove()
+                history.replaceState(null, "", location.search.replace(/([?&])group=[^&]*&?/, "$1").replace(/[?&]$/, "") || location.pathname)
+                replot()
+              });
+        }
+        controls.append("br");
+      }
+
+      // in run mode, the benchmarks can be run again.  Each run is added as
+      // a new series.
+      var rerunButton = controls.append("button")
+          .text("re-run benchmarks")
+          .style("display", "none")
+          .on("click", function() {
+            rerunButton.property("disabled", true)
+            d3.xhr("rerun").post(null, function(error) {
+              loadData()
+            })
+          });
+
+      // add the parse warning banner to the webpage
+      var warnings = d3.select("body").append("div")
+          .attr("class", "warnings");
+
+      // add the notes about each run to the webpage
+      var notesDiv = d3.select("body").append("div")
+          .attr("class", "notes");
+
+      // add the panel of files to plot, beside the graph
+      var filesDiv = d3.select("body").append("div")
+          .attr("class", "files");
+
+      // add the graph canvas to the body of the webpage
+      var svg = d3.select("body").append("svg")
+          .attr("width", width + margin.left + margin.right)
+          .attr("height", height + margin.top + margin.bottom)
+        .append("g")
+          .attr("transform", "translate(" + margin.left + "," + margin.top + ")");
+
+      // add the panels of the other responses, below the graph
+      var panelsDiv = d3.select("body").append("div")
+          .attr("class", "panels");
+
+      // add the table of benchmarks which don't match nre to the webpage
+      var unparamTableDiv = d3.select("body").append("div")
+          .attr("class", "unparameterized");
+
+      // add the distribution of the runs of a clicked benchmark to the webpage
+      var distributionDiv = d3.select("body").append("div")
+          .attr("class", "distribution");
+
+      // showDistribution draws a histogram of every run of a benchmark, which
+      // shows whether it is bimodal, as from thermal throttling or background
+      // load.  This needs the server, so it isn't available in reports.
+      function showDistribution(b) {
+        distributionDiv.selectAll("*").remove()
+        d3.json("distribution?benchmark=" + encodeURIComponent(b.Name) +
+            "&pkg=" + encodeURIComponent(b.Package || "") +
+            "&yvar=" + encodeURIComponent(yVar), function(error, dist) {
+          distributionDiv.selectAll("*").remove()
+          if (error || !dist) {
+            return
+          }
+          var p = distributionDiv.append("p")
+              .text(dist.Benchmark + ": " + dist.Values.length + " runs, " + yUnits[yVar] + " ")
+          p.append("a")
+              .attr("href", "#")
+              .text("close")
+              .on("click", function() {
+                d3.event.preventDefault()
+                distributionDiv.selectAll("*").remove()
+              })
+          if (b.Profiles) {
+            var profiles = distributionDiv.append("p").text("profiles of this run: ")
+            b.Profiles.forEach(function(kind) {
+              var q = "profile?file=" + encodeURIComponent(b.File) +
+                  "&benchmark=" + encodeURIComponent(b.Name) +
+                  "&kind=" + encodeURIComponent(kind)
+              profiles.append("a")
+                  .attr("href", q)
+                  .attr("target", "_blank")
+                  .text(kind)
+              profiles.append("span").text(" (")
+              profiles.append("a")
+                  .attr("href", q + "&raw=1")
+                  .text("download")
+              profiles.append("span").text(") ")
+            })
+          }
+
+          var w = 300, h = 80, pad = 20
+          var x = d3.scale.linear()
+              .domain([dist.Bins[0].Low, dist.Bins[dist.Bins.length - 1].High])
+              .range([0, w])
+          var y = d3.scale.linear()
+              .domain([0, d3.max(dist.Bins, function(d) { return d.Count })])
+              .range([h, 0])
+          var g = distributionDiv.append("svg")
+              .attr("width", w + 2 * pad)
+              .attr("height", h + 2 * pad)
+            .append("g")
+              .attr("transform", "translate(" + pad + "," + pad / 2 + ")")
+          g.selectAll("rect")
+              .data(dist.Bins)
+            .enter().append("rect")
+              .attr("x", function(d) { return x(d.Low) })
+              .attr("width", function(d) { return Math.max(1, x(d.High) - x(d.Low) - 1) })
+              .attr("y", function(d) { return y(d.Count) })
+              .attr("height", function(d) { return h - y(d.Count) })
+            .append("title")
+              .text(function(d) { return d.Count + " runs from " + d.Low + " to " + d.High })
+          g.append("g")
+              .attr("class", "x axis")
+              .attr("transform", "translate(0," + h + ")")
+              .call(d3.svg.axis().scale(x).orient("bottom").ticks(4))
+        })
+      }
+
+      // add the trend of a clicked benchmark over the stored runs to the
+      // webpage
+      var trendDiv = d3.select("body").append("div")
+          .attr("class", "trend");
+
+      // showTrend draws the mean of a benchmark's group at its N in each of
+      // the runs in the history database, from /history, with the range of
+      // the repeated runs.  It isn't drawn if there isn't a database, or
+      // fewer than two runs have the benchmark.
+      function showTrend(b) {
+        trendDiv.selectAll("*").remove()
+        var matches = b.Name.match(nre)
+        var group = matches && matches.length > 2 ? matches[1] : b.Name.match(procre)[1]
+        d3.json("history?benchmark=" + encodeURIComponent(group) +
+            "&n=" + encodeURIComponent(b.X) +
+            "&yvar=" + encodeURIComponent(yVar) +
+            "&nre=" + encodeURIComponent(nre.source), function(error, points) {
+          trendDiv.selectAll("*").remove()
+          if (error || !points || points.length < 2) {
+            return
+          }
+          points.forEach(function(d) { d.Date = new Date(d.Time) })
+          points.sort(function(a, b) { return a.Date - b.Date })
+          var p = trendDiv.append("p")
+              .text(group + " at N = " + b.X + " in " + points.length + " stored runs, " + yUnits[yVar] + " ")
+          p.append("a")
+              .attr("href", "#")
+              .text("close")
+              .on("click", function() {
+                d3.event.preventDefault()
+                trendDiv.selectAll("*").remove()
+              })
+
+          var w = 300, h = 80, pad = 40
+          var x = d3.time.scale()
+              .domain(d3.extent(points, function(d) { return d.Date }))
+              .range([0, w])
+          var y = d3.scale.linear()
+              .domain([d3.min(points, function(d) { return d.Min }), d3.max(points, function(d) { return d.Max })])
+              .range([h, 0])
+              .nice()
+          var g = trendDiv.append("svg")
+              .attr("width", w + 2 * pad)
+              .attr("height", h + pad)
+            .append("g")
+              .attr("transform", "translate(" + pad + "," + pad / 4 + ")")
+          g.selectAll("line.range")
+              .data(points.filter(function(d) { return d.Runs > 1 }))
+            .enter().append("line")
+              .attr("class", "range")
+              .attr("x1", function(d) { return x(d.Date) })
+              .attr("x2", function(d) { return x(d.Date) })
+              .attr("y1", function(d) { return y(d.Min) })
+              .attr("y2", function(d) { return y(d.Max) })
+              .style("stroke", color(b.Group))
+          g.append("path")
+              .datum(points)
+              .attr("d", d3.svg.line()
+                  .x(function(d) { return x(d.Date) })
+                  .y(function(d) { return y(d.Mean) }))
+              .style("fill", "none")
+              .style("stroke", color(b.Group))
+          g.selectAll("circle")
+              .data(points)
+            .enter().append("circle")
+              .attr("r", 2)
+              .attr("cx", function(d) { return x(d.Date) })
+              .attr("cy", function(d) { return y(d.Mean) })
+              .style("fill", color(b.Group))
+            .append("title")
+              .text(function(d) {
+                return d.ID + ": " + d.Mean + (d.Runs > 1 ? " (" + d.Runs + " runs from " + d.Min + " to " + d.Max + ")" : "") +
+                    Object.keys(d.Labels || {}).sort().map(function(k) { return "\n" + k + "=" + d.Labels[k] }).join("")
+              })
+          g.append("g")
+              .attr("class", "x axis")
+              .attr("transform", "translate(0," + h + ")")
+              .call(d3.svg.axis().scale(x).orient("bottom").ticks(4))
+          g.append("g")
+              .attr("class", "y axis")
+              .call(d3.svg.axis().scale(y).orient("left").ticks(3))
+        })
+      }
+
+      // add the partial residual plots of a group to the webpage
+      var partialsDiv = d3.select("body").append("div")
+          .attr("class", "partials");
+
+      // showPartials draws the component plus residual plot of each term of
+      // a group's model, which bends where the term is misspecified.  This
+      // needs the server, so it isn't available in reports.
+      function showPartials(group, t) {
+        partialsDiv.selectAll("*").remove()
+        d3.json("partial?" + analysisQuery().replace(/xtransform=[^&]*/, "xtransform=" + encodeURIComponent(xTransforms[t])) +
+            "&group=" + encodeURIComponent(group), function(error, partials) {
+          partialsDiv.selectAll("*").remove()
+          if (error || !partials) {
+            return
+          }
+          var p = partialsDiv.append("p")
+              .text("partial residuals of " + group + ", " + xTransforms[t] + " ")
+          p.append("a")
+              .attr("href", "#")
+              .text("close")
+              .on("click", function() {
+                d3.event.preventDefault()
+                partialsDiv.selectAll("*").remove()
+              })
+          var w = 240, h = 160, pad = 40
+          partials.forEach(function(pr) {
+            var x = d3.scale.linear()
+                .domain(d3.extent(pr.Points, function(d) { return d.X }))
+                .range([0, w])
+            var y = d3.scale.linear()
+                .domain(d3.extent(pr.Points.concat(pr.Means), function(d) { return d.Y }))
+                .range([h, 0])
+            var g = partialsDiv.append("svg")
+                .attr("width", w + 2 * pad)
+                .attr("height", h + 2 * pad)
+              .append("g")
+                .attr("transform", "translate(" + pad + "," + pad / 2 + ")")
+            g.selectAll(".dot")
+                .data(pr.Points)
+              .enter().append("circle")
+                .attr("r", 2)
+                .attr("cx", function(d) { return x(d.X) })
+                .attr("cy", function(d) { return y(d.Y) })
+                .style("fill", color(group))
+              .append("title")
+                .text(function(d) { return "N = " + d.N })
+            // the fitted term is a straight line, and the means bend away
+            // from it where the term is misspecified.
+            var xs = x.domain()
+            g.append("line")
+                .attr("x1", x(xs[0])).attr("y1", y(pr.Beta * xs[0]))
+                .attr("x2", x(xs[1])).attr("y2", y(pr.Beta * xs[1]))
+                .style("stroke", "#000")
+            g.append("path")
+                .datum(pr.Means)
+                .attr("d", d3.svg.line()
+                    .x(function(d) { return x(d.X) })
+                    .y(function(d) { return y(d.Y) }))
+                .style("fill", "none")
+                .style("stroke", color(group))
+                .style("stroke-dasharray", "4,2")
+            g.append("g")
+                .attr("class", "x axis")
+                .attr("transform", "translate(0," + h + ")")
+                .call(d3.svg.axis().scale(x).orient("bottom").ticks(4))
+              .append("text")
+                .attr("x", w)
+                .attr("y", 30)
+                .style("text-anchor", "end")
+                .text(pr.Term)
+            g.append("g")
+                .attr("class", "y axis")
+                .call(d3.svg.axis().scale(y).orient("left").ticks(4))
+          })
+        })
+      }
+
+      // add the predictions to the webpage
+      var predictionsDiv = d3.select("body").append("div")
+          .attr("class", "predictions");
+
+      // add the crossovers between groups to the webpage
+      var crossoversDiv = d3.select("body").append("div")
+          .attr("class", "crossovers");
+
+      // add the table comparing the goodness of fit of each model
+      var fitTableDiv = d3.select("body").append("div")
+          .attr("class", "fits");
+
+      // fitSummaries holds the fit statistics for each group and model.
+      var fitSummaries = []
+
+      // showFits lists the goodness of fit of each group and model.
+      function showFits() {
+        fitTableDiv.selectAll("*").remove()
+        if (fitSummaries.length == 0) {
+          return
+        }
+        fitSummaries.sort(function(a, b) {
+          return orderBy("Group")(a, b) || a.Transform - b.Transform
+        })
+        var table = fitTableDiv.append("table")
+        var header = table.append("tr")
+        header.append("th").text("group")
+        header.append("th").text("model")
+        header.append("th").text("R²")
+        header.append("th").text("MSE")
+        header.append("th").text("equation")
+        header.append("th").text("LaTeX")
+        header.append("th").text("condition")
+        header.append("th").text("model details")
+        var rows = table.selectAll(".row")
+            .data(fitSummaries)
+          .enter().append("tr")
+            .classed("collinear", function(d) { return d.Collinear; })
+        var groupCells = rows.append("td")
+        groupCells.append("span")
+            .attr("title", function(d) { return d.Group; })
+            .text(function(d) { return displayName(d.Group); })
+        if (!report) {
+          groupCells.append("span").text(" ")
+          groupCells.filter(function(d) { return (d.ResultModel || []).length > 1 })
+            .append("a")
+              .attr("href", "#")
+              .attr("title", "partial residual plots of each term")
+              .text("partials")
+              .on("click", function(d) {
+                d3.event.preventDefault()
+                showPartials(d.Group, d.Transform)
+              })
+        }
+        var model = rows.append("td")
+        model.append("svg")
+            .attr("width", 30)
+            .attr("height", 10)
+          .append("line")
+            .attr("x1", 0).attr("y1", 5).attr("x2", 30).attr("y2", 5)
+            .style("stroke", function(d) { return color(d.Group); })
+            .style("stroke-width", "1.5px")
+            .style("stroke-dasharray", function(d) { return transformDashes[d.Transform]; })
+        model.append("span").text(function(d) { return " " + xTransforms[d.Transform]; })
+        model.filter(function(d) { return d.NonFinite; })
+          .append("span")
+            .attr("class", "nonfinite")
+            .attr("title", function(d) { return "a term isn't finite at N = " + d.NonFinite.join(", ") })
+            .text(function(d) { return " (" + d.NonFinite.length + " left out)" })
+        rows.append("td").text(function(d) { return d3.format(".6f")(d.R2); })
+        rows.append("td").text(function(d) { return d3.format(".4g")(d.MSE); })
+        rows.append("td").text(function(d) { return d.Equation; })
+        rows.append("td").append("code").text(function(d) { return d.LaTeX; })
+
+        // nearly collinear terms make the coefficients unreliable, even if
+        // the fit looks good.
+        rows.append("td")
+            .attr("title", function(d) {
+              return "variance inflation factors:\n" + (d.ResultModel || []).map(function(m) {
+                return m.XTrans + ": " + (m.VIF ? d3.format(".3g")(m.VIF) : "-")
+              }).join("\n")
+            })
+            .text(function(d) {
+              var terms = (d.ResultModel || []).length
+              if (d.Rank < terms) {
+                return "rank deficient (rank " + d.Rank + " of " + terms + " terms)"
+              }
+              return d3.format(".3g")(d.Condition) + (d.Collinear ? " (nearly collinear)" : "")
+            })
+
+        // the full summary of the fit, which is collapsed since it's mostly
+        // of interest when the fit is in doubt.  Like R², they're uncentered.
+        var details = rows.append("td").append("details")
+        details.append("summary").text(function(d) { return "df " + d.DF })
+        details.append("div").text(function(d) {
+          var terms = (d.ResultModel || []).length
+          return "degrees of freedom: " + terms + " terms, " + d.DF + " residual"
+        })
+        details.append("div").text(function(d) { return "adjusted R²: " + d3.format(".6f")(d.AdjR2) })
+        details.append("div").text(function(d) {
+          return "F(" + (d.ResultModel || []).length + ", " + d.DF + "): " + d3.format(".4g")(d.F)
+        })
+        details.append("div").text(function(d) {
+          return "p-value: " + (d.PValue < 1e-4 ? "< 0.0001" : d3.format(".4f")(d.PValue))
+        })
+      }
+
+      // add the coefficient tables to the webpage
+      var coefficientsDiv = d3.select("body").append("div")
+          .attr("class", "coefficients");
+
+      // showCoefficients lines up the coefficients of every group, with one
+      // table for each model, so that groups can be compared term by term.
+      function showCoefficients() {
+        coefficientsDiv.selectAll("*").remove()
+        for (var t = 0; t < xTransforms.length; t++) {
+          var fits = fitSummaries.filter(function(d) { return d.Transform == t && d.ResultModel; })
+          if (fits.length == 0) {
+            continue
+          }
+          var title = coefficientsDiv.append("p")
+          title.append("span").text("coefficients of " + xTransforms[t] + " (± 95% confidence interval, and standardized)")
+          // the server can write the fits as Go functions
+          if (!report) {
+            title.append("span").text(" ")
+            title.append("a")
+                .attr("href", "costfunc?" + analysisQuery().replace(/xtransform=[^&]*/, "xtransform=" + encodeURIComponent(xTransforms[t])))
+                .attr("target", "_blank")
+                .text("export as Go")
+            title.append("span").text(" ")
+            title.append("a")
+                .attr("href", "vega?" + analysisQuery().replace(/xtransform=[^&]*/, "xtransform=" + encodeURIComponent(xTransforms[t])))
+                .attr("target", "_blank")
+                .text("export as Vega-Lite")
+          }
+          var table = coefficientsDiv.append("table")
+          var header = table.append("tr")
+          header.append("th").text("group")
+          fits[0].ResultModel.forEach(function(term) {
+            header.append("th").text(term.XTrans)
+          })
+          var rows = table.selectAll(".row")
+              .data(fits)
+            .enter().append("tr")
+          rows.append("td").text(function(d) { return d.Group; })
+          rows.selectAll(".coefficient")
+              .data(function(d) {
+                // each coefficient's correlation with the others is in its
+                // tooltip
+                return d.ResultModel.map(function(m, j) {
+                  return {Beta: m.Beta, BInt: m.BInt, Std: m.Std, Correlations: d.ResultModel.map(function(other, k) {
+                    return j == k || !d.Correlation ? null : "correlation with " + other.XTrans + ": " + d3.format(".3f")(d.Correlation[j][k])
+                  }).filter(function(c) { return c })}
+                })
+              })
+            .enter().append("td")
+              .attr("title", function(d) { return d.Correlations.join("\n") })
+              .text(function(d) { return d3.format(".4g")(d.Beta) + " ± " + d3.format(".2g")(d.BInt); })
+            .append("span")
+              .attr("class", "std")
+              .attr("title", "standardized coefficient, in standard deviations of the response per standard deviation of the term")
+              .text(function(d) { return d.Std ? " (" + d3.format(".2f")(d.Std) + " sd)" : ""; })
+        }
+      }
+
+      // add the ratio of the fits of two groups to the webpage.  It needs
+      // the server, so reports don't have it.
+      var ratioA = "", ratioB = ""
+      var ratioDiv = d3.select("body").append("div")
+          .attr("class", "ratio")
+          .style("display", report ? "none" : null)
+      ratioDiv.append("label").text("ratio of the fit of ")
+      var ratioBSelect = ratioDiv.append("select")
+          .on("change", function() {
+            ratioB = this.value
+            showRatio()
+          })
+      ratioDiv.append("label").text(" to ")
+      var ratioASelect = ratioDiv.append("select")
+          .on("change", function() {
+            ratioA = this.value
+            showRatio()
+          })
+      var ratioChart = ratioDiv.append("div")
+
+      // showRatioChoices fills in the groups that can be compared.
+      function showRatioChoices(dataset) {
+        var groups = d3.set(dataset.map(cValue)).values().sort()
+        ;[[ratioASelect, ratioA], [ratioBSelect, ratioB]].forEach(function(s) {
+          s[0].selectAll("option").remove()
+          s[0].selectAll("option")
+              .data([""].concat(groups))
+            .enter().append("option")
+              .attr("value", function(d) { return d; })
+              .text(function(d) { return d == "" ? "none" : displayName(d); })
+        })
+        if (groups.indexOf(ratioA) < 0) {
+          ratioA = ""
+        }
+        if (groups.indexOf(ratioB) < 0) {
+          ratioB = ""
+        }
+        ratioASelect.property("value", ratioA)
+        ratioBSelect.property("value", ratioB)
+      }
+
+      // showRatio draws the ratio of the first model's fit of group B to
+      // that of group A, over the N that both were fit on, with its
+      // approximate 95% confidence band.
+      function showRatio() {
+        ratioChart.selectAll("*").remove()
+        if (report || ratioA == "" || ratioB == "" || ratioA == ratioB || xTransforms.length == 0) {
+          return
+        }
+        var generation = plotGeneration
+        d3.json("ratio?" + analysisQuery() +
+            "&a=" + encodeURIComponent(ratioA) +
+            "&b=" + encodeURIComponent(ratioB), function(error, gr) {
+          if (generation != plotGeneration) {
+            return
+          }
+          ratioChart.selectAll("*").remove()
+          if (error || !gr || gr.Points.length == 0) {
+            ratioChart.append("p").text("unable to compare " + displayName(ratioB) + " to " + displayName(ratioA) +
+                (error && error.responseText ? ": " + error.responseText : ""))
+            return
+          }
+          var w = 400, h = 150, pad = 40
+          var x = d3.scale.linear()
+              .domain(d3.extent(gr.Points, function(p) { return p.N }))
+              .range([0, w])
+          var y = d3.scale.linear()
+              .domain([
+                Math.min(1, d3.min(gr.Points, function(p) { return p.Ratio - p.Width })),
+                Math.max(1, d3.max(gr.Points, function(p) { return p.Ratio + p.Width }))])
+              .range([h, 0])
+              .nice()
+          var g = ratioChart.append("svg")
+              .attr("width", w + 2 * pad)
+              .attr("height", h + 2 * pad)
+            .append("g")
+              .attr("transform", "translate(" + pad + "," + pad / 2 + ")")
+          g.append("path")
+              .datum(gr.Points)
+              .attr("class", "band")
+              .attr("d", d3.svg.area()
+                  .x(function(p) { return x(p.N) })
+                  .y0(function(p) { return y(p.Ratio - p.Width) })
+                  .y1(function(p) { return y(p.Ratio + p.Width) }))
+          g.append("line")
+              .attr("class", "one")
+              .attr("x2", w)
+              .attr("y1", y(1))
+              .attr("y2", y(1))
+          g.append("path")
+              .datum(gr.Points)
+              .attr("class", "line")
+              .attr("d", d3.svg.line()
+                  .x(function(p) { return x(p.N) })
+                  .y(function(p) { return y(p.Ratio) }))
+          g.append("g")
+              .attr("class", "x axis")
+              .attr("transform", "translate(0," + h + ")")
+              .call(d3.svg.axis().scale(x).orient("bottom").ticks(5))
+          g.append("g")
+              .attr("class", "y axis")
+              .call(d3.svg.axis().scale(y).orient("left").ticks(5))
+            .append("text")
+              .attr("transform", "rotate(-90)")
+              .attr("y", 6)
+              .attr("dy", ".71em")
+              .style("text-anchor", "end")
+              .text(displayName(gr.B) + " / " + displayName(gr.A))
+        })
+      }
+
+      // add the comparison of two machines to the webpage
+      var machinesDiv = d3.select("body").append("div")
+          .attr("class", "machines");
+
+      // showMachineComparison shows the ratio of the coefficients of the
+      // first model of each group on the two machines being compared.
+      function showMachineComparison() {
+        machinesDiv.selectAll("*").remove()
+        if (!comparing()) {
+          return
+        }
+        var generation = plotGeneration
+        d3.json("machines?" + analysisQuery() +
+            "&a=" + encodeURIComponent(compareA) +
+            "&b=" + encodeURIComponent(compareB), function(error, mc) {
+          if (generation != plotGeneration || error || !mc || mc.Groups.length == 0) {
+            return
+          }
+          machinesDiv.append("p").text("coefficients of " + xTransforms[0] + " on " + mc.B + " relative to " + mc.A + ":")
+          var table = machinesDiv.append("table")
+          var header = table.append("tr")
+          header.append("th").text("group")
+          mc.Terms.forEach(function(term) {
+            header.append("th").text(term)
+          })
+          var rows = table.selectAll(".row")
+              .data(mc.Groups)
+            .enter().append("tr")
+          rows.classed("regression", function(d) { return d.ComplexityRegression; })
+          rows.append("td").text(function(d) { return d.Group; })
+          rows.selectAll(".ratio")
+              .data(function(d) { return d.Terms; })
+            .enter().append("td")
+              .attr("title", function(d) {
+                return mc.A + ": " + d3.format(".4g")(d.A) + " ± " + d3.format(".2g")(d.AInt) + "\n" +
+                    mc.B + ": " + d3.format(".4g")(d.B) + " ± " + d3.format(".2g")(d.BInt)
+              })
+              .text(function(d) {
+                return d.A == 0 ? "-" : d3.format(".3g")(d.Ratio) + "× ± " + d3.format(".2g")(d.RatioInt)
+              })
+          header.append("th").text("complexity")
+          rows.append("td").text(function(d) {
+            if (d.ComplexityA == d.ComplexityB) {
+              return d.ComplexityA
+            }
+            return d.ComplexityA + " → " + d.ComplexityB + (d.ComplexityRegression ? " (regression)" : "")
+          })
+        })
+      }
+
+      // add the tests of equal coefficients to the webpage
+      var chowDiv = d3.select("body").append("div")
+          .attr("class", "chow");
+
+      // showChowTests shows whether each pair of groups has the same
+      // coefficients for the first model, using a Chow test.
+      function showChowTests() {
+        var generation = plotGeneration
+        var draw = function(error, cts) {
+          if (generation != plotGeneration) {
+            return
+          }
+          chowDiv.selectAll("*").remove()
+          if (error || !cts || cts.length == 0) {
+            return
+          }
+          chowDiv.append("p").text("tests of equal coefficients of " + xTransforms[0] + ":")
+          var table = chowDiv.append("table")
+          var header = table.append("tr")
+          header.append("th").text("groups")
+          header.append("th").text("F")
+          header.append("th").text("p")
+          var rows = table.selectAll(".row")
+              .data(cts)
+            .enter().append("tr")
+          rows.append("td").text(function(d) { return d.A + " vs " + d.B; })
+          rows.append("td").text(function(d) { return "F(" + d.DF1 + ", " + d.DF2 + ") = " + d3.format(".4g")(d.F); })
+          rows.append("td").text(function(d) { return d3.format(".3g")(d.P) + (d.P < 0.05 ? " (different)" : ""); })
+        }
+        if (report) {
+          draw(null, report.ChowTests)
+          return
+        }
+        d3.json("chow?" + analysisQuery(), draw)
+      }
 
-      var regLineLB = d3.svg.line()
-          .x(function(d) { return xScale(d.X); })
-          .y(function(d) { return yScale(d.Yhat + d.ConfWidth); });
+      // add the empirical exponents to the webpage
+      var exponentsDiv = d3.select("body").append("div")
+          .attr("class", "exponents");
 
-      var regLineUB = d3.svg.line()
-          .x(function(d) { return xScale(d.X); })
-          .y(function(d) { return yScale(d.Yhat - d.ConfWidth); });
+      // showExponents shows the power law which best fits each group, which
+      // doesn't depend on the models.
+      function showExponents() {
+        var generation = plotGeneration
+        var draw = function(error, exps) {
+          if (generation != plotGeneration) {
+            return
+          }
+          exponentsDiv.selectAll("*").remove()
+          if (error || !exps || exps.length == 0) {
+            return
+          }
+          exponentsDiv.append("p").text("empirical exponents, from fitting log(" + yVar + ") against log(N):")
+          var table = exponentsDiv.append("table")
+          var rows = table.selectAll(".row")
+              .data(exps)
+            .enter().append("tr")
+          rows.append("td").text(function(d) { return d.Group; })
+          rows.append("td").text(function(d) {
+            return d3.format(".3g")(d.Constant) + " · N^" + d3.format(".3f")(d.Exponent) + " (± " + d3.format(".2g")(d.ExponentInt) + ")"
+          })
+        }
+        if (report) {
+          draw(null, report.Exponents)
+          return
+        }
+        d3.json("exponent?" + analysisQuery(), draw)
+      }
 
-      // setup fill color
-      var cValue = function(d) { return d.Group;},
-          color = d3.scale.category10();
+      // add the drift table to the webpage
+      var driftsDiv = d3.select("body").append("div")
+          .attr("class", "drifts");
 
-      // add the graph canvas to the body of the webpage
-      var svg = d3.select("body").append("svg")
-          .attr("width", width + margin.left + margin.right)
-          .attr("height", height + margin.top + margin.bottom)
-        .append("g")
-          .attr("transform", "translate(" + margin.left + "," + margin.top + ")");
+      // showDrifts lists the groups whose response changed over the
+      // benchmark session, from fitting the model with the position of each
+      // benchmark in its file as another term.
+      function showDrifts() {
+        var generation = plotGeneration
+        var draw = function(error, drifts) {
+          if (generation != plotGeneration) {
+            return
+          }
+          driftsDiv.selectAll("*").remove()
+          if (error || !drifts) {
+            return
+          }
+          drifts = drifts.filter(function(d) { return d.Significant })
+          if (drifts.length == 0) {
+            return
+          }
+          driftsDiv.append("p").text("drift over the session, from the position of each benchmark in its file:")
+          var table = driftsDiv.append("table")
+          var rows = table.selectAll(".row")
+              .data(drifts)
+            .enter().append("tr")
+          rows.append("td").text(function(d) { return d.Group; })
+          rows.append("td").text(function(d) {
+            return d3.format("+.3g")(d.PerRun) + " per benchmark (± " + d3.format(".2g")(d.PerRunInt) + "), " +
+                d3.format("+.3g")(d.Total) + " over the session, p = " + d3.format(".2g")(d.P)
+          })
+          rows.append("td").text(function(d) { return d.Confounded ? "confounded with N" : ""; })
+        }
+        if (report) {
+          draw(null, report.Drifts)
+          return
+        }
+        d3.json("drift?" + analysisQuery(), draw)
+      }
 
       // add the tooltip area to the webpage
       var tooltip = d3.select("body").append("div")
@@ -163,12 +2006,196 @@ const plotHTML = `
         }
       }
 
+      // segments splits a regression line into runs of points which are all
+      // either within the range of the data, or extrapolated.  Adjacent
+      // segments share an end point so that the line stays continuous.
+      function segments(line) {
+        var segs = []
+        var cur = null
+        for (var k = 0; k < line.length; k++) {
+          if (!cur || cur.extrapolated != line[k].Extrapolated) {
+            cur = {extrapolated: line[k].Extrapolated, points: cur ? [cur.points[cur.points.length - 1]] : []}
+            segs.push(cur)
+          }
+          cur.points.push(line[k])
+        }
+        return segs
+      }
+
+      // analysisQuery returns the querystring describing the current grouping
+      // and the first model, for the analysis endpoints.
+      function analysisQuery() {
+        return "nre=" + encodeURIComponent(nre.source) +
+            "&unparameterized=" + encodeURIComponent(unparameterized) +
+            "&pkg=" + encodeURIComponent(pkgFilter) +
+            "&bypkg=" + groupByPackage +
+            "&machine=" + encodeURIComponent(machineFilter) +
+            "&bymachine=" + groupByMachine +
+            "&byfile=" + groupByFile +
+            "&aggregate=" + encodeURIComponent(aggregation) +
+            "&cluster=" + encodeURIComponent(clusterBy) +
+            (groupFilter != "" ? "&group=" + encodeURIComponent(groupFilter) : "") +
+            (groupSearch != "" ? "&filter=" + encodeURIComponent(groupSearch) + "&filterre=" + searchRegexp : "") +
+            Object.keys(hiddenFiles).map(function(path) { return "&hide=" + encodeURIComponent(path) }).join("") +
+            Object.keys(hiddenGroups).map(function(group) { return "&hidegroup=" + encodeURIComponent(group) }).join("") +
+            "&xtransform=" + encodeURIComponent(xTransforms[0] || "") +
+            "&yvar=" + encodeURIComponent(yVar) +
+            fitterQuery()
+      }
+
+      // formatY formats a response for people to read, using larger units
+      // of time where they fit better.
+      function formatY(v) {
+        if (yVar != "NsPerOp" || (report && report.UnitLabel != "ns/op")) {
+          return d3.format(".4g")(v) + " " + yUnits[yVar]
+        }
+        return formatNs(v)
+      }
+
+      // formatNs formats a duration in nanoseconds in the largest unit it
+      // has at least one of.
+      function formatNs(v) {
+        var units = [[1e9, "s"], [1e6, "ms"], [1e3, "µs"], [1, "ns"]]
+        for (var k = 0; k < units.length; k++) {
+          if (Math.abs(v) >= units[k][0] || k == units.length - 1) {
+            return d3.format(".3g")(v / units[k][0]) + " " + units[k][1]
+          }
+        }
+      }
+
+      // benchmarkDetails describes how a point was measured, for its
+      // tooltip: the iterations that go test ran it for, b.N, how long they
+      // took altogether, and where it was parsed from.  Repeated runs which
+      // were summarized have the totals of all of them.
+      function benchmarkDetails(d) {
+        if (d.Decimated) {
+          return ""
+        }
+        var s = ""
+        if (d.N) {
+          s += "<br/>b.N = " + d.N + (d.Runs > 1 ? " in total" : "") +
+              (isFinite(d.TotalNs) ? ", " + formatNs(d.TotalNs) + " measured" : "")
+        }
+        if (d.File) {
+          s += "<br/>" + escapeHTML(d.File) + (d.Line && !(d.Runs > 1) ? ":" + d.Line : "")
+        }
+        return s
+      }
+
+      // predict shows the estimated response of every group at the N in the
+      // prediction input, and the largest N of every group within the budget
+      // in the budget input.
+      function predict() {
+        predictionsDiv.selectAll("*").remove()
+        var n = Number(predictInput.property("value"))
+        if (predictInput.property("value").trim() != "" && !isNaN(n)) {
+          d3.json("predict?n=" + encodeURIComponent(n) + "&" + analysisQuery(), function(error, preds) {
+            predictionsDiv.selectAll(".prediction").remove()
+            if (error) {
+              predictionsDiv.append("p").attr("class", "prediction")
+                  .text("unable to predict: " + error.responseText)
+              return
+            }
+            predictionsDiv.selectAll(".prediction")
+                .data(preds)
+              .enter().append("p")
+                .attr("class", "prediction")
+                .classed("extrapolated", function(d) { return d.Extrapolated; })
+                .text(function(d) {
+                  return d.Group + " at N=" + d.N + " ≈ " + formatY(d.Yhat) + " ± " + formatY(d.PredWidth) +
+                      (d.Extrapolated ? " (extrapolated)" : "")
+                })
+          })
+        }
+        var budget = budgetInput.property("value").trim()
+        if (budget != "") {
+          d3.json("solve?budget=" + encodeURIComponent(budget) + "&" + analysisQuery(), function(error, sols) {
+            predictionsDiv.selectAll(".solution").remove()
+            if (error) {
+              predictionsDiv.append("p").attr("class", "solution")
+                  .text("unable to solve: " + error.responseText)
+              return
+            }
+            var formatN = d3.format(",.0f")
+            predictionsDiv.selectAll(".solution")
+                .data(sols)
+              .enter().append("p")
+                .attr("class", "solution")
+                .classed("extrapolated", function(d) { return d.Extrapolated; })
+                .text(function(d) {
+                  if (d.Unbounded) {
+                    return d.Group + " is within " + formatY(d.Budget) + " for every N"
+                  }
+                  return d.Group + " is within " + formatY(d.Budget) + " up to N ≈ " + formatN(d.N) +
+                      " (" + formatN(d.NLow) + " to " + formatN(d.NHigh) + ")" +
+                      (d.Extrapolated ? " (extrapolated)" : "")
+                })
+          })
+        }
+      }
+
+      // showCrossovers marks where the first model of every pair of groups
+      // cross, shading the confidence interval of the crossover.
+      function showCrossovers(xlb, xub) {
+        var generation = plotGeneration
+        var draw = function(error, cs) {
+          if (generation != plotGeneration) {
+            return
+          }
+          crossoversDiv.selectAll("*").remove()
+          if (error || !cs) {
+            return
+          }
+          var formatN = d3.format(",.0f")
+          var px = function(v) { return Math.max(0, Math.min(width, xScale(v))); }
+          cs.forEach(function(c) {
+            var g = svg.append("g")
+                .classed("extrapolated", c.Extrapolated)
+            g.append("rect")
+                .attr("class", "crossoverband")
+                .attr("x", px(c.NLow))
+                .attr("width", px(c.NHigh) - px(c.NLow))
+                .attr("height", height)
+            g.append("line")
+                .attr("class", "crossover")
+                .attr("x1", px(c.N))
+                .attr("x2", px(c.N))
+                .attr("y2", height)
+            g.append("text")
+                .attr("x", px(c.N) + 3)
+                .attr("y", height - 6)
+                .text("N ≈ " + formatN(c.N))
+
+            var higher = c.Lower == c.A ? c.B : c.A
+            crossoversDiv.append("p")
+                .classed("extrapolated", c.Extrapolated)
+                .text(c.Lower + " is lower than " + higher + " below N ≈ " + formatN(c.N) +
+                    " (95% CI " + formatN(c.NLow) + " to " + formatN(c.NHigh) + ")" +
+                    (c.Extrapolated ? " (extrapolated)" : ""))
+          })
+        }
+        if (report) {
+          draw(null, report.Crossovers)
+          return
+        }
+        d3.json("crossover?xlb=" + encodeURIComponent(xlb) + "&xub=" + encodeURIComponent(xub) +
+            "&" + analysisQuery(), draw)
+      }
+
       // regHandler returns a function which can plot regression lines.  It
-      // is necessary because we "forget" what group we are using when we get
-      // a response from the call to fit.  There is probably a better way to do
-      // this kind of currying in javascript.
-      function regHandler(Group) {
+      // is necessary because we "forget" what group and model we are using
+      // when we get a response from the call to fit.  There is probably a
+      // better way to do this kind of currying in javascript.
+      function regHandler(Group, t) {
+          var generation = plotGeneration
           return function(error, data) {
+          // the plot has been redrawn since this fit was requested
+          if (generation != plotGeneration) {
+            return
+          }
+          if (error || !data || !data.ResultLine) {
+            return
+          }
           // TODO(jonlawlor): handle error
           // TODO(jonlawlor): do something with model form and model stats
           var linedataset = []
@@ -179,48 +2206,743 @@ const plotHTML = `
             linedataset.push(data.ResultLine[j])
             }
 
-          svg.append("path")
-            .datum(linedataset)
-            .attr("class", "line")
-            .attr("d", regLine)
-            .style("stroke", function(d) { return color(Group);});
+          // extrapolated parts of the line are faded and dotted, to show that
+          // they are much less certain.
+          segments(linedataset).forEach(function(seg) {
+            svg.append("path")
+              .datum(seg.points)
+              .attr("class", "band")
+              .classed("extrapolated", seg.extrapolated)
+              .attr("d", confBand)
+              .style("fill", function(d) { return color(Group);});
+
+            svg.append("path")
+              .datum(seg.points)
+              .attr("class", "line")
+              .classed("extrapolated", seg.extrapolated)
+              .attr("d", regLine)
+              .style("stroke", function(d) { return color(Group);})
+              .style("stroke-dasharray", seg.extrapolated ? "3,3" : transformDashes[t]);
+          })
+
+          fitSummaries.push({Group: Group, Transform: t, R2: data.R2, MSE: data.MSE, ResultModel: data.ResultModel,
+              Equation: data.Equation, LaTeX: data.LaTeX, Complexity: data.Complexity,
+              Condition: data.Condition, Collinear: data.Collinear, Rank: data.Rank,
+              Correlation: data.Correlation, NonFinite: data.NonFinite,
+              DF: data.DF, AdjR2: data.AdjR2, F: data.F, PValue: data.PValue})
+          showFits()
+          showCoefficients()
+          svg.selectAll(".legend text").text(legendLabel)
+          }
+        }
+
+      // showWarnings adds a banner for each file which could not be completely
+      // parsed, naming the lines that were skipped.
+      function showWarnings(data) {
+        warnings.selectAll("*").remove()
+        for (i in data.Files) {
+          var f = data.Files[i]
+          var msgs = []
+          var details = []
+          if (f.Err) {
+            msgs.push(f.Err)
+          }
+          if (f.Warnings && f.Warnings.length > 0) {
+            var lines = []
+            for (j in f.Warnings) {
+              lines.push(f.Warnings[j].Line)
+              details.push("line " + f.Warnings[j].Line + ": " + f.Warnings[j].Err + ": " + f.Warnings[j].Text)
+            }
+            msgs.push("skipped malformed line" + (lines.length > 1 ? "s " : " ") + lines.join(", "))
+          }
+          if (msgs.length > 0) {
+            warnings.append("div")
+                .attr("title", details.join("\n"))
+                .text(f.Path + ": " + msgs.join("; "))
+          }
+        }
+      }
+
+      // annotations are labeled vertical lines at values of N, which are
+      // drawn across all of the groups.
+      var annotations = report ? (report.Annotations || []) : []
+
+      // loadAnnotations fetches the annotations from the server, and plots
+      // them if they've changed.
+      function loadAnnotations() {
+        if (report) {
+          return
+        }
+        d3.json("annotations", function(error, a) {
+          if (error || !a) {
+            return
+          }
+          // the view's annotations are drawn along with the server's
+          if (view && view.Config.Annotations) {
+            view.Config.Annotations.forEach(function(v) {
+              if (!a.some(function(b) { return b.N == v.N && b.Label == v.Label })) {
+                a.push(v)
+              }
+            })
+          }
+          if (JSON.stringify(a) == JSON.stringify(annotations)) {
+            return
+          }
+          annotations = a
+          replot()
+        })
+      }
+
+      // displayNames label the groups in the legend, tooltips, and tables,
+      // by their names.  The groups are still found and fit by their own
+      // names.
+      var displayNames = report ? (report.Names || {}) : {}
+      if (!report) {
+        d3.json("names", function(error, names) {
+          if (!error && names && Object.keys(names).length > 0) {
+            displayNames = names
+            replot()
+          }
+        })
+      }
+
+      // displayName returns the display name of a group, which is also used
+      // for groups that end with a named one after a package, machine, or
+      // series, like pkg.BenchmarkSort.
+      function displayName(group) {
+        if (displayNames.hasOwnProperty(group)) {
+          return displayNames[group]
+        }
+        for (var name in displayNames) {
+          var i = group.length - name.length
+          if (i > 0 && group.slice(i) == name && ". ".indexOf(group[i - 1]) >= 0) {
+            return group.slice(0, i) + displayNames[name]
+          }
+        }
+        return group
+      }
+
+      // fileNotes has the notes from the sidecar of each file, by its path.
+      var fileNotes = {}
+
+      // showNotes lists the notes about each file which has them, like
+      // whether turbo was disabled for its run, so that they're kept with the
+      // plot and the reports made from it.
+      function showNotes(data) {
+        notesDiv.selectAll("*").remove()
+        fileNotes = {}
+        data.Files.forEach(function(f) {
+          if (!f.Meta) {
+            return
+          }
+          fileNotes[f.Path] = f.Meta.map(function(m) { return m.Key + ": " + m.Value })
+          notesDiv.append("div")
+              .text(f.Path + "\n  " + fileNotes[f.Path].join("\n  "))
+        })
+      }
+
+      // escapeHTML escapes text so that it can be added to the tooltip.
+      function escapeHTML(s) {
+        return String(s).replace(/&/g, "&amp;").replace(/</g, "&lt;").replace(/>/g, "&gt;")
+      }
+
+      // the areas of the points, in square pixels, normally and when they're
+      // hovered over.
+      var dotSize = 40
+      var hoverSize = 120
+
+      // markerShapes are the markers of each file's points, in order, when
+      // the groups are split by file.  Otherwise the points are circles.
+      var markerShapes = ["circle", "square", "triangle-up", "diamond", "cross", "triangle-down"]
+      var fileMarkers = {}
+
+      // how the markers of the points are chosen: "auto" marks each file's
+      // points when the groups are split by file, and otherwise marks each
+      // group once there are more groups than colors.  "group" always marks
+      // each group, and "circle" draws every point as a circle.
+      var markerBy = "auto"
+
+      // the number of groups which the colors are chosen from category10
+      // for, beyond which they're chosen from category20 and the groups are
+      // marked by cycling through groupShapes, so that every pair of color
+      // and shape is distinct for the first 60 groups.
+      var maxColors = 10
+      var groupShapes = ["circle", "square", "triangle-up"]
+      var groupMarkers = {}
+
+      // marker returns a function drawing the marker of a point, with the
+      // area size.
+      function marker(size) {
+        return d3.svg.symbol()
+            .type(function(d) { return groupMarkers[d.Group] || fileMarkers[d.File] || "circle" })
+            .size(size)
+      }
+
+      // setGroupStyles chooses the color and marker of each of the groups,
+      // which are sorted.  Colors which have already been chosen are kept.
+      function setGroupStyles(groups) {
+        color.range(groups.length > maxColors ? d3.scale.category20().range() : d3.scale.category10().range())
+        groups.forEach(color)
+        groupMarkers = {}
+        if (markerBy == "group" || (markerBy == "auto" && groups.length > maxColors && Object.keys(fileMarkers).length == 0)) {
+          groups.forEach(function(g) {
+            groupMarkers[g] = groupShapes[color.domain().indexOf(g) % groupShapes.length]
+          })
+        }
+      }
+
+      // showMarkers chooses the marker of each file, and shows them in the
+      // list of files, if the groups are split by file.
+      function showMarkers(data, byFile) {
+        fileMarkers = {}
+        if (byFile && markerBy == "auto") {
+          data.Files.filter(function(f) { return !f.Series }).forEach(function(f, i) {
+            fileMarkers[f.Path] = markerShapes[i % markerShapes.length]
+          })
+        }
+        filesDiv.selectAll("svg.marker")
+            .style("display", Object.keys(fileMarkers).length > 0 ? null : "none")
+          .select("path")
+            .attr("d", function(path) { return marker(dotSize)({File: path}) })
+      }
+
+      // showFiles lists the files in the data, with a checkbox for whether
+      // each is plotted.  Reports are fit on all of their files, so they
+      // can't be left out.
+      function showFiles(data) {
+        filesDiv.selectAll("*").remove()
+        if (data.Files.length < 2) {
+          return
+        }
+        filesDiv.append("div").text("files:")
+        var labels = filesDiv.selectAll("label")
+            .data(data.Files.map(function(f) { return f.Path }))
+          .enter().append("label")
+            .attr("title", function(d) { return d })
+        labels.append("input")
+            .attr("type", "checkbox")
+            .property("checked", function(d) { return !hiddenFiles[d] })
+            .classed("refit", true)
+            .property("disabled", !!report && !localFit)
+            .on("change", function(d) {
+              if (this.checked) {
+                delete hiddenFiles[d]
+              } else {
+                hiddenFiles[d] = true
+              }
+              replot()
+            })
+        labels.append("svg")
+            .attr("class", "marker")
+            .attr("width", 12)
+            .attr("height", 12)
+          .append("path")
+            .attr("transform", "translate(6,6)")
+            .style("stroke", "#000")
+        labels.append("span").text(function(d) { return " " + d })
+        showMarkers(data, groupByFile)
+      }
+
+      // showUnparameterized lists the benchmarks which don't match nre.
+      function showUnparameterized(unmatched) {
+        unparamTableDiv.selectAll("*").remove()
+        if (unmatched.length == 0) {
+          return
+        }
+        unparamTableDiv.append("p").text("Benchmarks without a parameter matching " + nre.source + ":")
+        var table = unparamTableDiv.append("table")
+        var header = table.append("tr")
+        header.append("th").text("benchmark")
+        header.append("th").text("file")
+        header.append("th").text(yVar)
+        var rows = table.selectAll(".row")
+            .data(unmatched)
+          .enter().append("tr")
+        rows.append("td").text(function(d) { return d.Name; })
+        rows.append("td").text(function(d) { return d.File; })
+        rows.append("td").text(yValue)
+      }
+
+      // loaded is the most recently loaded data, so that the plot can be
+      // redrawn when a setting changes.
+      var loaded = null
+
+      // plotGeneration is incremented on every redraw, so that fits which
+      // arrive after a redraw are ignored.
+      var plotGeneration = 0
+
+      // how often to reload live data, in milliseconds
+      var reloadInterval = 2000
+
+      function load(data) {
+        loaded = data
+        rerunButton
+            .style("display", data.Rerun ? null : "none")
+            .property("disabled", data.Live)
+        showWarnings(data)
+        loadAnnotations()
+        showNotes(data)
+        showFiles(data)
+        showPackages(data)
+        showMachines(data)
+        replot()
+
+        // the benchmarks are still being run, so check for more, unless the
+        // server will send them over the socket.
+        if (data.Live && !socket) {
+          setTimeout(loadData, reloadInterval)
+        }
+      }
+
+      // loadData loads the data from the server.  It is streamed as
+      // newline delimited events, the same as the ones sent over the socket,
+      // so that a large data set is plotted as it arrives rather than
+      // waiting for one giant document.
+      function loadData() {
+        if (!window.fetch || !window.TextDecoder || !window.ReadableStream) {
+          d3.json("data", load)
+          return
+        }
+        fetch("data?format=ndjson").then(function(resp) {
+          var reader = resp.body.getReader()
+          var decoder = new TextDecoder()
+          var buf = ""
+          function read() {
+            return reader.read().then(function(chunk) {
+              if (chunk.done) {
+                if (buf) {
+                  handleEvent(JSON.parse(buf))
+                }
+                if (loaded) {
+                  showPackages(loaded)
+                  showMachines(loaded)
+                  replot()
+                }
+                return
+              }
+              buf += decoder.decode(chunk.value, {stream: true})
+              var lines = buf.split("\n")
+              buf = lines.pop()
+              lines.forEach(function(line) {
+                if (line) {
+                  handleEvent(JSON.parse(line))
+                }
+              })
+              return read()
+            })
+          }
+          return read()
+        })
+      }
+
+      // showPackages fills in the package filter with every package in the
+      // data.
+      function showPackages(data) {
+        showChoices(pkgSelect, data, "Package", pkgFilter)
+      }
+
+      // showMachines fills in the machine filter with every machine in the
+      // data.
+      function showMachines(data) {
+        showChoices(machineSelect, data, "Machine", machineFilter)
+        showChoices(compareASelect, data, "Machine", compareA)
+        showChoices(compareBSelect, data, "Machine", compareB)
+        compareASelect.select("option").text("none")
+        compareBSelect.select("option").text("none")
+      }
+
+      // showChoices fills in a filter with every value of a field of the
+      // benchmarks, after "all".
+      function showChoices(sel, data, field, value) {
+        var values = {}
+        for (i in data.Files) {
+          for (j in data.Files[i].Benchmarks) {
+            values[data.Files[i].Benchmarks[j][field]] = true
+          }
+        }
+        var options = [""].concat(Object.keys(values).filter(function(v) { return v != "" && v != "undefined"; }).sort())
+        sel.selectAll("option").remove()
+        sel.selectAll("option")
+            .data(options)
+          .enter().append("option")
+            .attr("value", function(d) { return d; })
+            .text(function(d) { return d == "" ? "all" : d; })
+        sel.property("value", value)
+      }
+
+      function replot() {
+        recordState()
+        if (!loaded) {
+          return
+        }
+        svg.selectAll("*").remove()
+        plot(loaded)
+      }
+
+      // socket is the websocket connection to the server, which sends new
+      // data as it arrives and answers fit requests.  It is null when there
+      // is no connection, in which case /data and /fit are used instead.
+      var socket = null
+
+      // callbacks for fits requested over the socket, keyed by request ID
+      var fitCallbacks = {}
+      var nextFitID = 1
 
-          svg.append("path")
-            .datum(linedataset)
-            .attr("class", "boundline")
-            .attr("d", regLineUB)
-            .style("stroke", function(d) { return color(Group);});
+      // connect opens the websocket, or falls back to loading /data if that
+      // isn't possible.
+      function connect() {
+        if (!window.WebSocket) {
+          loadData()
+          return
+        }
+        // the server's paths are relative, so that a project's plotter under
+        // /p/{project}/ uses its own data.
+        var ws = new WebSocket((location.protocol == "https:" ? "wss://" : "ws://") + location.host + location.pathname.replace(/[^\/]*$/, "") + "ws")
+        var opened = false
+        ws.onopen = function() {
+          opened = true
+          socket = ws
+        }
+        ws.onmessage = function(msg) {
+          handleEvent(JSON.parse(msg.data))
+        }
+        ws.onclose = function() {
+          socket = null
+          // requests in flight will never be answered
+          fitCallbacks = {}
+          if (!opened) {
+            loadData()
+          }
+        }
+      }
 
-          svg.append("path")
-            .datum(linedataset)
-            .attr("class", "boundline")
-            .attr("d", regLineLB)
-            .style("stroke", function(d) { return color(Group);});
+      // handleEvent handles an event sent by the server over the socket.
+      function handleEvent(e) {
+        switch (e.Type) {
+        case "` + eventData + `":
+          load(e.Data)
+          break
+        case "` + eventParse + `":
+          if (loaded && loaded.Files[e.File]) {
+            loaded.Files[e.File].Benchmarks.push(e.Benchmark)
+            scheduleReplot()
           }
+          break
+        case "` + eventFit + `":
+          var callback = fitCallbacks[e.ID]
+          delete fitCallbacks[e.ID]
+          if (callback) {
+            callback(e.Err || null, e.Fit)
+          }
+          break
+        }
+      }
+
+      // scheduleReplot redraws the plot soon, so that a burst of new
+      // benchmarks only causes a single redraw.
+      var replotPending = false
+      function scheduleReplot() {
+        if (replotPending) {
+          return
+        }
+        replotPending = true
+        setTimeout(function() {
+          replotPending = false
+          replot()
+        }, 250)
+      }
+
+      // requestFit fits the benchmarks with the parameters in query, and
+      // calls callback(error, fit) with the result.
+      function requestFit(query, benchmarks, callback) {
+        if (localFit) {
+          localFit(query, benchmarks, callback)
+          return
+        }
+        if (socket) {
+          var id = nextFitID++
+          fitCallbacks[id] = callback
+          socket.send(JSON.stringify({ID: id, Query: query, Benchmarks: benchmarks}))
+          return
         }
+        var body = JSON.stringify(benchmarks)
+        function post(tries) {
+          d3.json("fit?" + query)
+            .header("Content-Type", "application/json")
+            .post(body, function(error, fit) {
+              // the server refuses fits while too many are queued, so
+              // they are sent again after a while.
+              if (error && error.status == 429 && tries < 5) {
+                setTimeout(function() { post(tries + 1) }, 1000 * (tries + 1))
+                return
+              }
+              callback(error, fit)
+            })
+        }
+        post(0)
+      }
 
 			//dataset
-      d3.json("/data", function(data) {
+      if (report) {
+        load(report.Data)
+      } else {
+        connect()
+      }
+
+      // aggregate summarizes the repeated runs of each group at the same N,
+      // in the same way as the server.  Each measurement is summarized
+      // separately, except that the best run has the largest MB/s.
+      function aggregate(dataset) {
+        if (aggregation == "` + aggregateAll + `") {
+          return dataset
+        }
+        var runs = {}
+        var keys = []
+        dataset.forEach(function(b) {
+          var key = b.Group + "\x00" + b.X
+          if (!runs[key]) {
+            runs[key] = []
+            keys.push(key)
+          }
+          runs[key].push(b)
+        })
+        var summary = {
+          "` + aggregateMean + `": function(v) { return d3.mean(v) },
+          "` + aggregateMedian + `": function(v) { return d3.median(v) },
+          "` + aggregateMin + `": function(v, larger) { return larger ? d3.max(v) : d3.min(v) }
+        }[aggregation]
+        return keys.map(function(key) {
+          var r = runs[key]
+          var a = {}
+          for (k in r[0]) {
+            a[k] = r[0][k]
+          }
+          a.N = d3.sum(r, function(b) { return b.N })
+          a.TotalNs = d3.sum(r, function(b) { return b.TotalNs })
+          a.NsPerOp = summary(r.map(function(b) { return b.NsPerOp }))
+          a.AllocedBytesPerOp = Math.round(summary(r.map(function(b) { return b.AllocedBytesPerOp })))
+          a.AllocsPerOp = Math.round(summary(r.map(function(b) { return b.AllocsPerOp })))
+          a.MBPerS = summary(r.map(function(b) { return b.MBPerS }), true)
+          // the throughput of the summary, so the mean is the harmonic mean
+          // of the runs' throughputs, as it is on the server.
+          a.OpsPerS = 1e9 / a.NsPerOp
+          ;["InstructionsPerOp", "CacheMissesPerOp", "BranchMissesPerOp"].forEach(function(c) {
+            // runs without the counter are left out of its summary
+            var v = r.filter(function(b) { return c in b })
+            if (v.length > 0) {
+              a[c] = summary(v.map(function(b) { return b[c] }))
+            }
+          })
+          a.Runs = r.length
+
+          // the spread of the runs is drawn as a boxplot: the minimum,
+          // quartiles, and maximum of the response.
+          var v = r.map(yValue).sort(d3.ascending)
+          a.Spread = [v[0], d3.quantile(v, 0.25), d3.quantile(v, 0.5), d3.quantile(v, 0.75), v[v.length - 1]]
+          return a
+        })
+      }
+
+      // showBaselines fills in the baseline select with the groups in the
+      // dataset.
+      function showBaselines(dataset) {
+        var groups = d3.set(dataset.map(cValue)).values().sort()
+        if (groups.indexOf(baselineGroup) < 0) {
+          baselineGroup = ""
+        }
+        baselineSelect.selectAll("option").remove()
+        baselineSelect.selectAll("option")
+            .data([""].concat(groups))
+          .enter().append("option")
+            .attr("value", function(d) { return d; })
+            .text(function(d) { return d == "" ? "none" : d; })
+        baselineSelect.property("value", baselineGroup)
+      }
+
+      // requestBaseline predicts the baseline group at every N in the
+      // dataset, and then plots the data again, normalized by it.
+      function requestBaseline(data, dataset) {
+        var generation = plotGeneration
+        var ns = d3.set(dataset.map(xValue)).values()
+        d3.json("predict?" + analysisQuery() + ns.map(function(n) { return "&n=" + encodeURIComponent(n) }).join(""), function(error, preds) {
+          if (generation != plotGeneration) {
+            return
+          }
+          if (error || !preds) {
+            // plot the responses themselves instead
+            baselineGroup = ""
+          }
+          var baseline = {}
+          for (i in preds) {
+            if (preds[i].Group == baselineGroup) {
+              baseline[preds[i].N] = preds[i].Yhat
+            }
+          }
+          svg.selectAll("*").remove()
+          plot(data, baseline)
+        })
+      }
+
+      // requestPoints requests a summary of the groups with more than
+      // maxPoints points, and then plots the data again, drawing the summary
+      // in place of their points.
+      function requestPoints(data, baseline) {
+        var generation = plotGeneration
+        d3.json("points?" + analysisQuery() + "&maxpoints=" + maxPoints, function(error, points) {
+          if (generation != plotGeneration) {
+            return
+          }
+          svg.selectAll("*").remove()
+          plot(data, baseline, error || !points ? {} : points)
+        })
+      }
+
+      // decimated replaces the benchmarks of each group in points with its
+      // summary, for drawing.
+      function decimated(dataset, points) {
+        var shown = dataset.filter(function(d) { return !points[d.Group] })
+        for (g in points) {
+          points[g].forEach(function(p) {
+            var d = {Group: g, X: p.X, Runs: p.Runs, Spread: p.Spread, Decimated: true}
+            d[yVar] = p.Y
+            shown.push(d)
+          })
+        }
+        return shown
+      }
+
+      // normalize divides the response of each benchmark by the baseline at
+      // the same N.  Benchmarks where the baseline isn't positive are left
+      // out, since their ratio is meaningless.
+      function normalize(dataset, baseline) {
+        return dataset.filter(function(d) { return baseline[d.X] > 0 }).map(function(d) {
+          var b = {}
+          for (k in d) {
+            b[k] = d[k]
+          }
+          b[yVar] = d[yVar] / baseline[d.X]
+          if (d.Spread) {
+            b.Spread = d.Spread.map(function(v) { return v / baseline[d.X] })
+          }
+          return b
+        })
+      }
+
+      // plot draws the data.  If there is a baseline group, its predictions
+      // are requested first, and then plot is called again with them.  The
+      // same goes for the summary points of groups with more than maxPoints
+      // points.
+      function plot(data, baseline, points) {
+        plotGeneration++
+        fitSummaries = []
+        showFits()
+        showCoefficients()
+
         var dataset = []
+        var unmatched = []
+        // the hidden groups which would otherwise be plotted
+        var hiddenShown = {}
+        showMarkers(data, groupByFile)
         // extract the dataset
-        for (i in data) {
-          for (j in data[i]) {
-            var matches = data[i][j].Name.match(nre)
-            var n;
-            if (matches && matches.length > 1) {
-              data[i][j].Group = matches[1]
-              data[i][j].X = Number(matches[2])
-              dataset.push(data[i][j])
+        for (i in data.Files) {
+          if (hiddenFiles[data.Files[i].Path]) {
+            continue
+          }
+          var benchmarks = data.Files[i].Benchmarks
+          for (j in benchmarks) {
+            if (pkgFilter != "" && benchmarks[j].Package != pkgFilter) {
+              continue
+            }
+            if (machineFilter != "" && benchmarks[j].Machine != machineFilter) {
+              continue
+            }
+            if (perfCounters[yVar] && !(yVar in benchmarks[j])) {
+              continue
+            }
+            if (comparing() && benchmarks[j].Machine != compareA && benchmarks[j].Machine != compareB) {
+              continue
+            }
+            benchmarks[j].File = data.Files[i].Path
+            benchmarks[j].Cluster = {
+              "` + clusterFile + `": data.Files[i].Path,
+              "` + clusterMachine + `": benchmarks[j].Machine || ""
+            }[clusterBy] || ""
+            benchmarks[j].OpsPerS = 1e9 / benchmarks[j].NsPerOp
+            benchmarks[j].TotalNs = benchmarks[j].N * benchmarks[j].NsPerOp
+            var matches = benchmarks[j].Name.match(nre)
+            if (matches && matches.length > 2) {
+              benchmarks[j].Group = matches[1]
+              benchmarks[j].X = Number(matches[2])
+              dataset.push(benchmarks[j])
+            } else if (unparameterized == "` + unparamOne + `") {
+              benchmarks[j].Group = benchmarks[j].Name.match(procre)[1]
+              benchmarks[j].X = 1
+              dataset.push(benchmarks[j])
+            } else {
+              unmatched.push(benchmarks[j])
+              continue
+              }
+            if (groupByPackage && benchmarks[j].Package) {
+              benchmarks[j].Group = benchmarks[j].Package + "." + benchmarks[j].Group
+              }
+            if ((groupByMachine || comparing()) && benchmarks[j].Machine) {
+              benchmarks[j].Group = "[" + benchmarks[j].Machine + "] " + benchmarks[j].Group
+              }
+            if (data.Files[i].Series) {
+              benchmarks[j].Group = data.Files[i].Series + ": " + benchmarks[j].Group
+              } else if (groupByFile) {
+              benchmarks[j].Group = data.Files[i].Path + ": " + benchmarks[j].Group
+              }
+            if (!plotsGroup(benchmarks[j].Group)) {
+              dataset.pop()
+              } else if (hiddenGroups[benchmarks[j].Group]) {
+              hiddenShown[benchmarks[j].Group] = true
+              dataset.pop()
               }
             }
           }
+        showUnparameterized(unmatched)
+        dataset = aggregate(dataset)
+        showBaselines(dataset)
+        showRatioChoices(dataset)
+        if (baselineGroup != "" && !report) {
+          if (!baseline) {
+            requestBaseline(data, dataset)
+            return
+          }
+          dataset = normalize(dataset, baseline)
+        }
+        // groups with too many points to draw are drawn from a summary
+        var shown = dataset
+        if (maxPoints > 0 && !report && baselineGroup == "" && !comparing()) {
+          if (!points) {
+            if (d3.max(groupBy(dataset, "Group"), function(g) { return g.benchmarks.length }) > maxPoints) {
+              requestPoints(data, baseline)
+              return
+            }
+          } else {
+            shown = decimated(dataset, points)
+          }
+        }
+        // the regressions are evaluated over the fit range, which may extend
+        // beyond the data.
+        var xlb = xlbSetting === null ? d3.min(dataset, xValue) : xlbSetting
+        var xub = xubSetting === null ? d3.max(dataset, xValue) : xubSetting
+
+        // the groups in the legend, including the hidden ones
+        var legendGroups = d3.set(dataset.map(cValue).concat(Object.keys(hiddenShown))).values().sort()
+        setGroupStyles(legendGroups)
+
         // don't want dots overlapping axis, so add in buffer to data domain
-        xScale.domain([d3.min(dataset, xValue)-1, d3.max(dataset, xValue)+1]);
-        yScale.domain([d3.min(dataset, yValue)-1, d3.max(dataset, yValue)+1]);
+        xScale.domain([Math.min(d3.min(shown, xValue), xlb)-1, Math.max(d3.max(shown, xValue), xub)+1]);
+        yScale.domain([
+          d3.min(shown, function(d) { return d.Spread ? d.Spread[0] : yValue(d) })-1,
+          d3.max(shown, function(d) { return d.Spread ? d.Spread[4] : yValue(d) })+1]);
 
         // sort the benchmark groups in alphabetical order, so that the same set
         // of benchmarks always results in the same coloring.
         dataset.sort(orderBy("Group"))
+        shown.sort(orderBy("Group"))
 
         // TODO(jonlawlor): allow log scale
         // x-axis
@@ -246,27 +2968,84 @@ const plotHTML = `
             .attr("y", 6)
             .attr("dy", ".71em")
             .style("text-anchor", "end")
-            .text("ns/op");
+            .text(baselineGroup ? yUnits[yVar] + " relative to " + baselineGroup : yUnits[yVar]);
+
+        // draw the annotations which are in the range of N.  Those of the
+        // cache sizes are only drawn if N is named like a number of bytes,
+        // which reports have already checked.
+        var xDomain = xScale.domain()
+        var byteSized = !!report || /byte|size/i.test(nre.nName || "")
+        var marks = svg.selectAll(".annotation")
+            .data(annotations.filter(function(a) {
+              return a.N >= xDomain[0] && a.N <= xDomain[1] && (!a.Bytes || byteSized)
+            }))
+          .enter().append("g")
+            .attr("class", "annotation")
+            .attr("transform", function(a) { return "translate(" + xScale(a.N) + ",0)" })
+        marks.append("line")
+            .attr("y1", 0)
+            .attr("y2", height)
+        marks.append("text")
+            .attr("transform", "rotate(-90)")
+            .attr("x", -4)
+            .attr("y", -3)
+            .style("text-anchor", "end")
+            .text(function(a) { return a.Label })
+        marks.append("title")
+            .text(function(a) { return a.Label + " at N = " + a.N })
+
+        // draw the spread of repeated runs behind their summary
+        var spreads = svg.selectAll(".spread")
+            .data(shown.filter(function(d) { return d.Runs > 1 }))
+          .enter().append("g")
+            .attr("class", "spread")
+            .style("stroke", function(d) { return color(cValue(d));})
+            .style("fill", function(d) { return color(cValue(d));})
+        spreads.append("line")
+            .attr("x1", xMap).attr("x2", xMap)
+            .attr("y1", function(d) { return yScale(d.Spread[0]) })
+            .attr("y2", function(d) { return yScale(d.Spread[4]) })
+        spreads.append("rect")
+            .attr("x", function(d) { return xMap(d) - 4 })
+            .attr("width", 8)
+            .attr("y", function(d) { return yScale(d.Spread[3]) })
+            .attr("height", function(d) { return yScale(d.Spread[1]) - yScale(d.Spread[3]) })
+        spreads.append("line")
+            .attr("x1", function(d) { return xMap(d) - 4 })
+            .attr("x2", function(d) { return xMap(d) + 4 })
+            .attr("y1", function(d) { return yScale(d.Spread[2]) })
+            .attr("y2", function(d) { return yScale(d.Spread[2]) })
 
         // draw dots
         svg.selectAll(".dot")
-            .data(dataset)
-          .enter().append("circle")
+            .data(shown)
+          .enter().append("path")
             .attr("class", "dot")
-            .attr("r", 3.5)
-            .attr("cx", xMap)
-            .attr("cy", yMap)
+            .attr("d", marker(dotSize))
+            .attr("transform", function(d) { return "translate(" + xMap(d) + "," + yMap(d) + ")" })
             .style("fill", function(d) { return color(cValue(d));})
+            .style("cursor", function(d) { return report || d.Decimated ? null : "pointer" })
+            .on("click", function(d) {
+              if (!report && !d.Decimated) {
+                showDistribution(d)
+                showTrend(d)
+              }
+            })
             .on("mouseover", function(d) {
+                highlight(d)
                 tooltip.transition()
                      .duration(200)
                      .style("opacity", .9);
-                tooltip.html(d.Group + "<br/> (" + xValue(d)
-      	        + ", " + yValue(d) + ")")
+                tooltip.html(escapeHTML(displayName(d.Group)) + "<br/> (" + xValue(d)
+      	        + ", " + yValue(d) + ")" +
+                    (d.Runs > 1 ? "<br/>" + d.Runs + " runs from " + d.Spread[0] + " to " + d.Spread[4] : "") +
+                    benchmarkDetails(d) +
+                    (fileNotes[d.File] ? "<br/>" + fileNotes[d.File].map(escapeHTML).join("<br/>") : ""))
                      .style("left", (d3.event.pageX + 5) + "px")
                      .style("top", (d3.event.pageY - 28) + "px");
             })
             .on("mouseout", function(d) {
+                unhighlight()
                 tooltip.transition()
                      .duration(500)
                      .style("opacity", 0);
@@ -275,38 +3054,223 @@ const plotHTML = `
         var benchGroups = groupBy(dataset, "Group")
 
         for (i in benchGroups) {
-          d3.json("/fit?" +
-                  "response=" + encodeURIComponent(yVar) +
-                  "&xlb=" + encodeURIComponent(d3.min(dataset, xValue)) +
-                  "&xub=" + encodeURIComponent(d3.max(dataset, xValue)) +
-                  "&xtransform=" + encodeURIComponent(xTransform) +
-                  "&yvar=" + encodeURIComponent(yVar) +
-                  "&nlinesteps=" + encodeURIComponent(nLineSteps))
-            .header("Content-Type", "application/json")
-            .post(JSON.stringify(benchGroups[i].benchmarks), regHandler(benchGroups[i].Group))
+          // a curve can't be fit through a single N
+          if (d3.min(benchGroups[i].benchmarks, xValue) == d3.max(benchGroups[i].benchmarks, xValue)) {
+            continue
+          }
+          for (var t = 0; t < xTransforms.length; t++) {
+            if (report && !localFit) {
+              // reports only contain fits for their own settings
+              if (report.Fits[benchGroups[i].Group]) {
+                regHandler(benchGroups[i].Group, t)(null, report.Fits[benchGroups[i].Group][t])
+              }
+              continue
+            }
+            requestFit("response=" + encodeURIComponent(yVar) +
+                    "&xlb=" + encodeURIComponent(xlb) +
+                    "&xub=" + encodeURIComponent(xub) +
+                    "&xtransform=" + encodeURIComponent(xTransforms[t]) +
+                    "&yvar=" + encodeURIComponent(yVar) +
+                    "&nlinesteps=" + encodeURIComponent(nLineSteps) +
+                    fitterQuery(),
+                    benchGroups[i].benchmarks, regHandler(benchGroups[i].Group, t))
+            }
           }
+        if (xTransforms.length > 0) {
+          showCrossovers(xlb, xub)
+          showChowTests()
+          showMachineComparison()
+          showRatio()
+        }
+        if (baselineGroup == "") {
+          showExponents()
+          showDrifts()
+        } else {
+          exponentsDiv.selectAll("*").remove()
+          driftsDiv.selectAll("*").remove()
+        }
 
-        // draw legend
+        // draw legend, with the hidden groups faded.  Clicking a group hides
+        // or shows it.
         var legend = svg.selectAll(".legend")
-            .data(color.domain())
+            .data(legendGroups)
           .enter().append("g")
             .attr("class", "legend")
-            .attr("transform", function(d, i) { return "translate(0," + i * 20 + ")"; });
+            .attr("transform", function(d, i) { return "translate(0," + i * 20 + ")"; })
+            .style("cursor", "pointer")
+            .style("opacity", function(d) { return hiddenGroups[d] ? 0.3 : 1 })
+            .on("click", function(d) {
+              if (hiddenGroups[d]) {
+                delete hiddenGroups[d]
+              } else {
+                hiddenGroups[d] = true
+              }
+              replot()
+            });
+        legend.append("title")
+            .text(function(d) { return (hiddenGroups[d] ? "show " : "hide ") + displayName(d) })
 
-        // draw legend colored rectangles
-        legend.append("rect")
-            .attr("x", 30)
-            .attr("width", 18)
-            .attr("height", 18)
-            .style("fill", color);
+        // draw legend colored rectangles, or the groups' markers if they
+        // have them
+        legend.each(function(d) {
+          if (groupMarkers[d]) {
+            d3.select(this).append("path")
+                .attr("transform", "translate(39,9)")
+                .style("stroke", "#000")
+                .attr("d", marker(hoverSize)({Group: d}))
+                .style("fill", color(d))
+            return
+          }
+          d3.select(this).append("rect")
+              .attr("x", 30)
+              .attr("width", 18)
+              .attr("height", 18)
+              .style("fill", color(d))
+        })
 
         // draw legend text
         legend.append("text")
             .attr("x", 52)
             .attr("y", 9)
             .attr("dy", ".35em")
-            .text(function(d) { return d;})
+            .text(legendLabel)
+
+        showPanels(dataset, xlb, xub)
+        }
+
+      // showPanels draws a panel of each of the responses in panelYs other
+      // than the plotted one, in the panels layout.  They share the chart's
+      // scale of N, and are fit with its first model.
+      function showPanels(dataset, xlb, xub) {
+        panelsDiv.selectAll("*").remove()
+        if (layout != "panels") {
+          return
+        }
+        var generation = plotGeneration
+        var ys = Object.keys(yUnits).filter(function(m) { return panelYs[m] && m != yVar })
+        ys.forEach(function(m, k) {
+          var value = function(d) { return d[m] }
+          var points = dataset.filter(function(d) { return isFinite(value(d)) })
+          var last = k == ys.length - 1
+          var panel = panelsDiv.append("svg")
+              .attr("width", width + margin.left + margin.right)
+              .attr("height", panelHeight + margin.top + (last ? margin.bottom : 0))
+            .append("g")
+              .attr("transform", "translate(" + margin.left + "," + margin.top + ")");
+          var y = d3.scale.linear()
+              .range([panelHeight, 0])
+              .domain(points.length > 0 ? [d3.min(points, value), d3.max(points, value)] : [0, 1])
+              .nice()
+
+          // only the bottom panel labels N, which is the same for all of them
+          panel.append("g")
+              .attr("class", "x axis")
+              .attr("transform", "translate(0," + panelHeight + ")")
+              .call(d3.svg.axis().scale(xScale).orient("bottom").tickFormat(last ? null : function() { return "" }))
+          panel.append("g")
+              .attr("class", "y axis")
+              .call(d3.svg.axis().scale(y).orient("left").ticks(5))
+            .append("text")
+              .attr("class", "label")
+              .attr("transform", "rotate(-90)")
+              .attr("y", 6)
+              .attr("dy", ".71em")
+              .style("text-anchor", "end")
+              .text(yUnits[m]);
+
+          // reports only have the fits of their own response
+          if (xTransforms.length > 0 && (!report || localFit)) {
+            var line = d3.svg.line()
+                .x(function(d) { return xScale(d.X) })
+                .y(function(d) { return y(d.Yhat) })
+            groupBy(points, "Group").forEach(function(g) {
+              if (d3.min(g.benchmarks, xValue) == d3.max(g.benchmarks, xValue)) {
+                return
+              }
+              requestFit("response=" + encodeURIComponent(m) +
+                  "&xlb=" + encodeURIComponent(xlb) +
+                  "&xub=" + encodeURIComponent(xub) +
+                  "&xtransform=" + encodeURIComponent(xTransforms[0]) +
+                  "&yvar=" + encodeURIComponent(m) +
+                  "&nlinesteps=" + encodeURIComponent(nLineSteps) +
+                  fitterQuery(),
+                  g.benchmarks, function(error, fit) {
+                    if (generation != plotGeneration || error || !fit || !fit.ResultLine) {
+                      return
+                    }
+                    panel.insert("path", ".dot")
+                        .datum(fit.ResultLine.map(function(p) { return {X: Number(p.X), Yhat: Number(p.Yhat)} }))
+                        .attr("class", "line")
+                        .attr("d", line)
+                        .style("stroke", color(g.Group))
+                  })
+            })
+          }
+
+          panel.selectAll(".dot")
+              .data(points)
+            .enter().append("path")
+              .attr("class", "dot")
+              .attr("d", marker(dotSize))
+              .attr("transform", function(d) { return "translate(" + xMap(d) + "," + y(value(d)) + ")" })
+              .style("fill", function(d) { return color(cValue(d)) })
+              .on("mouseover", function(d) {
+                highlight(d)
+                tooltip.transition()
+                    .duration(200)
+                    .style("opacity", .9);
+                tooltip.html(escapeHTML(displayName(d.Group)) + "<br/>N = " + xValue(d) +
+                    [yVar].concat(ys).map(function(r) { return "<br/>" + d[r] + " " + yUnits[r] }).join("") +
+                    benchmarkDetails(d))
+                    .style("left", (d3.event.pageX + 5) + "px")
+                    .style("top", (d3.event.pageY - 28) + "px");
+              })
+              .on("mouseout", function() {
+                unhighlight()
+                tooltip.transition()
+                    .duration(500)
+                    .style("opacity", 0);
+              });
         })
+      }
+
+      // highlight links hovering over a benchmark in the chart and the
+      // panels, by enlarging it in each of them and marking its N across
+      // them.
+      function highlight(d) {
+        d3.selectAll(".dot")
+            .filter(function(e) { return e === d })
+            .attr("d", marker(hoverSize))
+        var x = xScale(xValue(d))
+        svg.append("line")
+            .attr("class", "hover")
+            .attr("x1", x).attr("x2", x)
+            .attr("y1", 0).attr("y2", height)
+        panelsDiv.selectAll("svg > g").append("line")
+            .attr("class", "hover")
+            .attr("x1", x).attr("x2", x)
+            .attr("y1", 0).attr("y2", panelHeight)
+      }
+
+      // unhighlight undoes highlight.
+      function unhighlight() {
+        d3.selectAll(".dot").attr("d", marker(dotSize))
+        d3.selectAll(".hover").remove()
+      }
+
+      // legendLabel labels a group in the legend with the R² of its first
+      // model and its complexity class, once they have been fit.
+      function legendLabel(group) {
+        var fits = fitSummaries.filter(function(d) { return d.Group == group && d.Transform == 0; })
+        if (fits.length == 0) {
+          return displayName(group)
+        }
+        var label = displayName(group) + " (R² " + d3.format(".4f")(fits[0].R2)
+        if (fits[0].Complexity) {
+          label += ", " + fits[0].Complexity
+        }
+        return label + ")"
+      }
 		</script>
 	</body>
 </html>
@@ -0,0 +1,146 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "fmt"
+
+// The frontends which the plotter can be served with.
+const (
+	frontendD3     = "d3"     // the interactive plotter in plotHTML
+	frontendPlotly = "plotly" // a simpler plotter in plotlyHTML
+)
+
+// frontendPage returns the page of the named frontend.
+func frontendPage(name string) (string, error) {
+	switch name {
+	case "", frontendD3:
+		return plotHTML, nil
+	case frontendPlotly:
+		return plotlyHTML, nil
+	}
+	return "", fmt.Errorf("invalid frontend: %s", name)
+}
+
+// plotlyURL is where the plotly frontend loads Plotly.js from.
+const plotlyURL = "https://cdn.plot.ly/plotly-2.35.2.min.js"
+
+// plotlyHTML is an alternative to the d3 plotter, which draws the Vega-Lite
+// spec from /vega with Plotly.js, so that zooming, hovering, and exporting
+// images come built in.  The settings are kept in the querystring, which is
+// passed along to /vega, so a plot can be linked to.
+const plotlyHTML = `
+<!DOCTYPE html>
+<html lang="en">
+	<head>
+		<meta charset="utf-8">
+		<title>go benchplot</title>
+		<script src="` + plotlyURL + `" charset="utf-8"></script>
+		<style type="text/css">
+      body {
+        font: 11px sans-serif;
+      }
+
+      form input[type=text] {
+        width: 300px;
+      }
+
+      .error {
+        color: #d62728;
+      }
+		</style>
+	</head>
+	<body>
+    <form method="get">
+      <label>N pattern <input type="text" name="nre" placeholder="default"></label>
+      <label>model <input type="text" name="xtransform" placeholder="default"></label>
+      <label>response
+        <select name="yvar">
+          <option value="NsPerOp">ns/op</option>
+          <option value="AllocedBytesPerOp">B/op</option>
+          <option value="AllocsPerOp">allocs/op</option>
+          <option value="MBPerS">MB/s</option>
+          <option value="OpsPerS">ops/s</option>
+          <option value="InstructionsPerOp">instructions/op</option>
+          <option value="CacheMissesPerOp">cache-misses/op</option>
+          <option value="BranchMissesPerOp">branch-misses/op</option>
+        </select>
+      </label>
+      <label>runs
+        <select name="aggregate">
+          <option value="all">all</option>
+          <option value="mean">mean</option>
+          <option value="median">median</option>
+          <option value="min">min</option>
+        </select>
+      </label>
+      <label>N axis
+        <select name="xscale">
+          <option value="linear">linear</option>
+          <option value="log">log</option>
+        </select>
+      </label>
+      <input type="submit" value="plot">
+    </form>
+    <p class="error"></p>
+    <div id="plot" style="width: 100%; height: 600px"></div>
+		<script type="text/javascript">
+      var params = new URLSearchParams(location.search)
+      params.forEach(function(value, name) {
+        var input = document.querySelector("form [name=" + name + "]")
+        if (input) {
+          input.value = value
+        }
+      })
+
+      // plot draws each group's benchmarks as markers and its fit as a line
+      // of the same color, which are hidden together from the legend.
+      function plot(spec) {
+        var colors = ["#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf"]
+        var points = spec.layer[0].data.values,
+            lines = spec.layer[1].data.values,
+            traces = {},
+            order = []
+        function trace(group) {
+          if (!traces[group]) {
+            var color = colors[order.length % colors.length]
+            order.push(group)
+            traces[group] = [
+              {x: [], y: [], name: group, legendgroup: group, mode: "markers", type: "scatter", marker: {color: color}},
+              {x: [], y: [], name: group + " fit", legendgroup: group, mode: "lines", type: "scatter", line: {color: color}, showlegend: false}
+            ]
+          }
+          return traces[group]
+        }
+        points.forEach(function(d) {
+          var t = trace(d.group)[0]
+          t.x.push(d.N)
+          t.y.push(d.y)
+        })
+        lines.forEach(function(d) {
+          var t = trace(d.group)[1]
+          t.x.push(d.N)
+          t.y.push(d.y)
+        })
+        var data = []
+        order.forEach(function(group) { data = data.concat(traces[group]) })
+        Plotly.newPlot("plot", data, {
+          xaxis: {title: {text: "N"}, type: spec.layer[0].encoding.x.scale.type},
+          yaxis: {title: {text: spec.layer[0].encoding.y.title}},
+          hovermode: "closest"
+        }, {responsive: true})
+      }
+
+      fetch("vega" + location.search).then(function(resp) {
+        if (!resp.ok) {
+          return resp.text().then(function(text) { throw new Error(text) })
+        }
+        return resp.json()
+      }).then(plot).catch(function(err) {
+        document.querySelector(".error").textContent = err.message
+      })
+		</script>
+	</body>
+</html>
+`
@@ -0,0 +1,28 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFrontendPage(t *testing.T) {
+	for name, want := range map[string]string{
+		"":             d3Script,
+		frontendD3:     d3Script,
+		frontendPlotly: plotlyURL,
+	} {
+		page, err := frontendPage(name)
+		if err != nil {
+			t.Errorf("frontendPage(%q) returned %v", name, err)
+		} else if !strings.Contains(page, want) {
+			t.Errorf("frontendPage(%q) does not load %s", name, want)
+		}
+	}
+	if _, err := frontendPage("vue"); err == nil {
+		t.Error("frontendPage accepted an unknown frontend")
+	}
+}
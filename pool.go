@@ -0,0 +1,117 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// fitQueueFactor is how many fits can wait for each one that is running,
+// before more are refused.
+const fitQueueFactor = 4
+
+// errOverloaded is returned when there are too many fits waiting to run.
+var errOverloaded = errors.New("too many fits are waiting, try again later")
+
+// fitPool bounds the number of fits which run at once.  The plotter sends a
+// fit for every group and model in parallel, so on a small machine they are
+// queued rather than all competing for the CPU, and once the queue is full
+// they are refused, so that the server stays responsive.
+type fitPool struct {
+	slots chan struct{}
+
+	mu       sync.Mutex
+	waiting  int
+	maxQueue int
+}
+
+// newFitPool returns a pool which runs at most n fits at once.  If n is 0,
+// fits aren't limited.
+func newFitPool(n int) *fitPool {
+	if n <= 0 {
+		return &fitPool{}
+	}
+	return &fitPool{slots: make(chan struct{}, n), maxQueue: fitQueueFactor * n}
+}
+
+// acquire waits for a fit to be able to run, and returns errOverloaded if
+// too many are already waiting, or the error of ctx if it is done first.
+// If it returns nil, release has to be called when the fit is done.
+func (p *fitPool) acquire(ctx context.Context) error {
+	if p.slots == nil {
+		return nil
+	}
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	p.mu.Lock()
+	if p.waiting >= p.maxQueue {
+		p.mu.Unlock()
+		return errOverloaded
+	}
+	p.waiting++
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.waiting--
+		p.mu.Unlock()
+	}()
+
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release lets the next fit run.
+func (p *fitPool) release() {
+	if p.slots != nil {
+		<-p.slots
+	}
+}
+
+var (
+	fitsOnce sync.Once
+	fits     *fitPool
+)
+
+// fitLimit returns the pool shared by every fit in the server, which is
+// sized by -max-fits.
+func fitLimit() *fitPool {
+	fitsOnce.Do(func() { fits = newFitPool(*maxFits) })
+	return fits
+}
+
+// fitPoolHandler runs h in the fit pool, and responds with a 429 if it is
+// overloaded.
+func fitPoolHandler(p *fitPool, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := p.acquire(r.Context()); err != nil {
+			if err == errOverloaded {
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, err.Error(), http.StatusTooManyRequests)
+				return
+			}
+			fitError(w, err)
+			return
+		}
+		defer p.release()
+		h.ServeHTTP(w, r)
+	})
+}
+
+// fitHandler serves a request which fits, in the fit pool and with at most
+// -fit-timeout to finish in, including the time it waits in the queue.
+func fitHandler(h http.Handler) http.Handler {
+	return fitTimeoutHandler(fitPoolHandler(fitLimit(), h))
+}
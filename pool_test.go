@@ -0,0 +1,62 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFitPool(t *testing.T) {
+	p := newFitPool(1)
+	if err := p.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the next fits wait for the first, until the queue is full
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	for i := 0; i < fitQueueFactor; i++ {
+		go func() { errs <- p.acquire(ctx) }()
+	}
+	for {
+		p.mu.Lock()
+		waiting := p.waiting
+		p.mu.Unlock()
+		if waiting == fitQueueFactor {
+			break
+		}
+	}
+	h := fitPoolHandler(p, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("an overloaded pool ran a fit")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/fit", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("got status %d from an overloaded pool, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	// releasing lets one of them run, and the rest give up
+	p.release()
+	if err := <-errs; err != nil {
+		t.Errorf("got error %v from the first fit in the queue", err)
+	}
+	cancel()
+	for i := 1; i < fitQueueFactor; i++ {
+		if err := <-errs; err != context.Canceled {
+			t.Errorf("got error %v from a cancelled fit, want %v", err, context.Canceled)
+		}
+	}
+
+	// an unlimited pool never waits
+	p = newFitPool(0)
+	for i := 0; i < 10; i++ {
+		if err := p.acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// pprofPrefix is where the profiles of the server are served.
+const pprofPrefix = "/debug/pprof/"
+
+// pprofMux returns the handlers of net/http/pprof.
+func pprofMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pprofPrefix, pprof.Index)
+	mux.HandleFunc(pprofPrefix+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(pprofPrefix+"profile", pprof.Profile)
+	mux.HandleFunc(pprofPrefix+"symbol", pprof.Symbol)
+	mux.HandleFunc(pprofPrefix+"trace", pprof.Trace)
+	return mux
+}
+
+// withPprof serves the profiles of the server along with h if enabled is set.
+// Importing net/http/pprof adds them to http.DefaultServeMux, so otherwise
+// they are hidden.
+func withPprof(h http.Handler, enabled bool) http.Handler {
+	profiles := pprofMux()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, pprofPrefix) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if !enabled {
+			http.NotFound(w, r)
+			return
+		}
+		profiles.ServeHTTP(w, r)
+	})
+}
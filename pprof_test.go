@@ -0,0 +1,37 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithPprof(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	for _, test := range []struct {
+		enabled bool
+		path    string
+		want    int
+	}{
+		{false, "/data", http.StatusOK},
+		{false, "/debug/pprof/", http.StatusNotFound},
+		{false, "/debug/pprof/cmdline", http.StatusNotFound},
+		{true, "/debug/pprof/cmdline", http.StatusOK},
+		{true, "/data", http.StatusOK},
+	} {
+		req, err := http.NewRequest("GET", test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		withPprof(mux, test.enabled).ServeHTTP(w, req)
+		if w.Code != test.want {
+			t.Errorf("got status %d for %s with pprof %v, want %d", w.Code, test.path, test.enabled, test.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// modelPreset is a named model of a common complexity class, which can be
+// used in place of its terms.
+type modelPreset struct {
+	Name       string
+	Label      string // the complexity class, like O(N log N)
+	XTransform string
+}
+
+// modelPresets are the named models, in order of increasing growth.
+var modelPresets = []modelPreset{
+	{"constant", "O(1)", "1.0"},
+	{"sqrt", "O(√N)", "math.Sqrt(N), 1.0"},
+	{"linear", "O(N)", "N, 1.0"},
+	{"nlogn", "O(N log N)", defaultXTransform},
+	{"quadratic", "O(N²)", "N * N, N, 1.0"},
+	{"cubic", "O(N³)", "N * N * N, N * N, N, 1.0"},
+	{"exp", "O(2^N)", "math.Pow(2, N), 1.0"},
+}
+
+// expandPreset returns the terms of the named preset, or the model itself if
+// it isn't the name of one, so that presets can be used wherever a model can.
+func expandPreset(model string) string {
+	for _, p := range modelPresets {
+		if p.Name == model {
+			return p.XTransform
+		}
+	}
+	return model
+}
+
+// modelValue returns the terms of the model in the querystring, which is
+// either given by xtransform, or named by model, or else is def.
+func modelValue(v url.Values, def string) (string, error) {
+	if x := v.Get("xtransform"); x != "" {
+		return expandPreset(x), nil
+	}
+	name := v.Get("model")
+	if name == "" {
+		return def, nil
+	}
+	if x := expandPreset(name); x != name {
+		return x, nil
+	}
+	return "", fmt.Errorf("invalid model: %s", name)
+}
@@ -0,0 +1,46 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/jonlawlor/parsefloat"
+)
+
+func TestModelPresets(t *testing.T) {
+	for _, p := range modelPresets {
+		if _, err := parsefloat.NewSlice("float64{"+p.XTransform+"}", modelVars); err != nil {
+			t.Errorf("preset %s: %v", p.Name, err)
+		}
+	}
+}
+
+func TestModelValue(t *testing.T) {
+	for _, test := range []struct {
+		query string
+		want  string
+		err   bool
+	}{
+		{"", defaultXTransform, false},
+		{"model=quadratic", "N * N, N, 1.0", false},
+		{"model=linear&xtransform=N", "N", false},
+		{"xtransform=cubic", "N * N * N, N * N, N, 1.0", false},
+		{"model=factorial", "", true},
+	} {
+		v, err := url.ParseQuery(test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := modelValue(v, defaultXTransform)
+		if (err != nil) != test.err {
+			t.Errorf("%s: got error %v", test.query, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.query, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// profileKinds are the profiles which can be linked to a benchmark, named
+// after the go test flags which write them, like -cpuprofile.
+var profileKinds = []string{"cpu", "mem", "block", "mutex"}
+
+// profilePath returns the path of the kind of profile of the named benchmark
+// in the file at benchPath, or "" if there isn't one.  Profiles are found
+// by their name in the -profiles directory, or next to the file if it isn't
+// set: the benchmark's name with slashes replaced by underscores, with or
+// without its GOMAXPROCS suffix, and the kind, like
+// BenchmarkSort_n=100.cpu.prof.
+func profilePath(benchPath, name, kind string) string {
+	dir := *profileDir
+	if dir == "" {
+		dir = filepath.Dir(benchPath)
+	}
+	for _, n := range []string{name, procRE.FindStringSubmatch(name)[1]} {
+		fn := filepath.Join(dir, strings.Replace(n, "/", "_", -1)+"."+kind+".prof")
+		if fi, err := os.Stat(fn); err == nil && fi.Mode().IsRegular() {
+			return fn
+		}
+	}
+	return ""
+}
+
+// findProfiles lists the kinds of profiles which are available for each of
+// the benchmarks in the file.
+func (bf *benchFile) findProfiles() {
+	for _, b := range bf.Benchmarks {
+		for _, kind := range profileKinds {
+			if profilePath(bf.Path, b.Name, kind) != "" {
+				b.Profiles = append(b.Profiles, kind)
+			}
+		}
+	}
+}
+
+// pprofStartTimeout is how long to wait for go tool pprof to serve a
+// profile, and maxPprofUIs is how many are kept running at once.
+const (
+	pprofStartTimeout = 10 * time.Second
+	maxPprofUIs       = 4
+)
+
+// pprofServingRE matches the line that go tool pprof -http writes to its
+// stderr once it has picked a port, with the URL that it is serving.
+var pprofServingRE = regexp.MustCompile(`Serving web UI on (http://\S+)`)
+
+// pprofCommand is the command which serves the profile at path on a free
+// port of localhost.  Go tool runs pprof as its child, so pprof is run
+// directly instead, to be able to kill it.
+var pprofCommand = func(path string) (*exec.Cmd, error) {
+	bin, err := exec.Command("go", "tool", "-n", "pprof").Output()
+	if err != nil {
+		return nil, fmt.Errorf("go tool -n pprof: %v", err)
+	}
+	return exec.Command(strings.TrimSpace(string(bin)), "-no_browser", "-http=localhost:", path), nil
+}
+
+// pprofUI is a running go tool pprof -http.  Ready is closed once it is
+// serving at url, or it failed with err, and exited is closed once it has
+// been waited on.
+type pprofUI struct {
+	cmd    *exec.Cmd
+	url    string
+	err    error
+	used   time.Time
+	ready  chan struct{}
+	exited chan struct{}
+}
+
+// start starts the command, and then reads the URL that it serves from
+// its stderr and waits for it to exit in the background.
+func (ui *pprofUI) start(path string) error {
+	stderr, err := ui.cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := ui.cmd.Start(); err != nil {
+		return err
+	}
+	go func() {
+		defer close(ui.exited)
+		sc := bufio.NewScanner(stderr)
+		found := false
+		for sc.Scan() {
+			if m := pprofServingRE.FindStringSubmatch(sc.Text()); m != nil && !found {
+				found = true
+				ui.url = m[1] + "/ui/flamegraph"
+				close(ui.ready)
+			}
+		}
+		err := ui.cmd.Wait()
+		if !found {
+			if err == nil {
+				err = errors.New("exited")
+			}
+			ui.err = fmt.Errorf("go tool pprof %s: %v", path, err)
+			close(ui.ready)
+		}
+	}()
+	return nil
+}
+
+// pprofUIs are the web interfaces of go tool pprof which have been started,
+// by the path of the profile that they serve, so that each is only started
+// once.  At most maxPprofUIs are kept, and the least recently used one is
+// killed to start another.
+type pprofUIs struct {
+	mu  sync.Mutex
+	uis map[string]*pprofUI
+}
+
+// pprofProcesses are the web interfaces shared by all of the plotters, which
+// are stopped when the server is.
+var pprofProcesses pprofUIs
+
+// url returns the URL of the flame graph of the profile at path, starting
+// go tool pprof -http on a free port of localhost if it isn't already.
+func (p *pprofUIs) url(path string) (string, error) {
+	ui, err := p.get(path)
+	if err != nil {
+		return "", err
+	}
+	select {
+	case <-ui.ready:
+	case <-time.After(pprofStartTimeout):
+		ui.cmd.Process.Kill()
+		return "", fmt.Errorf("go tool pprof %s didn't start", path)
+	}
+	if ui.err != nil {
+		return "", ui.err
+	}
+	return ui.url, nil
+}
+
+// get returns the running interface of the profile at path, which might not
+// be ready yet, starting it if needed.
+func (p *pprofUIs) get(path string) (*pprofUI, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.uis == nil {
+		p.uis = make(map[string]*pprofUI)
+	}
+	if ui, ok := p.uis[path]; ok {
+		select {
+		case <-ui.exited:
+			delete(p.uis, path)
+		default:
+			ui.used = time.Now()
+			return ui, nil
+		}
+	}
+	for len(p.uis) >= maxPprofUIs {
+		var lru string
+		for path, ui := range p.uis {
+			if lru == "" || ui.used.Before(p.uis[lru].used) {
+				lru = path
+			}
+		}
+		p.uis[lru].cmd.Process.Kill()
+		delete(p.uis, lru)
+	}
+
+	cmd, err := pprofCommand(path)
+	if err != nil {
+		return nil, err
+	}
+	ui := &pprofUI{
+		cmd:    cmd,
+		used:   time.Now(),
+		ready:  make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	if err := ui.start(path); err != nil {
+		return nil, err
+	}
+	p.uis[path] = ui
+	return ui, nil
+}
+
+// stop kills all of the interfaces, and waits for them to exit.
+func (p *pprofUIs) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for path, ui := range p.uis {
+		ui.cmd.Process.Kill()
+		<-ui.exited
+		delete(p.uis, path)
+	}
+}
+
+// stopPprofOnSignal stops the go tool pprof processes when the server is
+// interrupted or terminated, and then lets the signal end the server.
+func stopPprofOnSignal() {
+	var sigs []os.Signal
+	for _, sig := range terminate {
+		if !signal.Ignored(sig) {
+			sigs = append(sigs, sig)
+		}
+	}
+	if len(sigs) == 0 {
+		return
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+	go func() {
+		sig := <-c
+		pprofProcesses.stop()
+		signal.Reset(sigs...)
+		if p, err := os.FindProcess(os.Getpid()); err == nil {
+			p.Signal(sig)
+		}
+	}()
+}
+
+// serveProfiles redirects to the flame graph of a benchmark's profile, which
+// go tool pprof serves on localhost, so it's only reachable from the same
+// machine.  With raw set, the profile itself is downloaded instead.  Only
+// the profiles of the benchmarks being plotted are served.
+func serveProfiles(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		file, name, kind := r.Form.Get("file"), r.Form.Get("benchmark"), r.Form.Get("kind")
+		valid := false
+		for _, k := range profileKinds {
+			valid = valid || k == kind
+		}
+		if !valid {
+			http.Error(w, "invalid kind: "+kind, http.StatusBadRequest)
+			return
+		}
+
+		var path string
+		for _, f := range src.dataSet().Files {
+			if f.Path != file {
+				continue
+			}
+			for _, b := range f.Benchmarks {
+				if b.Name == name {
+					path = profilePath(f.Path, b.Name, kind)
+					break
+				}
+			}
+		}
+		if path == "" {
+			http.Error(w, "no "+kind+" profile of "+name, http.StatusNotFound)
+			return
+		}
+
+		if r.Form.Get("raw") != "" {
+			w.Header().Set("Content-Disposition", "attachment; filename="+filepath.Base(path))
+			http.ServeFile(w, r, path)
+			return
+		}
+		u, err := pprofProcesses.url(path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, u, http.StatusFound)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+func TestServeProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for fn, contents := range map[string]string{
+		"BenchmarkSort_n=10-8.cpu.prof": "cpu",
+		"BenchmarkSort_n=10.mem.prof":   "mem",
+		"BenchmarkSort_n=100.cpu.prof":  "other cpu",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, fn), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bf := benchFile{Path: filepath.Join(dir, "bench.txt"), Benchmarks: []*benchmark{
+		{Benchmark: parse.Benchmark{Name: "BenchmarkSort/n=10-8"}},
+		{Benchmark: parse.Benchmark{Name: "BenchmarkSort/n=1000-8"}},
+	}}
+	bf.findProfiles()
+	if got, want := bf.Benchmarks[0].Profiles, []string{"cpu", "mem"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got profiles %v, want %v", got, want)
+	}
+	if got := bf.Benchmarks[1].Profiles; got != nil {
+		t.Errorf("got profiles %v of a benchmark without any", got)
+	}
+
+	h := serveProfiles(fixedSource{Files: []benchFile{bf}})
+	tests := []struct {
+		name, kind string
+		code       int
+		body       string
+	}{
+		{"BenchmarkSort/n=10-8", "cpu", http.StatusOK, "cpu"},
+		{"BenchmarkSort/n=10-8", "mem", http.StatusOK, "mem"},
+		{"BenchmarkSort/n=10-8", "trace", http.StatusBadRequest, ""},
+		{"BenchmarkSort/n=1000-8", "cpu", http.StatusNotFound, ""},
+		// only the profiles of plotted benchmarks are served
+		{"BenchmarkSort/n=100-8", "cpu", http.StatusNotFound, ""},
+	}
+	for _, test := range tests {
+		q := url.Values{"file": {bf.Path}, "benchmark": {test.name}, "kind": {test.kind}, "raw": {"1"}}
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest("GET", "/profile?"+q.Encode(), nil))
+		if w.Code != test.code {
+			t.Errorf("%s %s: got status %d, want %d", test.name, test.kind, w.Code, test.code)
+			continue
+		}
+		if test.body != "" && w.Body.String() != test.body {
+			t.Errorf("%s %s: got %q, want %q", test.name, test.kind, w.Body.String(), test.body)
+		}
+	}
+}
+
+func TestPprofUIs(t *testing.T) {
+	defer func(c func(string) (*exec.Cmd, error)) { pprofCommand = c }(pprofCommand)
+	pprofCommand = func(path string) (*exec.Cmd, error) {
+		if path == "bad.prof" {
+			return exec.Command("sh", "-c", "echo failed to fetch >&2; exit 1"), nil
+		}
+		return exec.Command("sh", "-c", "echo Serving web UI on http://localhost:1/"+path+" >&2; exec sleep 60"), nil
+	}
+	var p pprofUIs
+	defer p.stop()
+
+	if _, err := p.url("bad.prof"); err == nil {
+		t.Error("got the URL of a profile which pprof failed to serve")
+	}
+	u, err := p.url("a.prof")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "http://localhost:1/a.prof/ui/flamegraph"; u != want {
+		t.Errorf("got url %q, want %q", u, want)
+	}
+	a := p.uis["a.prof"]
+	if _, err := p.url("a.prof"); err != nil || p.uis["a.prof"] != a {
+		t.Errorf("started pprof again for the same profile: %v", err)
+	}
+
+	// a is the least recently used, so it is stopped to start another
+	for i := 0; i < maxPprofUIs; i++ {
+		if _, err := p.url(fmt.Sprintf("%d.prof", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(p.uis) != maxPprofUIs {
+		t.Errorf("got %d running, want %d", len(p.uis), maxPprofUIs)
+	}
+	select {
+	case <-a.exited:
+	case <-time.After(10 * time.Second):
+		t.Error("the least recently used pprof wasn't stopped")
+	}
+
+	var uis []*pprofUI
+	for _, ui := range p.uis {
+		uis = append(uis, ui)
+	}
+	p.stop()
+	for _, ui := range uis {
+		select {
+		case <-ui.exited:
+		default:
+			t.Errorf("%v is still running after stop", ui.cmd.Args)
+		}
+	}
+}
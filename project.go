@@ -0,0 +1,104 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// projectRE matches valid project names, which are also directory names.
+var projectRE = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]*$`)
+
+// projectServer serves each subdirectory of dir as a separate plotter, at
+// /p/{project}/, so that the benchmarks of different teams don't mix.  The
+// project's benchmarks are the *.txt files in its directory, its history
+// database is the history subdirectory, and its views are kept in
+// views.json.  Projects are opened when they are
+// first requested, so new ones can be added without a restart.
+type projectServer struct {
+	dir string
+
+	mu       sync.Mutex
+	projects map[string]http.Handler
+}
+
+func newProjectServer(dir string) *projectServer {
+	return &projectServer{dir: dir, projects: make(map[string]http.Handler)}
+}
+
+func (ps *projectServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/p/")
+	name := rest
+	if i := strings.Index(rest, "/"); i >= 0 {
+		name = rest[:i]
+	} else {
+		// the plotter's paths are relative to the project
+		http.Redirect(w, r, "/p/"+name+"/", http.StatusMovedPermanently)
+		return
+	}
+	h, err := ps.project(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	h.ServeHTTP(w, r)
+}
+
+// project returns the handler of the named project, opening it if it hasn't
+// been requested before.
+func (ps *projectServer) project(name string) (http.Handler, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if h, ok := ps.projects[name]; ok {
+		return h, nil
+	}
+	if !projectRE.MatchString(name) {
+		return nil, errNoProject(name)
+	}
+	dir := filepath.Join(ps.dir, name)
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return nil, errNoProject(name)
+	}
+	db, err := openHistory(filepath.Join(dir, "history"))
+	if err != nil {
+		return nil, err
+	}
+	views, err := openViews(filepath.Join(dir, "views.json"))
+	if err != nil {
+		return nil, err
+	}
+	src := historySource{globSource{filepath.Join(dir, "*.txt")}, db, nil}
+	h := http.StripPrefix("/p/"+name, plotterMux(src, db, newEventHub(), views))
+	ps.projects[name] = h
+	return h, nil
+}
+
+type errNoProject string
+
+func (e errNoProject) Error() string { return "no project " + string(e) }
+
+// listHandleFunc returns the names of the projects.
+func (ps *projectServer) listHandleFunc(w http.ResponseWriter, r *http.Request) {
+	fis, err := ioutil.ReadDir(ps.dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	names := []string{}
+	for _, fi := range fis {
+		if fi.IsDir() && projectRE.MatchString(fi.Name()) {
+			names = append(names, fi.Name())
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(names)
+}
@@ -0,0 +1,81 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjects(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, p := range []string{"a", "b"} {
+		if err := os.Mkdir(filepath.Join(dir, p), 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	in := []byte("BenchmarkSort10-4\t1000\t100 ns/op\n")
+	if err := ioutil.WriteFile(filepath.Join(dir, "a", "bench.txt"), in, 0666); err != nil {
+		t.Fatal(err)
+	}
+	ps := newProjectServer(dir)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		ps.ServeHTTP(w, req)
+		return w
+	}
+	get := func(path string) *httptest.ResponseRecorder { return do("GET", path, "") }
+
+	// each project only has its own benchmarks
+	for p, want := range map[string]int{"a": 1, "b": 0} {
+		w := get("/p/" + p + "/data")
+		var ds dataSet
+		if err := json.NewDecoder(w.Body).Decode(&ds); err != nil {
+			t.Fatal(err)
+		}
+		if len(ds.Files) != want {
+			t.Errorf("got %d files in project %s, want %d", len(ds.Files), p, want)
+		}
+	}
+	if w := get("/p/a"); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/p/a/" {
+		t.Errorf("got status %d to %q, want a redirect to /p/a/", w.Code, w.Header().Get("Location"))
+	}
+	for _, path := range []string{"/p/c/data", "/p/../data"} {
+		if w := get(path); w.Code != http.StatusNotFound {
+			t.Errorf("got status %d for %s, want %d", w.Code, path, http.StatusNotFound)
+		}
+	}
+
+	// each project only has its own views, which are kept in its directory
+	if w := do("POST", "/p/a/views?name=allocs", `{"YVar":"AllocsPerOp"}`); w.Code != http.StatusOK {
+		t.Fatalf("got status %d saving a view in project a: %s", w.Code, w.Body)
+	}
+	if w := get("/p/a/views?name=allocs"); w.Code != http.StatusOK {
+		t.Errorf("got status %d for the view in project a, want %d", w.Code, http.StatusOK)
+	}
+	for _, path := range []string{"/p/b/views?name=allocs", "/p/b/?view=allocs", "/p/b/dashboard?view=allocs"} {
+		if w := get(path); w.Code != http.StatusNotFound {
+			t.Errorf("got status %d for %s, want %d", w.Code, path, http.StatusNotFound)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a", "views.json")); err != nil {
+		t.Errorf("the view of project a wasn't saved in its directory: %v", err)
+	}
+}
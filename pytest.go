@@ -0,0 +1,112 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+// pytestFile is pytest-benchmark's JSON output, from --benchmark-json or
+// --benchmark-autosave.
+type pytestFile struct {
+	MachineInfo *struct {
+		PythonImplementation string `json:"python_implementation"`
+		PythonVersion        string `json:"python_version"`
+		Machine              string `json:"machine"`
+		CPU                  struct {
+			BrandRaw string `json:"brand_raw"`
+			Brand    string `json:"brand"`
+		} `json:"cpu"`
+	} `json:"machine_info"`
+	Version    string            `json:"version"`
+	Benchmarks []pytestBenchmark `json:"benchmarks"`
+}
+
+type pytestBenchmark struct {
+	Name     string                     `json:"name"`
+	Fullname string                     `json:"fullname"`
+	Params   map[string]json.RawMessage `json:"params"`
+	Stats    *struct {
+		Mean float64   `json:"mean"`
+		Data []float64 `json:"data"` // time of an iteration in each round, in seconds
+	} `json:"stats"`
+}
+
+// parsePytestBenchmark parses pytest-benchmark's JSON output.  Each round
+// is a run of its benchmark if they were saved with --benchmark-save-data,
+// otherwise the mean is.  A test like test_sort[1024] from
+// tests/test_sort.py, parametrized by n, is named test_sort/n=1024-1 in the
+// package tests/test_sort.py, so Python baselines group like Go benchmarks
+// named BenchmarkSort/n=1024.
+func parsePytestBenchmark(data []byte) (benchFile, bool, error) {
+	var f pytestFile
+	if err := json.Unmarshal(data, &f); err != nil || f.MachineInfo == nil || f.Benchmarks == nil {
+		return benchFile{}, false, nil
+	}
+	bf := benchFile{Config: make(map[string]string)}
+	mi := f.MachineInfo
+	cpu := mi.CPU.BrandRaw
+	if cpu == "" {
+		cpu = mi.CPU.Brand
+	}
+	for key, v := range map[string]string{
+		"cpu":              cpu,
+		"arch":             mi.Machine,
+		"python":           strings.TrimSpace(mi.PythonImplementation + " " + mi.PythonVersion),
+		"pytest-benchmark": f.Version,
+	} {
+		if v != "" {
+			bf.Config[key] = v
+		}
+	}
+
+	for i, pb := range f.Benchmarks {
+		if pb.Stats == nil {
+			bf.Warnings = append(bf.Warnings, parseWarning{i + 1, pb.Name, "no stats"})
+			continue
+		}
+		name := pb.Name
+		if j := strings.Index(name, "["); j >= 0 && len(pb.Params) > 0 {
+			name = name[:j]
+		}
+		var params []string
+		for k := range pb.Params {
+			params = append(params, k)
+		}
+		sort.Strings(params)
+		for _, k := range params {
+			v := string(pb.Params[k])
+			var s string
+			if json.Unmarshal(pb.Params[k], &s) == nil {
+				v = s
+			}
+			name += "/" + k + "=" + v
+		}
+		name += "-1"
+		pkg := ""
+		if j := strings.Index(pb.Fullname, "::"); j >= 0 {
+			pkg = pb.Fullname[:j]
+		}
+
+		times := pb.Stats.Data
+		if len(times) == 0 {
+			times = []float64{pb.Stats.Mean}
+		}
+		for _, t := range times {
+			bf.Benchmarks = append(bf.Benchmarks, &benchmark{Benchmark: parse.Benchmark{
+				Name:     name,
+				N:        1,
+				NsPerOp:  t * 1e9,
+				Measured: parse.NsPerOp,
+				Ord:      len(bf.Benchmarks),
+			}, Package: pkg})
+		}
+	}
+	return bf, true, nil
+}
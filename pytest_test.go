@@ -0,0 +1,54 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const pytestJSON = `{
+  "machine_info": {
+    "machine": "x86_64",
+    "python_implementation": "CPython",
+    "python_version": "3.11.4",
+    "cpu": {"brand_raw": "Intel(R) Core(TM) i7-8650U CPU @ 1.90GHz"}
+  },
+  "benchmarks": [
+    {
+      "name": "test_sort[1024-asc]",
+      "fullname": "tests/test_sort.py::test_sort[1024-asc]",
+      "params": {"n": 1024, "order": "asc"},
+      "stats": {"mean": 0.0002, "data": [0.0001, 0.0003]}
+    },
+    {
+      "name": "test_parse",
+      "fullname": "tests/test_parse.py::test_parse",
+      "params": null,
+      "stats": {"mean": 0.5}
+    }
+  ],
+  "version": "4.0.0"
+}`
+
+func TestParsePytestBenchmark(t *testing.T) {
+	bf, err := parseBenchFile(strings.NewReader(pytestJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bf.Benchmarks) != 3 {
+		t.Fatalf("got %d benchmarks, want a run for each round and one for the mean", len(bf.Benchmarks))
+	}
+	b := bf.Benchmarks[1]
+	if b.Name != "test_sort/n=1024/order=asc-1" || b.Package != "tests/test_sort.py" || b.NsPerOp != 3e5 {
+		t.Errorf("got %s in %s at %g ns/op", b.Name, b.Package, b.NsPerOp)
+	}
+	if b := bf.Benchmarks[2]; b.Name != "test_parse-1" || b.NsPerOp != 5e8 {
+		t.Errorf("got %s at %g ns/op, want test_parse-1 at its mean", b.Name, b.NsPerOp)
+	}
+	if bf.Config["python"] != "CPython 3.11.4" || bf.Config["cpu"] == "" {
+		t.Errorf("got config %v", bf.Config)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// defaultRatioSteps is the number of points on a ratio curve, and
+// maxRatioSteps is the most that can be asked for.
+const (
+	defaultRatioSteps = 100
+	maxRatioSteps     = 10000
+)
+
+// ratioPoint is the ratio of B's fit to A's at N.  Width is the half width
+// of its approximate 95% confidence interval.
+type ratioPoint struct {
+	N     float64
+	Ratio float64
+	Width float64
+}
+
+// groupRatio is the ratio of the fitted curves of groups B and A, over the
+// range of N that both of them were fit on, which generalizes comparing an
+// old and a new run to any two groups.
+type groupRatio struct {
+	A, B   string
+	Points []ratioPoint
+}
+
+// ratioCurve evaluates the ratio of b's fit to a's at steps points.  Its
+// confidence interval is from the delta method, like that of the ratio of
+// coefficients, since the groups are fit independently.  N where a's fit is
+// 0 are left out.
+func ratioCurve(a, b groupFit, steps int) (groupRatio, error) {
+	gr := groupRatio{A: a.Group, B: b.Group, Points: []ratioPoint{}}
+	lo, hi := math.Max(a.xMin, b.xMin), math.Min(a.xMax, b.xMax)
+	if lo > hi {
+		return gr, fmt.Errorf("%s and %s weren't fit over any of the same N", a.Group, b.Group)
+	}
+	for i := 0; i < steps; i++ {
+		x := lo
+		if steps > 1 {
+			x += (hi - lo) * float64(i) / float64(steps-1)
+		}
+		ya, va := a.at(x)
+		yb, vb := b.at(x)
+		tr := ratio(ya, conf95(math.Sqrt(a.mse*va), a.cdof), yb, conf95(math.Sqrt(b.mse*vb), b.cdof))
+		if ya == 0 || math.IsNaN(tr.Ratio) || math.IsInf(tr.Ratio, 0) || math.IsNaN(tr.RatioInt) {
+			continue
+		}
+		gr.Points = append(gr.Points, ratioPoint{x, tr.Ratio, finite(tr.RatioInt)})
+	}
+	return gr, nil
+}
+
+// serveRatios fits the groups with the settings in the querystring, and
+// returns the ratio of the fit of group b to that of group a.
+func serveRatios(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q, err := parseAnalysisQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		a, b := r.Form.Get("a"), r.Form.Get("b")
+		if a == "" || b == "" {
+			http.Error(w, "both groups a and b are required", http.StatusBadRequest)
+			return
+		}
+		steps := defaultRatioSteps
+		if v := r.Form.Get("steps"); v != "" {
+			if steps, err = strconv.Atoi(v); err != nil || steps < 1 || steps > maxRatioSteps {
+				http.Error(w, "invalid steps: "+v, http.StatusBadRequest)
+				return
+			}
+		}
+
+		fits, err := q.fitGroupsContext(r.Context(), src.dataSet())
+		if err != nil {
+			fitError(w, err)
+			return
+		}
+		var fa, fb *groupFit
+		for i := range fits {
+			switch fits[i].Group {
+			case a:
+				fa = &fits[i]
+			case b:
+				fb = &fits[i]
+			}
+		}
+		for _, g := range []struct {
+			name string
+			fit  *groupFit
+		}{{a, fa}, {b, fb}} {
+			if g.fit == nil {
+				http.Error(w, "no fit of group "+g.name, http.StatusNotFound)
+				return
+			}
+		}
+		gr, err := ratioCurve(*fa, *fb, steps)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(gr)
+	}
+}
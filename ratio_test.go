@@ -0,0 +1,78 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestServeRatios(t *testing.T) {
+	// BenchmarkNew is three times as slow as BenchmarkOld, and BenchmarkBig
+	// was only run at larger N.
+	var in []string
+	for _, g := range []struct {
+		name   string
+		slope  float64
+		lo, hi int
+	}{{"Old", 2, 10, 1000}, {"New", 6, 10, 1000}, {"Big", 1, 10000, 100000}} {
+		for n := g.lo; n <= g.hi; n *= 10 {
+			for i := 0; i < 3; i++ {
+				ns := g.slope*float64(n) + float64(i)
+				in = append(in, fmt.Sprintf("Benchmark%s%d-4\t1000\t%g ns/op", g.name, n, ns))
+			}
+		}
+	}
+	bf, err := parseBenchFile(strings.NewReader(strings.Join(in, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := serveRatios(fixedSource{Files: []benchFile{bf}})
+
+	get := func(v url.Values) *httptest.ResponseRecorder {
+		v.Set("xtransform", "N, 1.0")
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest("GET", "/ratio?"+v.Encode(), nil))
+		return w
+	}
+	w := get(url.Values{"a": {"BenchmarkOld"}, "b": {"BenchmarkNew"}, "steps": {"5"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body.String())
+	}
+	var gr groupRatio
+	if err := json.NewDecoder(w.Body).Decode(&gr); err != nil {
+		t.Fatal(err)
+	}
+	if len(gr.Points) != 5 || gr.Points[0].N != 10 || gr.Points[4].N != 1000 {
+		t.Fatalf("got points %+v, want 5 from N = 10 to 1000", gr.Points)
+	}
+	for _, p := range gr.Points {
+		// the runs add 1 ns/op on average
+		want := (6*p.N + 1) / (2*p.N + 1)
+		if math.Abs(p.Ratio-want) > 1e-6 || p.Width <= 0 {
+			t.Errorf("got ratio %g ± %g at N = %g, want %g", p.Ratio, p.Width, p.N, want)
+		}
+	}
+
+	for _, test := range []struct {
+		v    url.Values
+		code int
+	}{
+		{url.Values{"a": {"BenchmarkOld"}}, http.StatusBadRequest},
+		{url.Values{"a": {"BenchmarkOld"}, "b": {"BenchmarkMissing"}}, http.StatusNotFound},
+		{url.Values{"a": {"BenchmarkOld"}, "b": {"BenchmarkBig"}}, http.StatusBadRequest},
+		{url.Values{"a": {"BenchmarkOld"}, "b": {"BenchmarkNew"}, "steps": {"0"}}, http.StatusBadRequest},
+	} {
+		if w := get(test.v); w.Code != test.code {
+			t.Errorf("%v: got status %d, want %d", test.v, w.Code, test.code)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"path"
+)
+
+// readOnlySafe are the endpoints which take a POST or PUT without changing
+// anything on the server, by the last element of their path, so that they
+// work in projects as well.
+var readOnlySafe = map[string]bool{
+	"fit":   true, // fits the data in the request
+	"parse": true, // parses the benchmarks in the request
+}
+
+// readOnlyRefused are the endpoints which are refused even when they're
+// read, by the last element of their path, because they start processes on
+// the server: /profile runs go tool pprof.
+var readOnlyRefused = map[string]bool{
+	"profile": true,
+}
+
+// readOnlyHandler refuses every request which could change the server's
+// state, like posting runs to the history, rerunning the benchmarks, or
+// reloading them, so that a dashboard of published results can be exposed
+// publicly.  Only reads and the endpoints in readOnlySafe are served, and
+// the endpoints in readOnlyRefused aren't served at all.
+func readOnlyHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if readOnlyRefused[path.Base(r.URL.Path)] {
+			http.Error(w, r.URL.Path+" is disabled in read-only mode", http.StatusForbidden)
+			return
+		}
+		switch r.Method {
+		case "GET", "HEAD", "OPTIONS":
+		default:
+			if !readOnlySafe[path.Base(r.URL.Path)] {
+				http.Error(w, r.URL.Path+" is disabled in read-only mode", http.StatusForbidden)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,47 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadOnlyHandler(t *testing.T) {
+	h := readOnlyHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, test := range []struct {
+		method, path string
+		want         int
+	}{
+		{"GET", "/data", http.StatusOK},
+		{"GET", "/runs", http.StatusOK},
+		{"POST", "/runs", http.StatusForbidden},
+		{"POST", "/p/sort/runs", http.StatusForbidden},
+		{"POST", "/rerun", http.StatusForbidden},
+		{"POST", "/reload", http.StatusForbidden},
+		{"DELETE", "/runs", http.StatusForbidden},
+		{"POST", "/parse", http.StatusOK},
+		{"PUT", "/p/sort/fit", http.StatusOK},
+		{"GET", "/fit", http.StatusOK},
+		{"GET", "/profile", http.StatusForbidden},
+		{"GET", "/p/sort/profile", http.StatusForbidden},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(test.method, test.path, nil))
+		if w.Code != test.want {
+			t.Errorf("%s %s: got status %d, want %d", test.method, test.path, w.Code, test.want)
+		}
+	}
+}
+
+func TestReadOnlyBadFit(t *testing.T) {
+	// a visitor's invalid fit is refused without stopping the server
+	w := httptest.NewRecorder()
+	readOnlyHandler(http.HandlerFunc(fitHandleFunc)).ServeHTTP(w, httptest.NewRequest("GET", "/fit?xlb=x", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d for an invalid fit, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"math"
+)
+
+// regularized is an estimator whose strength of regularization can be set.
+type regularized interface {
+	estimator
+	withLambda(lambda float64) estimator
+}
+
+// defaultLambda is the strength of regularization when none is given.
+const defaultLambda = 0.01
+
+func init() {
+	registerFitter("ridge", ridgeFitter{defaultLambda})
+	registerFitter("lasso", lassoFitter{defaultLambda})
+}
+
+// ridgeFitter fits by least squares with an L2 penalty on the coefficients,
+// which keeps them stable when the terms are nearly collinear.  The penalty
+// is applied to the standardized terms, so lambda doesn't depend on their
+// scale, and the intercept is not penalized.
+type ridgeFitter struct {
+	lambda float64
+}
+
+func (f ridgeFitter) withLambda(lambda float64) estimator { return ridgeFitter{lambda} }
+
+func (f ridgeFitter) Fit(s samp) (model, fitStats, error) {
+	st := standardize(s)
+	p := st.cols
+
+	// Constant terms are zero once they are standardized, so they are left
+	// out.
+	var keep []int
+	for j := 0; j < p; j++ {
+		if st.scale[j] != 0 {
+			keep = append(keep, j)
+		}
+	}
+	b := make([]float64, p)
+	if len(keep) > 0 {
+		bk, err := ridge(st, keep, f.lambda)
+		if err != nil {
+			return nil, fitStats{}, err
+		}
+		for k, j := range keep {
+			b[j] = bk[k]
+		}
+	}
+	beta := st.unstandardize(b)
+	var fs fitStats
+	fs.r2, fs.mse, fs.cint, fs.iXTX = stats(beta, s)
+	return beta, fs, nil
+}
+
+// ridge returns the ridge estimate of the standardized sample, using only the
+// kept terms.
+func ridge(st standardized, keep []int, lambda float64) ([]float64, error) {
+	n, p := len(st.z.y), st.cols
+
+	// minimizing |y - Zb|²/2n + lambda |b|²/2 is least squares with rows
+	// of sqrt(n lambda) I appended to Z.
+	var aug samp
+	for i := 0; i < n; i++ {
+		for _, j := range keep {
+			aug.x = append(aug.x, st.z.x[i*p+j])
+		}
+	}
+	aug.y = append(aug.y, st.z.y...)
+	w := math.Sqrt(float64(n) * lambda)
+	for k := range keep {
+		row := make([]float64, len(keep))
+		row[k] = w
+		aug.x = append(aug.x, row...)
+		aug.y = append(aug.y, 0)
+	}
+	bk := estimate(aug)
+	if bk == nil {
+		return nil, errors.New("unable to estimate the model")
+	}
+	return bk, nil
+}
+
+// lassoFitter fits by least squares with an L1 penalty on the coefficients,
+// which sets the coefficients of unnecessary terms to zero.  Like ridge, the
+// penalty is applied to the standardized terms, except for the intercept.
+type lassoFitter struct {
+	lambda float64
+}
+
+func (f lassoFitter) withLambda(lambda float64) estimator { return lassoFitter{lambda} }
+
+// lassoIterations is the maximum number of passes of coordinate descent.
+const lassoIterations = 10000
+
+func (f lassoFitter) Fit(s samp) (model, fitStats, error) {
+	st := standardize(s)
+	n, p := len(st.z.y), st.cols
+	z, y := st.z.x, st.z.y
+
+	norm := make([]float64, p)
+	for j := range norm {
+		for i := 0; i < n; i++ {
+			norm[j] += z[i*p+j] * z[i*p+j]
+		}
+	}
+
+	// coordinate descent on |y - Zb|²/2n + lambda |b|, keeping the
+	// residuals up to date.  Constant terms are zero once they are
+	// standardized, so they are skipped.
+	b := make([]float64, p)
+	r := append([]float64{}, y...)
+	for iter := 0; iter < lassoIterations; iter++ {
+		change, size := 0.0, 0.0
+		for j := 0; j < p; j++ {
+			if norm[j] == 0 {
+				continue
+			}
+			rho := norm[j] * b[j]
+			for i := 0; i < n; i++ {
+				rho += z[i*p+j] * r[i]
+			}
+			next := softThreshold(rho, float64(n)*f.lambda) / norm[j]
+			if d := next - b[j]; d != 0 {
+				for i := 0; i < n; i++ {
+					r[i] -= d * z[i*p+j]
+				}
+				change = math.Max(change, math.Abs(d))
+				b[j] = next
+			}
+			size = math.Max(size, math.Abs(b[j]))
+		}
+		if change <= 1e-10*size {
+			break
+		}
+	}
+	beta := st.unstandardize(b)
+	var fs fitStats
+	fs.r2, fs.mse, fs.cint, fs.iXTX = stats(beta, s)
+	return beta, fs, nil
+}
+
+func softThreshold(v, lambda float64) float64 {
+	switch {
+	case v > lambda:
+		return v - lambda
+	case v < -lambda:
+		return v + lambda
+	}
+	return 0
+}
+
+// standardized is a sample whose terms have been centered and scaled to unit
+// variance, so that the coefficients can be penalized equally.
+type standardized struct {
+	z         samp
+	cols      int
+	intercept int // index of the constant term, or -1 if there isn't one
+	constant  float64
+	mean      []float64
+	scale     []float64
+	yMean     float64
+}
+
+// standardize centers and scales every term of the sample, except for the
+// first constant term, which is the intercept.  If there is an intercept, the
+// response is centered as well.  Terms that are constant are scaled to zero.
+func standardize(s samp) standardized {
+	n := len(s.y)
+	p := len(s.x) / n
+	st := standardized{
+		z:         samp{x: make([]float64, len(s.x)), y: make([]float64, n)},
+		cols:      p,
+		intercept: -1,
+		mean:      make([]float64, p),
+		scale:     make([]float64, p),
+	}
+	for j := 0; j < p; j++ {
+		var sum, ss kahan
+		for i := 0; i < n; i++ {
+			sum.add(s.x[i*p+j])
+		}
+		st.mean[j] = sum.value() / float64(n)
+		for i := 0; i < n; i++ {
+			d := s.x[i*p+j] - st.mean[j]
+			ss.add(d * d)
+		}
+		st.scale[j] = math.Sqrt(ss.value() / float64(n))
+		if st.scale[j] == 0 && st.intercept < 0 && st.mean[j] != 0 {
+			st.intercept, st.constant = j, st.mean[j]
+		}
+	}
+	if st.intercept >= 0 {
+		var sum kahan
+		for _, y := range s.y {
+			sum.add(y)
+		}
+		st.yMean = sum.value() / float64(n)
+	}
+	for i := 0; i < n; i++ {
+		st.z.y[i] = s.y[i] - st.yMean
+		for j := 0; j < p; j++ {
+			switch {
+			case st.scale[j] == 0:
+				st.z.x[i*p+j] = 0
+			case st.intercept >= 0:
+				st.z.x[i*p+j] = (s.x[i*p+j] - st.mean[j]) / st.scale[j]
+			default:
+				// without an intercept, the terms can't be centered
+				st.z.x[i*p+j] = s.x[i*p+j] / st.scale[j]
+			}
+		}
+	}
+	return st
+}
+
+// unstandardize converts the coefficients of the standardized terms back to
+// coefficients of the original terms.
+func (st standardized) unstandardize(b []float64) model {
+	beta := make(model, st.cols)
+	offset := st.yMean
+	for j := range beta {
+		if st.scale[j] == 0 {
+			continue
+		}
+		beta[j] = b[j] / st.scale[j]
+		if st.intercept >= 0 {
+			offset -= beta[j] * st.mean[j]
+		}
+	}
+	if st.intercept >= 0 {
+		beta[st.intercept] = offset / st.constant
+	}
+	return beta
+}
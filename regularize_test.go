@@ -0,0 +1,66 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"net/url"
+	"testing"
+)
+
+func TestRegularized(t *testing.T) {
+	// y = 3x + 5, with a log term that isn't needed and a term that is
+	// nearly collinear with x.
+	var s samp
+	for i := 0; i < 30; i++ {
+		x := float64(10 * (i + 1))
+		s.x = append(s.x, x, math.Log(x), x*(1+1e-6*float64(i%2)), 1)
+		s.y = append(s.y, 3*x+5+0.01*float64(i%3-1))
+	}
+	predict := func(beta model, x float64) float64 {
+		return beta[0]*x + beta[1]*math.Log(x) + beta[2]*x + beta[3]
+	}
+
+	for _, name := range []string{"ridge", "lasso"} {
+		f, err := parseFitter(url.Values{"method": {name}, "lambda": {"0.001"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		beta, st, err := f.Fit(s)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if st.r2 < 0.999 {
+			t.Errorf("%s: got r2 %g", name, st.r2)
+		}
+		// the collinear terms share the slope, rather than cancelling
+		if name == "ridge" && math.Abs(beta[0]-beta[2]) > 0.1 {
+			t.Errorf("%s: got unstable coefficients %v", name, beta)
+		}
+		if got := predict(beta, 150); math.Abs(got-455) > 5 {
+			t.Errorf("%s: got prediction %g at 150, want 455", name, got)
+		}
+	}
+
+	// lasso drops the unneeded log term entirely
+	f, _ := parseFitter(url.Values{"fitter": {"lasso"}, "lambda": {"0.01"}})
+	beta, _, err := f.Fit(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if beta[1] != 0 {
+		t.Errorf("lasso: got log coefficient %g, want 0", beta[1])
+	}
+
+	for _, v := range []url.Values{
+		{"fitter": {"ols"}, "lambda": {"1"}},
+		{"fitter": {"ridge"}, "lambda": {"-1"}},
+		{"fitter": {"ridge"}, "lambda": {"x"}},
+	} {
+		if _, err := parseFitter(v); err == nil {
+			t.Errorf("parseFitter accepted %v", v)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+)
+
+// reloader reads the benchmarks again, which finds any new files that match
+// the patterns, and sends them to every connected plotter, so that a long
+// running server picks up new results without a restart or a refresh.
+type reloader struct {
+	src dataSource
+	hub *eventHub
+}
+
+// reload reads and publishes the benchmarks.
+func (rl reloader) reload() dataSet {
+	ds := rl.src.dataSet()
+	rl.hub.publish(event{Type: eventData, Data: &ds})
+	slog.Info("reloaded benchmarks", "files", len(ds.Files))
+	return ds
+}
+
+// reloadOnSignal reloads every time the process gets a SIGHUP.
+func (rl reloader) reloadOnSignal() {
+	if hangup == nil {
+		return
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, hangup)
+	go func() {
+		for range c {
+			rl.reload()
+		}
+	}()
+}
+
+// reloadHandleFunc reloads when it is posted to, and responds with the
+// number of files that were read.
+func (rl reloader) reloadHandleFunc(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "reload requires a POST", http.StatusMethodNotAllowed)
+		return
+	}
+	ds := rl.reload()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct{ Files int }{len(ds.Files)})
+}
@@ -0,0 +1,43 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReload(t *testing.T) {
+	bf, err := parseBenchFile(strings.NewReader("BenchmarkSort10-4\t1000\t100 ns/op\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := newEventHub()
+	c := hub.subscribe()
+	defer hub.unsubscribe(c)
+	rl := reloader{fixedSource{Version: dataVersion, Files: []benchFile{bf}}, hub}
+
+	w := httptest.NewRecorder()
+	rl.reloadHandleFunc(w, httptest.NewRequest("GET", "/reload", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /reload: got status %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	w = httptest.NewRecorder()
+	rl.reloadHandleFunc(w, httptest.NewRequest("POST", "/reload", nil))
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"Files":1}` {
+		t.Errorf("POST /reload: got %d %q", w.Code, w.Body.String())
+	}
+	select {
+	case e := <-c:
+		if e.Type != eventData || len(e.Data.Files) != 1 {
+			t.Errorf("got event %+v, want the data", e)
+		}
+	default:
+		t.Errorf("reloading didn't publish the data")
+	}
+}
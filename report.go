@@ -0,0 +1,293 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jonlawlor/parsefloat"
+)
+
+// report contains everything the plotter needs to display the benchmarks
+// without a server.
+type report struct {
+	Data        dataSet
+	Fits        map[string][]fitResponse // one per XTransform, keyed by group
+	Crossovers  []crossover              // of the first XTransform
+	ChowTests   []chowTest               // of the first XTransform
+	Exponents   []exponent
+	Drifts      []drift
+	Annotations []annotation
+	Names       map[string]string // display names of the groups
+	YVar        string
+	Unit        unitConv // the response is converted to Unit, labeled UnitLabel
+	UnitLabel   string
+	Fitter      string
+	Lambda      string // empty for the fitter's default
+	XTransforms []string
+	NLineSteps  int
+	XLB, XUB    *float64 // nil for the range of the data
+
+	NRE             string
+	Unparameterized string
+	Aggregate       string
+	Cluster         string
+}
+
+func reportUsage(fs *flag.FlagSet) func() {
+	return func() {
+		fmt.Fprintf(os.Stderr, "usage: benchplot report [options] bench1.txt [bench2.txt ...]\n")
+		fmt.Fprintf(os.Stderr, "writes a standalone html report of the fitted benchmarks\n")
+		fmt.Fprintf(os.Stderr, "example:\n")
+		fmt.Fprintf(os.Stderr, "   benchplot report -o report.html bench.txt\n")
+		fmt.Fprintf(os.Stderr, "options:\n")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+}
+
+func reportMain(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	fs.Usage = reportUsage(fs)
+	out := fs.String("o", "report.html", "output file, or - for stdout")
+	d3Path := fs.String("d3", "", "local copy of d3 to inline in the report (default is to download it from "+d3URL+")")
+	wasmPath := fs.String("wasm", "", "WebAssembly build of benchplot to include, so that the report can be refit in the browser, built with GOOS=js GOARCH=wasm go build -o benchplot.wasm (default is to only include the fits for the report's settings)")
+	yVar := fs.String("yvar", "NsPerOp", "response to fit")
+	fitterName := fs.String("fitter", defaultFitter, "estimator of the models: "+strings.Join(fitterNames(), ", "))
+	lambda := fs.String("lambda", "", "strength of regularization, for the ridge and lasso fitters (default "+strconv.FormatFloat(defaultLambda, 'g', -1, 64)+")")
+	var xTransformValues stringsFlag
+	fs.Var(&xTransformValues, "xtransform", "comma separated terms of a model to fit, or the name of a preset like nlogn, which can be repeated to compare models (default \""+defaultXTransform+"\")")
+	nLineSteps := fs.Int("nlinesteps", 1000, "number of points to evaluate for the regressions")
+	xlbValue := fs.String("xlb", "", "lower bound of the regressions, which can be used to extrapolate (default is the smallest N)")
+	xubValue := fs.String("xub", "", "upper bound of the regressions, which can be used to extrapolate (default is the largest N)")
+	nreValue := fs.String("nre", defaultNRE, "regexp or name template, like Benchmark{Group}/{N:int}, matching the group and N in benchmark names")
+	unparameterized := fs.String("unparameterized", unparamTable, "how to handle benchmarks which don't match nre: "+unparamTable+" lists them, "+unparamOne+" plots them at N=1")
+	aggregate := fs.String("aggregate", aggregateAll, "how to summarize repeated runs at the same N: "+strings.Join([]string{aggregateAll, aggregateMean, aggregateMedian, aggregateMin}, ", "))
+	cluster := fs.String("cluster", clusterNone, "what to cluster the confidence intervals by, so that runs in the same one aren't treated as independent: "+clusterFile+" or "+clusterMachine+" (default is not to cluster)")
+	unitName := fs.String("unit", "", "unit to plot the response in, like ms or KiB, which the fits and tables use as well (default is the unit of go test)")
+	perElement := fs.Bool("per-element", false, "plot the response divided by N, per element rather than per op")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+	}
+	checkPatterns(fs.Args())
+
+	if _, ok := validYs[*yVar]; !ok {
+		fatalf("invalid yvar: %s", *yVar)
+	}
+	unit, err := parseUnit(*yVar, *unitName, *perElement)
+	if err != nil {
+		fatal(err)
+	}
+	fitter, err := parseFitter(url.Values{"fitter": {*fitterName}, "lambda": {*lambda}})
+	if err != nil {
+		fatal(err)
+	}
+	if *nLineSteps < 2 || *nLineSteps > maxLineSteps {
+		fatalf("invalid number of line steps: %d", *nLineSteps)
+	}
+	if len(xTransformValues) == 0 {
+		xTransformValues = stringsFlag{defaultXTransform}
+	}
+	var xTransforms [][]parsefloat.Expression
+	for i, v := range xTransformValues {
+		v = expandPreset(v)
+		xTransformValues[i] = v
+		xTransform, err := parseTransform(v)
+		if err != nil {
+			fatal(err)
+		}
+		xTransforms = append(xTransforms, xTransform)
+	}
+
+	nre, err := compileNRE(*nreValue)
+	if err != nil {
+		fatalf("invalid nre %s: %v", *nreValue, err)
+	}
+	if *unparameterized != unparamTable && *unparameterized != unparamOne {
+		fatalf("invalid unparameterized: %s", *unparameterized)
+	}
+	switch *aggregate {
+	case aggregateAll, aggregateMean, aggregateMedian, aggregateMin:
+	default:
+		fatalf("invalid aggregate: %s", *aggregate)
+	}
+	switch *cluster {
+	case clusterNone, clusterFile, clusterMachine:
+	default:
+		fatalf("invalid cluster: %s", *cluster)
+	}
+
+	xlbSetting, err := parseBound(*xlbValue)
+	if err != nil {
+		fatalf("invalid xlb: %v", err)
+	}
+	xubSetting, err := parseBound(*xubValue)
+	if err != nil {
+		fatalf("invalid xub: %v", err)
+	}
+
+	d3, err := readD3(*d3Path)
+	if err != nil {
+		fatalf("unable to read d3: %v", err)
+	}
+	var wasm, wasmSupport string
+	if *wasmPath != "" {
+		if wasm, wasmSupport, err = readWASM(*wasmPath); err != nil {
+			fatalf("unable to read the WebAssembly build: %v", err)
+		}
+	}
+
+	rep := report{
+		Data:        readDataSet(fs.Args()),
+		Fits:        make(map[string][]fitResponse),
+		YVar:        *yVar,
+		Unit:        unit,
+		UnitLabel:   unit.label(*yVar),
+		Fitter:      *fitterName,
+		Lambda:      *lambda,
+		XTransforms: xTransformValues,
+		NLineSteps:  *nLineSteps,
+		XLB:         xlbSetting,
+		XUB:         xubSetting,
+
+		NRE:             nre.String(),
+		Unparameterized: *unparameterized,
+		Aggregate:       *aggregate,
+		Cluster:         *cluster,
+	}
+	anns, err := initialAnnotations()
+	if err != nil {
+		fatal(err)
+	}
+	if rep.Names, err = displayNames(); err != nil {
+		fatal(err)
+	}
+	for _, a := range anns {
+		// the plotter can't tell what N is from the report's regexp
+		if !a.Bytes || byteSized(*nreValue) {
+			rep.Annotations = append(rep.Annotations, a)
+		}
+	}
+
+	// Evaluate every regression line over the range of the whole data set,
+	// unless a range was given.
+	g := grouping{nre: nre, unparameterized: *unparameterized, yVar: *yVar, aggregate: *aggregate, unit: unit, cluster: *cluster}
+	groups, _, xlb, xub := groupBenchmarks(rep.Data, g)
+	if xlbSetting != nil {
+		xlb = *xlbSetting
+	}
+	if xubSetting != nil {
+		xub = *xubSetting
+	}
+	// Groups which can't be fit by a model have a nil ResultLine for it.
+	for group, benchSet := range groups {
+		fits := make([]fitResponse, len(xTransforms))
+		for i, xTransform := range xTransforms {
+			if !canFit(benchSet, len(xTransform)) {
+				continue
+			}
+			f, err := fit(context.Background(), benchSet, fitRequest{
+				xlb:        xlb,
+				xub:        xub,
+				xTransform: xTransform,
+				yVar:       *yVar,
+				nLineSteps: *nLineSteps,
+				fitter:     fitter,
+			})
+			if err != nil {
+				slog.Warn("unable to fit", "group", group, "xtransform", xTransformValues[i], "err", err)
+				continue
+			}
+			fits[i] = f
+		}
+		rep.Fits[group] = fits
+	}
+	q := analysisQuery{grouping: g, xTransform: xTransforms[0], yVar: *yVar, fitter: fitter}
+	groupFits := q.fitGroups(rep.Data)
+	rep.Crossovers = allCrossovers(groupFits, &xlb, &xub)
+	rep.ChowTests = allChowTests(groupFits, *yVar)
+	rep.Exponents = q.allExponents(rep.Data)
+	rep.Drifts = q.allDrifts(rep.Data)
+
+	b, err := json.Marshal(rep)
+	if err != nil {
+		fatal(err)
+	}
+
+	// Inline d3 and the report into the plotter.  json.Marshal escapes '<', so
+	// benchmark names can't close the script element.  The WebAssembly build
+	// goes in first, along with the go code that runs it, so that d3 can't
+	// get in the way of finding the end of the head.
+	page := plotHTML
+	if wasm != "" {
+		page = strings.Replace(page, "</head>", "<script>\n"+wasmSupport+"\n</script>\n</head>", 1)
+		page = strings.Replace(page, wasmVar, "var benchplotWasm = \""+wasm+"\"", 1)
+	}
+	page = strings.Replace(page, d3Script, "<script charset=\"utf-8\">\n"+d3+"\n</script>", 1)
+	page = strings.Replace(page, reportVar, "var report = "+string(b), 1)
+
+	if *out == "-" {
+		_, err = os.Stdout.WriteString(page)
+	} else {
+		err = ioutil.WriteFile(*out, []byte(page), 0666)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// parseBound parses an optional bound of the regression line, which is nil
+// if it is empty.
+func parseBound(v string) (*float64, error) {
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// stringsFlag is a flag which can be given more than once.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, " ") }
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// readD3 returns the source of d3, either from the file at path or, if path
+// is empty, by downloading it.
+func readD3(path string) (string, error) {
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		return string(b), err
+	}
+	resp, err := http.Get(d3URL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: %s", d3URL, resp.Status)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	return string(b), err
+}
@@ -0,0 +1,93 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestReportMain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bench := filepath.Join(dir, "bench.txt")
+	if err := ioutil.WriteFile(bench, []byte(`BenchmarkSort10-4	1000	100 ns/op
+BenchmarkSort100-4	1000	1000 ns/op
+BenchmarkSort1000-4	1000	10500 ns/op
+BenchmarkEncode-4	1000	50 ns/op
+`), 0666); err != nil {
+		t.Fatal(err)
+	}
+	d3 := filepath.Join(dir, "d3.js")
+	if err := ioutil.WriteFile(d3, []byte("var d3 = {}"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args   []string
+		groups []string
+		fits   int
+	}{
+		{nil, []string{"BenchmarkSort"}, 1},
+		{[]string{"-xtransform=N,1.0", "-xtransform=nlogn"}, []string{"BenchmarkSort"}, 2},
+		// a single N can't be fit, but the group is still in the report
+		{[]string{"-unparameterized=one"}, []string{"BenchmarkEncode", "BenchmarkSort"}, 1},
+	}
+	for _, test := range tests {
+		out := filepath.Join(dir, "report.html")
+		reportMain(append(append([]string{"-o", out, "-d3", d3}, test.args...), bench))
+		b, err := ioutil.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page := string(b)
+
+		// the report is standalone, with d3 and the data inlined
+		if strings.Contains(page, d3Script) || !strings.Contains(page, "var d3 = {}") {
+			t.Errorf("%q: d3 isn't inlined", test.args)
+		}
+		i := strings.Index(page, "var report = ")
+		if i < 0 || strings.Contains(page, reportVar) {
+			t.Fatalf("%q: the report isn't inlined", test.args)
+		}
+		line := page[i+len("var report = "):]
+		line = line[:strings.Index(line, "\n")]
+		var rep report
+		if err := json.Unmarshal([]byte(line), &rep); err != nil {
+			t.Fatalf("%q: %v", test.args, err)
+		}
+		if len(rep.Data.Files) != 1 || len(rep.Data.Files[0].Benchmarks) != 4 {
+			t.Errorf("%q: got files %+v, want the benchmarks", test.args, rep.Data.Files)
+		}
+		var groups []string
+		for group, fits := range rep.Fits {
+			groups = append(groups, group)
+			if len(fits) != test.fits {
+				t.Errorf("%q: got %d fits of %s, want %d", test.args, len(fits), group, test.fits)
+			}
+		}
+		sort.Strings(groups)
+		if !reflect.DeepEqual(groups, test.groups) {
+			t.Errorf("%q: got fits of %q, want %q", test.args, groups, test.groups)
+		}
+		for i, f := range rep.Fits["BenchmarkSort"] {
+			if len(f.ResultLine) != 1000 {
+				t.Errorf("%q: got %d points of the %s fit, want 1000", test.args, len(f.ResultLine), rep.XTransforms[i])
+			}
+		}
+		if f := rep.Fits["BenchmarkEncode"]; f != nil && f[0].ResultLine != nil {
+			t.Errorf("%q: fit a group with a single N", test.args)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+func runUsage(fs *flag.FlagSet) func() {
+	return func() {
+		fmt.Fprintf(os.Stderr, "usage: benchplot [options] run [run options] [packages]\n")
+		fmt.Fprintf(os.Stderr, "runs go test -bench and plots the benchmarks as they complete\n")
+		fmt.Fprintf(os.Stderr, "example:\n")
+		fmt.Fprintf(os.Stderr, "   benchplot -http=:8080 run -bench=Sort ./...\n")
+		fmt.Fprintf(os.Stderr, "run options:\n")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+}
+
+func runMain(args []string) {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	fs.Usage = runUsage(fs)
+	bench := fs.String("bench", ".", "run only the benchmarks matching the regular expression")
+	benchtime := fs.String("benchtime", "", "passed to go test -benchtime")
+	benchmem := fs.Bool("benchmem", false, "passed to go test -benchmem")
+	count := fs.Int("count", 0, "passed to go test -count")
+	cpu := fs.String("cpu", "", "passed to go test -cpu")
+	// the webhook options can also be given here, where they were first
+	fs.Var(&webhooks, "webhook", "the same as benchplot -webhook")
+	fs.Float64Var(threshold, "threshold", *threshold, "the same as benchplot -threshold")
+	fs.StringVar(plotURL, "url", *plotURL, "the same as benchplot -url")
+	fs.Parse(args)
+
+	// Don't run any tests, only the benchmarks.
+	testArgs := []string{"test", "-run=^$", "-bench=" + *bench}
+	if *benchtime != "" {
+		testArgs = append(testArgs, "-benchtime="+*benchtime)
+	}
+	if *benchmem {
+		testArgs = append(testArgs, "-benchmem")
+	}
+	if *count > 0 {
+		testArgs = append(testArgs, fmt.Sprintf("-count=%d", *count))
+	}
+	if *cpu != "" {
+		testArgs = append(testArgs, "-cpu="+*cpu)
+	}
+	testArgs = append(testArgs, fs.Args()...)
+
+	src := &runSource{args: testArgs}
+	c, err := regressionChecks()
+	if err != nil {
+		fatal(err)
+	}
+	if c != nil {
+		// reruns are checked against the stored baseline, if there is one
+		var db *historyDB
+		if *dbDir != "" {
+			if db, err = openHistory(*dbDir); err != nil {
+				fatal(err)
+			}
+		}
+		src.check = func(first, run benchFile) {
+			for _, err := range c.checkRerun(db, first, run) {
+				slog.Warn("webhook failed", "err", err)
+			}
+		}
+	}
+	src.start()
+
+	// Rerun runs the benchmarks again, and adds the results as a new series.
+	http.HandleFunc("/rerun", src.rerunHandleFunc)
+
+	runServer(src)
+}
+
+// runSource runs go test -bench, and provides the benchmarks that it has
+// completed so far.  The benchmarks can be run more than once, and each run
+// is a separate series.
+type runSource struct {
+	args []string // arguments to go
+
+	// check, if it isn't nil, is called with the first run and each
+	// rerun that completes without an error.
+	check func(baseline, run benchFile)
+
+	mu      sync.Mutex
+	runs    []benchFile
+	running bool
+}
+
+func (rs *runSource) dataSet() dataSet {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	ds := dataSet{
+		Version: dataVersion,
+		Live:    rs.running,
+		Rerun:   !*readOnly,
+	}
+	for i := range rs.runs {
+		ds.Files = append(ds.Files, rs.runs[i].clone())
+	}
+	return ds
+}
+
+// start starts a new run of the benchmarks in the background.  It returns
+// false if the benchmarks are already running.
+func (rs *runSource) start() bool {
+	rs.mu.Lock()
+	defer rs.publish()
+	defer rs.mu.Unlock()
+	if rs.running {
+		return false
+	}
+	rs.running = true
+	rs.runs = append(rs.runs, benchFile{
+		Path:   "go " + strings.Join(rs.args, " "),
+		Series: fmt.Sprintf("run %d", len(rs.runs)+1),
+		Config: make(map[string]string),
+	})
+	go rs.run(len(rs.runs) - 1)
+	return true
+}
+
+// publish sends the current data to the plotters.
+func (rs *runSource) publish() {
+	ds := rs.dataSet()
+	events.publish(event{Type: eventData, Data: &ds})
+}
+
+// run runs the benchmarks, and parses the output into the i'th run as it is
+// written.  The output is also copied to stdout.
+func (rs *runSource) run(i int) {
+	rs.finish(i, rs.exec(i))
+}
+
+// finish records that the i'th run has finished, with the error err, and
+// checks it against the first run.
+func (rs *runSource) finish(i int, err error) {
+	rs.mu.Lock()
+	if err != nil {
+		rs.runs[i].Err = err.Error()
+		slog.Error("benchmarks failed", "cmd", rs.runs[i].Path, "err", err)
+	}
+	rs.running = false
+	baseline, run := rs.runs[0].clone(), rs.runs[i].clone()
+	rs.mu.Unlock()
+	rs.publish()
+
+	if rs.check != nil && i > 0 && err == nil {
+		rs.check(baseline, run)
+	}
+}
+
+func (rs *runSource) exec(i int) error {
+	cmd := exec.Command("go", rs.args...)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// Any error reading the output will also be reported by Wait.
+	// Each new benchmark is sent to the plotters as it is parsed.
+	scanLines(io.TeeReader(stdout, os.Stdout), func(line int, text string) {
+		rs.mu.Lock()
+		n := len(rs.runs[i].Benchmarks)
+		rs.runs[i].parseLine(line, text)
+		var b *benchmark
+		if len(rs.runs[i].Benchmarks) > n {
+			bc := *rs.runs[i].Benchmarks[n]
+			b = &bc
+		}
+		rs.mu.Unlock()
+		if b != nil {
+			events.publish(event{Type: eventParse, File: i, Benchmark: b})
+		}
+	})
+	return cmd.Wait()
+}
+
+func (rs *runSource) rerunHandleFunc(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "rerun requires a POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if !rs.start() {
+		http.Error(w, "the benchmarks are already running", http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
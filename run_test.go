@@ -0,0 +1,193 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testRunSource returns a runSource which runs the benchmarks in src, a
+// test file.
+func testRunSource(t *testing.T, src string) *runSource {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip(err)
+	}
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	fn := filepath.Join(dir, "x_test.go")
+	if err := ioutil.WriteFile(fn, []byte(src), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return &runSource{args: []string{"test", "-run=^$", "-bench=.", "-benchtime=1x", fn}}
+}
+
+// wait waits for the benchmarks to stop running.
+func (rs *runSource) wait(t *testing.T) {
+	deadline := time.Now().Add(time.Minute)
+	for rs.dataSet().Live {
+		if time.Now().After(deadline) {
+			t.Fatal("the benchmarks are still running")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunSource(t *testing.T) {
+	tests := []struct {
+		src        string
+		benchmarks []string
+		fails      bool
+	}{
+		{`package x
+import "testing"
+func BenchmarkSum10(b *testing.B) {}
+func BenchmarkSum100(b *testing.B) {}
+func TestSum(t *testing.T) { t.Fatal("tests aren't run") }
+`, []string{"BenchmarkSum10", "BenchmarkSum100"}, false},
+		// the benchmarks which completed are kept
+		{`package x
+import "testing"
+func BenchmarkSum10(b *testing.B) {}
+func BenchmarkSum100(b *testing.B) { b.Fatal("failed") }
+`, []string{"BenchmarkSum10"}, true},
+		{`package x
+func BenchmarkSum10(`, nil, true},
+	}
+	for i, test := range tests {
+		rs := testRunSource(t, test.src)
+		c := events.subscribe()
+		if !rs.start() {
+			t.Fatalf("%d: the benchmarks weren't started", i)
+		}
+		rs.wait(t)
+		events.unsubscribe(c)
+
+		ds := rs.dataSet()
+		if len(ds.Files) != 1 || ds.Files[0].Series != "run 1" {
+			t.Fatalf("%d: got files %+v, want the first run", i, ds.Files)
+		}
+		f := ds.Files[0]
+		var names []string
+		for _, b := range f.Benchmarks {
+			names = append(names, procRE.FindStringSubmatch(b.Name)[1])
+		}
+		if len(names) != len(test.benchmarks) {
+			t.Errorf("%d: got benchmarks %q, want %q", i, names, test.benchmarks)
+		}
+		for j := range names {
+			if j < len(test.benchmarks) && names[j] != test.benchmarks[j] {
+				t.Errorf("%d: got benchmarks %q, want %q", i, names, test.benchmarks)
+			}
+		}
+		if test.fails != (f.Err != "") {
+			t.Errorf("%d: got error %q, want failure %v", i, f.Err, test.fails)
+		}
+
+		// each benchmark is sent to the plotters as it is parsed, between
+		// the data when the run starts and when it stops
+		var types []string
+		parsed := 0
+		for len(c) > 0 {
+			e := <-c
+			types = append(types, e.Type)
+			if e.Type == eventParse {
+				parsed++
+			}
+		}
+		if len(types) < 2 || types[0] != eventData || types[len(types)-1] != eventData || parsed != len(test.benchmarks) {
+			t.Errorf("%d: got events %q, want the benchmarks as they were parsed", i, types)
+		}
+	}
+}
+
+func TestRerunHandleFunc(t *testing.T) {
+	rs := testRunSource(t, `package x
+import "testing"
+func BenchmarkSum10(b *testing.B) {}
+`)
+	checked := make(chan []string, 10)
+	rs.check = func(baseline, run benchFile) {
+		checked <- []string{baseline.Series, run.Series}
+	}
+	rs.start()
+	rs.wait(t)
+
+	tests := []struct {
+		method  string
+		running bool
+		code    int
+		runs    int
+	}{
+		{"GET", false, http.StatusMethodNotAllowed, 1},
+		{"POST", true, http.StatusConflict, 1},
+		{"POST", false, http.StatusAccepted, 2},
+		{"POST", false, http.StatusAccepted, 3},
+	}
+	for _, test := range tests {
+		rs.mu.Lock()
+		rs.running = test.running
+		rs.mu.Unlock()
+		w := httptest.NewRecorder()
+		rs.rerunHandleFunc(w, httptest.NewRequest(test.method, "/rerun", nil))
+		if w.Code != test.code {
+			t.Errorf("%s while running %v: got status %d, want %d", test.method, test.running, w.Code, test.code)
+		}
+		if test.running {
+			rs.mu.Lock()
+			rs.running = false
+			rs.mu.Unlock()
+		}
+		rs.wait(t)
+		if ds := rs.dataSet(); len(ds.Files) != test.runs {
+			t.Errorf("%s while running %v: got %d runs, want %d", test.method, test.running, len(ds.Files), test.runs)
+		}
+
+		// each rerun is checked against the first run
+		if test.code != http.StatusAccepted {
+			continue
+		}
+		select {
+		case c := <-checked:
+			if want := []string{"run 1", fmt.Sprintf("run %d", test.runs)}; !reflect.DeepEqual(c, want) {
+				t.Errorf("checked %q, want %q", c, want)
+			}
+		case <-time.After(time.Minute):
+			t.Errorf("run %d wasn't checked", test.runs)
+		}
+	}
+	if len(checked) > 0 {
+		t.Errorf("checked %q, which wasn't rerun", <-checked)
+	}
+
+	// each rerun is a new series
+	ds := rs.dataSet()
+	for i, f := range ds.Files {
+		if want := fmt.Sprintf("run %d", i+1); f.Series != want || len(f.Benchmarks) != 1 {
+			t.Errorf("got series %q with %d benchmarks, want %q with 1", f.Series, len(f.Benchmarks), want)
+		}
+	}
+
+	// the plotter only shows the rerun button if it can be used
+	if !ds.Rerun {
+		t.Error("can't rerun")
+	}
+	defer func(ro bool) { *readOnly = ro }(*readOnly)
+	*readOnly = true
+	if rs.dataSet().Rerun {
+		t.Error("can rerun in read-only mode")
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"time"
+
+	"github.com/jonlawlor/parsefloat"
+)
+
+// Models come from the querystring, so they are limited to what can be
+// evaluated quickly, which is a few short terms of arithmetic and the
+// functions in mathFuncs.
+const (
+	maxTransformLen = 1024 // bytes in the terms of a model
+	maxTerms        = 16
+	maxDepth        = 32 // nesting of the expressions in a term
+)
+
+// evalTimeout is how long a model can take to be evaluated at probePoints
+// before it is rejected.
+var evalTimeout = time.Second
+
+// mathFuncs are the functions in the math package which can be used in a
+// model, with the number of arguments they take.
+var mathFuncs = map[string]int{
+	"Abs":   1,
+	"Cbrt":  1,
+	"Ceil":  1,
+	"Exp":   1,
+	"Exp2":  1,
+	"Floor": 1,
+	"Log":   1,
+	"Log10": 1,
+	"Log1p": 1,
+	"Log2":  1,
+	"Sqrt":  1,
+	"Hypot": 2,
+	"Max":   2,
+	"Min":   2,
+	"Pow":   2,
+}
+
+// checkTerm returns the position of the first part of a term which can't be
+// used in a model, and a message describing it, or an empty message if the
+// term can be evaluated.
+func checkTerm(term ast.Expr) (pos token.Pos, msg string) {
+	return checkNested(term, 0)
+}
+
+// checkNested checks a term which is nested depth deep in another.
+func checkNested(term ast.Expr, depth int) (pos token.Pos, msg string) {
+	stack := make([]ast.Node, depth)
+	ast.Inspect(term, func(n ast.Node) bool {
+		if msg != "" {
+			return false
+		}
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		stack = append(stack, n)
+		if len(stack) > depth {
+			depth = len(stack)
+		}
+		if depth > maxDepth {
+			pos, msg = n.Pos(), fmt.Sprintf("the term is nested more than %d deep", maxDepth)
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.BasicLit:
+			if n.Kind != token.INT && n.Kind != token.FLOAT {
+				pos, msg = n.Pos(), fmt.Sprintf("invalid number: %s", n.Value)
+			}
+		case *ast.Ident:
+			if _, ok := modelVars[n.Name]; !ok {
+				pos, msg = n.Pos(), fmt.Sprintf("undefined: %s", n.Name)
+			}
+		case *ast.ParenExpr:
+		case *ast.UnaryExpr:
+			if n.Op != token.ADD && n.Op != token.SUB {
+				pos, msg = n.Pos(), fmt.Sprintf("invalid operator: %s", n.Op)
+			}
+		case *ast.BinaryExpr:
+			switch n.Op {
+			case token.ADD, token.SUB, token.MUL, token.QUO:
+			default:
+				pos, msg = n.OpPos, fmt.Sprintf("invalid operator: %s", n.Op)
+			}
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || !isMath(sel.X) {
+				pos, msg = n.Pos(), "only functions in the math package can be called"
+				return false
+			}
+			nargs, ok := mathFuncs[sel.Sel.Name]
+			switch {
+			case !ok:
+				pos, msg = sel.Sel.Pos(), fmt.Sprintf("math.%s can't be used in a model", sel.Sel.Name)
+			case len(n.Args) != nargs || n.Ellipsis.IsValid():
+				pos, msg = n.Lparen, fmt.Sprintf("math.%s takes %d arguments", sel.Sel.Name, nargs)
+			}
+			// the arguments are checked, but not the function
+			for _, arg := range n.Args {
+				if msg == "" {
+					pos, msg = checkNested(arg, len(stack))
+				}
+			}
+			stack = stack[:len(stack)-1]
+			return false
+		default:
+			pos, msg = n.Pos(), "only arithmetic and math functions can be used"
+		}
+		return msg == ""
+	})
+	return pos, msg
+}
+
+// isMath returns true if e is the math package.
+func isMath(e ast.Expr) bool {
+	x, ok := e.(*ast.Ident)
+	return ok && x.Name == "math"
+}
+
+// probePoints are the N which a model is evaluated at before it is fit.
+var probePoints = func() []float64 {
+	var points []float64
+	for n := 1.0; n <= 1<<30; n *= 1.25 {
+		points = append(points, n)
+	}
+	return points
+}()
+
+// probeTransform evaluates the terms at probePoints, and returns an error if
+// they take longer than evalTimeout, or ctx is done first.  They're evaluated
+// by the caller, and ctx is checked before each term, so the evaluation stops
+// when it times out rather than being left running.  A single term can't be
+// interrupted, but it is bounded by maxTransformLen and maxDepth.
+func probeTransform(ctx context.Context, xTransform []parsefloat.Expression) error {
+	probe, cancel := context.WithTimeout(ctx, evalTimeout)
+	defer cancel()
+	vars := map[string]float64{"N": 0, "K": 1}
+	for _, n := range probePoints {
+		vars["N"] = n
+		for _, x := range xTransform {
+			if probe.Err() != nil {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				return errors.New("the model took too long to evaluate")
+			}
+			x.Eval(vars)
+		}
+	}
+	return nil
+}
+
+// parseTransform parses the terms of a model, which can be a preset, after
+// checking that they can be evaluated safely.
+func parseTransform(xTransformValue string) ([]parsefloat.Expression, error) {
+	v, xTransform := checkTransform(xTransformValue)
+	if !v.Valid {
+		if v.Pos >= 0 {
+			return nil, fmt.Errorf("invalid xtransform %s: at %d: %s", xTransformValue, v.Pos+1, v.Error)
+		}
+		return nil, fmt.Errorf("invalid xtransform %s: %s", xTransformValue, v.Error)
+	}
+	return xTransform, nil
+}
@@ -0,0 +1,94 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jonlawlor/parsefloat"
+)
+
+func TestSandbox(t *testing.T) {
+	for _, xTransform := range []string{
+		"N, math.Inf(1)",
+		"math.Pow(N, 2, 3)",
+		"N % 2",
+		"N, math.Pi",
+		`math.Log("N")`,
+		"func() float64 { return N }()",
+		"fmt.Println(N)",
+		"N" + strings.Repeat(", N", maxTerms),
+		strings.Repeat("math.Sqrt(", maxDepth) + "N" + strings.Repeat(")", maxDepth),
+		strings.Repeat("N + ", maxTransformLen),
+	} {
+		if _, err := parseTransform(xTransform); err == nil {
+			t.Errorf("%.40q: didn't fail", xTransform)
+		}
+	}
+	for _, xTransform := range []string{
+		"math.Pow(N, 2), -N, 1.0",
+		"math.Max(math.Log2(N), 1) * N",
+		saturatingModel,
+	} {
+		if _, err := parseTransform(xTransform); err != nil {
+			t.Errorf("%q: %v", xTransform, err)
+		}
+	}
+}
+
+// slowExpr is an expression which takes too long to evaluate.  It counts
+// how many times it has been evaluated.
+type slowExpr struct{ evals *int32 }
+
+func (x slowExpr) Eval(map[string]float64) float64 {
+	atomic.AddInt32(x.evals, 1)
+	time.Sleep(time.Millisecond)
+	return 0
+}
+
+func (slowExpr) String() string { return "slow" }
+
+func TestProbeTransform(t *testing.T) {
+	defer func(d time.Duration) { evalTimeout = d }(evalTimeout)
+	evalTimeout = 10 * time.Millisecond
+	x := slowExpr{new(int32)}
+	if err := probeTransform(context.Background(), []parsefloat.Expression{x}); err == nil {
+		t.Errorf("probeTransform didn't time out")
+	}
+
+	// the evaluation stops soon after it times out
+	time.Sleep(5 * time.Millisecond)
+	evals := atomic.LoadInt32(x.evals)
+	time.Sleep(20 * time.Millisecond)
+	if got := atomic.LoadInt32(x.evals); got != evals || int(got) >= len(probePoints) {
+		t.Errorf("the model was evaluated %d times after timing out, and %d before", got-evals, evals)
+	}
+}
+
+func TestProbeTransformCancel(t *testing.T) {
+	x := slowExpr{new(int32)}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := probeTransform(ctx, []parsefloat.Expression{x}); err != context.Canceled {
+		t.Errorf("got %v after the request was cancelled, want %v", err, context.Canceled)
+	}
+	if got := atomic.LoadInt32(x.evals); got != 0 {
+		t.Errorf("the model was evaluated %d times after the request was cancelled", got)
+	}
+
+	// the validation is abandoned along with the request
+	req := httptest.NewRequest("GET", "/validate?xtransform=N", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	serveValidate(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d validating for a cancelled request, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/jonlawlor/parsefloat"
+)
+
+// modelVars are the variables that the terms of a model can use.  N is the
+// explanatory variable, and K is a nonlinear parameter which is estimated
+// along with the coefficients, like the half saturation point of
+//
+//	N / (K + N)
+//
+// which is the model of a response that levels off, like the MB/s of a copy
+// as the buffer grows past the cache.
+var modelVars = map[string]struct{}{"N": struct{}{}, "K": struct{}{}}
+
+// kRE matches K in the string form of a term.
+var kRE = regexp.MustCompile(`\bK\b`)
+
+// saturatingModel is a model that levels off at its coefficient, for
+// throughputs.
+const saturatingModel = "N / (K + N)"
+
+// kSteps is the number of steps of the search for K.
+const kSteps = 60
+
+// resolveK estimates K in the terms, if any of them use it, and returns the
+// terms with K replaced by its estimate.  For a given K the model is linear,
+// so K is the one with the smallest mean squared error, found by a golden
+// section search over log K from a hundredth of the smallest N to a hundred
+// times the largest.  The confidence intervals of the fit are conditional
+// on K.  Every N has to be positive.
+func resolveK(ctx context.Context, benchSet []benchmarkResponse, xTransform []parsefloat.Expression, yVar string, fitter estimator) ([]parsefloat.Expression, float64, error) {
+	uses := false
+	for _, x := range xTransform {
+		uses = uses || kRE.MatchString(x.String())
+	}
+	if !uses {
+		return xTransform, 0, nil
+	}
+	xMin, xMax := math.Inf(1), math.Inf(-1)
+	for _, b := range benchSet {
+		xMin = math.Min(xMin, b.X)
+		xMax = math.Max(xMax, b.X)
+	}
+	if !(xMin > 0) {
+		return nil, 0, fmt.Errorf("K can only be estimated if every N is positive")
+	}
+
+	mse := func(logK float64) float64 {
+		xt, err := substituteK(xTransform, math.Exp(logK))
+		if err != nil {
+			return math.Inf(1)
+		}
+		_, st, err := fitter.Fit(sampleGroup(benchSet, xt, yVar))
+		if err != nil || math.IsNaN(st.mse) {
+			return math.Inf(1)
+		}
+		return st.mse
+	}
+	phi := (math.Sqrt(5) - 1) / 2
+	a, b := math.Log(xMin/100), math.Log(xMax*100)
+	c, d := b-phi*(b-a), a+phi*(b-a)
+	fc, fd := mse(c), mse(d)
+	for i := 0; i < kSteps; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		if fc < fd {
+			b, d, fd = d, c, fc
+			c = b - phi*(b-a)
+			fc = mse(c)
+		} else {
+			a, c, fc = c, d, fd
+			d = a + phi*(b-a)
+			fd = mse(d)
+		}
+	}
+	k := math.Exp((a + b) / 2)
+	if math.IsInf(mse(math.Log(k)), 1) {
+		return nil, 0, fmt.Errorf("unable to estimate K")
+	}
+	xt, err := substituteK(xTransform, k)
+	return xt, k, err
+}
+
+// substituteK returns the terms with K replaced by its value.
+func substituteK(xTransform []parsefloat.Expression, k float64) ([]parsefloat.Expression, error) {
+	v := strconv.FormatFloat(k, 'g', 6, 64)
+	xt := make([]parsefloat.Expression, len(xTransform))
+	for i, x := range xTransform {
+		var err error
+		if xt[i], err = parsefloat.New(kRE.ReplaceAllString(x.String(), v), modelVars); err != nil {
+			return nil, err
+		}
+	}
+	return xt, nil
+}
@@ -0,0 +1,46 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/jonlawlor/parsefloat"
+	"golang.org/x/tools/benchmark/parse"
+)
+
+func TestResolveK(t *testing.T) {
+	var benchSet []benchmarkResponse
+	for n := 16.0; n <= 1<<20; n *= 2 {
+		benchSet = append(benchSet, benchmarkResponse{Benchmark: parse.Benchmark{MBPerS: 500 * n / (2000 + n)}, X: n})
+	}
+	xTransform, err := parsefloat.NewSlice("float64{"+saturatingModel+"}", modelVars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fitter := fitters[defaultFitter]
+	xt, k, err := resolveK(context.Background(), benchSet, xTransform, "MBPerS", fitter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(k-2000)/2000 > 1e-3 {
+		t.Errorf("got K = %g, want 2000", k)
+	}
+	beta, _, err := fitter.Fit(sampleGroup(benchSet, xt, "MBPerS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(beta[0]-500)/500 > 1e-3 {
+		t.Errorf("got a saturation of %g MB/s, want 500", beta[0])
+	}
+
+	// N has to be positive
+	benchSet[0].X = 0
+	if _, _, err := resolveK(context.Background(), benchSet, xTransform, "MBPerS", fitter); err == nil {
+		t.Errorf("resolveK didn't fail with N = 0")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// selector picks stored runs by their labels, like
+//
+//	branch=main, cpu=~"Xeon", goarch!=386
+//
+// which is similar to a Prometheus label matcher.  A run is selected if it
+// matches every one of the matchers.  The labels of a run include its benchfmt
+// configuration, like goos, goarch, and cpu, as well as the labels it was
+// stored with.
+type selector []matcher
+
+// matcher compares a single label.  A missing label is the empty string.
+type matcher struct {
+	label string
+	op    string // =, !=, =~, or !~
+	value string
+	re    *regexp.Regexp // for =~ and !~, which aren't anchored
+}
+
+func (m matcher) matches(labels map[string]string) bool {
+	v := labels[m.label]
+	switch m.op {
+	case "=":
+		return v == m.value
+	case "!=":
+		return v != m.value
+	case "=~":
+		return m.re.MatchString(v)
+	default:
+		return !m.re.MatchString(v)
+	}
+}
+
+func (s selector) matches(labels map[string]string) bool {
+	for _, m := range s {
+		if !m.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectorRE matches the label and operator at the start of a matcher.
+var selectorRE = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*`)
+
+// parseSelector parses a comma separated list of matchers.  Values can be
+// quoted, as in Go, to include commas or leading spaces.  An empty selector
+// matches every run.
+func parseSelector(s string) (selector, error) {
+	var sel selector
+	rest := s
+	for strings.TrimSpace(rest) != "" {
+		loc := selectorRE.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			return nil, fmt.Errorf("invalid selector %q: expected a label and one of =, !=, =~, !~ at %q", s, rest)
+		}
+		m := matcher{label: rest[loc[2]:loc[3]], op: rest[loc[4]:loc[5]]}
+		rest = rest[loc[1]:]
+
+		if strings.HasPrefix(rest, `"`) {
+			q := quotedPrefix(rest)
+			v, err := strconv.Unquote(q)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector %q: bad quoted value %s", s, q)
+			}
+			m.value = v
+			rest = strings.TrimSpace(rest[len(q):])
+			if rest != "" && rest[0] != ',' {
+				return nil, fmt.Errorf("invalid selector %q: expected a comma at %q", s, rest)
+			}
+		} else {
+			i := strings.Index(rest, ",")
+			if i < 0 {
+				i = len(rest)
+			}
+			m.value = strings.TrimSpace(rest[:i])
+			rest = rest[i:]
+		}
+		rest = strings.TrimPrefix(rest, ",")
+
+		if m.op == "=~" || m.op == "!~" {
+			re, err := regexp.Compile(m.value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector %q: %v", s, err)
+			}
+			m.re = re
+		}
+		sel = append(sel, m)
+	}
+	return sel, nil
+}
+
+// quotedPrefix returns the double quoted string at the start of s, up to
+// the first unescaped quote after the opening one, or all of s if it isn't
+// terminated.
+func quotedPrefix(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return s[:i+1]
+		}
+	}
+	return s
+}
+
+// runLabels returns the labels of a stored run, which are its benchfmt
+// configuration along with the labels it was stored with, which take
+// precedence.
+func runLabels(sr storedRun) map[string]string {
+	labels := make(map[string]string, len(sr.File.Config)+len(sr.Labels))
+	for k, v := range sr.File.Config {
+		labels[k] = v
+	}
+	for k, v := range sr.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// selectRuns returns the runs that match the selector.
+func selectRuns(runs []storedRun, sel selector) []storedRun {
+	selected := []storedRun{}
+	for _, sr := range runs {
+		if sel.matches(runLabels(sr)) {
+			selected = append(selected, sr)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,54 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+func TestSelector(t *testing.T) {
+	labels := map[string]string{
+		"branch": "main",
+		"goarch": "amd64",
+		"cpu":    "Intel(R) Xeon(R) CPU @ 2.20GHz, 8 cores",
+	}
+	for _, test := range []struct {
+		sel  string
+		want bool
+	}{
+		{"", true},
+		{"branch=main", true},
+		{`branch=main, cpu=~"Xeon", goarch=amd64`, true},
+		{`branch = main , goarch != 386`, true},
+		{`cpu="Intel(R) Xeon(R) CPU @ 2.20GHz, 8 cores"`, true},
+		{`cpu!~"Xeon"`, false},
+		{"branch=dev", false},
+		{"commit=", true},
+		{"commit!=", false},
+	} {
+		sel, err := parseSelector(test.sel)
+		if err != nil {
+			t.Errorf("parseSelector(%q): %v", test.sel, err)
+			continue
+		}
+		if got := sel.matches(labels); got != test.want {
+			t.Errorf("selector %q matches = %v, want %v", test.sel, got, test.want)
+		}
+	}
+
+	for _, sel := range []string{"branch", "=main", `cpu=~"(Xeon"`, `cpu="Xeon`, `cpu="Xeon" goarch=amd64`, "bad-label=1"} {
+		if _, err := parseSelector(sel); err == nil {
+			t.Errorf("parseSelector(%q) succeeded", sel)
+		}
+	}
+
+	// the benchfmt configuration is a label, unless the run was stored with
+	// the same label
+	sr := storedRun{
+		Labels: map[string]string{"machine": "ci"},
+		File:   benchFile{Config: map[string]string{"goarch": "arm64", "machine": "ignored"}},
+	}
+	if l := runLabels(sr); l["goarch"] != "arm64" || l["machine"] != "ci" {
+		t.Errorf("got labels %v, want goarch=arm64 and machine=ci", l)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"html/template"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+// tableColumns are the columns of /table, by the name they're sorted by.
+var tableColumns = []struct{ Key, Label string }{
+	{"name", "benchmark"},
+	{"n", "N"},
+	{"ns", "ns/op"},
+	{"bytes", "B/op"},
+	{"allocs", "allocs/op"},
+	{"file", "file"},
+}
+
+// tableRow is a parsed benchmark in /table.  The values are N and the
+// measurements, which are NaN if the benchmark doesn't have them.
+type tableRow struct {
+	Name, File string
+	values     [4]float64
+}
+
+// Cells formats the values of the row.
+func (r tableRow) Cells() []string {
+	cells := make([]string, len(r.values))
+	for i, v := range r.values {
+		if !math.IsNaN(v) {
+			cells[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return cells
+}
+
+// byTableColumn sorts the rows of /table by a column, with the rows which
+// don't have a value last, and otherwise in the order they were parsed.
+type byTableColumn struct {
+	rows []tableRow
+	col  int
+	desc bool
+}
+
+func (a byTableColumn) Len() int      { return len(a.rows) }
+func (a byTableColumn) Swap(i, j int) { a.rows[i], a.rows[j] = a.rows[j], a.rows[i] }
+func (a byTableColumn) Less(i, j int) bool {
+	ri, rj := a.rows[i], a.rows[j]
+	switch a.col {
+	case 0:
+		return a.ordered(ri.Name < rj.Name, rj.Name < ri.Name)
+	case len(tableColumns) - 1:
+		return a.ordered(ri.File < rj.File, rj.File < ri.File)
+	}
+	vi, vj := ri.values[a.col-1], rj.values[a.col-1]
+	if math.IsNaN(vi) || math.IsNaN(vj) {
+		return !math.IsNaN(vi) && math.IsNaN(vj)
+	}
+	return a.ordered(vi < vj, vj < vi)
+}
+
+// ordered returns whether the first of two values is before the second,
+// given whether it is less or greater than it.
+func (a byTableColumn) ordered(less, greater bool) bool {
+	if a.desc {
+		return greater
+	}
+	return less
+}
+
+// tableRows returns a row for each benchmark in ds whose name matches
+// filter, if it isn't nil.  N is found by nre, and is NaN if the name
+// doesn't match it.
+func tableRows(ds dataSet, nre, filter *regexp.Regexp) []tableRow {
+	var rows []tableRow
+	for _, f := range ds.Files {
+		for _, b := range f.Benchmarks {
+			if filter != nil && !filter.MatchString(b.Name) {
+				continue
+			}
+			r := tableRow{Name: b.Name, File: f.Path}
+			for i := range r.values {
+				r.values[i] = math.NaN()
+			}
+			if m := nre.FindStringSubmatch(b.Name); len(m) > 2 {
+				if n, err := strconv.ParseFloat(m[2], 64); err == nil {
+					r.values[0] = n
+				}
+			}
+			if b.Measured&parse.NsPerOp != 0 {
+				r.values[1] = b.NsPerOp
+			}
+			if b.Measured&parse.AllocedBytesPerOp != 0 {
+				r.values[2] = float64(b.AllocedBytesPerOp)
+			}
+			if b.Measured&parse.AllocsPerOp != 0 {
+				r.values[3] = float64(b.AllocsPerOp)
+			}
+			rows = append(rows, r)
+		}
+	}
+	return rows
+}
+
+// tableHTML is the table of the parsed benchmarks, with a form to filter
+// them.  The headers link to the table sorted by their column, so that it
+// works without scripts.
+var tableHTML = template.Must(template.New("table").Parse(`<!DOCTYPE html>
+<html lang="en">
+	<head>
+		<meta charset="utf-8">
+		<title>go benchplot benchmarks</title>
+		<style type="text/css">
+      body { font: 12px sans-serif; }
+      table { border-collapse: collapse; }
+      th, td { padding: 2px 8px; text-align: right; }
+      th:first-child, td:first-child, th:last-child, td:last-child { text-align: left; }
+      tbody tr:nth-child(even) { background: #f4f4f4; }
+		</style>
+	</head>
+	<body>
+		<form>
+			<label>filter benchmarks: <input type="search" name="filter" value="{{.Filter}}"></label>
+			<label><input type="checkbox" name="filterre" value="true"{{if .FilterRegexp}} checked{{end}}> regexp</label>
+			<input type="hidden" name="nre" value="{{.NRE}}">
+			<input type="hidden" name="sort" value="{{.Sort}}">
+			{{if .Desc}}<input type="hidden" name="desc" value="true">{{end}}
+			<input type="submit" value="filter">
+		</form>
+		<table>
+			<caption>{{len .Rows}} benchmarks, with N from {{.NRE}}</caption>
+			<thead><tr>
+				{{range .Headers}}<th scope="col"{{if .Sorted}} aria-sort="{{.Sorted}}"{{end}}><a href="{{.Href}}">{{.Label}}</a></th>
+				{{end}}</tr></thead>
+			<tbody>
+				{{range .Rows}}<tr><td>{{.Name}}</td>{{range .Cells}}<td>{{.}}</td>{{end}}<td>{{.File}}</td></tr>
+				{{end}}</tbody>
+		</table>
+	</body>
+</html>
+`))
+
+// serveTable serves every parsed benchmark in an HTML table, with its N,
+// measurements, and file, as an alternative to the plot which also shows
+// how the files were parsed.  It is sorted by the column in sort, which is
+// reversed by desc=true, and filtered by the names matching filter, like
+// the groups are.  N is found by nre.
+func serveTable(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		nreValue := r.Form.Get("nre")
+		if nreValue == "" {
+			nreValue = defaultNRE
+		}
+		nre, err := compileNRE(nreValue)
+		if err != nil {
+			http.Error(w, "invalid nre: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		filterRegexp := r.Form.Get("filterre") == "true"
+		filter, err := compileFilter(r.Form.Get("filter"), filterRegexp)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sortKey, desc := r.Form.Get("sort"), r.Form.Get("desc") == "true"
+		col := -1
+		for i, c := range tableColumns {
+			if c.Key == sortKey {
+				col = i
+			}
+		}
+		if sortKey != "" && col < 0 {
+			http.Error(w, "invalid sort: "+sortKey, http.StatusBadRequest)
+			return
+		}
+
+		rows := tableRows(src.dataSet(), nre, filter)
+		if col >= 0 {
+			sort.Stable(byTableColumn{rows, col, desc})
+		}
+
+		type header struct {
+			Label  string
+			Href   template.URL // the table sorted by the column
+			Sorted string       // the order it's sorted in, if it is
+		}
+		page := struct {
+			Filter       string
+			FilterRegexp bool
+			NRE, Sort    string
+			Desc         bool
+			Headers      []header
+			Rows         []tableRow
+		}{r.Form.Get("filter"), filterRegexp, nreValue, sortKey, desc, nil, rows}
+		for i, c := range tableColumns {
+			// clicking the sorted column reverses it
+			q := url.Values{"sort": {c.Key}}
+			for _, key := range []string{"filter", "filterre", "nre"} {
+				if v := r.Form.Get(key); v != "" {
+					q.Set(key, v)
+				}
+			}
+			h := header{Label: c.Label}
+			if i == col {
+				h.Sorted = "ascending"
+				if desc {
+					h.Sorted = "descending"
+				} else {
+					q.Set("desc", "true")
+				}
+			}
+			h.Href = template.URL("?" + q.Encode())
+			page.Headers = append(page.Headers, h)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		tableHTML.Execute(w, page)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestServeTable(t *testing.T) {
+	in := `BenchmarkSort100-4	1000	900 ns/op	64 B/op	2 allocs/op
+BenchmarkSort10-4	1000	100 ns/op	16 B/op	1 allocs/op
+BenchmarkSearch10-4	1000	50 ns/op
+BenchmarkUnparameterized-4	1000	75 ns/op
+`
+	bf, err := parseBenchFile(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bf.Path = "bench.txt"
+	h := serveTable(fixedSource{Files: []benchFile{bf}})
+
+	// names are the benchmarks in the order of the rows
+	names := regexp.MustCompile(`<tr><td>(Benchmark[\w-]+)</td>`)
+	tests := []struct {
+		query string
+		code  int
+		want  []string
+	}{
+		{"", http.StatusOK, []string{"BenchmarkSort100-4", "BenchmarkSort10-4", "BenchmarkSearch10-4", "BenchmarkUnparameterized-4"}},
+		{"sort=n", http.StatusOK, []string{"BenchmarkSort10-4", "BenchmarkSearch10-4", "BenchmarkSort100-4", "BenchmarkUnparameterized-4"}},
+		// the benchmarks without a value stay last
+		{"sort=bytes&desc=true", http.StatusOK, []string{"BenchmarkSort100-4", "BenchmarkSort10-4", "BenchmarkSearch10-4", "BenchmarkUnparameterized-4"}},
+		{"sort=name", http.StatusOK, []string{"BenchmarkSearch10-4", "BenchmarkSort10-4", "BenchmarkSort100-4", "BenchmarkUnparameterized-4"}},
+		{"sort=ns&filter=sort", http.StatusOK, []string{"BenchmarkSort10-4", "BenchmarkSort100-4"}},
+		{"filter=Sort10-&filterre=true", http.StatusOK, []string{"BenchmarkSort10-4"}},
+		{"sort=color", http.StatusBadRequest, nil},
+		{"filter=(&filterre=true", http.StatusBadRequest, nil},
+		{"nre=(", http.StatusBadRequest, nil},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest("GET", "/table?"+test.query, nil))
+		if w.Code != test.code {
+			t.Errorf("%s: got status %d, want %d", test.query, w.Code, test.code)
+			continue
+		}
+		if test.code != http.StatusOK {
+			continue
+		}
+		var got []string
+		for _, m := range names.FindAllStringSubmatch(w.Body.String(), -1) {
+			got = append(got, m[1])
+		}
+		if strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("%s: got rows %v, want %v", test.query, got, test.want)
+		}
+	}
+
+	// the sorted column links to it reversed
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest("GET", "/table?sort=ns&filter=sort", nil))
+	if body := w.Body.String(); !strings.Contains(body, `aria-sort="ascending"><a href="?desc=true&amp;filter=sort&amp;sort=ns">`) {
+		t.Errorf("the ns/op header doesn't link to it in descending order:\n%s", body)
+	}
+}
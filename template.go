@@ -0,0 +1,108 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// templateVarRE matches a variable in a name template, like {N:int} or
+// {Group}.  No regexp can contain one, since a repetition has to be numeric,
+// so it also tells templates apart from regexps.
+var templateVarRE = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)(?::([A-Za-z]+))?\}`)
+
+// templateTypes are the regexps that match each type of variable in a name
+// template.
+var templateTypes = map[string]string{
+	"int":    `\d+`,
+	"float":  `\d+(?:\.\d*)?(?:[eE][-+]?\d+)?`,
+	"string": `.*?`,
+}
+
+// compileNRE compiles the pattern that finds the group and N in benchmark
+// names, which is either a regexp with the group and N as its first two
+// submatches, or a name template.
+func compileNRE(s string) (*regexp.Regexp, error) {
+	if templateVarRE.MatchString(s) {
+		var err error
+		if s, err = templateNRE(s); err != nil {
+			return nil, err
+		}
+	}
+	return regexp.Compile(s)
+}
+
+// templateNRE converts a name template, like
+//
+//	BenchmarkSort{N:int}-{P:int}
+//	Benchmark{Group}/size={size:int}
+//
+// into a regexp that finds the group and N.  The text outside of braces
+// matches itself, and each variable matches its type: int, float, or string,
+// which is the default.  N is the variable named N, or the only int or float
+// variable.  The group is the variable named Group, or if there isn't one,
+// all of the name before N.  The GOMAXPROCS suffix can be left off of a
+// template.  The plotter has a copy of this, in templateNRE.
+func templateNRE(tmpl string) (string, error) {
+	locs := templateVarRE.FindAllStringSubmatchIndex(tmpl, -1)
+
+	// find N
+	n := -1
+	var numeric []int
+	for i, loc := range locs {
+		name, typ := tmpl[loc[2]:loc[3]], "string"
+		if loc[4] >= 0 {
+			typ = tmpl[loc[4]:loc[5]]
+		}
+		if _, ok := templateTypes[typ]; !ok {
+			return "", fmt.Errorf("invalid template %q: unknown type %s of %s", tmpl, typ, name)
+		}
+		if name == "N" {
+			n = i
+		}
+		if typ != "string" {
+			numeric = append(numeric, i)
+		}
+	}
+	if n < 0 {
+		if len(numeric) != 1 {
+			return "", fmt.Errorf("invalid template %q: no variable named N, or single int or float variable, to plot against", tmpl)
+		}
+		n = numeric[0]
+	}
+
+	var re []string
+	prev := 0
+	hasGroup := false
+	for i, loc := range locs {
+		name, typ := tmpl[loc[2]:loc[3]], "string"
+		if loc[4] >= 0 {
+			typ = tmpl[loc[4]:loc[5]]
+		}
+		re = append(re, regexp.QuoteMeta(tmpl[prev:loc[0]]))
+		prev = loc[1]
+		switch {
+		case i == n:
+			if !hasGroup {
+				// the group is everything before N
+				re = append([]string{"("}, re...)
+				re = append(re, ")")
+			}
+			re = append(re, "("+templateTypes[typ]+")")
+		case name == "Group":
+			if i > n {
+				return "", fmt.Errorf("invalid template %q: Group has to come before N", tmpl)
+			}
+			hasGroup = true
+			re = append(re, "("+templateTypes[typ]+")")
+		default:
+			re = append(re, "(?:"+templateTypes[typ]+")")
+		}
+	}
+	re = append(re, regexp.QuoteMeta(tmpl[prev:]))
+	return "^" + strings.Join(re, "") + `(?:-\d+)?$`, nil
+}
@@ -0,0 +1,49 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+func TestCompileNRE(t *testing.T) {
+	for _, test := range []struct {
+		pattern, name string
+		group, n      string // n is "" if it shouldn't match
+	}{
+		{defaultNRE, "BenchmarkSort100-4", "BenchmarkSort", "100"},
+		{"BenchmarkSort{N:int}-{P:int}", "BenchmarkSort100-4", "BenchmarkSort", "100"},
+		{"BenchmarkSort{N:int}-{P:int}", "BenchmarkStableSort100-4", "", ""},
+		{"Benchmark{Group}/{size:int}", "BenchmarkMap/1000-8", "Map", "1000"},
+		{"Benchmark{Group}/{size:int}", "BenchmarkMap/1000", "Map", "1000"},
+		{"Benchmark{Group}/size={size:float}", "BenchmarkFill/size=2.5e3-8", "Fill", "2.5e3"},
+		{"Benchmark{op}/{N:int}/{mode}", "BenchmarkGet/10/fast-4", "BenchmarkGet/", "10"},
+		{"Bench.{N:int}", "BenchXSort1", "", ""},
+	} {
+		nre, err := compileNRE(test.pattern)
+		if err != nil {
+			t.Errorf("compileNRE(%q): %v", test.pattern, err)
+			continue
+		}
+		m := nre.FindStringSubmatch(test.name)
+		if test.n == "" {
+			if m != nil {
+				t.Errorf("%q matched %q: %q", test.pattern, test.name, m)
+			}
+			continue
+		}
+		if len(m) < 3 || m[1] != test.group || m[2] != test.n {
+			t.Errorf("%q on %q: got %q, want group %q and N %q", test.pattern, test.name, m, test.group, test.n)
+		}
+	}
+
+	for _, pattern := range []string{
+		"Benchmark{Group}/{a:int}/{b:int}",
+		"Benchmark{N:int}/{Group}",
+		"Benchmark{Group}/{N:uint}",
+	} {
+		if _, err := compileNRE(pattern); err == nil {
+			t.Errorf("compileNRE(%q) didn't fail", pattern)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// brailleBits are the bits of the dots in a braille character, by column and
+// row.  Each character is a cell of 2 by 4 dots.
+var brailleBits = [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+// termPlot is a scatter plot drawn with characters, for terminals without a
+// browser.  Lines are drawn in braille, which has 8 dots per character, and
+// points are drawn over them as whole characters.
+type termPlot struct {
+	width, height int // in characters
+	dots          [][]rune
+	marks         [][]bool
+
+	logX                   bool
+	xMin, xMax, yMin, yMax float64
+}
+
+// newTermPlot returns an empty plot of the ranges, which are widened if they
+// are empty.
+func newTermPlot(width, height int, logX bool, xMin, xMax, yMin, yMax float64) *termPlot {
+	if xMin == xMax {
+		if logX {
+			xMin, xMax = xMin/2, xMax*2
+		} else {
+			xMin, xMax = xMin-1, xMax+1
+		}
+	}
+	if yMin == yMax {
+		yMin, yMax = yMin-1, yMax+1
+	}
+	p := &termPlot{width: width, height: height, logX: logX, xMin: xMin, xMax: xMax, yMin: yMin, yMax: yMax}
+	p.dots = make([][]rune, height)
+	p.marks = make([][]bool, height)
+	for i := range p.dots {
+		p.dots[i] = make([]rune, width)
+		p.marks[i] = make([]bool, width)
+	}
+	return p
+}
+
+// dot returns the position of (x, y) in dots from the top left, and whether it
+// is inside the plot.
+func (p *termPlot) dot(x, y float64) (col, row int, ok bool) {
+	lo, hi := p.xMin, p.xMax
+	if p.logX {
+		x, lo, hi = math.Log(x), math.Log(lo), math.Log(hi)
+	}
+	fx := (x - lo) / (hi - lo) * float64(2*p.width-1)
+	fy := (p.yMax - y) / (p.yMax - p.yMin) * float64(4*p.height-1)
+	if math.IsNaN(fx) || math.IsNaN(fy) || fx < -0.5 || fy < -0.5 || fx > float64(2*p.width)-0.5 || fy > float64(4*p.height)-0.5 {
+		return 0, 0, false
+	}
+	return int(fx + 0.5), int(fy + 0.5), true
+}
+
+// point marks the character at (x, y).
+func (p *termPlot) point(x, y float64) {
+	if col, row, ok := p.dot(x, y); ok {
+		p.marks[row/4][col/2] = true
+	}
+}
+
+// termX returns the ith of n values of x spaced evenly from lo to hi, or
+// spaced logarithmically if logX is set.
+func termX(lo, hi float64, i, n int, logX bool) float64 {
+	t := float64(i) / float64(n-1)
+	if logX {
+		return math.Exp(math.Log(lo) + t*(math.Log(hi)-math.Log(lo)))
+	}
+	return lo + t*(hi-lo)
+}
+
+// line draws f over the range of x, filling in each column of dots up to the
+// value in the column before so that steep lines are unbroken.
+func (p *termPlot) line(f func(x float64) float64) {
+	prev := -1
+	for col := 0; col < 2*p.width; col++ {
+		y := f(termX(p.xMin, p.xMax, col, 2*p.width, p.logX))
+		fy := (p.yMax - y) / (p.yMax - p.yMin) * float64(4*p.height-1)
+		if math.IsNaN(fy) || math.IsInf(fy, 0) {
+			prev = -1
+			continue
+		}
+		row := int(math.Floor(fy + 0.5))
+		lo, hi := row, row
+		if prev >= 0 && prev < row {
+			lo = prev + 1
+		} else if prev > row {
+			hi = prev - 1
+		}
+		for r := lo; r <= hi; r++ {
+			if r >= 0 && r < 4*p.height {
+				p.dots[r/4][col/2] |= brailleBits[col%2][r%4]
+			}
+		}
+		prev = row
+	}
+}
+
+// write draws the plot with its axes, labeled with the ranges of x and y.
+func (p *termPlot) write(w io.Writer, xLabel, yLabel string) error {
+	yTop, yBottom := fmt.Sprintf("%.4g", p.yMax), fmt.Sprintf("%.4g", p.yMin)
+	margin := len(yTop)
+	if len(yBottom) > margin {
+		margin = len(yBottom)
+	}
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%*s\n", margin+1+len(yLabel), yLabel)
+	for i := range p.dots {
+		label := ""
+		switch i {
+		case 0:
+			label = yTop
+		case p.height - 1:
+			label = yBottom
+		}
+		fmt.Fprintf(&buf, "%*s ┤", margin, label)
+		for j, d := range p.dots[i] {
+			switch {
+			case p.marks[i][j]:
+				buf.WriteRune('•')
+			case d == 0:
+				buf.WriteRune(' ')
+			default:
+				buf.WriteRune(0x2800 + d)
+			}
+		}
+		buf.WriteString("\n")
+	}
+	fmt.Fprintf(&buf, "%*s └%s\n", margin, "", strings.Repeat("─", p.width))
+	xLo, xHi := fmt.Sprintf("%g", p.xMin), fmt.Sprintf("%g", p.xMax)
+	gap := p.width - len(xLo) - len(xHi)
+	if gap < 1 {
+		gap = 1
+	}
+	fmt.Fprintf(&buf, "%*s  %s%s%s\n", margin, "", xLo, strings.Repeat(" ", gap), xHi)
+	fmt.Fprintf(&buf, "%*s  %*s\n", margin, "", (p.width+len(xLabel))/2, xLabel)
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// writeTermPlot draws the benchmarks of a fit as points, and the fit as a
+// line through them.
+func writeTermPlot(w io.Writer, gf groupFit, yVar, yLabel string, width, height int, logX bool) error {
+	y := sampleGroup(gf.benchSet, nil, yVar).y
+	yMin, yMax := math.Inf(1), math.Inf(-1)
+	for _, v := range y {
+		yMin, yMax = math.Min(yMin, v), math.Max(yMax, v)
+	}
+	fitted := func(x float64) float64 {
+		yhat, _ := gf.at(x)
+		return yhat
+	}
+	for i := 0; i < 2*width; i++ {
+		if v := fitted(termX(gf.xMin, gf.xMax, i, 2*width, logX)); !math.IsNaN(v) && !math.IsInf(v, 0) {
+			yMin, yMax = math.Min(yMin, v), math.Max(yMax, v)
+		}
+	}
+	p := newTermPlot(width, height, logX, gf.xMin, gf.xMax, yMin, yMax)
+	p.line(fitted)
+	for i, b := range gf.benchSet {
+		p.point(b.X, y[i])
+	}
+	return p.write(w, "N", yLabel)
+}
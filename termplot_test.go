@@ -0,0 +1,47 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestWriteFits(t *testing.T) {
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"N, 1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gf, ok := fitGroup(context.Background(), "linear", linearBenchSet(3, 5), q.xTransform, q.yVar, q.fitter)
+	if !ok {
+		t.Fatal("unable to fit")
+	}
+	var buf bytes.Buffer
+	if err := writeFits(&buf, []groupFit{gf}, q.yVar, unitConv{}, plotTerm, 20, 5, false); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if !strings.HasPrefix(lines[0], "linear: ns/op = 2.995*(N) + 5.17") {
+		t.Errorf("got model %q", lines[0])
+	}
+	// the model, the y label, each row, the x axis, and its labels
+	if len(lines) != 1+1+5+1+2 {
+		t.Fatalf("got %d lines, want 10:\n%s", len(lines), buf.String())
+	}
+	points, braille := 0, false
+	for _, line := range lines[2:7] {
+		points += strings.Count(line, "•")
+		braille = braille || strings.ContainsAny(line, "⠁⠂⠄⡀⠈⠐⠠⢀⣀⠉⠒⠤")
+	}
+	if points == 0 || !braille {
+		t.Errorf("got no points or line in the plot:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[8], "10") || !strings.Contains(lines[8], "60") {
+		t.Errorf("got x axis labels %q, want 10 and 60", lines[8])
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// withFitTimeout returns a context which is done after -fit-timeout, if it
+// is set, so that fits for plotters which have gone away, or which take
+// far too long, don't keep running.
+func withFitTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if *fitTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, *fitTimeout)
+}
+
+// fitTimeoutHandler gives each request to h at most -fit-timeout to fit in.
+// The request is also cancelled if the client goes away.
+func fitTimeoutHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := withFitTimeout(r.Context())
+		defer cancel()
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// fitError responds with an error from fitting, which is a 503 if the fit
+// was abandoned, rather than a problem with the request.
+func fitError(w http.ResponseWriter, err error) {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		http.Error(w, "the fit was abandoned: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
@@ -0,0 +1,45 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestFitCancelled(t *testing.T) {
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {saturatingModel}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := fitRequest{xTransform: q.xTransform, yVar: "NsPerOp", fitter: q.fitter, xlb: 1, xub: 10, nLineSteps: 10}
+	if _, err := fit(ctx, linearBenchSet(3, 5), req); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v from a cancelled fit, want %v", err, context.Canceled)
+	}
+	if _, ok := fitGroup(ctx, "a", linearBenchSet(3, 5), q.xTransform, q.yVar, q.fitter); ok {
+		t.Errorf("a cancelled fitGroup succeeded")
+	}
+}
+
+func TestFitTimeoutHandler(t *testing.T) {
+	defer func(d time.Duration) { *fitTimeout = d }(*fitTimeout)
+	*fitTimeout = time.Nanosecond
+	h := fitTimeoutHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		fitError(w, r.Context().Err())
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/fit", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d after the timeout, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// units are the units that each response can be converted to, and their
+// size in the units that go test reports.
+var units = map[string]map[string]float64{
+	"NsPerOp": {"ns": 1, "us": 1e3, "µs": 1e3, "ms": 1e6, "s": 1e9},
+	"AllocedBytesPerOp": {
+		"B":  1,
+		"kB": 1e3, "MB": 1e6, "GB": 1e9,
+		"KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30,
+	},
+	"AllocsPerOp":       {"allocs": 1},
+	"MBPerS":            {"MB/s": 1, "GB/s": 1e3},
+	"OpsPerS":           {"ops/s": 1, "kops/s": 1e3, "Mops/s": 1e6},
+	"InstructionsPerOp": {"instructions": 1},
+	"CacheMissesPerOp":  {"cache-misses": 1},
+	"BranchMissesPerOp": {"branch-misses": 1},
+}
+
+// unitConv converts a response to other units, for reports and exports.
+// The zero value leaves the response as it is.
+type unitConv struct {
+	Name       string  // like ms or KiB
+	Scale      float64 // size of the unit, in the units of the response
+	PerElement bool    // divide by N, to get the cost of each element rather than each op
+}
+
+// parseUnit returns the conversion of the response to the named unit,
+// which is the response's own unit if it is empty.  Throughputs can't be
+// per element, since they are rates.
+func parseUnit(yVar, name string, perElement bool) (unitConv, error) {
+	if perElement && throughputs[yVar] {
+		return unitConv{}, fmt.Errorf("%s can't be per element", yVar)
+	}
+	if name == "" {
+		name = strings.SplitN(validYs[yVar], "/", 2)[0]
+		if throughputs[yVar] {
+			name = validYs[yVar]
+		}
+	}
+	scale, ok := units[yVar][name]
+	if !ok {
+		var names []string
+		for n := range units[yVar] {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return unitConv{}, fmt.Errorf("invalid unit %s of %s: should be one of %s", name, yVar, strings.Join(names, ", "))
+	}
+	return unitConv{Name: name, Scale: scale, PerElement: perElement}, nil
+}
+
+// converts reports whether the conversion changes the response.
+func (u unitConv) converts() bool {
+	return (u.Scale != 0 && u.Scale != 1) || u.PerElement
+}
+
+// label is the unit of the converted response, like ms/op.
+func (u unitConv) label(yVar string) string {
+	if !u.converts() {
+		return validYs[yVar]
+	}
+	if throughputs[yVar] {
+		return u.Name
+	}
+	if u.PerElement {
+		return u.Name + "/elem"
+	}
+	return u.Name + "/op"
+}
+
+// divisor is what the response of a benchmark at N is divided by.
+func (u unitConv) divisor(n float64) float64 {
+	d := u.Scale
+	if d == 0 {
+		d = 1
+	}
+	if u.PerElement {
+		d *= n
+	}
+	return d
+}
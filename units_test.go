@@ -0,0 +1,77 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseUnit(t *testing.T) {
+	for _, test := range []struct {
+		yVar, name string
+		perElement bool
+		label      string
+		scale      float64
+	}{
+		{"NsPerOp", "", false, "ns/op", 1},
+		{"NsPerOp", "ms", false, "ms/op", 1e6},
+		{"NsPerOp", "µs", true, "µs/elem", 1e3},
+		{"AllocedBytesPerOp", "KiB", false, "KiB/op", 1024},
+		{"AllocsPerOp", "", true, "allocs/elem", 1},
+		{"MBPerS", "GB/s", false, "GB/s", 1e3},
+		{"MBPerS", "", false, "MB/s", 1},
+		{"OpsPerS", "Mops/s", false, "Mops/s", 1e6},
+	} {
+		u, err := parseUnit(test.yVar, test.name, test.perElement)
+		if err != nil {
+			t.Errorf("parseUnit(%q, %q, %v): %v", test.yVar, test.name, test.perElement, err)
+			continue
+		}
+		if u.Scale != test.scale || u.label(test.yVar) != test.label {
+			t.Errorf("parseUnit(%q, %q, %v) = %+v labeled %q, want scale %g labeled %q", test.yVar, test.name, test.perElement, u, u.label(test.yVar), test.scale, test.label)
+		}
+	}
+	for _, test := range []struct {
+		yVar, name string
+		perElement bool
+	}{
+		{"NsPerOp", "KiB", false},
+		{"MBPerS", "", true},
+		{"OpsPerS", "", true},
+	} {
+		if _, err := parseUnit(test.yVar, test.name, test.perElement); err == nil {
+			t.Errorf("parseUnit(%q, %q, %v) didn't fail", test.yVar, test.name, test.perElement)
+		}
+	}
+}
+
+func TestUnitConversion(t *testing.T) {
+	in := "BenchmarkCopy10-4\t1000\t2000 ns/op\nBenchmarkCopy100-4\t1000\t20000 ns/op\nBenchmarkCopy1000-4\t1000\t200000 ns/op\n"
+	bf, err := parseBenchFile(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := dataSet{Files: []benchFile{bf}}
+	q, err := parseAnalysisQuery(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.nre = regexp.MustCompile(defaultNRE)
+	q.xTransform = q.xTransform[1:] // only the constant
+	if q.unit, err = parseUnit("NsPerOp", "µs", true); err != nil {
+		t.Fatal(err)
+	}
+	fits := q.fitGroups(ds)
+	if len(fits) != 1 {
+		t.Fatalf("got %d fits, want 1", len(fits))
+	}
+	// 200 ns per element is 0.2 µs
+	if y, _ := fits[0].predict(100); math.Abs(y-0.2) > 1e-9 {
+		t.Errorf("got %g µs/elem, want 0.2", y)
+	}
+}
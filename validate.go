@@ -0,0 +1,118 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/scanner"
+	"go/token"
+	"net/http"
+	"strings"
+
+	"github.com/jonlawlor/parsefloat"
+)
+
+// validation is the result of validating a model.  If it isn't valid, Pos
+// is the byte offset of the error in the model, or -1 if it isn't known.
+type validation struct {
+	Valid bool
+	Terms []string `json:",omitempty"` // the terms, formatted like gofmt
+	Error string   `json:",omitempty"`
+	Pos   int
+}
+
+// transformPrefix wraps the terms of a model to parse them as a go
+// expression, which is how parsefloat reads them.
+const transformPrefix = "float64{"
+
+// checkTransform checks that a model, which can be a preset, parses and can
+// be evaluated safely, without fitting it, and returns its terms if it can.
+func checkTransform(xTransformValue string) (validation, []parsefloat.Expression) {
+	return checkTransformContext(context.Background(), xTransformValue)
+}
+
+// checkTransformContext is checkTransform, which gives up on evaluating the
+// model when ctx is done.
+func checkTransformContext(ctx context.Context, xTransformValue string) (validation, []parsefloat.Expression) {
+	if len(xTransformValue) > maxTransformLen {
+		return validation{Error: fmt.Sprintf("the model is longer than %d bytes", maxTransformLen), Pos: maxTransformLen}, nil
+	}
+	src := transformPrefix + expandPreset(xTransformValue) + "}"
+	fset := token.NewFileSet()
+	e, err := parser.ParseExprFrom(fset, "", src, 0)
+	if err != nil {
+		if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+			return invalidTransform(xTransformValue, list[0].Pos.Offset, list[0].Msg), nil
+		}
+		return invalidTransform(xTransformValue, -1, err.Error()), nil
+	}
+	lit, ok := e.(*ast.CompositeLit)
+	if !ok || len(lit.Elts) == 0 {
+		return validation{Error: "there are no terms", Pos: -1}, nil
+	}
+	if len(lit.Elts) > maxTerms {
+		return invalidTransform(xTransformValue, fset.Position(lit.Elts[maxTerms].Pos()).Offset, fmt.Sprintf("there are more than %d terms", maxTerms)), nil
+	}
+
+	v := validation{Valid: true}
+	for _, elt := range lit.Elts {
+		if pos, msg := checkTerm(elt); msg != "" {
+			return invalidTransform(xTransformValue, fset.Position(pos).Offset, msg), nil
+		}
+		var b bytes.Buffer
+		printer.Fprint(&b, fset, elt)
+		v.Terms = append(v.Terms, b.String())
+	}
+
+	// parsefloat has the final say, although its errors can't be placed.
+	xTransform, err := parsefloat.NewSlice(src, modelVars)
+	if err == nil {
+		err = probeTransform(ctx, xTransform)
+	}
+	if err != nil {
+		return validation{Error: err.Error(), Pos: -1}, nil
+	}
+	return v, xTransform
+}
+
+// invalidTransform returns the validation of an error at offset in the
+// parsed source, which is moved back to the model's terms.  Errors in a
+// preset, or past the end of the terms, are placed at the end.
+func invalidTransform(xTransformValue string, offset int, msg string) validation {
+	pos := offset - len(transformPrefix)
+	if offset < 0 {
+		pos = -1
+	} else if pos < 0 {
+		pos = 0
+	} else if pos >= len(xTransformValue) || expandPreset(xTransformValue) != xTransformValue {
+		// the closing brace isn't part of the model
+		pos = len(xTransformValue)
+		msg = strings.Replace(msg, "'}'", "the end", 1)
+	}
+	return validation{Error: msg, Pos: pos}
+}
+
+// serveValidate validates the model in xtransform, so that it can be checked
+// while it is being typed rather than when it is fit.  Evaluating the model
+// is abandoned if the request is.
+func serveValidate(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	v, _ := checkTransformContext(r.Context(), r.Form.Get("xtransform"))
+	if err := r.Context().Err(); err != nil {
+		fitError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,38 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckTransform(t *testing.T) {
+	for _, test := range []struct {
+		xTransform string
+		terms      []string
+		pos        int
+	}{
+		{"math.Log(N)*N,1.0", []string{"math.Log(N) * N", "1.0"}, 0},
+		{"linear", []string{"N", "1.0"}, 0},
+		{"N, 1.0 +", nil, 8},
+		{"N, M", nil, 3},
+		{"N, os.Exit(1)", nil, 3},
+		{"N * (K", nil, 6},
+		{"", nil, -1},
+	} {
+		v, _ := checkTransform(test.xTransform)
+		if v.Valid != (test.terms != nil) {
+			t.Errorf("%q: got valid %t, error %q", test.xTransform, v.Valid, v.Error)
+			continue
+		}
+		if !reflect.DeepEqual(v.Terms, test.terms) {
+			t.Errorf("%q: got terms %q, want %q", test.xTransform, v.Terms, test.terms)
+		}
+		if v.Pos != test.pos {
+			t.Errorf("%q: got error %q at %d, want at %d", test.xTransform, v.Error, v.Pos, test.pos)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// vegaLiteSchema is the version of Vega-Lite that the specs are written for.
+const vegaLiteSchema = "https://vega.github.io/schema/vega-lite/v5.json"
+
+// defaultVegaSteps is how many points each fitted line is evaluated at.
+const defaultVegaSteps = 100
+
+// vegaPoint is a benchmark, or a point on a fitted line, in a Vega-Lite spec.
+type vegaPoint struct {
+	Group string  `json:"group"`
+	N     float64 `json:"N"`
+	Y     float64 `json:"y"`
+}
+
+// vegaSpec writes the benchmarks of each group and their fitted lines as a
+// Vega-Lite spec, a layer of points under a layer of lines, colored by group.
+// The data is inlined, so the spec can be restyled or embedded in a notebook
+// without the server.  The lines are evaluated at steps points, spaced
+// logarithmically if logX is set.
+func vegaSpec(groups map[string][]benchmarkResponse, fits []groupFit, yVar string, steps int, logX bool) map[string]interface{} {
+	var names []string
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+	points := []vegaPoint{}
+	for _, group := range names {
+		benchSet := groups[group]
+		y := sampleGroup(benchSet, nil, yVar).y
+		for i, b := range benchSet {
+			points = append(points, vegaPoint{group, b.X, y[i]})
+		}
+	}
+
+	lines := []vegaPoint{}
+	for _, gf := range fits {
+		lo, hi := gf.xMin, gf.xMax
+		if logX {
+			lo, hi = math.Log(lo), math.Log(hi)
+		}
+		for i := 0; i < steps; i++ {
+			x := lo + (hi-lo)*float64(i)/float64(steps-1)
+			switch {
+			case i == 0:
+				x = gf.xMin
+			case i == steps-1:
+				x = gf.xMax
+			case logX:
+				x = math.Exp(x)
+			}
+			yhat, _ := gf.at(x)
+			if !math.IsNaN(yhat) && !math.IsInf(yhat, 0) {
+				lines = append(lines, vegaPoint{gf.Group, x, yhat})
+			}
+		}
+	}
+
+	xScale := map[string]interface{}{"type": "linear"}
+	if logX {
+		xScale["type"] = "log"
+	}
+	encoding := func(extra map[string]interface{}) map[string]interface{} {
+		enc := map[string]interface{}{
+			"x":     map[string]interface{}{"field": "N", "type": "quantitative", "scale": xScale},
+			"y":     map[string]interface{}{"field": "y", "type": "quantitative", "title": validYs[yVar]},
+			"color": map[string]interface{}{"field": "group", "type": "nominal"},
+		}
+		for k, v := range extra {
+			enc[k] = v
+		}
+		return enc
+	}
+	return map[string]interface{}{
+		"$schema": vegaLiteSchema,
+		"width":   600,
+		"height":  400,
+		"layer": []interface{}{
+			map[string]interface{}{
+				"data": map[string]interface{}{"values": points},
+				"mark": map[string]interface{}{"type": "point", "filled": true},
+				"encoding": encoding(map[string]interface{}{
+					"tooltip": []interface{}{
+						map[string]interface{}{"field": "group", "type": "nominal"},
+						map[string]interface{}{"field": "N", "type": "quantitative"},
+						map[string]interface{}{"field": "y", "type": "quantitative", "title": validYs[yVar]},
+					},
+				}),
+			},
+			map[string]interface{}{
+				"data":     map[string]interface{}{"values": lines},
+				"mark":     "line",
+				"encoding": encoding(nil),
+			},
+		},
+	}
+}
+
+// serveVega returns a handler which writes the benchmarks and fits of the
+// querystring as a Vega-Lite spec.  The lines are evaluated at steps points,
+// on a log scale of N if xscale=log.
+func serveVega(src dataSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q, err := parseAnalysisQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		steps := defaultVegaSteps
+		if v := r.Form.Get("steps"); v != "" {
+			if steps, err = strconv.Atoi(v); err != nil || steps < 2 {
+				http.Error(w, "invalid steps: "+v, http.StatusBadRequest)
+				return
+			}
+		}
+		logX := false
+		switch v := r.Form.Get("xscale"); v {
+		case "", "linear":
+		case "log":
+			logX = true
+		default:
+			http.Error(w, "invalid xscale: "+v, http.StatusBadRequest)
+			return
+		}
+
+		ds := src.dataSet()
+		groups, _, xlb, _ := groupBenchmarks(ds, q.grouping)
+		if logX && xlb <= 0 {
+			http.Error(w, "xscale=log needs every N to be positive", http.StatusBadRequest)
+			return
+		}
+		fits, err := q.fitGroupsContext(r.Context(), ds)
+		if err != nil {
+			fitError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(vegaSpec(groups, fits, q.yVar, steps, logX))
+	}
+}
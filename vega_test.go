@@ -0,0 +1,41 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"testing"
+)
+
+func TestVegaSpec(t *testing.T) {
+	q, err := parseAnalysisQuery(url.Values{"xtransform": {"N, 1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	benchSet := linearBenchSet(3, 5)
+	gf, ok := fitGroup(context.Background(), "linear", benchSet, q.xTransform, q.yVar, q.fitter)
+	if !ok {
+		t.Fatal("unable to fit")
+	}
+	spec := vegaSpec(map[string][]benchmarkResponse{"linear": benchSet}, []groupFit{gf}, q.yVar, 10, true)
+	layers := spec["layer"].([]interface{})
+	points := layers[0].(map[string]interface{})["data"].(map[string]interface{})["values"].([]vegaPoint)
+	lines := layers[1].(map[string]interface{})["data"].(map[string]interface{})["values"].([]vegaPoint)
+	if len(points) != len(benchSet) || len(lines) != 10 {
+		t.Fatalf("got %d points and %d line points, want %d and 10", len(points), len(lines), len(benchSet))
+	}
+	if lines[0].N != 10 || math.Abs(lines[9].N-60) > 1e-9 || math.Abs(lines[9].Y-185) > 1 {
+		t.Errorf("got line from %v to %v, want from N=10 to about (60, 185)", lines[0], lines[9])
+	}
+	if math.Abs(lines[1].N-10*math.Pow(6, 1.0/9)) > 1e-9 {
+		t.Errorf("got second step at %g, want the steps spaced logarithmically", lines[1].N)
+	}
+	x := layers[1].(map[string]interface{})["encoding"].(map[string]interface{})["x"].(map[string]interface{})
+	if x["scale"].(map[string]interface{})["type"] != "log" {
+		t.Errorf("got x encoding %v, want a log scale", x)
+	}
+}
@@ -0,0 +1,349 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxViewSize is the largest view configuration which can be posted.
+const maxViewSize = 1 << 20
+
+// viewVar is the declaration of the saved view in the plotter.  The plotter
+// is served with the view in place of it when it is opened with ?view=name.
+const viewVar = "var view = null"
+
+// viewNameRE matches the names of views, which are used in links.
+var viewNameRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 ._-]{0,63}$`)
+
+// viewConfig is the configuration of the plotter which is saved in a view:
+// its filters, models, range, and annotations.  Settings which aren't set
+// are left at the plotter's defaults.
+type viewConfig struct {
+	NRE             string       `json:",omitempty"`
+	Unparameterized string       `json:",omitempty"`
+	Package         string       `json:",omitempty"`
+	GroupByPackage  bool         `json:",omitempty"`
+	Machine         string       `json:",omitempty"`
+	GroupByMachine  bool         `json:",omitempty"`
+	GroupByFile     bool         `json:",omitempty"`
+	Filter          string       `json:",omitempty"`
+	FilterRegexp    bool         `json:",omitempty"`
+	Baseline        string       `json:",omitempty"`
+	YVar            string       `json:",omitempty"`
+	Aggregate       string       `json:",omitempty"`
+	Cluster         string       `json:",omitempty"`
+	HiddenFiles     []string     `json:",omitempty"`
+	HiddenGroups    []string     `json:",omitempty"`
+	XTransforms     []string     `json:",omitempty"`
+	XLB             *float64     `json:",omitempty"`
+	XUB             *float64     `json:",omitempty"`
+	Fitter          string       `json:",omitempty"`
+	Lambda          string       `json:",omitempty"`
+	Annotations     []annotation `json:",omitempty"`
+}
+
+// validate checks that the plotter could use the configuration.
+func (c viewConfig) validate() error {
+	if c.NRE != "" {
+		if _, err := compileNRE(c.NRE); err != nil {
+			return fmt.Errorf("invalid N pattern %q: %v", c.NRE, err)
+		}
+	}
+	if _, err := compileFilter(c.Filter, c.FilterRegexp); err != nil {
+		return err
+	}
+	if c.YVar != "" {
+		if _, ok := validYs[c.YVar]; !ok {
+			return fmt.Errorf("invalid response: %s", c.YVar)
+		}
+	}
+	for _, xt := range c.XTransforms {
+		if _, err := parseTransform(xt); err != nil {
+			return fmt.Errorf("invalid model %q: %v", xt, err)
+		}
+	}
+	for _, a := range c.Annotations {
+		if math.IsNaN(a.N) || math.IsInf(a.N, 0) || a.Label == "" {
+			return fmt.Errorf("invalid annotation at %g: %q", a.N, a.Label)
+		}
+	}
+	return nil
+}
+
+// values returns the settings of the configuration as the querystring of
+// the analysis endpoints, so that they can be grouped and fit the same way
+// as in the plotter.
+func (c viewConfig) values() url.Values {
+	v := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+	set("nre", c.NRE)
+	set("unparameterized", c.Unparameterized)
+	set("pkg", c.Package)
+	set("machine", c.Machine)
+	set("filter", c.Filter)
+	set("aggregate", c.Aggregate)
+	set("cluster", c.Cluster)
+	set("yvar", c.YVar)
+	set("fitter", c.Fitter)
+	set("lambda", c.Lambda)
+	if c.GroupByPackage {
+		v.Set("bypkg", "true")
+	}
+	if c.GroupByMachine {
+		v.Set("bymachine", "true")
+	}
+	if c.GroupByFile {
+		v.Set("byfile", "true")
+	}
+	if c.FilterRegexp {
+		v.Set("filterre", "true")
+	}
+	if len(c.XTransforms) > 0 {
+		v.Set("xtransform", c.XTransforms[0])
+	}
+	if len(c.HiddenFiles) > 0 {
+		v["hide"] = c.HiddenFiles
+	}
+	if len(c.HiddenGroups) > 0 {
+		v["hidegroup"] = c.HiddenGroups
+	}
+	return v
+}
+
+// view is a named configuration of the plotter, so that a team can keep a
+// set of standard dashboards.
+type view struct {
+	Name   string
+	Config viewConfig
+	Saved  time.Time
+}
+
+type byViewName []view
+
+func (v byViewName) Len() int           { return len(v) }
+func (v byViewName) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
+func (v byViewName) Less(i, j int) bool { return v[i].Name < v[j].Name }
+
+// viewStore holds the saved views, ordered by name.  If it has a path, they
+// are written to it whenever they change, and otherwise they're only kept
+// in memory.
+type viewStore struct {
+	path string
+
+	mu    sync.Mutex
+	views []view
+}
+
+// openViews reads the views in the file at path, if it exists.
+func openViews(path string) (*viewStore, error) {
+	s := &viewStore{path: path}
+	if path == "" {
+		return s, nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &s.views); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	sort.Sort(byViewName(s.views))
+	return s, nil
+}
+
+// all returns a copy of the views.
+func (s *viewStore) all() []view {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]view{}, s.views...)
+}
+
+// get returns the view with the name.
+func (s *viewStore) get(name string) (view, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range s.views {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return view{}, false
+}
+
+// put saves the view, replacing any with the same name.
+func (s *viewStore) put(v view) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	views := []view{v}
+	for _, old := range s.views {
+		if old.Name != v.Name {
+			views = append(views, old)
+		}
+	}
+	sort.Sort(byViewName(views))
+	return s.save(views)
+}
+
+// remove removes the view with the name, and reports whether there was one.
+func (s *viewStore) remove(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var views []view
+	for _, v := range s.views {
+		if v.Name != name {
+			views = append(views, v)
+		}
+	}
+	if len(views) == len(s.views) {
+		return false, nil
+	}
+	return true, s.save(views)
+}
+
+// save replaces the views, after writing them to the file if there is one,
+// so that a view is only reported as saved if it will outlast the server.
+// s.mu must be held.
+func (s *viewStore) save(views []view) error {
+	if s.path != "" {
+		b, err := json.MarshalIndent(views, "", "\t")
+		if err != nil {
+			return err
+		}
+		tmp := s.path + ".tmp"
+		if err := ioutil.WriteFile(tmp, b, 0666); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, s.path); err != nil {
+			return err
+		}
+	}
+	s.views = views
+	return nil
+}
+
+// viewsHTML lists the views, with links which open the plotter with them.
+var viewsHTML = template.Must(template.New("views").Parse(`<!DOCTYPE html>
+<html lang="en">
+	<head>
+		<meta charset="utf-8">
+		<title>go benchplot views</title>
+		<style type="text/css">
+      body { font: 12px sans-serif; }
+      td { padding: 2px 12px 2px 0; }
+		</style>
+	</head>
+	<body>
+		<h3>views</h3>
+		{{if .}}<table>
+			{{range .}}<tr><td><a href="./?view={{.Name}}">{{.Name}}</a></td><td>{{.Saved.Format "2006-01-02 15:04"}}</td></tr>
+			{{end}}</table>
+		{{else}}<p>No views have been saved.  Save one from the <a href="./">plotter</a>.</p>
+		{{end}}
+	</body>
+</html>
+`))
+
+// serveViews lists the views on a GET, as a page of links, or as JSON with
+// format=json, or only the one named name.  A POST saves the configuration
+// in its body as the view named name, and a DELETE removes it.
+func serveViews(s *viewStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the body is the view, so the parameters are only in the URL
+		query := r.URL.Query()
+		name := query.Get("name")
+		switch r.Method {
+		case "GET", "HEAD":
+			if name != "" {
+				v, ok := s.get(name)
+				if !ok {
+					http.Error(w, "no view named "+name, http.StatusNotFound)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(v)
+				return
+			}
+			if query.Get("format") == "json" {
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(s.all())
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			viewsHTML.Execute(w, s.all())
+		case "POST":
+			if !viewNameRE.MatchString(name) {
+				http.Error(w, "invalid view name: "+name, http.StatusBadRequest)
+				return
+			}
+			var c viewConfig
+			dec := json.NewDecoder(io.LimitReader(r.Body, maxViewSize))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&c); err != nil {
+				http.Error(w, "invalid view: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := c.validate(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			v := view{Name: name, Config: c, Saved: time.Now().UTC()}
+			if err := s.put(v); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v)
+		case "DELETE":
+			ok, err := s.remove(name)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if !ok {
+				http.Error(w, "no view named "+name, http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(s.all())
+		default:
+			http.Error(w, "views requires a GET, POST, or DELETE", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// viewPage returns the plotter page with the named view in place of
+// viewVar, so that it starts with the view's settings.
+func viewPage(page string, s *viewStore, name string) (string, error) {
+	v, ok := s.get(name)
+	if !ok {
+		return "", fmt.Errorf("no view named %s", name)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return strings.Replace(page, viewVar, "var view = "+string(b), 1), nil
+}
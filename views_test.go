@@ -0,0 +1,101 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServeViews(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "views.json")
+
+	s, err := openViews(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := serveViews(s)
+	do := func(method, query, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(method, "/views?"+query, strings.NewReader(body)))
+		return w
+	}
+
+	tests := []struct {
+		method, query, body string
+		code                int
+	}{
+		{"POST", "name=sorts", `{"NRE": "BenchmarkSort/{N:int}", "XTransforms": ["N*math.Log(N), 1.0"], "Annotations": [{"N": 4096, "Label": "page size"}]}`, http.StatusOK},
+		{"POST", "name=allocs", `{"YVar": "AllocsPerOp"}`, http.StatusOK},
+		{"POST", "name=sorts", `{"NRE": "BenchmarkSort(\\d+)"}`, http.StatusOK},
+		{"POST", "name=", `{}`, http.StatusBadRequest},
+		{"POST", "name=..%2Fetc", `{}`, http.StatusBadRequest},
+		{"POST", "name=bad", `{"YVar": "Furlongs"}`, http.StatusBadRequest},
+		{"POST", "name=bad", `{"XTransforms": ["N +"]}`, http.StatusBadRequest},
+		{"POST", "name=bad", `{"Colour": "red"}`, http.StatusBadRequest},
+		{"POST", "name=bad", `[]`, http.StatusBadRequest},
+		{"GET", "name=bad", "", http.StatusNotFound},
+		{"DELETE", "name=bad", "", http.StatusNotFound},
+		{"PUT", "name=bad", "", http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		if w := do(test.method, test.query, test.body); w.Code != test.code {
+			t.Errorf("%s %s %s: got status %d, want %d: %s", test.method, test.query, test.body, w.Code, test.code, w.Body.String())
+		}
+	}
+
+	// the views are listed by name, and the second post replaced the first
+	var views []view
+	if err := json.NewDecoder(do("GET", "format=json", "").Body).Decode(&views); err != nil {
+		t.Fatal(err)
+	}
+	if len(views) != 2 || views[0].Name != "allocs" || views[1].Name != "sorts" || views[1].Config.NRE != `BenchmarkSort(\d+)` {
+		t.Fatalf("got views %+v, want allocs and the second sorts", views)
+	}
+	if w := do("GET", "", ""); !strings.Contains(w.Body.String(), `href="./?view=allocs"`) {
+		t.Errorf("the views page doesn't link to allocs:\n%s", w.Body.String())
+	}
+
+	// the views outlast the server
+	if s, err = openViews(fn); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.all(); len(got) != 2 {
+		t.Errorf("got %d views after reopening, want 2", len(got))
+	}
+
+	// the plotter starts with the view's settings
+	page, err := viewPage(plotHTML, s, "allocs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(page, viewVar) || !strings.Contains(page, `"YVar":"AllocsPerOp"`) {
+		t.Errorf("the plotter wasn't given the view")
+	}
+	if _, err := viewPage(plotHTML, s, "missing"); err == nil {
+		t.Errorf("opening a missing view didn't fail")
+	}
+
+	if w := do("DELETE", "name=allocs", ""); w.Code != http.StatusOK {
+		t.Errorf("got status %d deleting a view, want %d", w.Code, http.StatusOK)
+	}
+	if s, err = openViews(fn); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.get("allocs"); ok {
+		t.Errorf("the deleted view is still saved")
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"runtime"
+)
+
+// fitJSON fits the benchmarks in body, with the parameters in query, in the
+// same way as /fit.  It returns the fit as JSON, or an object with an Error.
+// It is how the WebAssembly build fits in the browser.
+func fitJSON(query string, body []byte) []byte {
+	v, err := url.ParseQuery(query)
+	if err != nil {
+		return fitErrorJSON(err.Error())
+	}
+	req, err := parseFitRequest(v)
+	if err != nil {
+		return fitErrorJSON(err.Error())
+	}
+	var benchSet []benchmarkResponse
+	if err := json.Unmarshal(body, &benchSet); err != nil {
+		return fitErrorJSON(err.Error())
+	}
+	if !canFit(benchSet, len(req.xTransform)) {
+		return fitErrorJSON("not enough benchmarks to fit")
+	}
+	res, err := fit(context.Background(), benchSet, req)
+	if err != nil {
+		return fitErrorJSON(err.Error())
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		return fitErrorJSON(err.Error())
+	}
+	return b
+}
+
+func fitErrorJSON(msg string) []byte {
+	b, _ := json.Marshal(struct{ Error string }{msg})
+	return b
+}
+
+// wasmVar is the declaration of the WebAssembly build in the plotter.
+// Reports can replace it with the build, along with the go support code
+// that runs it, so that they can be refit without a server.
+const wasmVar = "var benchplotWasm = null"
+
+// readWASM returns the WebAssembly build of benchplot at path, encoded in
+// base64, and the support code from the go installation that runs it.
+func readWASM(path string) (wasm, support string, err error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	// wasm_exec.js moved from misc/wasm to lib/wasm in go 1.24
+	for _, dir := range []string{"lib", "misc"} {
+		s, err := ioutil.ReadFile(filepath.Join(runtime.GOROOT(), dir, "wasm", "wasm_exec.js"))
+		if err == nil {
+			return base64.StdEncoding.EncodeToString(b), string(s), nil
+		}
+	}
+	return "", "", errors.New("unable to find wasm_exec.js in " + runtime.GOROOT())
+}
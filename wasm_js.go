@@ -0,0 +1,28 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"syscall/js"
+)
+
+// hangup is nil, since there are no signals in the browser.
+var hangup os.Signal
+
+// terminate is empty, since there are no signals in the browser.
+var terminate []os.Signal
+
+// serveWASM makes fitJSON available to the plotter as
+// benchplotFit(query, benchmarks), and then waits for it to be called.
+func serveWASM() {
+	js.Global().Set("benchplotFit", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if len(args) != 2 {
+			return string(fitErrorJSON("benchplotFit takes a query and the benchmarks"))
+		}
+		return string(fitJSON(args[0].String(), []byte(args[1].String())))
+	}))
+	select {}
+}
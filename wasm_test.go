@@ -0,0 +1,67 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFitJSON(t *testing.T) {
+	body, err := json.Marshal(linearBenchSet(3, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res fitResponse
+	if err := json.Unmarshal(fitJSON("xtransform=N,1.0&yvar=NsPerOp&xlb=10&xub=60&nlinesteps=6", body), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.ResultLine) != 6 || len(res.ResultModel) != 2 {
+		t.Errorf("got %d points and %d terms, want 6 and 2", len(res.ResultLine), len(res.ResultModel))
+	}
+
+	for _, test := range []struct {
+		query, body string
+	}{
+		{"xtransform=N%2B&yvar=NsPerOp&xlb=10&xub=60&nlinesteps=6", string(body)},
+		{"xtransform=N,1.0&yvar=NsPerOp&xlb=10&xub=60&nlinesteps=6", "[{"},
+		{"xtransform=N,1.0&yvar=NsPerOp&xlb=10&xub=60&nlinesteps=6", "[]"},
+	} {
+		var e struct{ Error string }
+		if err := json.Unmarshal(fitJSON(test.query, []byte(test.body)), &e); err != nil || e.Error == "" {
+			t.Errorf("%s with %.10s: got %v, want an error", test.query, test.body, err)
+		}
+	}
+}
+
+func TestReadWASM(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "benchplot.wasm")
+	if err := ioutil.WriteFile(path, []byte("\x00asm"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	wasm, support, err := readWASM(path)
+	if err != nil {
+		t.Skip(err)
+	}
+	if wasm != base64.StdEncoding.EncodeToString([]byte("\x00asm")) {
+		t.Errorf("got %q, want the build in base64", wasm)
+	}
+	if !strings.Contains(support, "class Go") && !strings.Contains(support, "Go =") {
+		t.Errorf("the support code doesn't define Go")
+	}
+	if _, _, err := readWASM(filepath.Join(dir, "missing.wasm")); err == nil {
+		t.Errorf("readWASM didn't fail for a missing build")
+	}
+}
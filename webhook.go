@@ -0,0 +1,220 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// webhookTimeout is how long to wait for a webhook to respond.
+const webhookTimeout = 10 * time.Second
+
+// webhookPayload is posted to the webhooks when a run regresses against the
+// baseline.  Text summarizes it, so that it can be posted directly to a Slack
+// incoming webhook, which requires it to be named text.
+type webhookPayload struct {
+	Text        string `json:"text"`
+	Baseline    string // series of the baseline run
+	Run         string // series of the new run
+	URL         string `json:",omitempty"` // of the plotter
+	Regressions []webhookRegression
+}
+
+// webhookRegression is a regressed group, with a link to the plotter
+// showing only that group, in both runs.
+type webhookRegression struct {
+	checkResult
+	Permalink string `json:",omitempty"`
+}
+
+// notifier posts regressions to webhooks.
+type notifier struct {
+	hooks  []string
+	url    string // of the plotter, for links in the notifications
+	client *http.Client
+}
+
+func newNotifier(hooks []string, url string) *notifier {
+	return &notifier{hooks: hooks, url: url, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+// payload returns the notification of the regressions in rep, and false if
+// there aren't any.
+func (n *notifier) payload(baseline, run string, rep checkReport) (webhookPayload, bool) {
+	p := webhookPayload{Baseline: baseline, Run: run, URL: n.url}
+	lines := []string{fmt.Sprintf("benchplot: %s regressed against %s", run, baseline)}
+	for _, res := range rep.Results {
+		if !res.Regression && !res.ComplexityRegression {
+			continue
+		}
+		reg := webhookRegression{checkResult: res, Permalink: n.permalink(res.Group)}
+		p.Regressions = append(p.Regressions, reg)
+		line := fmt.Sprintf("• %s: %+.1f%% at N=%g", res.Group, 100*res.Delta, res.N)
+		if res.ComplexityRegression {
+			line += ", " + res.complexityChange()
+		}
+		if reg.Permalink != "" {
+			line += " " + reg.Permalink
+		}
+		lines = append(lines, line)
+	}
+	p.Text = strings.Join(lines, "\n")
+	return p, len(p.Regressions) > 0
+}
+
+// permalink returns the URL of the plotter filtered to the group in every
+// series, like "run 1: BenchmarkSort" and "run 2: BenchmarkSort", or "" if
+// the URL of the plotter isn't known.
+func (n *notifier) permalink(group string) string {
+	if n.url == "" {
+		return ""
+	}
+	u, err := url.Parse(n.url)
+	if err != nil {
+		return n.url
+	}
+	q := u.Query()
+	q.Set("filter", "(^|: )"+regexp.QuoteMeta(group)+"$")
+	q.Set("filterre", "true")
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// notify posts the regressions in rep, if there are any, to every webhook.
+// Failures are returned, but don't stop the remaining webhooks.
+func (n *notifier) notify(baseline, run string, rep checkReport) []error {
+	p, ok := n.payload(baseline, run, rep)
+	if !ok {
+		return nil
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	for _, hook := range n.hooks {
+		resp, err := n.client.Post(hook, "application/json", bytes.NewReader(b))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			errs = append(errs, fmt.Errorf("POST %s: %s", hook, resp.Status))
+		}
+	}
+	return errs
+}
+
+// regressionCheck checks new runs against a baseline, and posts their
+// regressions to the webhooks.
+type regressionCheck struct {
+	q         analysisQuery
+	threshold float64
+	notifier  *notifier
+
+	// baseline picks the stored run that new runs are checked against, the
+	// latest which it matches, if stored is true.
+	baseline selector
+	stored   bool
+}
+
+var (
+	checkOnce sync.Once
+	checker   *regressionCheck
+	checkErr  error
+)
+
+// regressionChecks returns the check of the -webhook, -threshold, -url, and
+// -baseline flags, or nil if there aren't any webhooks.  It is made once,
+// and shared by every plotter.
+func regressionChecks() (*regressionCheck, error) {
+	checkOnce.Do(func() {
+		if len(webhooks) == 0 {
+			return
+		}
+		if *threshold < 0 {
+			checkErr = fmt.Errorf("invalid threshold: %g", *threshold)
+			return
+		}
+		c := &regressionCheck{threshold: *threshold, notifier: newNotifier(webhooks, *plotURL)}
+		if c.q, checkErr = parseAnalysisQuery(url.Values{}); checkErr != nil {
+			return
+		}
+		if c.baseline, checkErr = parseSelector(*baselineSel); checkErr != nil {
+			return
+		}
+		c.stored = *baselineSel != ""
+		checker = c
+	})
+	return checker, checkErr
+}
+
+// check compares run with baseline, and posts any regressions.  They're
+// compared without their series, which would otherwise be part of the name
+// of every group, so that each group is compared with itself.
+func (c *regressionCheck) check(baseline, run benchFile) []error {
+	old, new := baseline, run
+	old.Series, new.Series = "", ""
+	rep := checkGroups(c.q, dataSet{Files: []benchFile{old}}, dataSet{Files: []benchFile{new}}, c.threshold)
+	return c.notifier.notify(baseline.Series, run.Series, rep)
+}
+
+// storedBaseline returns the latest run in db which the baseline selector
+// matches, of those stored before the time before, if it isn't zero.
+func (c *regressionCheck) storedBaseline(db *historyDB, before time.Time) (storedRun, bool, error) {
+	runs, err := db.runs()
+	if err != nil {
+		return storedRun{}, false, err
+	}
+	var latest storedRun
+	ok := false
+	for _, sr := range selectRuns(runs, c.baseline) {
+		if (before.IsZero() || sr.Time.Before(before)) && (!ok || sr.Time.After(latest.Time)) {
+			latest, ok = sr, true
+		}
+	}
+	return latest, ok, nil
+}
+
+// checkStored checks a run which was stored in db against the latest run
+// stored before it that the baseline selector matches, which is the previous
+// run if -baseline isn't set.  The first run has nothing to be checked
+// against.
+func (c *regressionCheck) checkStored(db *historyDB, sr storedRun) []error {
+	baseline, ok, err := c.storedBaseline(db, sr.Time)
+	if err != nil {
+		return []error{err}
+	}
+	if !ok {
+		return nil
+	}
+	return c.check(baseline.File, sr.File)
+}
+
+// checkRerun checks a rerun of benchplot run against the stored baseline,
+// if there is a database and -baseline was given, or otherwise against the
+// first run.
+func (c *regressionCheck) checkRerun(db *historyDB, first, run benchFile) []error {
+	if db != nil && c.stored {
+		sr, ok, err := c.storedBaseline(db, time.Time{})
+		if err != nil {
+			return []error{err}
+		}
+		if !ok {
+			return []error{fmt.Errorf("no stored run matches the baseline %s", *baselineSel)}
+		}
+		first = sr.File
+	}
+	return c.check(first, run)
+}
@@ -0,0 +1,137 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNotify(t *testing.T) {
+	var got []webhookPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Error(err)
+		}
+		got = append(got, p)
+	}))
+	defer ts.Close()
+
+	n := newNotifier([]string{ts.URL}, "http://localhost:6060/")
+	ok := checkReport{Results: []checkResult{{Group: "BenchmarkFoo"}}}
+	if errs := n.notify("run 1", "run 2", ok); len(errs) != 0 || len(got) != 0 {
+		t.Fatalf("got %d notifications and errors %v without a regression", len(got), errs)
+	}
+
+	bad := checkReport{Results: []checkResult{
+		{Group: "BenchmarkFoo"},
+		{Group: "BenchmarkBar", N: 1000, Delta: 0.25, Regression: true},
+	}}
+	if errs := n.notify("run 1", "run 3", bad); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if len(got) != 1 || len(got[0].Regressions) != 1 || got[0].Run != "run 3" {
+		t.Fatalf("got notifications %+v, want one for BenchmarkBar", got)
+	}
+	for _, s := range []string{"BenchmarkBar: +25.0% at N=1000", "http://localhost:6060/"} {
+		if !strings.Contains(got[0].Text, s) {
+			t.Errorf("notification %q is missing %q", got[0].Text, s)
+		}
+	}
+}
+
+// benchRun returns a run of BenchmarkSort in the series, taking scale ns per
+// item sorted.
+func benchRun(t *testing.T, series string, scale float64) benchFile {
+	var in string
+	for _, n := range []int{10, 100, 1000, 10000} {
+		in += fmt.Sprintf("BenchmarkSort%d-4\t1000\t%g ns/op\n", n, scale*float64(n)+50)
+	}
+	bf, err := parseBenchFile(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bf.Path, bf.Series = "go test -bench=.", series
+	return bf
+}
+
+func TestRunSourceCheck(t *testing.T) {
+	got := make(chan webhookPayload, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Error(err)
+		}
+		got <- p
+	}))
+	defer ts.Close()
+
+	q, err := parseAnalysisQuery(url.Values{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &regressionCheck{q: q, threshold: 0.1, notifier: newNotifier([]string{ts.URL}, "http://localhost:6060/")}
+	var db *historyDB
+	rs := &runSource{check: func(first, run benchFile) {
+		if errs := c.checkRerun(db, first, run); len(errs) != 0 {
+			t.Error(errs)
+		}
+	}}
+
+	// a rerun as fast as the first run isn't a regression
+	rs.runs = []benchFile{benchRun(t, "run 1", 10), benchRun(t, "run 2", 10)}
+	rs.finish(1, nil)
+	if len(got) != 0 {
+		t.Fatalf("got %+v without a regression", <-got)
+	}
+
+	// a slower rerun is, even though its groups are named by its series
+	rs.runs = append(rs.runs, benchRun(t, "run 3", 20))
+	rs.finish(2, nil)
+	if len(got) != 1 {
+		t.Fatalf("got %d notifications of a regression, want 1", len(got))
+	}
+	p := <-got
+	if p.Baseline != "run 1" || p.Run != "run 3" || len(p.Regressions) != 1 || p.Regressions[0].Group != "BenchmarkSort" {
+		t.Fatalf("got notification %+v, want BenchmarkSort regressing in run 3", p)
+	}
+	if link := p.Regressions[0].Permalink; !strings.HasPrefix(link, "http://localhost:6060/?filter=") || !strings.Contains(p.Text, link) {
+		t.Errorf("got permalink %q in %q, want one filtering the plotter to BenchmarkSort", link, p.Text)
+	}
+
+	// with -baseline, reruns are checked against the stored run instead, so
+	// a rerun as slow as the first run can still be a regression
+	dir, err := ioutil.TempDir("", "benchplot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if db, err = openHistory(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.add(benchRun(t, "", 5), map[string]string{"branch": "main"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.baseline, err = parseSelector("branch=main"); err != nil {
+		t.Fatal(err)
+	}
+	c.stored = true
+	rs.runs = rs.runs[:2]
+	rs.finish(1, nil)
+	if len(got) != 1 {
+		t.Fatalf("got %d notifications of a regression against the stored run, want 1", len(got))
+	}
+	if p := <-got; p.Run != "run 2" || len(p.Regressions) != 1 {
+		t.Errorf("got notification %+v, want run 2 regressing against the stored run", p)
+	}
+}
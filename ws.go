@@ -0,0 +1,183 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// Types of events sent over the websocket at /ws.
+const (
+	eventData  = "data"  // Data holds the complete benchmark data
+	eventParse = "parse" // Benchmark was parsed into the File'th file
+	eventFit   = "fit"   // Fit holds the result of the fit request with ID
+)
+
+// event is a message sent from the server to the plotter.
+type event struct {
+	Type string
+
+	Data *dataSet `json:",omitempty"`
+
+	File      int
+	Benchmark *benchmark `json:",omitempty"`
+
+	ID  int          `json:",omitempty"`
+	Fit *fitResponse `json:",omitempty"`
+	Err string       `json:",omitempty"`
+}
+
+// wsFitRequest is a message sent from the plotter to the server, asking it to
+// fit the benchmarks with the parameters in Query, which has the same form as
+// the querystring of /fit.
+type wsFitRequest struct {
+	ID         int
+	Query      string
+	Benchmarks []benchmarkResponse
+}
+
+// eventHub distributes events to all of the connected plotters.
+type eventHub struct {
+	mu   sync.Mutex
+	subs map[chan event]struct{}
+}
+
+// events is where data sources publish changes to their data.  Projects
+// have their own.
+var events = newEventHub()
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[chan event]struct{})}
+}
+
+// eventBuffer is the number of events which can be queued for a single
+// plotter.  Events are dropped for plotters that fall further behind.
+const eventBuffer = 64
+
+func (h *eventHub) subscribe() chan event {
+	c := make(chan event, eventBuffer)
+	h.mu.Lock()
+	h.subs[c] = struct{}{}
+	h.mu.Unlock()
+	return c
+}
+
+func (h *eventHub) unsubscribe(c chan event) {
+	h.mu.Lock()
+	delete(h.subs, c)
+	h.mu.Unlock()
+}
+
+// publish sends the event to every subscriber, without waiting for them.
+func (h *eventHub) publish(e event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.subs {
+		select {
+		case c <- e:
+		default:
+		}
+	}
+}
+
+// serveEvents returns a websocket handler which sends the current data from
+// src when a plotter connects, followed by every event published to hub.  It
+// also answers fit requests sent by the plotter.
+func serveEvents(src dataSource, hub *eventHub) websocket.Handler {
+	return func(ws *websocket.Conn) {
+		ws.MaxPayloadBytes = maxFitBytes
+		c := hub.subscribe()
+		defer hub.unsubscribe(c)
+
+		// Fit requests are read and evaluated in their own goroutine, so that
+		// all of the writes to the websocket happen here.
+		replies := make(chan event)
+		streams := newFitStreams()
+		done := make(chan struct{})
+		quit := make(chan struct{})
+		defer close(quit)
+		go func() {
+			defer close(done)
+			for {
+				var req wsFitRequest
+				if err := websocket.JSON.Receive(ws, &req); err != nil {
+					return
+				}
+				select {
+				case replies <- wsFit(ws.Request().Context(), streams, req):
+				case <-quit:
+					return
+				}
+			}
+		}()
+
+		ds := src.dataSet()
+		if err := websocket.JSON.Send(ws, event{Type: eventData, Data: &ds}); err != nil {
+			return
+		}
+		for {
+			var e event
+			select {
+			case e = <-c:
+			case e = <-replies:
+			case <-done:
+				return
+			}
+			if err := websocket.JSON.Send(ws, e); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// wsFit evaluates a fit request sent over the websocket, in the fit pool and
+// with as long as a request to /fit would have.
+func wsFit(ctx context.Context, streams *fitStreams, req wsFitRequest) event {
+	ctx, cancel := withFitTimeout(ctx)
+	defer cancel()
+	if err := fitLimit().acquire(ctx); err != nil {
+		return event{Type: eventFit, ID: req.ID, Err: err.Error()}
+	}
+	defer fitLimit().release()
+	res, err := wsFitResponse(ctx, streams, req)
+	if err != nil {
+		return event{Type: eventFit, ID: req.ID, Err: err.Error()}
+	}
+	return event{Type: eventFit, ID: req.ID, Fit: &res}
+}
+
+// wsFitResponse fits the benchmarks in a request.  Ordinary least squares
+// fits are made incrementally with streams, if it isn't nil, since while
+// benchmarks are streaming in the plotter asks for the same fits again with
+// a few more benchmarks each time.
+func wsFitResponse(ctx context.Context, streams *fitStreams, req wsFitRequest) (fitResponse, error) {
+	v, err := url.ParseQuery(req.Query)
+	if err != nil {
+		return fitResponse{}, err
+	}
+	fr, err := parseFitRequest(v)
+	if err != nil {
+		return fitResponse{}, err
+	}
+	if !canFit(req.Benchmarks, len(fr.xTransform)) {
+		return fitResponse{}, errors.New("not enough benchmarks to fit")
+	}
+	b, err := json.Marshal(req.Benchmarks)
+	if err != nil {
+		return fitResponse{}, err
+	}
+	key := fitKey(v, b)
+	if res, ok := fitResults.get(key); ok {
+		return res, nil
+	}
+	fr.fitter = streams.fitter(fr, req.Benchmarks)
+	return fitCached(ctx, key, req.Benchmarks, fr)
+}
@@ -0,0 +1,123 @@
+// Copyright ©2016 Jonathan J Lawlor. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestServeEvents(t *testing.T) {
+	bf, err := parseBenchFile(strings.NewReader("BenchmarkSort10-4\t1000\t100 ns/op\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := newEventHub()
+	srv := httptest.NewServer(serveEvents(fixedSource{Files: []benchFile{bf}}, hub))
+	defer srv.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), "", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive := func() event {
+		ws.SetReadDeadline(time.Now().Add(10 * time.Second))
+		var e event
+		if err := websocket.JSON.Receive(ws, &e); err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+
+	// the current data is sent first
+	if e := receive(); e.Type != eventData || e.Data == nil || len(e.Data.Files) != 1 {
+		t.Fatalf("got %+v, want the data", e)
+	}
+
+	// then the published events
+	hub.publish(event{Type: eventParse, File: 2, Benchmark: bf.Benchmarks[0]})
+	if e := receive(); e.Type != eventParse || e.File != 2 || e.Benchmark == nil || e.Benchmark.Name != "BenchmarkSort10-4" {
+		t.Errorf("got %+v, want the published parse event", e)
+	}
+
+	// and the answers to fit requests, with their IDs
+	var bs []benchmarkResponse
+	if err := json.Unmarshal([]byte(`[{"Name": "BenchmarkSort10", "N": 1000, "NsPerOp": 100, "X": 10},
+		{"Name": "BenchmarkSort100", "N": 1000, "NsPerOp": 1000, "X": 100},
+		{"Name": "BenchmarkSort1000", "N": 1000, "NsPerOp": 10500, "X": 1000}]`), &bs); err != nil {
+		t.Fatal(err)
+	}
+	const good = "xlb=10&xub=1000&xtransform=N,1.0&yvar=NsPerOp&nlinesteps=10"
+	tests := []struct {
+		req   wsFitRequest
+		fails bool
+	}{
+		{wsFitRequest{ID: 1, Query: good, Benchmarks: bs}, false},
+		{wsFitRequest{ID: 2, Query: strings.Replace(good, "xlb=10", "xlb=x", 1), Benchmarks: bs}, true},
+		{wsFitRequest{ID: 3, Query: good, Benchmarks: bs[:1]}, true},
+		{wsFitRequest{ID: 4, Query: strings.Replace(good, "nlinesteps=10", "nlinesteps=1000000000", 1), Benchmarks: bs}, true},
+	}
+	for _, test := range tests {
+		if err := websocket.JSON.Send(ws, test.req); err != nil {
+			t.Fatal(err)
+		}
+		e := receive()
+		if e.Type != eventFit || e.ID != test.req.ID {
+			t.Errorf("request %d: got %+v, want its fit", test.req.ID, e)
+			continue
+		}
+		if test.fails != (e.Err != "") || test.fails != (e.Fit == nil) {
+			t.Errorf("request %d: got fit %v and error %q, want failure %v", test.req.ID, e.Fit, e.Err, test.fails)
+		}
+		if e.Fit != nil && len(e.Fit.ResultLine) != 10 {
+			t.Errorf("request %d: got %d points of the line, want 10", test.req.ID, len(e.Fit.ResultLine))
+		}
+	}
+
+	// plotters which disconnect are unsubscribed
+	ws.Close()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		hub.mu.Lock()
+		n := len(hub.subs)
+		hub.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d plotters are still subscribed after disconnecting", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEventHub(t *testing.T) {
+	hub := newEventHub()
+	a, b := hub.subscribe(), hub.subscribe()
+	hub.publish(event{Type: eventParse, File: 1})
+	for _, c := range []chan event{a, b} {
+		if e := <-c; e.File != 1 {
+			t.Errorf("got %+v, want the published event", e)
+		}
+	}
+
+	// events for subscribers which fall behind are dropped, rather than
+	// blocking the publisher
+	hub.unsubscribe(b)
+	for i := 0; i < eventBuffer+10; i++ {
+		hub.publish(event{Type: eventParse, File: i})
+	}
+	if len(a) != eventBuffer {
+		t.Errorf("got %d queued events, want %d", len(a), eventBuffer)
+	}
+	if len(b) != 0 {
+		t.Errorf("got %d events after unsubscribing", len(b))
+	}
+}